@@ -0,0 +1,331 @@
+// Package app dựng ứng dụng fiber đầy đủ từ các thành phần của dd_goshare:
+// config, database + migrate, RBAC, Firebase, middleware mặc định, route, đồng bộ rule và tắt an toàn.
+//
+//	a, err := app.New(
+//		app.WithRBAC(rbac.NewConfig()),
+//		app.WithFirebase(),
+//		app.WithHealth(""),
+//		app.WithRoutes(func(a *app.App) error {
+//			api := a.Fiber.Group("/api")
+//			rbac.Get(api, "/topics", false, rbac.PublicRoute(), topicHandler.List)
+//			return nil
+//		}),
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Fatal(a.Run(context.Background()))
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/techmaster-vietnam/dd_goshare/config"
+	"github.com/techmaster-vietnam/dd_goshare/database"
+	"github.com/techmaster-vietnam/dd_goshare/health"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/rbac"
+	"gorm.io/gorm"
+)
+
+// App là ứng dụng đã được dựng xong, sẵn sàng Run
+type App struct {
+	Fiber  *fiber.App
+	Config *config.Config
+	DB     *gorm.DB
+	// Health là checker của readiness probe; nil nếu không dùng WithReadiness
+	Health *health.Checker
+
+	opts           options
+	ownsDB         bool
+	metrics        *metrics
+	stopRevocation context.CancelFunc
+	rateLimitStore io.Closer // storage in-memory do app tạo cho WithUserRateLimiter
+}
+
+// New dựng ứng dụng theo thứ tự: config → database → khóa JWT + denylist token → RBAC → Firebase →
+// fiber + middleware → module → route của service → đồng bộ rule RBAC → hook OnStart
+func New(opts ...Option) (*App, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	a := &App{opts: o}
+
+	if err := a.initConfig(); err != nil {
+		return nil, err
+	}
+	if err := a.initDatabase(); err != nil {
+		return nil, err
+	}
+	if err := a.build(); err != nil {
+		a.stopBackground()
+		a.closeDB()
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *App) initConfig() error {
+	if a.opts.cfg != nil {
+		a.Config = a.opts.cfg
+		return nil
+	}
+	cfg, err := config.Load(a.opts.loadOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	a.Config = cfg
+	return nil
+}
+
+func (a *App) initDatabase() error {
+	if a.opts.db != nil {
+		a.DB = a.opts.db
+		return nil
+	}
+	migrator := a.opts.migrator
+	if migrator == nil {
+		migrator = database.DBMigrator
+	}
+	db, err := database.Init(a.Config.Database, migrator)
+	if err != nil {
+		return err
+	}
+	a.DB = db
+	a.ownsDB = true
+	return nil
+}
+
+func (a *App) build() error {
+	o := &a.opts
+
+	// Nạp khóa JWT ngay khi khởi động để cấu hình sai làm dừng ứng dụng thay vì lỗi ở request đầu tiên
+	if err := middleware.InitJWTKeys(a.Config.JWT); err != nil {
+		return fmt.Errorf("failed to init JWT keys: %w", err)
+	}
+	if o.revocation {
+		if err := a.initTokenRevocation(); err != nil {
+			return err
+		}
+	}
+
+	if o.rbacConfig != nil {
+		if err := rbac.InitRBAC(a.DB, *o.rbacConfig); err != nil {
+			return err
+		}
+	}
+	if o.firebase {
+		if err := middleware.InitFirebaseAuth(a.Config.Firebase); err != nil {
+			return fmt.Errorf("failed to init firebase: %w", err)
+		}
+	}
+
+	fiberConfig := o.fiberConfig
+	if fiberConfig.ErrorHandler == nil {
+		fiberConfig.ErrorHandler = middleware.ErrorHandler()
+	}
+	a.Fiber = fiber.New(fiberConfig)
+
+	a.Fiber.Use(recover.New())
+	if o.metricsPath != "" {
+		a.metrics = newMetrics()
+		a.Fiber.Use(a.metrics.middleware())
+	}
+	if o.security {
+		a.Fiber.Use(middleware.SecurityMiddleware())
+	}
+	if o.logger {
+		a.Fiber.Use(logger.New(middleware.CustomLogger()))
+	}
+	// CheckPermissionMiddleware đọc DB từ Locals khi kiểm tra rule Protected
+	db := a.DB
+	a.Fiber.Use(func(c *fiber.Ctx) error {
+		c.Locals("db", db)
+		return c.Next()
+	})
+	if o.rateLimit != nil {
+		a.Fiber.Use(limiter.New(a.rateLimitConfig()))
+	}
+	if o.userRateLimit != nil {
+		auth, limit := a.userRateLimiter()
+		a.Fiber.Use(auth, limit)
+	}
+	for _, handler := range o.middlewares {
+		a.Fiber.Use(handler)
+	}
+
+	if err := a.registerModules(); err != nil {
+		return err
+	}
+	for _, register := range o.routes {
+		if err := register(a); err != nil {
+			return fmt.Errorf("failed to register routes: %w", err)
+		}
+	}
+
+	if o.rbacConfig != nil {
+		rbac.BuildPublicRoutes(a.Fiber)
+		if err := rbac.SyncRulesToDB(); err != nil {
+			return err
+		}
+		if err := rbac.ReloadRules(); err != nil {
+			return err
+		}
+	}
+
+	for _, hook := range o.onStart {
+		if err := hook(context.Background(), a); err != nil {
+			return fmt.Errorf("start hook failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// initTokenRevocation nạp denylist access token vào AuthMiddleware và đồng bộ định kỳ cho tới Shutdown
+func (a *App) initTokenRevocation() error {
+	tokenTTL := time.Duration(a.Config.JWT.Expiry) * time.Hour
+	store := middleware.NewTokenRevocationStore(repositories.NewTokenRevocationRepository(a.DB), tokenTTL)
+	if err := store.Load(context.Background()); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	store.Start(ctx, a.opts.revocationInterval)
+	a.stopRevocation = cancel
+	middleware.SetRevocationStore(store)
+	return nil
+}
+
+func (a *App) stopBackground() {
+	if a.stopRevocation != nil {
+		a.stopRevocation()
+	}
+	if a.rateLimitStore != nil {
+		a.rateLimitStore.Close()
+	}
+}
+
+// rateLimitConfig bỏ qua health/readiness/metrics để probe của orchestrator không bị giới hạn
+func (a *App) rateLimitConfig() limiter.Config {
+	cfg := *a.opts.rateLimit
+	cfg.Next = a.skipProbes(cfg.Next)
+	return cfg
+}
+
+// userRateLimiter trả về middleware xác định principal và limiter theo user cho WithUserRateLimiter
+func (a *App) userRateLimiter() (fiber.Handler, fiber.Handler) {
+	cfg := *a.opts.userRateLimit
+	cfg.Next = a.skipProbes(cfg.Next)
+	if cfg.Storage == nil {
+		window := cfg.Window
+		if window <= 0 {
+			window = time.Minute
+		}
+		storage := middleware.NewMemoryRateLimitStorage(window)
+		a.rateLimitStore = storage
+		cfg.Storage = storage
+	}
+	if cfg.Resolver == nil && a.opts.userRateLimitPolicy != nil && a.DB != nil {
+		policy := *a.opts.userRateLimitPolicy
+		if policy.DefaultLimit <= 0 {
+			policy.DefaultLimit = cfg.DefaultLimit
+		}
+		cfg.Resolver = middleware.NewDBRateLimitResolver(a.DB, policy)
+	}
+
+	auth := a.opts.userRateLimitAuth
+	if auth == nil {
+		// Chỉ nhận diện principal, không chặn: token sai/hết hạn để route tự xử lý, request bị giới hạn theo IP
+		authenticator := middleware.NewAuthenticator(middleware.NewAppJWTVerifier(a.Config))
+		auth = func(c *fiber.Ctx) error {
+			if principal, err := authenticator.Authenticate(c); err == nil {
+				middleware.SetPrincipal(c, principal)
+			}
+			return c.Next()
+		}
+	}
+	return auth, middleware.UserRateLimiter(cfg)
+}
+
+// skipProbes bỏ qua rate limit cho health/readiness/metrics, sau đó mới xét next
+func (a *App) skipProbes(next func(c *fiber.Ctx) bool) func(c *fiber.Ctx) bool {
+	skip := map[string]bool{}
+	for _, path := range []string{a.opts.healthPath, a.opts.readyPath, a.opts.metricsPath} {
+		if path != "" {
+			skip[path] = true
+		}
+	}
+	return func(c *fiber.Ctx) bool {
+		if skip[c.Path()] {
+			return true
+		}
+		return next != nil && next(c)
+	}
+}
+
+// Addr trả về địa chỉ lắng nghe từ config.Server
+func (a *App) Addr() string {
+	return net.JoinHostPort(a.Config.Server.Host, a.Config.Server.Port)
+}
+
+// Run lắng nghe tại Addr cho tới khi ctx bị huỷ hoặc nhận SIGINT/SIGTERM, rồi Shutdown
+func (a *App) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- a.Fiber.Listen(a.Addr())
+	}()
+
+	select {
+	case err := <-listenErr:
+		// Listen trả về ngay khi không mở được cổng
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), a.opts.shutdownTimeout)
+		defer cancel()
+		return errors.Join(err, a.Shutdown(shutdownCtx))
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.opts.shutdownTimeout)
+	defer cancel()
+	return a.Shutdown(shutdownCtx)
+}
+
+// Shutdown ngừng nhận request mới, chờ request đang xử lý (tối đa tới deadline của ctx),
+// chạy hook OnShutdown theo thứ tự ngược rồi đóng database
+func (a *App) Shutdown(ctx context.Context) error {
+	var errs []error
+	if err := a.Fiber.ShutdownWithContext(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("server shutdown: %w", err))
+	}
+	for i := len(a.opts.onShutdown) - 1; i >= 0; i-- {
+		if err := a.opts.onShutdown[i](ctx, a); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook: %w", err))
+		}
+	}
+	a.stopBackground()
+	a.closeDB()
+	return errors.Join(errs...)
+}
+
+func (a *App) closeDB() {
+	if a.ownsDB && a.DB != nil {
+		database.Close(a.DB)
+	}
+}
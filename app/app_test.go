@@ -0,0 +1,73 @@
+package app
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/config"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+)
+
+func TestUserRateLimiter(t *testing.T) {
+	cfg := &config.Config{JWT: &config.JWTConfig{Algorithm: "HS256", Secret: "test-secret", Expiry: 1}}
+	if err := middleware.InitJWTKeys(cfg.JWT); err != nil {
+		t.Fatal(err)
+	}
+	o := defaultOptions()
+	WithHealth("")(&o)
+	WithUserRateLimiter(nil, middleware.UserRateLimiterConfig{DefaultLimit: 1, Window: time.Minute}, nil)(&o)
+	a := &App{opts: o, Config: cfg}
+	a.Fiber = fiber.New()
+	auth, limit := a.userRateLimiter()
+	a.Fiber.Use(auth, limit)
+	a.Fiber.Get("/*", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	defer a.stopBackground()
+
+	if a.rateLimitStore == nil {
+		t.Fatalf("Expected app to own the default in-memory storage")
+	}
+
+	do := func(path, token string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := a.Fiber.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+	tokenFor := func(userID string) string {
+		token, err := middleware.GenerateToken(userID, userID+"@example.com", cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	// Hai user cùng IP có bộ đếm riêng
+	for _, user := range []string{"u1", "u2"} {
+		token := tokenFor(user)
+		if status := do("/api", token); status != fiber.StatusOK {
+			t.Errorf("Expected first request of %s to pass, got %d", user, status)
+		}
+		if status := do("/api", token); status != fiber.StatusTooManyRequests {
+			t.Errorf("Expected second request of %s to be limited, got %d", user, status)
+		}
+	}
+
+	// Token không hợp lệ không bị chặn ở bước nhận diện mà rơi về bộ đếm theo IP
+	if status := do("/api", "bogus"); status != fiber.StatusOK {
+		t.Errorf("Expected invalid token to be IP-limited rather than rejected, got %d", status)
+	}
+	if status := do("/api", ""); status != fiber.StatusTooManyRequests {
+		t.Errorf("Expected anonymous request to share the IP bucket, got %d", status)
+	}
+
+	if status := do(a.opts.healthPath, ""); status != fiber.StatusOK {
+		t.Errorf("Expected health probe to skip the limiter, got %d", status)
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/rbac"
+	"gorm.io/gorm"
+)
+
+// metrics đếm request theo nhóm status và thời gian xử lý, dùng cho module metrics
+type metrics struct {
+	startedAt    time.Time
+	inFlight     atomic.Int64
+	total        atomic.Int64
+	status2xx    atomic.Int64
+	status3xx    atomic.Int64
+	status4xx    atomic.Int64
+	status5xx    atomic.Int64
+	latencyTotal atomic.Int64 // microseconds
+}
+
+func newMetrics() *metrics {
+	return &metrics{startedAt: time.Now()}
+}
+
+func (m *metrics) middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		m.inFlight.Add(1)
+		start := time.Now()
+		err := c.Next()
+		m.inFlight.Add(-1)
+		m.total.Add(1)
+		m.latencyTotal.Add(time.Since(start).Microseconds())
+
+		status := c.Response().StatusCode()
+		if e, ok := err.(*fiber.Error); ok {
+			status = e.Code
+		} else if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+		switch {
+		case status >= 500:
+			m.status5xx.Add(1)
+		case status >= 400:
+			m.status4xx.Add(1)
+		case status >= 300:
+			m.status3xx.Add(1)
+		default:
+			m.status2xx.Add(1)
+		}
+		return err
+	}
+}
+
+func (m *metrics) handler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		total := m.total.Load()
+		avgLatencyMs := 0.0
+		if total > 0 {
+			avgLatencyMs = float64(m.latencyTotal.Load()) / float64(total) / 1000
+		}
+		data := fiber.Map{
+			"uptime_seconds": int64(time.Since(m.startedAt).Seconds()),
+			"requests": fiber.Map{
+				"total":          total,
+				"in_flight":      m.inFlight.Load(),
+				"status_2xx":     m.status2xx.Load(),
+				"status_3xx":     m.status3xx.Load(),
+				"status_4xx":     m.status4xx.Load(),
+				"status_5xx":     m.status5xx.Load(),
+				"avg_latency_ms": avgLatencyMs,
+			},
+		}
+		if sqlDB, err := db.DB(); err == nil {
+			stats := sqlDB.Stats()
+			data["db_pool"] = fiber.Map{
+				"open_connections": stats.OpenConnections,
+				"in_use":           stats.InUse,
+				"idle":             stats.Idle,
+				"wait_count":       stats.WaitCount,
+				"wait_duration_ms": stats.WaitDuration.Milliseconds(),
+			}
+		}
+		if rbac.GetDB() != nil {
+			data["rbac"] = rbac.GetSystemStats()
+		}
+		return c.JSON(data)
+	}
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/health"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/handlers"
+	"github.com/techmaster-vietnam/dd_goshare/rbac"
+)
+
+// registerModules gắn các module tuỳ chọn đã bật
+func (a *App) registerModules() error {
+	if a.opts.healthPath != "" {
+		a.Fiber.Get(a.opts.healthPath, health.Liveness())
+	}
+	if a.opts.readyPath != "" {
+		a.registerReadiness()
+	}
+	if a.opts.metricsPath != "" {
+		guard, err := a.metricsGuard()
+		if err != nil {
+			return err
+		}
+		a.Fiber.Get(a.opts.metricsPath, append(guard, a.metrics.handler(a.DB))...)
+	}
+	if a.opts.rbacAdminPath != "" {
+		if err := a.registerRBACAdmin(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *App) registerReadiness() {
+	checks := []health.Check{health.Database(a.DB)}
+	if a.opts.rbacConfig != nil {
+		checks = append(checks, health.RBAC())
+	}
+	a.Health = health.NewChecker(append(checks, a.opts.readyChecks...)...)
+	a.Fiber.Get(a.opts.readyPath, a.Health.Handler())
+}
+
+// metricsGuard trả về chuỗi middleware bảo vệ metrics: guard của WithMetrics,
+// mặc định là đăng nhập với HighestRole của RBAC
+func (a *App) metricsGuard() ([]fiber.Handler, error) {
+	if a.opts.metricsGuard != nil {
+		return []fiber.Handler{a.opts.metricsGuard}, nil
+	}
+	if a.opts.rbacConfig == nil {
+		return nil, fmt.Errorf("metrics module requires a guard or WithRBAC")
+	}
+	return []fiber.Handler{
+		middleware.AuthMiddleware(a.Config),
+		rbac.RequireRoles(a.opts.rbacConfig.HighestRole),
+	}, nil
+}
+
+func (a *App) registerRBACAdmin() error {
+	if a.opts.rbacConfig == nil {
+		return fmt.Errorf("RBAC admin module requires WithRBAC")
+	}
+
+	roleNames := a.opts.rbacAdminRoles
+	if len(roleNames) == 0 {
+		roleNames = []string{a.opts.rbacConfig.HighestRole}
+	}
+	roleIDs := make([]int, 0, len(roleNames))
+	for _, name := range roleNames {
+		id, ok := rbac.Roles[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("RBAC admin module: role %q does not exist", name)
+		}
+		roleIDs = append(roleIDs, id)
+	}
+
+	auth := a.opts.rbacAdminAuth
+	if auth == nil {
+		auth = middleware.AuthMiddleware(a.Config)
+	}
+
+	// Helper của rbac tính path rule theo tiền tố /api nên route được đăng ký trên group /api;
+	// middleware xác thực gắn riêng cho tiền tố admin
+	a.Fiber.Use(a.opts.rbacAdminPath, auth)
+	api := a.Fiber.Group("/api")
+	prefix := strings.TrimPrefix(a.opts.rbacAdminPath, "/api")
+
+	h := handlers.NewRBACAdminHandler()
+	allow := rbac.AllowProtected(roleIDs...)
+	rbac.Get(api, prefix+"/stats", true, allow, h.Stats)
+	rbac.Get(api, prefix+"/consistency", true, allow, h.Consistency)
+	rbac.Post(api, prefix+"/reload", true, allow, h.Reload)
+	rbac.Put(api, prefix+"/rules/:id/mfa", true, allow, h.SetRuleRequireMFA)
+	// Tắt require_mfa là tác vụ nhạy cảm nên bản thân route cũng yêu cầu 2FA
+	rbac.RequireMFA(api, "PUT", prefix+"/rules/:id/mfa")
+	return nil
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/config"
+	"github.com/techmaster-vietnam/dd_goshare/rbac"
+)
+
+// newTestApp dựng App chỉ với Fiber và các module, không cần database
+func newTestApp(t *testing.T, opts ...Option) (*App, error) {
+	t.Helper()
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	a := &App{opts: o, Config: &config.Config{JWT: &config.JWTConfig{Algorithm: "HS256", Secret: "test-secret"}}}
+	a.Fiber = fiber.New()
+	a.metrics = newMetrics()
+	return a, a.registerModules()
+}
+
+func TestMetricsGuard(t *testing.T) {
+	t.Run("TestRequiresGuardOrRBAC", func(t *testing.T) {
+		if _, err := newTestApp(t, WithMetrics("", nil)); err == nil {
+			t.Errorf("Expected error when metrics has neither a guard nor RBAC")
+		}
+	})
+
+	cases := []struct {
+		name string
+		opts []Option
+		want int
+	}{
+		{"DefaultRequiresLogin", []Option{WithMetrics("", nil), WithRBAC(rbac.NewConfig())}, fiber.StatusUnauthorized},
+		{"CustomGuard", []Option{WithMetrics("/internal/metrics", func(c *fiber.Ctx) error {
+			return fiber.NewError(fiber.StatusForbidden, "operator only")
+		})}, fiber.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			a, err := newTestApp(t, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := a.Fiber.Test(httptest.NewRequest("GET", a.opts.metricsPath, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tc.want {
+				t.Errorf("Expected status %d, got %d", tc.want, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRBACAdminRequiresMFA(t *testing.T) {
+	rbac.Roles = map[string]int{"admin": 1}
+	if _, err := newTestApp(t, WithRBAC(rbac.NewConfig()), WithRBACAdmin(nil)); err != nil {
+		t.Fatal(err)
+	}
+	route, ok := rbac.GetRouteInfo("/api/admin/rbac/rules/:id/mfa", "PUT")
+	if !ok {
+		t.Fatal("Expected rule MFA route to be registered")
+	}
+	if !route.RequireMFA {
+		t.Errorf("Expected rule MFA route to require MFA")
+	}
+}
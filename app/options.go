@@ -0,0 +1,218 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/techmaster-vietnam/dd_goshare/config"
+	"github.com/techmaster-vietnam/dd_goshare/health"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+	"github.com/techmaster-vietnam/dd_goshare/rbac"
+	"gorm.io/gorm"
+)
+
+// Hook chạy tại một điểm trong vòng đời ứng dụng; lỗi ở OnStart dừng khởi động
+type Hook func(ctx context.Context, a *App) error
+
+// RouteRegistrar đăng ký route của service; chạy trước khi đồng bộ rule RBAC
+type RouteRegistrar func(a *App) error
+
+// Option cấu hình App theo kiểu functional options
+type Option func(*options)
+
+type options struct {
+	cfg         *config.Config
+	loadOptions []config.LoadOption
+	db          *gorm.DB
+	migrator    func(*gorm.DB) error
+	fiberConfig fiber.Config
+
+	security    bool
+	logger      bool
+	rateLimit   *limiter.Config
+	middlewares []fiber.Handler
+
+	rbacConfig *rbac.Config
+	firebase   bool
+
+	revocation         bool
+	revocationInterval time.Duration
+
+	routes     []RouteRegistrar
+	onStart    []Hook
+	onShutdown []Hook
+
+	shutdownTimeout time.Duration
+
+	healthPath     string
+	readyPath      string
+	readyChecks    []health.Check
+	metricsPath    string
+	metricsGuard   fiber.Handler
+	rbacAdminPath  string
+	rbacAdminAuth  fiber.Handler
+	rbacAdminRoles []string
+
+	userRateLimit       *middleware.UserRateLimiterConfig
+	userRateLimitAuth   fiber.Handler
+	userRateLimitPolicy *middleware.RateLimitPolicy
+}
+
+func defaultOptions() options {
+	rateLimit := middleware.RateLimiterConfig()
+	return options{
+		fiberConfig:        fiber.Config{},
+		security:           true,
+		logger:             true,
+		rateLimit:          &rateLimit,
+		revocation:         true,
+		revocationInterval: 30 * time.Second,
+		shutdownTimeout:    15 * time.Second,
+	}
+}
+
+// WithConfig dùng config có sẵn thay vì gọi config.Load
+func WithConfig(cfg *config.Config) Option {
+	return func(o *options) { o.cfg = cfg }
+}
+
+// WithConfigOptions truyền option cho config.Load (ví dụ config.WithEnvFiles)
+func WithConfigOptions(opts ...config.LoadOption) Option {
+	return func(o *options) { o.loadOptions = append(o.loadOptions, opts...) }
+}
+
+// WithDB dùng kết nối có sẵn thay vì mở từ config (ví dụ trong test); App sẽ không tự đóng kết nối này
+func WithDB(db *gorm.DB) Option {
+	return func(o *options) { o.db = db }
+}
+
+// WithMigrator chọn hàm migrate chạy khi khởi động, ví dụ database.VersionedMigrator(); mặc định database.DBMigrator
+func WithMigrator(migrator func(*gorm.DB) error) Option {
+	return func(o *options) { o.migrator = migrator }
+}
+
+// WithFiberConfig thay cấu hình fiber; ErrorHandler mặc định là middleware.ErrorHandler nếu để trống
+func WithFiberConfig(cfg fiber.Config) Option {
+	return func(o *options) { o.fiberConfig = cfg }
+}
+
+// WithoutSecurityHeaders tắt middleware.SecurityMiddleware
+func WithoutSecurityHeaders() Option {
+	return func(o *options) { o.security = false }
+}
+
+// WithoutLogger tắt request logger
+func WithoutLogger() Option {
+	return func(o *options) { o.logger = false }
+}
+
+// WithRateLimiter thay cấu hình rate limiter toàn cục (mặc định middleware.RateLimiterConfig, bỏ qua health/readiness/metrics)
+func WithRateLimiter(cfg limiter.Config) Option {
+	return func(o *options) { o.rateLimit = &cfg }
+}
+
+// WithoutRateLimiter tắt rate limiter toàn cục
+func WithoutRateLimiter() Option {
+	return func(o *options) { o.rateLimit = nil }
+}
+
+// WithUserRateLimiter gắn middleware.UserRateLimiter sau bước xác thực để mỗi user/API key có bộ đếm riêng
+// thay vì chung bucket theo IP (user sau NAT). auth xác định principal trước limiter; nil thì đọc app JWT
+// nếu có và hợp lệ, request còn lại bị giới hạn theo IP. cfg.Resolver nil và policy khác nil thì limit
+// lấy theo role/gói cước qua middleware.NewDBRateLimitResolver. cfg.Storage nil thì dùng storage in-memory
+// (đóng khi Shutdown); chạy nhiều instance thì truyền middleware.NewPostgresRateLimitStorage.
+func WithUserRateLimiter(auth fiber.Handler, cfg middleware.UserRateLimiterConfig, policy *middleware.RateLimitPolicy) Option {
+	return func(o *options) {
+		o.userRateLimit = &cfg
+		o.userRateLimitAuth = auth
+		o.userRateLimitPolicy = policy
+	}
+}
+
+// WithMiddleware thêm middleware chạy sau các middleware mặc định, trước route
+func WithMiddleware(handlers ...fiber.Handler) Option {
+	return func(o *options) { o.middlewares = append(o.middlewares, handlers...) }
+}
+
+// WithRBAC bật RBAC: rbac.InitRBAC khi khởi động và đồng bộ rule sau khi đăng ký route
+func WithRBAC(cfg rbac.Config) Option {
+	return func(o *options) { o.rbacConfig = &cfg }
+}
+
+// WithFirebase khởi tạo verifier Firebase theo config.Firebase
+func WithFirebase() Option {
+	return func(o *options) { o.firebase = true }
+}
+
+// WithRoutes đăng ký route của service
+func WithRoutes(registrars ...RouteRegistrar) Option {
+	return func(o *options) { o.routes = append(o.routes, registrars...) }
+}
+
+// OnStart thêm hook chạy sau khi đăng ký route và đồng bộ RBAC, trước khi nhận request
+func OnStart(hooks ...Hook) Option {
+	return func(o *options) { o.onStart = append(o.onStart, hooks...) }
+}
+
+// OnShutdown thêm hook chạy sau khi server ngừng nhận request, trước khi đóng database (theo thứ tự ngược)
+func OnShutdown(hooks ...Hook) Option {
+	return func(o *options) { o.onShutdown = append(o.onShutdown, hooks...) }
+}
+
+// WithShutdownTimeout giới hạn thời gian chờ request đang xử lý khi tắt (mặc định 15s)
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.shutdownTimeout = timeout }
+}
+
+// WithHealth bật liveness probe tại path (mặc định /healthz)
+func WithHealth(path string) Option {
+	return func(o *options) { o.healthPath = defaultPath(path, "/healthz") }
+}
+
+// WithReadiness bật readiness probe tại path (mặc định /readyz) với check database,
+// RBAC (khi dùng WithRBAC) và các check thêm, ví dụ health.MediaTools()
+func WithReadiness(path string, checks ...health.Check) Option {
+	return func(o *options) {
+		o.readyPath = defaultPath(path, "/readyz")
+		o.readyChecks = append(o.readyChecks, checks...)
+	}
+}
+
+// WithMetrics bật module metrics tại path (mặc định /metrics). Metrics lộ số liệu RBAC và connection pool
+// nên luôn được bảo vệ: guard là middleware kiểm soát truy cập (xác thực, allowlist IP...);
+// nil thì yêu cầu đăng nhập với HighestRole của RBAC (cần WithRBAC)
+func WithMetrics(path string, guard fiber.Handler) Option {
+	return func(o *options) {
+		o.metricsPath = defaultPath(path, "/metrics")
+		o.metricsGuard = guard
+	}
+}
+
+// WithTokenRevocationSync đổi chu kỳ đồng bộ denylist access token giữa các instance (mặc định 30 giây)
+func WithTokenRevocationSync(interval time.Duration) Option {
+	return func(o *options) { o.revocationInterval = interval }
+}
+
+// WithoutTokenRevocation tắt kiểm tra thu hồi access token (logout, đổi mật khẩu...) trong AuthMiddleware
+func WithoutTokenRevocation() Option {
+	return func(o *options) { o.revocation = false }
+}
+
+// WithRBACAdmin bật các endpoint quản trị RBAC dưới /api/admin/rbac; auth là middleware xác thực
+// (nil thì dùng middleware.AuthMiddleware), roles là role được phép (mặc định HighestRole của RBAC)
+func WithRBACAdmin(auth fiber.Handler, roles ...string) Option {
+	return func(o *options) {
+		o.rbacAdminPath = "/api/admin/rbac"
+		o.rbacAdminAuth = auth
+		o.rbacAdminRoles = roles
+	}
+}
+
+func defaultPath(path, fallback string) string {
+	if path == "" {
+		return fallback
+	}
+	return path
+}
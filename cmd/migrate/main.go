@@ -0,0 +1,147 @@
+// Command migrate chạy migration có đánh số phiên bản cho database của dd_goshare.
+//
+//	go run ./cmd/migrate up            # chạy mọi migration chưa chạy
+//	go run ./cmd/migrate up 5          # chạy tới version 5
+//	go run ./cmd/migrate down [n]      # rollback n migration gần nhất (mặc định 1)
+//	go run ./cmd/migrate status        # trạng thái từng migration
+//	go run ./cmd/migrate plan          # các migration sẽ chạy
+//	go run ./cmd/migrate mark-applied 1  # đánh dấu đã chạy (database cũ dùng AutoMigrate)
+//	go run ./cmd/migrate baseline [dir]  # sinh baseline SQL từ model vào dir (mặc định in ra stdout); không ghi đè baseline đã có
+//
+// Kết nối database đọc từ DB_* (biến môi trường hoặc .env), giống config.Load.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/techmaster-vietnam/dd_goshare/config"
+	"github.com/techmaster-vietnam/dd_goshare/database"
+	"github.com/techmaster-vietnam/dd_goshare/database/migrate"
+	"gorm.io/gorm"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate <up|down|status|plan|mark-applied|baseline> [arg]")
+	}
+	command, arg := args[0], ""
+	if len(args) > 1 {
+		arg = args[1]
+	}
+
+	if command == "baseline" {
+		return writeBaseline(arg)
+	}
+
+	dbConfig, err := config.LoadDBConfig()
+	if err != nil {
+		return err
+	}
+	db, err := database.Init(dbConfig, func(*gorm.DB) error { return nil })
+	if err != nil {
+		return err
+	}
+	runner, err := database.NewMigrationRunner(db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		target, err := optionalInt(arg, 0)
+		if err != nil {
+			return err
+		}
+		done, err := runner.UpTo(ctx, target)
+		printMigrations("applied", done)
+		return err
+	case "down":
+		steps, err := optionalInt(arg, 1)
+		if err != nil {
+			return err
+		}
+		done, err := runner.Down(ctx, int(steps))
+		printMigrations("rolled back", done)
+		return err
+	case "plan":
+		pending, err := runner.Plan(ctx)
+		if err != nil {
+			return err
+		}
+		printMigrations("pending", pending)
+		return nil
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			state := "pending"
+			if st.Applied {
+				state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if st.ChecksumMismatch {
+				state += " (MODIFIED)"
+			}
+			if st.Missing {
+				state += " (MISSING FROM SOURCE)"
+			}
+			fmt.Printf("%6d  %-40s %s\n", st.Version, st.Name, state)
+		}
+		return nil
+	case "mark-applied":
+		version, err := optionalInt(arg, 0)
+		if err != nil || version <= 0 {
+			return fmt.Errorf("mark-applied requires a version")
+		}
+		done, err := runner.MarkApplied(ctx, version)
+		printMigrations("marked as applied", done)
+		return err
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func writeBaseline(dir string) error {
+	if dir != "" {
+		return migrate.WriteBaseline(dir, database.Models()...)
+	}
+	up, _, err := migrate.GenerateBaseline(database.Models()...)
+	if err != nil {
+		return err
+	}
+	fmt.Print(up)
+	return nil
+}
+
+func optionalInt(value string, fallback int64) (int64, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return n, nil
+}
+
+func printMigrations(label string, migrations []migrate.Migration) {
+	if len(migrations) == 0 {
+		fmt.Printf("no migrations %s\n", label)
+		return
+	}
+	for _, m := range migrations {
+		fmt.Printf("%s %d_%s\n", label, m.Version, m.Name)
+	}
+}
@@ -0,0 +1,84 @@
+// Command seed nạp fixture vào database của dd_goshare và in báo cáo bản ghi được tạo/cập nhật.
+//
+//	go run ./cmd/seed                      # fixture cho APP_ENV (bắt buộc khi không truyền -env)
+//	go run ./cmd/seed -env staging         # chọn môi trường
+//	go run ./cmd/seed -dir ./fixtures      # dùng thư mục fixture riêng (chứa base/ và <env>/)
+//	go run ./cmd/seed -dry-run -json       # chạy thử, in báo cáo JSON
+//	go run ./cmd/seed -admin E1234567890   # gán role admin cho tài khoản quản trị đầu tiên
+//
+// Kết nối database đọc từ DB_* (biến môi trường hoặc .env), giống cmd/migrate.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/techmaster-vietnam/dd_goshare/config"
+	"github.com/techmaster-vietnam/dd_goshare/database"
+	"github.com/techmaster-vietnam/dd_goshare/database/seed"
+	"gorm.io/gorm"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "seed:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	env := flags.String("env", "", "fixture environment (default: APP_ENV, required if unset)")
+	dir := flags.String("dir", "", "fixture directory containing base/ and <env>/ (default: built-in fixtures)")
+	dryRun := flags.Bool("dry-run", false, "roll back after seeding and only print the report")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	admin := flags.String("admin", "", "user ID to assign the admin role to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	dbConfig, err := config.LoadDBConfig()
+	if err != nil {
+		return err
+	}
+	if *env == "" {
+		profile, err := config.ParseProfile(os.Getenv("APP_ENV"))
+		if err != nil {
+			return err
+		}
+		*env = string(profile)
+	}
+
+	var fixtures fs.FS = database.Fixtures()
+	if *dir != "" {
+		fixtures = os.DirFS(*dir)
+	}
+
+	db, err := database.Init(dbConfig, func(*gorm.DB) error { return nil })
+	if err != nil {
+		return err
+	}
+	seeder := seed.New(db, fixtures)
+	if *dryRun {
+		seeder.DryRun()
+	}
+	if *admin != "" {
+		seeder.WithAdmin(*admin)
+	}
+	report, err := seeder.Run(context.Background(), *env)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	fmt.Print(report.String())
+	return nil
+}
@@ -1,8 +1,13 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+)
+
 // AIConfig holds AI configuration
 type AIConfig struct {
-	APIKey       string
+	APIKey       string `redact:"true"`
 	BaseURL      string
 	GenImageURL  string
 	EditImageURL string
@@ -16,4 +21,22 @@ func NewAIConfig() *AIConfig {
 		GenImageURL:  GetEnv("GEN_IMAGE_URL", ""),
 		EditImageURL: GetEnv("EDIT_IMAGE_URL", ""),
 	}
-}
\ No newline at end of file
+}
+
+// Validate yêu cầu API_KEY và API_URL ngoài môi trường development
+func (c *AIConfig) Validate(profile Profile) error {
+	if profile.IsDevelopment() {
+		return nil
+	}
+	var missing []string
+	if c.APIKey == "" {
+		missing = append(missing, "API_KEY")
+	}
+	if c.BaseURL == "" {
+		missing = append(missing, "API_URL")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s required in %s", strings.Join(missing, " and "), profile)
+	}
+	return nil
+}
@@ -5,7 +5,7 @@ package config
 type AuthConfigs struct {
 	// Firebase handles all OAuth flows, so no individual provider configs needed
 	// JWT secret for token generation (if not using Firebase tokens directly)
-	JWTSecret string
+	JWTSecret string `redact:"true"`
 }
 
 // NewAuthConfigs creates minimal auth configs
@@ -2,6 +2,7 @@ package config
 
 // Config holds all configuration for the application
 type Config struct {
+	Profile  Profile
 	Server   *ServerConfig
 	Database *DBConfig
 	JWT      *JWTConfig
@@ -10,9 +11,16 @@ type Config struct {
 	Firebase *FirebaseConfig
 }
 
-// LoadAllConfigs loads all configuration from environment variables
+// LoadAllConfigs loads all configuration from environment variables.
+//
+// Deprecated: dùng Load, hàm này không validate và dừng chương trình khi thiếu biến DB.
 func LoadAllConfigs() *Config {
+	profile, err := ParseProfile(AppEnv())
+	if err != nil {
+		profile = ProfileProduction
+	}
 	return &Config{
+		Profile:  profile,
 		Server:   NewServerConfig(),
 		Database: NewDBConfig(),
 		JWT:      NewJWTConfig(),
@@ -1,9 +1,15 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/caarlos0/env"
 	"github.com/joho/godotenv"
 )
 
@@ -12,21 +18,106 @@ type DBConfig struct {
 	DBHost     string `env:"DB_HOST,required"`
 	DBPort     string `env:"DB_PORT,required"`
 	DBUser     string `env:"DB_USER,required"`
-	DBPassword string `env:"DB_PASSWORD,required"`
+	DBPassword string `env:"DB_PASSWORD" redact:"true"` // bắt buộc, có thể đọc từ DB_PASSWORD_FILE (xem parseDBConfig)
 	DBName     string `env:"DB_NAME,required"`
 	DBSSLMode  string `env:"DB_SSLMODE,required"`
+
+	// Timezone của session và giới hạn thời gian mỗi câu lệnh (0 = không giới hạn)
+	DBTimeZone         string        `env:"DB_TIMEZONE" envDefault:"Asia/Ho_Chi_Minh"`
+	DBStatementTimeout time.Duration `env:"DB_STATEMENT_TIMEOUT" envDefault:"0s"`
+
+	// Connection pool
+	DBMaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS" envDefault:"25"`
+	DBMaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS" envDefault:"10"`
+	DBConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME" envDefault:"30m"`
+	DBConnMaxIdleTime time.Duration `env:"DB_CONN_MAX_IDLE_TIME" envDefault:"5m"`
+
+	// Kết nối lại khi khởi động: số lần thử và thời gian chờ ban đầu (tăng gấp đôi sau mỗi lần)
+	DBConnectRetries int           `env:"DB_CONNECT_RETRIES" envDefault:"5"`
+	DBConnectBackoff time.Duration `env:"DB_CONNECT_BACKOFF" envDefault:"1s"`
+
+	// DSN của các read replica, phân tách bằng dấu phẩy; để trống nếu không dùng replica
+	DBReplicaDSNs []string `env:"DB_REPLICA_DSNS" envSeparator:"," redact:"true"`
+	// Chu kỳ ping replica để đưa replica đã hồi phục trở lại định tuyến (0 = chỉ kiểm tra khi gọi Health)
+	DBReplicaCheckInterval time.Duration `env:"DB_REPLICA_CHECK_INTERVAL" envDefault:"10s"`
 }
 
-// NewDBConfig tạo database config từ environment variables
-func NewDBConfig() *DBConfig {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatalf("Error loading .env file")
+// DSN trả về chuỗi kết nối tới primary kèm timezone và statement timeout
+func (c *DBConfig) DSN() string {
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+		c.DBHost, c.DBUser, c.DBPassword, c.DBName, c.DBPort, c.DBSSLMode,
+	)
+	return c.WithSessionParams(dsn)
+}
+
+// WithSessionParams thêm TimeZone và statement_timeout vào DSN (dạng key=value hoặc URL) nếu DSN chưa khai báo
+func (c *DBConfig) WithSessionParams(dsn string) string {
+	params := [][2]string{}
+	if c.DBTimeZone != "" {
+		params = append(params, [2]string{"TimeZone", c.DBTimeZone})
+	}
+	if c.DBStatementTimeout > 0 {
+		params = append(params, [2]string{"statement_timeout", strconv.FormatInt(c.DBStatementTimeout.Milliseconds(), 10)})
+	}
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		query := u.Query()
+		for _, p := range params {
+			if !query.Has(p[0]) {
+				query.Set(p[0], p[1])
+			}
+		}
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	for _, p := range params {
+		if !strings.Contains(dsn, p[0]+"=") {
+			dsn += " " + p[0] + "=" + p[1]
+		}
 	}
+	return dsn
+}
+
+// Validate kiểm tra timezone và các giá trị pool/retry
+func (c *DBConfig) Validate() error {
+	var problems []string
+	if c.DBTimeZone != "" {
+		if _, err := time.LoadLocation(c.DBTimeZone); err != nil {
+			problems = append(problems, fmt.Sprintf("DB_TIMEZONE %q is not a valid timezone", c.DBTimeZone))
+		}
+	}
+	if c.DBStatementTimeout < 0 {
+		problems = append(problems, "DB_STATEMENT_TIMEOUT must not be negative")
+	}
+	if c.DBMaxOpenConns < 0 || c.DBMaxIdleConns < 0 {
+		problems = append(problems, "DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must not be negative")
+	}
+	if c.DBMaxOpenConns > 0 && c.DBMaxIdleConns > c.DBMaxOpenConns {
+		problems = append(problems, "DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS")
+	}
+	if c.DBConnectRetries < 0 || c.DBConnectBackoff < 0 {
+		problems = append(problems, "DB_CONNECT_RETRIES and DB_CONNECT_BACKOFF must not be negative")
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
 
-	config := &DBConfig{}
+// NewDBConfig tạo database config từ environment variables; file .env không bắt buộc.
+//
+// Deprecated: dùng Load để nhận lỗi thay vì dừng chương trình.
+func NewDBConfig() *DBConfig {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("Error loading .env file: %v", err)
+	}
 
-	if err := env.Parse(config); err != nil {
+	config, err := parseDBConfig()
+	if err != nil {
 		log.Fatalf("Failed to parse env variables: %v", err)
 	}
 
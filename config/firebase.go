@@ -2,19 +2,41 @@ package config
 
 import (
 	"fmt"
+	"strings"
+)
+
+// Cách xác thực Firebase ID token
+const (
+	FirebaseVerifierFirebase = "firebase" // Firebase Auth thật, cần service account
+	FirebaseVerifierEmulator = "emulator" // Firebase Auth emulator (dev/test)
+	FirebaseVerifierLocal    = "local"    // token RS256 tự ký, xác thực bằng public key cấu hình sẵn
 )
 
 // FirebaseConfig holds Firebase configuration for backend
 type FirebaseConfig struct {
 	ProjectID         string // Required: Firebase project ID
-	ServiceAccountKey string // Required: JSON string of service account key for Admin SDK
+	ServiceAccountKey string `redact:"true"` // Required (verifier "firebase"): JSON string of service account key for Admin SDK
+
+	Verifier         string   // firebase (mặc định), emulator hoặc local
+	AuthEmulatorHost string   // host:port của Auth emulator, ví dụ localhost:9099
+	LocalKeyFiles    []string // public key PEM cho verifier local, dạng "kid=path" hoặc "path"
+	Environment      string
 }
 
 // NewFirebaseConfig tạo Firebase config từ environment variables
 func NewFirebaseConfig() *FirebaseConfig {
+	emulatorHost := GetEnv("FIREBASE_AUTH_EMULATOR_HOST", "")
+	defaultVerifier := FirebaseVerifierFirebase
+	if emulatorHost != "" {
+		defaultVerifier = FirebaseVerifierEmulator
+	}
 	return &FirebaseConfig{
 		ProjectID:         GetEnv("FIREBASE_PROJECT_ID", ""),
 		ServiceAccountKey: GetEnv("FIREBASE_SERVICE_ACCOUNT_KEY", ""),
+		Verifier:          strings.ToLower(GetEnv("FIREBASE_VERIFIER", defaultVerifier)),
+		AuthEmulatorHost:  emulatorHost,
+		LocalKeyFiles:     splitAndTrim(GetEnv("FIREBASE_LOCAL_KEY_FILES", "")),
+		Environment:       AppEnv(),
 	}
 }
 
@@ -23,8 +45,24 @@ func (c *FirebaseConfig) Validate() error {
 	if c.ProjectID == "" {
 		return fmt.Errorf("FIREBASE_PROJECT_ID is required")
 	}
-	if c.ServiceAccountKey == "" {
-		return fmt.Errorf("FIREBASE_SERVICE_ACCOUNT_KEY is required")
+	switch c.Verifier {
+	case "", FirebaseVerifierFirebase:
+		if c.ServiceAccountKey == "" {
+			return fmt.Errorf("FIREBASE_SERVICE_ACCOUNT_KEY is required")
+		}
+	case FirebaseVerifierEmulator, FirebaseVerifierLocal:
+		// Token emulator/local không có chữ ký của Google, không được dùng ngoài môi trường dev
+		if !IsDevelopment(c.Environment) {
+			return fmt.Errorf("FIREBASE_VERIFIER=%s is only allowed in development (APP_ENV=%s)", c.Verifier, c.Environment)
+		}
+		if c.Verifier == FirebaseVerifierEmulator && c.AuthEmulatorHost == "" {
+			return fmt.Errorf("FIREBASE_AUTH_EMULATOR_HOST is required for the emulator verifier")
+		}
+		if c.Verifier == FirebaseVerifierLocal && len(c.LocalKeyFiles) == 0 {
+			return fmt.Errorf("FIREBASE_LOCAL_KEY_FILES is required for the local verifier")
+		}
+	default:
+		return fmt.Errorf("unsupported FIREBASE_VERIFIER %q (expected firebase, emulator or local)", c.Verifier)
 	}
 	return nil
 }
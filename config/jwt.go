@@ -1,15 +1,115 @@
 package config
 
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultJWTSecret là secret mặc định khi không cấu hình JWT_SECRET, chỉ được phép dùng ở môi trường dev
+const DefaultJWTSecret = "your-secret-key"
+
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret string
-	Expiry int // in hours
+	Secret        string `redact:"true"`
+	Expiry        int    // in hours
+	RefreshExpiry int    // in hours
+
+	// Algorithm dùng để ký token: HS256 (mặc định), RS256 hoặc EdDSA
+	Algorithm string
+	// KeyID là kid của khóa ký; để trống thì tự sinh từ thumbprint của public key
+	KeyID string
+	// PrivateKey/PrivateKeyFile chứa khóa ký dạng PEM (RS256/EdDSA)
+	PrivateKey     string `redact:"true"`
+	PrivateKeyFile string
+	// VerifyKeyFiles là danh sách public key PEM còn được chấp nhận khi xoay vòng khóa,
+	// dạng "kid=path" hoặc "path" (kid tự sinh), phân tách bởi dấu phẩy
+	VerifyKeyFiles []string
+	// AllowLegacyHS256 cho phép token HS256 cũ (không có kid) khi đã chuyển sang khóa bất đối xứng
+	AllowLegacyHS256 bool
+
+	Environment string
 }
 
 // NewJWTConfig tạo JWT config từ environment variables
 func NewJWTConfig() *JWTConfig {
 	return &JWTConfig{
-		Secret: GetEnv("JWT_SECRET", "your-secret-key"),
-		Expiry: GetEnvAsInt("JWT_EXPIRY", 24),
+		Secret:           GetEnv("JWT_SECRET", DefaultJWTSecret),
+		Expiry:           GetEnvAsInt("JWT_EXPIRY", 24),
+		RefreshExpiry:    GetEnvAsInt("JWT_REFRESH_EXPIRY", 720),
+		Algorithm:        strings.ToUpper(GetEnv("JWT_ALGORITHM", "HS256")),
+		KeyID:            GetEnv("JWT_KEY_ID", ""),
+		PrivateKey:       strings.ReplaceAll(GetEnv("JWT_PRIVATE_KEY", ""), `\n`, "\n"),
+		PrivateKeyFile:   GetEnv("JWT_PRIVATE_KEY_FILE", ""),
+		VerifyKeyFiles:   splitAndTrim(GetEnv("JWT_VERIFY_KEY_FILES", "")),
+		AllowLegacyHS256: GetEnv("JWT_ALLOW_LEGACY_HS256", "false") == "true",
+		Environment:      AppEnv(),
+	}
+}
+
+// validateExpiry kiểm tra thời hạn token khi Load; không nằm trong Validate vì KeySet chỉ cần khóa
+func (c *JWTConfig) validateExpiry() error {
+	if c.Expiry <= 0 {
+		return fmt.Errorf("JWT_EXPIRY must be a positive number of hours, got %d", c.Expiry)
+	}
+	if c.RefreshExpiry <= 0 {
+		return fmt.Errorf("JWT_REFRESH_EXPIRY must be a positive number of hours, got %d", c.RefreshExpiry)
+	}
+	return nil
+}
+
+// Validate kiểm tra JWT config; secret mặc định bị từ chối ngoài môi trường dev
+func (c *JWTConfig) Validate() error {
+	switch c.Algorithm {
+	case "HS256":
+		if c.Secret == "" {
+			return fmt.Errorf("JWT_SECRET is required for HS256")
+		}
+	case "RS256", "EDDSA":
+		if c.PrivateKey == "" && c.PrivateKeyFile == "" {
+			return fmt.Errorf("JWT_PRIVATE_KEY or JWT_PRIVATE_KEY_FILE is required for %s", c.Algorithm)
+		}
+	default:
+		return fmt.Errorf("unsupported JWT_ALGORITHM %q (supported: HS256, RS256, EdDSA)", c.Algorithm)
 	}
-}
\ No newline at end of file
+
+	usesSecret := c.Algorithm == "HS256" || c.AllowLegacyHS256
+	if usesSecret && c.Secret == DefaultJWTSecret && !IsDevelopment(c.Environment) {
+		return fmt.Errorf("JWT_SECRET must be set in %s environment (default secret is only allowed in development)", c.Environment)
+	}
+
+	// Kiểm tra file khóa ngay khi load thay vì đợi lúc dựng KeySet
+	if c.PrivateKey == "" && c.PrivateKeyFile != "" {
+		if err := checkReadable(c.PrivateKeyFile); err != nil {
+			return fmt.Errorf("JWT_PRIVATE_KEY_FILE: %w", err)
+		}
+	}
+	for _, entry := range c.VerifyKeyFiles {
+		path := entry
+		if idx := strings.Index(entry, "="); idx > 0 {
+			path = entry[idx+1:]
+		}
+		if err := checkReadable(path); err != nil {
+			return fmt.Errorf("JWT_VERIFY_KEY_FILES: %w", err)
+		}
+	}
+	return nil
+}
+
+func checkReadable(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func splitAndTrim(value string) []string {
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		if p := strings.TrimSpace(part); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,340 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/caarlos0/env"
+	"github.com/joho/godotenv"
+)
+
+// Profile là môi trường chạy ứng dụng, đọc từ APP_ENV
+type Profile string
+
+const (
+	ProfileDevelopment Profile = "development"
+	ProfileStaging     Profile = "staging"
+	ProfileProduction  Profile = "production"
+)
+
+// ParseProfile chuẩn hóa APP_ENV; dev/local/test được coi là development.
+// APP_ENV rỗng là lỗi: bản deploy production quên đặt biến không được âm thầm chạy với kiểm tra nới lỏng.
+func ParseProfile(value string) (Profile, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "":
+		return "", errors.New("APP_ENV is required (development, staging or production)")
+	case "dev", "development", "local", "test":
+		return ProfileDevelopment, nil
+	case "stage", "staging":
+		return ProfileStaging, nil
+	case "prod", "production":
+		return ProfileProduction, nil
+	}
+	return "", fmt.Errorf("unsupported APP_ENV %q (expected development, staging or production)", value)
+}
+
+// IsDevelopment cho biết profile có nới lỏng kiểm tra hay không
+func (p Profile) IsDevelopment() bool {
+	return p == ProfileDevelopment
+}
+
+// secretEnvKeys là các biến bí mật có thể đọc từ file qua biến <KEY>_FILE (Docker/Kubernetes secrets)
+var secretEnvKeys = []string{
+	"DB_PASSWORD",
+	"JWT_SECRET",
+	"API_KEY",
+	"FIREBASE_SERVICE_ACCOUNT_KEY",
+}
+
+// intEnvKeys là các biến số nguyên đọc qua GetEnvAsInt; Load báo lỗi nếu giá trị không parse được
+var intEnvKeys = []string{
+	"JWT_EXPIRY",
+	"JWT_REFRESH_EXPIRY",
+}
+
+// ValidationErrors gom tất cả lỗi cấu hình để báo một lần thay vì dừng ở lỗi đầu tiên
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, err := range e {
+		lines = append(lines, "  - "+err.Error())
+	}
+	return fmt.Sprintf("invalid configuration (%d errors):\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+type loadOptions struct {
+	envFiles []string
+}
+
+// LoadOption tùy biến Load
+type LoadOption func(*loadOptions)
+
+// WithEnvFiles thay danh sách file .env mặc định (.env.<profile>, .env); file không tồn tại được bỏ qua
+func WithEnvFiles(files ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.envFiles = files
+	}
+}
+
+// Load đọc toàn bộ cấu hình từ biến môi trường, file .env (không bắt buộc) và các secret <KEY>_FILE.
+// Biến môi trường đã có luôn được ưu tiên hơn giá trị trong .env.
+// Mọi lỗi parse/validate được gom vào ValidationErrors thay vì dừng chương trình.
+func Load(opts ...LoadOption) (*Config, error) {
+	profile, errs := loadEnvironment(opts)
+
+	cfg := &Config{
+		Profile:  profile,
+		Server:   NewServerConfig(),
+		JWT:      NewJWTConfig(),
+		AI:       NewAIConfig(),
+		Auth:     NewAuthConfigs(),
+		Firebase: NewFirebaseConfig(),
+	}
+	cfg.JWT.Environment = string(profile)
+	cfg.Firebase.Environment = string(profile)
+
+	db, err := parseDBConfig()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("database: %w", err))
+	}
+	cfg.Database = db
+
+	for _, key := range intEnvKeys {
+		if _, err := parseEnvInt(key, 0); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, cfg.validate()...)
+	if len(errs) > 0 {
+		return cfg, errs
+	}
+	return cfg, nil
+}
+
+// LoadDBConfig chỉ đọc cấu hình database (cùng nguồn với Load), dùng cho công cụ dòng lệnh như migrate/seed
+func LoadDBConfig(opts ...LoadOption) (*DBConfig, error) {
+	_, errs := loadEnvironment(opts)
+	db, err := parseDBConfig()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("database: %w", err))
+	} else if err := db.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("database: %w", err))
+	}
+	if len(errs) > 0 {
+		return db, errs
+	}
+	return db, nil
+}
+
+// loadEnvironment nạp các file .env, xác định profile và đọc secret <KEY>_FILE
+func loadEnvironment(opts []LoadOption) (Profile, ValidationErrors) {
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var errs ValidationErrors
+
+	files := o.envFiles
+	if files == nil {
+		// APP_ENV có thể chỉ nằm trong .env; cần biết profile trước để nạp .env.<profile>
+		appEnv := os.Getenv("APP_ENV")
+		if appEnv == "" {
+			if values, err := godotenv.Read(".env"); err == nil {
+				appEnv = values["APP_ENV"]
+			}
+		}
+		if p, err := ParseProfile(appEnv); err == nil {
+			files = []string{".env." + string(p), ".env"}
+		} else {
+			files = []string{".env"}
+		}
+	}
+	for _, file := range files {
+		// godotenv.Load không ghi đè biến đã có nên file nạp trước được ưu tiên
+		if err := godotenv.Load(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to load %s: %w", file, err))
+		}
+	}
+
+	profile, err := ParseProfile(os.Getenv("APP_ENV"))
+	if err != nil {
+		// Validate các phần còn lại theo profile chặt nhất để báo đủ lỗi
+		errs = append(errs, err)
+		profile = ProfileProduction
+	}
+
+	secrets, secretErrs := resolveSecretFiles()
+	setFileSecrets(secrets)
+	errs = append(errs, secretErrs...)
+	return profile, errs
+}
+
+// MustLoad gọi Load và panic nếu cấu hình không hợp lệ; chỉ dùng trong main hoặc test
+func MustLoad(opts ...LoadOption) *Config {
+	cfg, err := Load(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+// resolveSecretFiles đọc giá trị của các <KEY>_FILE; không ghi vào biến môi trường của process
+// (tránh lộ secret cho process con) mà trả về để GetEnv tra cứu sau biến môi trường
+func resolveSecretFiles() (map[string]string, []error) {
+	var errs []error
+	secrets := map[string]string{}
+	for _, key := range secretEnvKeys {
+		path := os.Getenv(key + "_FILE")
+		if path == "" {
+			continue
+		}
+		if os.Getenv(key) != "" {
+			errs = append(errs, fmt.Errorf("%s and %s_FILE are both set, use only one", key, key))
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s_FILE: %w", key, err))
+			continue
+		}
+		secrets[key] = strings.TrimRight(string(data), "\r\n")
+	}
+	return secrets, errs
+}
+
+var (
+	fileSecretsMu sync.RWMutex
+	fileSecrets   map[string]string
+)
+
+func setFileSecrets(secrets map[string]string) {
+	fileSecretsMu.Lock()
+	fileSecrets = secrets
+	fileSecretsMu.Unlock()
+}
+
+// lookupEnv đọc biến môi trường, nếu không có thì lấy secret đọc từ <KEY>_FILE ở lần Load gần nhất
+func lookupEnv(key string) (string, bool) {
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+	fileSecretsMu.RLock()
+	defer fileSecretsMu.RUnlock()
+	value, ok := fileSecrets[key]
+	return value, ok
+}
+
+// parseDBConfig đọc DBConfig từ biến môi trường; DB_PASSWORD có thể đến từ DB_PASSWORD_FILE
+func parseDBConfig() (*DBConfig, error) {
+	db := &DBConfig{}
+	if err := env.Parse(db); err != nil {
+		return db, err
+	}
+	password, ok := lookupEnv("DB_PASSWORD")
+	if !ok {
+		return db, errors.New(`required environment variable "DB_PASSWORD" is not set`)
+	}
+	db.DBPassword = password
+	return db, nil
+}
+
+func (c *Config) validate() []error {
+	var errs []error
+	if c.Database != nil {
+		if err := c.Database.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("database: %w", err))
+		}
+	}
+	if err := c.Server.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("server: %w", err))
+	}
+	if err := c.JWT.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("jwt: %w", err))
+	}
+	if err := c.JWT.validateExpiry(); err != nil {
+		errs = append(errs, fmt.Errorf("jwt: %w", err))
+	}
+	if err := c.Auth.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("auth: %w", err))
+	}
+	if err := c.Firebase.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("firebase: %w", err))
+	}
+	if err := c.AI.Validate(c.Profile); err != nil {
+		errs = append(errs, fmt.Errorf("ai: %w", err))
+	}
+	return errs
+}
+
+// Redacted trả về cấu hình dạng "Section.Field=value" đã che các trường bí mật (tag redact:"true"),
+// dùng để log lúc khởi động
+func (c *Config) Redacted() string {
+	lines := []string{"Profile=" + string(c.Profile)}
+	sections := map[string]interface{}{
+		"Server":   c.Server,
+		"Database": c.Database,
+		"JWT":      c.JWT,
+		"AI":       c.AI,
+		"Auth":     c.Auth,
+		"Firebase": c.Firebase,
+	}
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines = append(lines, redactFields(name, sections[name])...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func redactFields(section string, value interface{}) []string {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	t := v.Type()
+	lines := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		var shown string
+		fv := v.Field(i)
+		switch {
+		case field.Tag.Get("redact") == "true":
+			if fv.IsZero() {
+				shown = "<empty>"
+			} else {
+				shown = "<redacted>"
+			}
+		case fv.Kind() == reflect.Slice:
+			parts := make([]string, 0, fv.Len())
+			for j := 0; j < fv.Len(); j++ {
+				parts = append(parts, fmt.Sprint(fv.Index(j).Interface()))
+			}
+			shown = strings.Join(parts, ",")
+		default:
+			shown = fmt.Sprint(fv.Interface())
+		}
+		lines = append(lines, section+"."+field.Name+"="+shown)
+	}
+	return lines
+}
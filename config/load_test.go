@@ -0,0 +1,250 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setBaseEnv đặt bộ biến môi trường hợp lệ tối thiểu cho profile production
+func setBaseEnv(t *testing.T) {
+	t.Helper()
+	for key, value := range map[string]string{
+		"APP_ENV":                      "production",
+		"DB_HOST":                      "localhost",
+		"DB_PORT":                      "5432",
+		"DB_USER":                      "app",
+		"DB_PASSWORD":                  "db-secret",
+		"DB_NAME":                      "app",
+		"DB_SSLMODE":                   "disable",
+		"SERVER_PORT":                  "8081",
+		"JWT_SECRET":                   "jwt-secret",
+		"JWT_ALGORITHM":                "HS256",
+		"API_KEY":                      "ai-key",
+		"API_URL":                      "http://ai.local",
+		"FIREBASE_PROJECT_ID":          "project",
+		"FIREBASE_SERVICE_ACCOUNT_KEY": "{}",
+	} {
+		t.Setenv(key, value)
+	}
+	for _, key := range []string{"FIREBASE_VERIFIER", "FIREBASE_AUTH_EMULATOR_HOST", "JWT_PRIVATE_KEY_FILE", "JWT_VERIFY_KEY_FILES", "JWT_EXPIRY", "JWT_REFRESH_EXPIRY"} {
+		unsetEnv(t, key)
+	}
+	for _, key := range secretEnvKeys {
+		unsetEnv(t, key+"_FILE")
+	}
+	t.Cleanup(func() { setFileSecrets(nil) })
+}
+
+func unsetEnv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+func noEnvFiles(t *testing.T) LoadOption {
+	return WithEnvFiles(filepath.Join(t.TempDir(), "missing.env"))
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseProfile(t *testing.T) {
+	cases := []struct {
+		value   string
+		want    Profile
+		wantErr bool
+	}{
+		{"", "", true},
+		{"   ", "", true},
+		{"dev", ProfileDevelopment, false},
+		{"Development", ProfileDevelopment, false},
+		{"local", ProfileDevelopment, false},
+		{"test", ProfileDevelopment, false},
+		{"stage", ProfileStaging, false},
+		{"staging", ProfileStaging, false},
+		{"prod", ProfileProduction, false},
+		{" PRODUCTION ", ProfileProduction, false},
+		{"qa", "", true},
+	}
+	for _, tc := range cases {
+		t.Run("TestParseProfile_"+tc.value, func(t *testing.T) {
+			got, err := ParseProfile(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error=%v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected profile %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	t.Run("TestEmptyIsNotDevelopment", func(t *testing.T) {
+		if IsDevelopment("") {
+			t.Errorf("Expected empty environment not to be development")
+		}
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("TestValid", func(t *testing.T) {
+		setBaseEnv(t)
+		cfg, err := Load(noEnvFiles(t))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Profile != ProfileProduction {
+			t.Errorf("Expected production profile, got %q", cfg.Profile)
+		}
+	})
+
+	t.Run("TestMissingAppEnvFallsBackToProduction", func(t *testing.T) {
+		setBaseEnv(t)
+		unsetEnv(t, "APP_ENV")
+		t.Setenv("JWT_SECRET", DefaultJWTSecret)
+
+		cfg, err := Load(noEnvFiles(t))
+		if err == nil {
+			t.Fatalf("Expected error for missing APP_ENV")
+		}
+		if cfg.Profile != ProfileProduction {
+			t.Errorf("Expected production profile, got %q", cfg.Profile)
+		}
+		msg := err.Error()
+		if !strings.Contains(msg, "APP_ENV is required") {
+			t.Errorf("Expected APP_ENV error, got %v", msg)
+		}
+		if !strings.Contains(msg, "JWT_SECRET must be set") {
+			t.Errorf("Expected default JWT secret to be rejected, got %v", msg)
+		}
+	})
+
+	t.Run("TestCollectsAllErrors", func(t *testing.T) {
+		setBaseEnv(t)
+		t.Setenv("SERVER_PORT", "abc")
+		t.Setenv("JWT_ALGORITHM", "none")
+		unsetEnv(t, "DB_PASSWORD")
+
+		_, err := Load(noEnvFiles(t))
+		var verrs ValidationErrors
+		if !errors.As(err, &verrs) {
+			t.Fatalf("Expected ValidationErrors, got %v", err)
+		}
+		if len(verrs) != 3 {
+			t.Errorf("Expected 3 errors, got %d: %v", len(verrs), err)
+		}
+	})
+
+	t.Run("TestInvalidJWTExpiry", func(t *testing.T) {
+		cases := []struct {
+			key, value, want string
+		}{
+			{"JWT_EXPIRY", "24h", `JWT_EXPIRY "24h" is not a valid integer`},
+			{"JWT_EXPIRY", "0", "JWT_EXPIRY must be a positive number of hours"},
+			{"JWT_REFRESH_EXPIRY", "-1", "JWT_REFRESH_EXPIRY must be a positive number of hours"},
+		}
+		for _, tc := range cases {
+			setBaseEnv(t)
+			t.Setenv(tc.key, tc.value)
+
+			_, err := Load(noEnvFiles(t))
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) || len(verrs) != 1 {
+				t.Fatalf("Expected a single validation error for %s=%s, got %v", tc.key, tc.value, err)
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected %q, got %v", tc.want, err)
+			}
+		}
+	})
+
+	t.Run("TestMissingJWTKeyFiles", func(t *testing.T) {
+		setBaseEnv(t)
+		missing := filepath.Join(t.TempDir(), "missing.pem")
+		t.Setenv("JWT_ALGORITHM", "RS256")
+		t.Setenv("JWT_PRIVATE_KEY_FILE", missing)
+		t.Setenv("JWT_VERIFY_KEY_FILES", "old="+missing)
+
+		_, err := Load(noEnvFiles(t))
+		if err == nil || !strings.Contains(err.Error(), "JWT_PRIVATE_KEY_FILE") {
+			t.Errorf("Expected JWT_PRIVATE_KEY_FILE error, got %v", err)
+		}
+	})
+}
+
+func TestSecretFiles(t *testing.T) {
+	t.Run("TestReadsWithoutSettingEnv", func(t *testing.T) {
+		setBaseEnv(t)
+		unsetEnv(t, "DB_PASSWORD")
+		unsetEnv(t, "JWT_SECRET")
+		t.Setenv("DB_PASSWORD_FILE", writeFile(t, "db", "from-file\n"))
+		t.Setenv("JWT_SECRET_FILE", writeFile(t, "jwt", "jwt-from-file"))
+
+		cfg, err := Load(noEnvFiles(t))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Database.DBPassword != "from-file" {
+			t.Errorf("Expected DB password from file, got %q", cfg.Database.DBPassword)
+		}
+		if cfg.JWT.Secret != "jwt-from-file" {
+			t.Errorf("Expected JWT secret from file, got %q", cfg.JWT.Secret)
+		}
+		for _, key := range []string{"DB_PASSWORD", "JWT_SECRET"} {
+			if _, ok := os.LookupEnv(key); ok {
+				t.Errorf("Expected %s not to be set in process env", key)
+			}
+		}
+	})
+
+	t.Run("TestBothSet", func(t *testing.T) {
+		setBaseEnv(t)
+		t.Setenv("DB_PASSWORD_FILE", writeFile(t, "db", "from-file"))
+
+		_, err := Load(noEnvFiles(t))
+		if err == nil || !strings.Contains(err.Error(), "DB_PASSWORD and DB_PASSWORD_FILE are both set") {
+			t.Errorf("Expected both-set error, got %v", err)
+		}
+	})
+
+	t.Run("TestUnreadableFile", func(t *testing.T) {
+		setBaseEnv(t)
+		unsetEnv(t, "API_KEY")
+		t.Setenv("API_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+
+		_, err := Load(noEnvFiles(t))
+		if err == nil || !strings.Contains(err.Error(), "API_KEY_FILE") {
+			t.Errorf("Expected API_KEY_FILE error, got %v", err)
+		}
+	})
+}
+
+func TestJWTConfigValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     JWTConfig
+		wantErr bool
+	}{
+		{"DefaultSecretInDevelopment", JWTConfig{Algorithm: "HS256", Secret: DefaultJWTSecret, Environment: "development"}, false},
+		{"DefaultSecretInProduction", JWTConfig{Algorithm: "HS256", Secret: DefaultJWTSecret, Environment: "production"}, true},
+		{"DefaultSecretWithoutEnvironment", JWTConfig{Algorithm: "HS256", Secret: DefaultJWTSecret}, true},
+		{"MissingPrivateKey", JWTConfig{Algorithm: "RS256", Environment: "production"}, true},
+		{"UnsupportedAlgorithm", JWTConfig{Algorithm: "none", Secret: "s"}, true},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error=%v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
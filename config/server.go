@@ -1,5 +1,10 @@
 package config
 
+import (
+	"fmt"
+	"strconv"
+)
+
 // ServerConfig holds server configuration
 type ServerConfig struct {
 	Port string
@@ -12,4 +17,13 @@ func NewServerConfig() *ServerConfig {
 		Port: GetEnv("SERVER_PORT", "8081"),
 		Host: GetEnv("SERVER_HOST", "0.0.0.0"),
 	}
-}
\ No newline at end of file
+}
+
+// Validate kiểm tra cổng server
+func (c *ServerConfig) Validate() error {
+	port, err := strconv.Atoi(c.Port)
+	if err != nil || port <= 0 || port > 65535 {
+		return fmt.Errorf("SERVER_PORT %q is not a valid port", c.Port)
+	}
+	return nil
+}
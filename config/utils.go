@@ -1,26 +1,42 @@
 package config
 
 import (
+	"fmt"
 	"net"
-	"os"
 	"strconv"
+	"strings"
 )
 
+// GetEnv đọc biến môi trường (hoặc secret từ <KEY>_FILE), trả về defaultValue nếu rỗng
 func GetEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+	if value, _ := lookupEnv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
-// getEnvAsInt gets an environment variable as integer or returns a default value
+// GetEnvAsInt đọc biến môi trường (hoặc <KEY>_FILE) dạng số nguyên, trả về defaultValue nếu rỗng hoặc không hợp lệ.
+// Load báo lỗi cho giá trị không hợp lệ của các biến trong intEnvKeys.
 func GetEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
+	value, err := parseEnvInt(key, defaultValue)
+	if err != nil {
+		return defaultValue
 	}
-	return defaultValue
+	return value
+}
+
+// parseEnvInt như GetEnvAsInt nhưng trả lỗi thay vì âm thầm dùng mặc định (ví dụ JWT_EXPIRY=24h)
+func parseEnvInt(key string, defaultValue int) (int, error) {
+	value, _ := lookupEnv(key)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultValue, nil
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue, fmt.Errorf("%s %q is not a valid integer", key, value)
+	}
+	return intValue, nil
 }
 
 // GetLocalIP returns the local IP address for network access
@@ -38,4 +54,17 @@ func GetLocalIP() string {
 		}
 	}
 	return ""
-}
\ No newline at end of file
+}
+// AppEnv trả về môi trường chạy (APP_ENV); rỗng nếu chưa đặt và khi đó được coi là production
+func AppEnv() string {
+	return strings.ToLower(GetEnv("APP_ENV", ""))
+}
+
+// IsDevelopment kiểm tra env có phải môi trường dev/local/test không; env rỗng không phải development
+func IsDevelopment(env string) bool {
+	switch strings.ToLower(strings.TrimSpace(env)) {
+	case "dev", "development", "local", "test":
+		return true
+	}
+	return false
+}
@@ -2,19 +2,19 @@ package database
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
 	"gorm.io/gorm"
 )
 
-func DBMigrator(db *gorm.DB) error {
-	if db == nil {
-		return fmt.Errorf("db is nil")
-	}
-
-	if err := db.AutoMigrate(
+// Models trả về toàn bộ model của hệ thống theo thứ tự migrate;
+// dùng chung cho AutoMigrate và khi sinh baseline migration
+func Models() []interface{} {
+	return []interface{}{
 		&models.Customer{},
 		&models.Employee{},
+		&models.Level{},
 		&models.Topic{},
 		&models.Dialog{},
 		&models.Audio{},
@@ -40,19 +40,71 @@ func DBMigrator(db *gorm.DB) error {
 		&models.DialogCompletion{},
 		// Authentication models
 		&models.AuthProvider{},
+		&models.SMSVerification{},
+		&models.PasswordResetToken{},
+		&models.MFAFactor{},
+		&models.MFARecoveryCode{},
+		&models.RefreshToken{},
+		&models.Session{},
+		&models.APIKey{},
+		&models.APIKeyAuditLog{},
+		&models.TokenRevocation{},
 		// RBAC models
 		&models.Role{},
 		&models.Rule{},
 		&models.UserRole{},
 		&models.RuleRole{},
-	); err != nil {
+		// Rate limiting
+		&models.RateLimitCounter{},
+	}
+}
+
+func DBMigrator(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("db is nil")
+	}
+
+	if err := db.AutoMigrate(Models()...); err != nil {
 		return fmt.Errorf("automigrate failed: %w", err)
 	}
 
-	// Seed business function rules only (no roles or assignments)
-	// if err := SeedBusinessFunctionRules(db); err != nil {
-	// 	log.Printf("Warning: Failed to seed business function rules: %v", err)
-	// }
+	if err := ensureTagNameIndex(db); err != nil {
+		return fmt.Errorf("failed to update idx_tags_name: %w", err)
+	}
+
+	// AutoMigrate không tạo được extension, cấu hình text search và index biểu thức cho full-text search;
+	// migration 0005 idempotent nên chạy lại được. Tìm kiếm phụ thuộc hoàn toàn vào chúng nên lỗi là lỗi migrate.
+	if err := ensureFullTextSearch(db); err != nil {
+		return fmt.Errorf("full-text search setup failed (requires the unaccent extension): %w", err)
+	}
 
 	return nil
 }
+
+// ensureTagNameIndex thay index unique cũ trên tags.name bằng index partial (chỉ tag chưa xoá).
+// AutoMigrate thấy index cùng tên đã tồn tại nên không tự tạo lại; migration 0002 làm việc này ở đường versioned.
+func ensureTagNameIndex(db *gorm.DB) error {
+	var def string
+	if err := db.Raw(`SELECT indexdef FROM pg_indexes
+		WHERE schemaname = current_schema() AND tablename = 'tags' AND indexname = 'idx_tags_name'`).
+		Scan(&def).Error; err != nil {
+		return err
+	}
+	if strings.Contains(def, "WHERE") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`DROP INDEX IF EXISTS "idx_tags_name"`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`CREATE UNIQUE INDEX "idx_tags_name" ON "tags" ("name") WHERE deleted_at IS NULL`).Error
+	})
+}
+
+func ensureFullTextSearch(db *gorm.DB) error {
+	sql, err := migrationFiles.ReadFile("migrations/0005_full_text_search.up.sql")
+	if err != nil {
+		return err
+	}
+	return db.Exec(string(sql)).Error
+}
@@ -0,0 +1,58 @@
+package database_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/database"
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+func tagNameIndexDef(t *testing.T, db *gorm.DB) string {
+	t.Helper()
+	var def string
+	if err := db.Raw(`SELECT indexdef FROM pg_indexes
+		WHERE schemaname = current_schema() AND tablename = 'tags' AND indexname = 'idx_tags_name'`).
+		Scan(&def).Error; err != nil {
+		t.Fatal(err)
+	}
+	return def
+}
+
+// assertTagNameReusable kiểm tra tên của tag đã xoá mềm dùng lại được
+func assertTagNameReusable(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	if def := tagNameIndexDef(t, db); !strings.Contains(def, "WHERE") {
+		t.Fatalf("Expected partial idx_tags_name, got %q", def)
+	}
+	if err := db.Create(&models.Tag{ID: "tag1", Name: "travel"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&models.Tag{ID: "tag1"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.Tag{ID: "tag2", Name: "travel"}).Error; err != nil {
+		t.Errorf("Expected name of deleted tag to be reusable, got %v", err)
+	}
+}
+
+func TestTagNameIndex(t *testing.T) {
+	t.Run("TestDBMigratorReplacesLegacyIndex", func(t *testing.T) {
+		db := testdb.OpenEmpty(t)
+		// Schema cũ trước khi có xoá mềm: unique trên toàn bộ tags.name
+		if err := db.Exec(`CREATE TABLE "tags" ("id" varchar(12), "name" varchar(100), PRIMARY KEY ("id"));
+			CREATE UNIQUE INDEX "idx_tags_name" ON "tags" ("name")`).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := database.DBMigrator(db); err != nil {
+			t.Fatalf("DBMigrator: %v", err)
+		}
+		assertTagNameReusable(t, db)
+	})
+
+	t.Run("TestVersionedMigrator", func(t *testing.T) {
+		assertTagNameReusable(t, testdb.Open(t))
+	})
+}
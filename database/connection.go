@@ -1,43 +1,139 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/techmaster-vietnam/dd_goshare/config"
+	"github.com/techmaster-vietnam/dd_goshare/database/replica"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var dbInstance *gorm.DB
 
-func Init(config *config.DBConfig, DBMigrator func(*gorm.DB) error) (*gorm.DB, error) {
-	uri := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Ho_Chi_Minh",
-		config.DBHost, config.DBUser, config.DBPassword, config.DBName, config.DBPort, config.DBSSLMode,
-	)
+// maxConnectBackoff giới hạn thời gian chờ giữa hai lần thử kết nối
+const maxConnectBackoff = 30 * time.Second
 
-	var err error
-	dbInstance, err = gorm.Open(postgres.Open(uri), &gorm.Config{})
+func Init(config *config.DBConfig, DBMigrator func(*gorm.DB) error) (*gorm.DB, error) {
+	db, err := Open(context.Background(), config)
 	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
 		return nil, err
 	}
 
 	// Sử dụng hàm DBMigrator để migrate
-	if err := DBMigrator(dbInstance); err != nil {
-		log.Fatalf("failed to migrate database: %v", err)
-		return nil, err
+	if err := DBMigrator(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	dbInstance = db
 	log.Println("✅ Database connected & migrated successfully!")
 	return dbInstance, nil
 }
 
+// Open kết nối primary (thử lại với backoff khi database chưa sẵn sàng), cấu hình pool
+// và đăng ký read replica nếu DB_REPLICA_DSNS được khai báo
+func Open(ctx context.Context, cfg *config.DBConfig) (*gorm.DB, error) {
+	db, err := openWithRetry(ctx, cfg, cfg.DSN(), "primary")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.DBReplicaDSNs) > 0 {
+		replicas := make([]*gorm.DB, 0, len(cfg.DBReplicaDSNs))
+		for i, dsn := range cfg.DBReplicaDSNs {
+			r, err := openWithRetry(ctx, cfg, cfg.WithSessionParams(dsn), fmt.Sprintf("replica %d", i+1))
+			if err != nil {
+				closeAll(append(replicas, db)...)
+				return nil, err
+			}
+			replicas = append(replicas, r)
+		}
+		resolver := replica.NewResolver(replicas...)
+		if err := db.Use(resolver); err != nil {
+			closeAll(append(replicas, db)...)
+			return nil, fmt.Errorf("failed to register read replicas: %w", err)
+		}
+		if cfg.DBReplicaCheckInterval > 0 {
+			resolver.Start(context.Background(), cfg.DBReplicaCheckInterval)
+		}
+	}
+	return db, nil
+}
+
+// openWithRetry mở kết nối và ping; lỗi thì chờ DBConnectBackoff (tăng gấp đôi, tối đa 30s) rồi thử lại
+func openWithRetry(ctx context.Context, cfg *config.DBConfig, dsn, name string) (*gorm.DB, error) {
+	backoff := cfg.DBConnectBackoff
+	var lastErr error
+	for attempt := 0; attempt <= cfg.DBConnectRetries; attempt++ {
+		if attempt > 0 {
+			log.Printf("database %s unavailable (attempt %d/%d): %v; retrying in %s", name, attempt, cfg.DBConnectRetries+1, lastErr, backoff)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > maxConnectBackoff {
+				backoff = maxConnectBackoff
+			}
+		}
+
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		configurePool(sqlDB, cfg)
+		if err := sqlDB.PingContext(ctx); err != nil {
+			sqlDB.Close()
+			lastErr = err
+			continue
+		}
+		return db, nil
+	}
+	return nil, fmt.Errorf("failed to connect to database %s: %w", name, lastErr)
+}
+
+func configurePool(sqlDB *sql.DB, cfg *config.DBConfig) {
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	// 0 với database/sql nghĩa là không giữ kết nối rảnh; giữ mặc định của database/sql khi không cấu hình
+	if cfg.DBMaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	}
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+}
+
+func closeAll(dbs ...*gorm.DB) {
+	for _, db := range dbs {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+// Close đóng primary và các read replica
+func Close(db *gorm.DB) {
+	if r := replica.FromDB(db); r != nil {
+		r.Stop()
+		closeAll(r.Replicas()...)
+	}
+	closeAll(db)
+}
+
 func GetDB() *gorm.DB {
 	if dbInstance == nil {
 		log.Println("Database not initialized. Call Init first.")
 		os.Exit(1)
 	}
 	return dbInstance
-}
\ No newline at end of file
+}
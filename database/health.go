@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/replica"
+	"gorm.io/gorm"
+)
+
+// PoolHealth là kết quả ping và thống kê pool của một kết nối
+type PoolHealth struct {
+	Name            string `json:"name"`
+	Healthy         bool   `json:"healthy"`
+	Error           string `json:"error,omitempty"`
+	LatencyMs       int64  `json:"latency_ms"`
+	OpenConnections int    `json:"open_connections"`
+	InUse           int    `json:"in_use"`
+	Idle            int    `json:"idle"`
+	WaitCount       int64  `json:"wait_count"`
+}
+
+// HealthReport là trạng thái primary và các read replica.
+// Healthy chỉ phụ thuộc primary; replica lỗi được loại khỏi định tuyến cho tới khi ping lại thành công.
+type HealthReport struct {
+	Healthy  bool         `json:"healthy"`
+	Primary  PoolHealth   `json:"primary"`
+	Replicas []PoolHealth `json:"replicas,omitempty"`
+}
+
+// Health ping primary và các replica của db
+func Health(ctx context.Context, db *gorm.DB) HealthReport {
+	report := HealthReport{Primary: pingPool(ctx, "primary", db)}
+	report.Healthy = report.Primary.Healthy
+
+	if r := replica.FromDB(db); r != nil {
+		errs := r.Check(ctx)
+		for i, replicaDB := range r.Replicas() {
+			h := poolStats(fmt.Sprintf("replica-%d", i+1), replicaDB)
+			h.Healthy = errs[i] == nil
+			if errs[i] != nil {
+				h.Error = errs[i].Error()
+			}
+			report.Replicas = append(report.Replicas, h)
+		}
+	}
+	return report
+}
+
+// Ping kiểm tra kết nối primary
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func pingPool(ctx context.Context, name string, db *gorm.DB) PoolHealth {
+	start := time.Now()
+	err := Ping(ctx, db)
+	h := poolStats(name, db)
+	h.LatencyMs = time.Since(start).Milliseconds()
+	h.Healthy = err == nil
+	if err != nil {
+		h.Error = err.Error()
+	}
+	return h
+}
+
+func poolStats(name string, db *gorm.DB) PoolHealth {
+	h := PoolHealth{Name: name}
+	if sqlDB, err := db.DB(); err == nil {
+		stats := sqlDB.Stats()
+		h.OpenConnections = stats.OpenConnections
+		h.InUse = stats.InUse
+		h.Idle = stats.Idle
+		h.WaitCount = stats.WaitCount
+	}
+	return h
+}
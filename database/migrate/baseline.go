@@ -0,0 +1,160 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder ghi lại câu lệnh SQL mà GORM sinh ra ở chế độ DryRun
+type sqlRecorder struct {
+	statements []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	upper := strings.ToUpper(strings.TrimSpace(sql))
+	// Bỏ các câu SELECT kiểm tra bảng/cột mà migrator chạy trước khi tạo
+	if strings.HasPrefix(upper, "CREATE") || strings.HasPrefix(upper, "ALTER") {
+		r.statements = append(r.statements, sql)
+	}
+}
+
+// GenerateBaseline sinh migration SQL tạo toàn bộ schema từ các model GORM (không cần kết nối database).
+// Kết quả dùng làm migration đầu tiên; database đã có schema từ AutoMigrate thì dùng Runner.MarkApplied.
+// Baseline chỉ sinh một lần rồi commit: thứ tự bảng/constraint do GORM quyết định có thể khác giữa các lần sinh.
+func GenerateBaseline(models ...interface{}) (up string, down string, err error) {
+	recorder := &sqlRecorder{}
+
+	// Migrator của GORM in mọi câu lệnh DryRun ra stdout; tạm chuyển hướng để không lẫn vào output của caller
+	if devNull, openErr := os.OpenFile(os.DevNull, os.O_WRONLY, 0); openErr == nil {
+		stdout := os.Stdout
+		os.Stdout = devNull
+		defer func() {
+			os.Stdout = stdout
+			devNull.Close()
+		}()
+	}
+
+	// DSN không bao giờ được kết nối vì DryRun và tắt ping
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=baseline sslmode=disable"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               recorder,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		return "", "", fmt.Errorf("failed to generate baseline: %w", err)
+	}
+
+	statements := sortIndexRuns(recorder.statements)
+
+	var upSQL, downSQL strings.Builder
+	upSQL.WriteString("-- Baseline sinh tự động từ model GORM\n")
+	var tables []string
+	for _, stmt := range statements {
+		upSQL.WriteString(stmt)
+		upSQL.WriteString(";\n")
+		if table := createdTable(stmt); table != "" {
+			tables = append(tables, table)
+		}
+	}
+	for i := len(tables) - 1; i >= 0; i-- {
+		fmt.Fprintf(&downSQL, "DROP TABLE IF EXISTS %s CASCADE;\n", tables[i])
+	}
+	return upSQL.String(), downSQL.String(), nil
+}
+
+// BaselineVersion là version của migration baseline
+const BaselineVersion int64 = 1
+
+// ErrBaselineExists báo thư mục đã có migration baseline
+var ErrBaselineExists = errors.New("baseline migration already exists")
+
+// WriteBaseline sinh baseline từ model và ghi vào dir thành 0001_baseline.up.sql / .down.sql.
+// Từ chối khi dir đã có migration version 1: migration đó có thể đã chạy ở database nào đó và
+// ghi đè sẽ làm checksum trong schema_migrations không còn khớp; thay đổi schema thì thêm migration mới.
+func WriteBaseline(dir string, models ...interface{}) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		if version, err := strconv.ParseInt(match[1], 10, 64); err == nil && version == BaselineVersion {
+			return fmt.Errorf("%w: %s", ErrBaselineExists, filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	up, down, err := GenerateBaseline(models...)
+	if err != nil {
+		return err
+	}
+	if err := writeNewFile(filepath.Join(dir, "0001_baseline.up.sql"), up); err != nil {
+		return err
+	}
+	return writeNewFile(filepath.Join(dir, "0001_baseline.down.sql"), down)
+}
+
+// writeNewFile ghi file mới, lỗi nếu file đã tồn tại
+func writeNewFile(name, content string) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// sortIndexRuns sắp xếp các CREATE INDEX liền nhau của cùng một bảng;
+// GORM duyệt index qua map nên thứ tự không cố định, sắp xếp lại để giảm khác biệt khi sinh lại baseline
+func sortIndexRuns(statements []string) []string {
+	result := append([]string(nil), statements...)
+	start := -1
+	for i := 0; i <= len(result); i++ {
+		isIndex := i < len(result) && strings.HasPrefix(strings.TrimLeft(strings.ToUpper(result[i]), " "), "CREATE ") &&
+			strings.Contains(strings.ToUpper(result[i]), " INDEX ")
+		if isIndex && start < 0 {
+			start = i
+		}
+		if !isIndex && start >= 0 {
+			sort.Strings(result[start:i])
+			start = -1
+		}
+	}
+	return result
+}
+
+func createdTable(stmt string) string {
+	const prefix = "CREATE TABLE "
+	if !strings.HasPrefix(stmt, prefix) {
+		return ""
+	}
+	rest := stmt[len(prefix):]
+	if end := strings.Index(rest, " "); end > 0 {
+		return rest[:end]
+	}
+	return ""
+}
@@ -0,0 +1,360 @@
+// Package migrate chạy migration có đánh số phiên bản (SQL hoặc Go) cho Postgres.
+//
+// Mỗi migration có version tăng dần, phần up và (không bắt buộc) down. Migration đã chạy
+// được ghi vào bảng schema_migrations kèm checksum; sửa nội dung một migration đã chạy sẽ
+// bị phát hiện. Runner giữ Postgres advisory lock trong suốt quá trình chạy để nhiều
+// instance khởi động cùng lúc không migrate chồng lên nhau.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultLockKey là khóa pg_advisory_lock mặc định của runner
+const DefaultLockKey int64 = 72_617_160_401
+
+// Migration là một bước thay đổi schema/dữ liệu.
+// Dùng UpSQL/DownSQL cho migration SQL hoặc UpFunc/DownFunc cho migration Go (backfill dữ liệu...).
+type Migration struct {
+	Version int64
+	Name    string
+
+	UpSQL   string
+	DownSQL string
+
+	UpFunc   func(tx *gorm.DB) error
+	DownFunc func(tx *gorm.DB) error
+
+	// NoTransaction chạy migration ngoài transaction (ví dụ CREATE INDEX CONCURRENTLY)
+	NoTransaction bool
+}
+
+// Checksum là SHA-256 của nội dung up/down; migration Go chỉ băm version và tên
+func (m Migration) Checksum() string {
+	h := sha256.New()
+	if m.UpFunc != nil || m.DownFunc != nil {
+		fmt.Fprintf(h, "go:%d:%s", m.Version, m.Name)
+	} else {
+		h.Write([]byte(m.UpSQL))
+		h.Write([]byte{0})
+		h.Write([]byte(m.DownSQL))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (m Migration) hasDown() bool {
+	return m.DownFunc != nil || m.DownSQL != ""
+}
+
+// AppliedMigration là một dòng trong bảng schema_migrations
+type AppliedMigration struct {
+	Version     int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name        string    `gorm:"size:255;not null"`
+	Checksum    string    `gorm:"size:64;not null"`
+	AppliedAt   time.Time `gorm:"not null"`
+	ExecutionMs int64     `gorm:"not null;default:0"`
+}
+
+// TableName chỉ định tên bảng cho AppliedMigration
+func (AppliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status là trạng thái của một migration
+type Status struct {
+	Version          int64      `json:"version"`
+	Name             string     `json:"name"`
+	Applied          bool       `json:"applied"`
+	AppliedAt        *time.Time `json:"applied_at,omitempty"`
+	ChecksumMismatch bool       `json:"checksum_mismatch,omitempty"` // nội dung đã bị sửa sau khi chạy
+	Missing          bool       `json:"missing,omitempty"`           // đã chạy nhưng không còn trong source
+}
+
+var (
+	ErrDuplicateVersion = errors.New("duplicate migration version")
+	ErrChecksumMismatch = errors.New("applied migration has been modified")
+	ErrNoDownMigration  = errors.New("migration has no down step")
+)
+
+// Runner chạy các migration theo thứ tự version
+type Runner struct {
+	db         *gorm.DB
+	migrations []Migration
+	lockKey    int64
+	logf       func(format string, args ...interface{})
+}
+
+// NewRunner tạo runner; migration được sắp theo version và không được trùng version
+func NewRunner(db *gorm.DB, migrations ...Migration) (*Runner, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateVersion, sorted[i].Version)
+		}
+	}
+	for _, m := range sorted {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("migration %q: version must be positive", m.Name)
+		}
+		if m.UpSQL == "" && m.UpFunc == nil {
+			return nil, fmt.Errorf("migration %d_%s has no up step", m.Version, m.Name)
+		}
+	}
+	return &Runner{db: db, migrations: sorted, lockKey: DefaultLockKey, logf: func(string, ...interface{}) {}}, nil
+}
+
+// WithLockKey đổi khóa advisory lock, dùng khi nhiều ứng dụng chung một database
+func (r *Runner) WithLockKey(key int64) *Runner {
+	r.lockKey = key
+	return r
+}
+
+// WithLogger bật log từng migration, ví dụ log.Printf
+func (r *Runner) WithLogger(logf func(format string, args ...interface{})) *Runner {
+	r.logf = logf
+	return r
+}
+
+// Migrations trả về danh sách migration đã sắp xếp
+func (r *Runner) Migrations() []Migration {
+	return append([]Migration(nil), r.migrations...)
+}
+
+// Up chạy mọi migration chưa chạy; trả về các migration đã áp dụng
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	return r.UpTo(ctx, 0)
+}
+
+// UpTo chạy các migration chưa chạy có version <= target (target = 0 nghĩa là tất cả)
+func (r *Runner) UpTo(ctx context.Context, target int64) ([]Migration, error) {
+	var done []Migration
+	err := r.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := r.verify(conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range r.migrations {
+			if target > 0 && m.Version > target {
+				break
+			}
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if err := r.apply(conn, m); err != nil {
+				return err
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rollback steps migration gần nhất theo thứ tự ngược
+func (r *Runner) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	var done []Migration
+	err := r.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := r.verify(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(r.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			m := r.migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if err := r.revert(conn, m); err != nil {
+				return err
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Plan trả về các migration sẽ được chạy bởi Up mà không thay đổi database
+func (r *Runner) Plan(ctx context.Context) ([]Migration, error) {
+	applied, err := r.applied(r.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range r.migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Status trả về trạng thái của mọi migration, kể cả migration đã chạy nhưng không còn trong source
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.applied(r.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(r.migrations))
+	known := make(map[int64]bool, len(r.migrations))
+	for _, m := range r.migrations {
+		known[m.Version] = true
+		st := Status{Version: m.Version, Name: m.Name}
+		if row, ok := applied[m.Version]; ok {
+			appliedAt := row.AppliedAt
+			st.Applied = true
+			st.AppliedAt = &appliedAt
+			st.ChecksumMismatch = row.Checksum != m.Checksum()
+		}
+		statuses = append(statuses, st)
+	}
+	for version, row := range applied {
+		if !known[version] {
+			appliedAt := row.AppliedAt
+			statuses = append(statuses, Status{Version: version, Name: row.Name, Applied: true, AppliedAt: &appliedAt, Missing: true})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// MarkApplied ghi nhận các migration có version <= version là đã chạy mà không thực thi chúng.
+// Dùng khi đưa database đã được AutoMigrate sang migration có phiên bản (baseline).
+func (r *Runner) MarkApplied(ctx context.Context, version int64) ([]Migration, error) {
+	var done []Migration
+	err := r.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := r.applied(conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range r.migrations {
+			if m.Version > version {
+				break
+			}
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if err := conn.Create(&AppliedMigration{
+				Version:   m.Version,
+				Name:      m.Name,
+				Checksum:  m.Checksum(),
+				AppliedAt: time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// withLock giữ một connection riêng cho advisory lock và toàn bộ migration
+func (r *Runner) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", r.lockKey).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", r.lockKey)
+
+		if err := conn.AutoMigrate(&AppliedMigration{}); err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+		return fn(conn)
+	})
+}
+
+func (r *Runner) applied(db *gorm.DB) (map[int64]AppliedMigration, error) {
+	result := make(map[int64]AppliedMigration)
+	if !db.Migrator().HasTable(&AppliedMigration{}) {
+		return result, nil
+	}
+	var rows []AppliedMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.Version] = row
+	}
+	return result, nil
+}
+
+// verify đọc các migration đã chạy và từ chối chạy tiếp nếu có migration đã bị sửa
+func (r *Runner) verify(conn *gorm.DB) (map[int64]AppliedMigration, error) {
+	applied, err := r.applied(conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range r.migrations {
+		if row, ok := applied[m.Version]; ok && row.Checksum != m.Checksum() {
+			return nil, fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, m.Version, m.Name)
+		}
+	}
+	return applied, nil
+}
+
+func (r *Runner) apply(conn *gorm.DB, m Migration) error {
+	start := time.Now()
+	run := func(tx *gorm.DB) error {
+		if err := execStep(tx, m.UpSQL, m.UpFunc); err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		return tx.Create(&AppliedMigration{
+			Version:     m.Version,
+			Name:        m.Name,
+			Checksum:    m.Checksum(),
+			AppliedAt:   time.Now(),
+			ExecutionMs: time.Since(start).Milliseconds(),
+		}).Error
+	}
+	var err error
+	if m.NoTransaction {
+		err = run(conn)
+	} else {
+		err = conn.Transaction(run)
+	}
+	if err == nil {
+		r.logf("migrate: applied %d_%s (%s)", m.Version, m.Name, time.Since(start).Round(time.Millisecond))
+	}
+	return err
+}
+
+func (r *Runner) revert(conn *gorm.DB, m Migration) error {
+	if !m.hasDown() {
+		return fmt.Errorf("%w: %d_%s", ErrNoDownMigration, m.Version, m.Name)
+	}
+	run := func(tx *gorm.DB) error {
+		if err := execStep(tx, m.DownSQL, m.DownFunc); err != nil {
+			return fmt.Errorf("rollback %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		return tx.Delete(&AppliedMigration{}, "version = ?", m.Version).Error
+	}
+	var err error
+	if m.NoTransaction {
+		err = run(conn)
+	} else {
+		err = conn.Transaction(run)
+	}
+	if err == nil {
+		r.logf("migrate: rolled back %d_%s", m.Version, m.Name)
+	}
+	return err
+}
+
+func execStep(tx *gorm.DB, sql string, fn func(*gorm.DB) error) error {
+	if fn != nil {
+		return fn(tx)
+	}
+	return tx.Exec(sql).Error
+}
@@ -0,0 +1,154 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/migrate"
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"gorm.io/gorm"
+)
+
+func testMigrations() []migrate.Migration {
+	return []migrate.Migration{
+		{Version: 1, Name: "create_items", UpSQL: "CREATE TABLE items (id int PRIMARY KEY)", DownSQL: "DROP TABLE items"},
+		{Version: 2, Name: "add_name", UpSQL: "ALTER TABLE items ADD COLUMN name text", DownSQL: "ALTER TABLE items DROP COLUMN name"},
+		{Version: 3, Name: "seed", UpFunc: func(tx *gorm.DB) error {
+			return tx.Exec("INSERT INTO items (id, name) VALUES (1, 'first')").Error
+		}, DownFunc: func(tx *gorm.DB) error {
+			return tx.Exec("DELETE FROM items").Error
+		}},
+	}
+}
+
+func versions(migrations []migrate.Migration) []int64 {
+	result := []int64{}
+	for _, m := range migrations {
+		result = append(result, m.Version)
+	}
+	return result
+}
+
+func TestRunner(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("TestUpDownStatus", func(t *testing.T) {
+		db := testdb.OpenEmpty(t)
+		runner, err := migrate.NewRunner(db, testMigrations()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done, err := runner.UpTo(ctx, 2); err != nil || len(done) != 2 {
+			t.Fatalf("Expected 2 migrations applied, got %v, %v", versions(done), err)
+		}
+		if pending, err := runner.Plan(ctx); err != nil || len(pending) != 1 || pending[0].Version != 3 {
+			t.Errorf("Expected version 3 pending, got %v, %v", versions(pending), err)
+		}
+		if done, err := runner.Up(ctx); err != nil || len(done) != 1 {
+			t.Fatalf("Expected 1 migration applied, got %v, %v", versions(done), err)
+		}
+		var count int64
+		if err := db.Table("items").Count(&count).Error; err != nil || count != 1 {
+			t.Errorf("Expected seeded row, got %d, %v", count, err)
+		}
+		if done, err := runner.Up(ctx); err != nil || len(done) != 0 {
+			t.Errorf("Expected Up to be idempotent, got %v, %v", versions(done), err)
+		}
+
+		done, err := runner.Down(ctx, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := versions(done); len(got) != 2 || got[0] != 3 || got[1] != 2 {
+			t.Errorf("Expected versions 3 and 2 rolled back, got %v", got)
+		}
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, st := range statuses {
+			if st.Applied != (st.Version == 1) {
+				t.Errorf("Expected only version 1 applied, got %+v", st)
+			}
+		}
+	})
+
+	t.Run("TestChecksumMismatch", func(t *testing.T) {
+		db := testdb.OpenEmpty(t)
+		runner, err := migrate.NewRunner(db, testMigrations()[:1]...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runner.Up(ctx); err != nil {
+			t.Fatal(err)
+		}
+		modified := testMigrations()
+		modified[0].UpSQL += " -- edited"
+		runner, err = migrate.NewRunner(db, modified...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runner.Up(ctx); !errors.Is(err, migrate.ErrChecksumMismatch) {
+			t.Errorf("Expected ErrChecksumMismatch, got %v", err)
+		}
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !statuses[0].ChecksumMismatch {
+			t.Errorf("Expected status to report the modified migration, got %+v", statuses[0])
+		}
+	})
+
+	t.Run("TestFailedMigrationRollsBack", func(t *testing.T) {
+		db := testdb.OpenEmpty(t)
+		broken := append(testMigrations()[:1], migrate.Migration{
+			Version: 2, Name: "broken", UpSQL: "ALTER TABLE items ADD COLUMN x int; SELECT missing_function()",
+		})
+		runner, err := migrate.NewRunner(db, broken...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runner.Up(ctx); err == nil {
+			t.Fatal("Expected migration error")
+		}
+		if db.Migrator().HasColumn("items", "x") {
+			t.Errorf("Expected failed migration to be rolled back")
+		}
+		if pending, _ := runner.Plan(ctx); len(pending) != 1 || pending[0].Version != 2 {
+			t.Errorf("Expected version 2 still pending, got %v", versions(pending))
+		}
+	})
+
+	t.Run("TestMarkApplied", func(t *testing.T) {
+		db := testdb.OpenEmpty(t)
+		runner, err := migrate.NewRunner(db, testMigrations()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done, err := runner.MarkApplied(ctx, 1); err != nil || len(done) != 1 {
+			t.Fatalf("Expected version 1 marked, got %v, %v", versions(done), err)
+		}
+		if db.Migrator().HasTable("items") {
+			t.Errorf("Expected MarkApplied not to run the migration")
+		}
+		if pending, _ := runner.Plan(ctx); len(pending) != 2 {
+			t.Errorf("Expected 2 pending migrations, got %v", versions(pending))
+		}
+	})
+
+	t.Run("TestDownWithoutDownStep", func(t *testing.T) {
+		db := testdb.OpenEmpty(t)
+		runner, err := migrate.NewRunner(db, migrate.Migration{Version: 1, Name: "one_way", UpSQL: "CREATE TABLE one_way (id int)"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runner.Up(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runner.Down(ctx, 1); !errors.Is(err, migrate.ErrNoDownMigration) {
+			t.Errorf("Expected ErrNoDownMigration, got %v", err)
+		}
+	})
+}
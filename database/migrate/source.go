@@ -0,0 +1,71 @@
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fileNamePattern khớp tên file dạng 0001_create_users.up.sql / 0001_create_users.down.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_\-]+)\.(up|down)\.sql$`)
+
+// noTransactionMarker đặt ở đầu file up để chạy migration ngoài transaction
+const noTransactionMarker = "-- migrate:no-transaction"
+
+// LoadFS đọc migration SQL trong thư mục dir của fsys (thường là embed.FS).
+// File không khớp quy ước tên bị bỏ qua; mỗi version phải có file up.
+func LoadFS(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	var order []int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+			order = append(order, version)
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("%w: %d (%s and %s)", ErrDuplicateVersion, version, m.Name, match[2])
+		}
+
+		sql := string(content)
+		if match[3] == "up" {
+			m.UpSQL = sql
+			m.NoTransaction = strings.HasPrefix(strings.TrimSpace(sql), noTransactionMarker)
+		} else {
+			m.DownSQL = sql
+		}
+	}
+
+	migrations := make([]Migration, 0, len(order))
+	for _, version := range order {
+		m := byVersion[version]
+		if m.UpSQL == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	return migrations, nil
+}
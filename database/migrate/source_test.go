@@ -0,0 +1,179 @@
+package migrate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/gorm"
+)
+
+func TestLoadFS(t *testing.T) {
+	t.Run("TestLoadsPairsInVersionOrder", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"m/0002_add_index.up.sql":     {Data: []byte("-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY x ON t (a);")},
+			"m/0001_create_t.up.sql":      {Data: []byte("CREATE TABLE t (a int);")},
+			"m/0001_create_t.down.sql":    {Data: []byte("DROP TABLE t;")},
+			"m/README.md":                 {Data: []byte("ignored")},
+			"m/0003_bad name.up.sql":      {Data: []byte("ignored")},
+			"m/nested/0004_child.up.sql":  {Data: []byte("ignored")},
+			"m/0005_no_extension.up.psql": {Data: []byte("ignored")},
+		}
+		migrations, err := LoadFS(fsys, "m")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(migrations) != 2 {
+			t.Fatalf("Expected 2 migrations, got %d: %+v", len(migrations), migrations)
+		}
+		first, second := migrations[0], migrations[1]
+		if first.Version != 1 || first.Name != "create_t" || first.DownSQL != "DROP TABLE t;" || first.NoTransaction {
+			t.Errorf("Unexpected first migration: %+v", first)
+		}
+		if second.Version != 2 || second.DownSQL != "" || !second.NoTransaction {
+			t.Errorf("Unexpected second migration: %+v", second)
+		}
+	})
+
+	cases := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr error
+		wantMsg string
+	}{
+		{"MissingUp", fstest.MapFS{"m/0001_a.down.sql": {Data: []byte("x")}}, nil, "has no up file"},
+		{"DuplicateVersion", fstest.MapFS{
+			"m/0001_a.up.sql": {Data: []byte("x")},
+			"m/0001_b.up.sql": {Data: []byte("y")},
+		}, ErrDuplicateVersion, ""},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			_, err := LoadFS(tc.fsys, "m")
+			if err == nil {
+				t.Fatalf("Expected error")
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("Expected %v, got %v", tc.wantErr, err)
+			}
+			if tc.wantMsg != "" && !strings.Contains(err.Error(), tc.wantMsg) {
+				t.Errorf("Expected error containing %q, got %v", tc.wantMsg, err)
+			}
+		})
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	base := Migration{Version: 1, Name: "a", UpSQL: "CREATE TABLE t (a int);", DownSQL: "DROP TABLE t;"}
+
+	t.Run("TestStable", func(t *testing.T) {
+		if base.Checksum() != base.Checksum() || len(base.Checksum()) != 64 {
+			t.Errorf("Expected a stable SHA-256 hex checksum, got %q", base.Checksum())
+		}
+	})
+
+	t.Run("TestContentChanges", func(t *testing.T) {
+		changedUp, changedDown := base, base
+		changedUp.UpSQL += " "
+		changedDown.DownSQL = ""
+		// Ranh giới up/down nằm trong checksum nên dời nội dung giữa hai phần cũng bị phát hiện
+		shifted := Migration{Version: 1, Name: "a", UpSQL: base.UpSQL + base.DownSQL}
+		for name, m := range map[string]Migration{"up": changedUp, "down": changedDown, "shifted": shifted} {
+			if m.Checksum() == base.Checksum() {
+				t.Errorf("Expected checksum to change when %s changes", name)
+			}
+		}
+	})
+
+	t.Run("TestGoMigration", func(t *testing.T) {
+		goMigration := Migration{Version: 2, Name: "backfill", UpFunc: func(*gorm.DB) error { return nil }}
+		renamed := goMigration
+		renamed.Name = "backfill_v2"
+		if goMigration.Checksum() == renamed.Checksum() {
+			t.Errorf("Expected Go migration checksum to depend on its name")
+		}
+		withSQL := goMigration
+		withSQL.UpSQL = "ignored"
+		if goMigration.Checksum() != withSQL.Checksum() {
+			t.Errorf("Expected Go migration checksum to ignore SQL")
+		}
+	})
+}
+
+func TestNewRunner(t *testing.T) {
+	t.Run("TestSortsByVersion", func(t *testing.T) {
+		runner, err := NewRunner(nil,
+			Migration{Version: 10, Name: "c", UpSQL: "x"},
+			Migration{Version: 2, Name: "b", UpSQL: "x"},
+			Migration{Version: 1, Name: "a", UpSQL: "x"},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var versions []int64
+		for _, m := range runner.Migrations() {
+			versions = append(versions, m.Version)
+		}
+		if len(versions) != 3 || versions[0] != 1 || versions[1] != 2 || versions[2] != 10 {
+			t.Errorf("Expected versions [1 2 10], got %v", versions)
+		}
+	})
+
+	cases := []struct {
+		name       string
+		migrations []Migration
+	}{
+		{"DuplicateVersion", []Migration{{Version: 1, Name: "a", UpSQL: "x"}, {Version: 1, Name: "b", UpSQL: "y"}}},
+		{"NonPositiveVersion", []Migration{{Version: 0, Name: "a", UpSQL: "x"}}},
+		{"NoUpStep", []Migration{{Version: 1, Name: "a", DownSQL: "x"}}},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			if _, err := NewRunner(nil, tc.migrations...); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestWriteBaseline(t *testing.T) {
+	type Widget struct {
+		ID   uint
+		Name string
+	}
+
+	t.Run("TestWritesFiles", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := WriteBaseline(dir, &Widget{}); err != nil {
+			t.Fatal(err)
+		}
+		up, err := os.ReadFile(filepath.Join(dir, "0001_baseline.up.sql"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(up), `CREATE TABLE "widgets"`) {
+			t.Errorf("Expected widgets table in baseline, got %s", up)
+		}
+	})
+
+	t.Run("TestRefusesExistingVersion", func(t *testing.T) {
+		dir := t.TempDir()
+		existing := filepath.Join(dir, "0001_initial.up.sql")
+		if err := os.WriteFile(existing, []byte("CREATE TABLE applied ();"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteBaseline(dir, &Widget{}); !errors.Is(err, ErrBaselineExists) {
+			t.Fatalf("Expected ErrBaselineExists, got %v", err)
+		}
+		content, _ := os.ReadFile(existing)
+		if string(content) != "CREATE TABLE applied ();" {
+			t.Errorf("Expected existing migration to be untouched, got %s", content)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "0001_baseline.up.sql")); !os.IsNotExist(err) {
+			t.Errorf("Expected no baseline file to be written, got %v", err)
+		}
+	})
+}
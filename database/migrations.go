@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"log"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/migrate"
+	"gorm.io/gorm"
+)
+
+// migrationFiles chứa các migration SQL có đánh số của thư viện
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations trả về các migration SQL có sẵn cùng các migration bổ sung (SQL hoặc Go) của ứng dụng
+func Migrations(extra ...migrate.Migration) ([]migrate.Migration, error) {
+	migrations, err := migrate.LoadFS(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return append(migrations, extra...), nil
+}
+
+// NewMigrationRunner tạo runner cho các migration của thư viện và migration bổ sung
+func NewMigrationRunner(db *gorm.DB, extra ...migrate.Migration) (*migrate.Runner, error) {
+	migrations, err := Migrations(extra...)
+	if err != nil {
+		return nil, err
+	}
+	runner, err := migrate.NewRunner(db, migrations...)
+	if err != nil {
+		return nil, err
+	}
+	return runner.WithLogger(log.Printf), nil
+}
+
+// VersionedMigrator trả về hàm migrate dùng cho Init thay cho DBMigrator (AutoMigrate):
+//
+//	db, err := database.Init(cfg, database.VersionedMigrator())
+func VersionedMigrator(extra ...migrate.Migration) func(*gorm.DB) error {
+	return func(db *gorm.DB) error {
+		runner, err := NewMigrationRunner(db, extra...)
+		if err != nil {
+			return err
+		}
+		_, err = runner.Up(context.Background())
+		return err
+	}
+}
@@ -0,0 +1,41 @@
+DROP TABLE IF EXISTS "rate_limit_counters" CASCADE;
+DROP TABLE IF EXISTS "user_roles" CASCADE;
+DROP TABLE IF EXISTS "rule_roles" CASCADE;
+DROP TABLE IF EXISTS "rules" CASCADE;
+DROP TABLE IF EXISTS "token_revocations" CASCADE;
+DROP TABLE IF EXISTS "api_key_audit_logs" CASCADE;
+DROP TABLE IF EXISTS "api_key_roles" CASCADE;
+DROP TABLE IF EXISTS "roles" CASCADE;
+DROP TABLE IF EXISTS "api_keys" CASCADE;
+DROP TABLE IF EXISTS "sessions" CASCADE;
+DROP TABLE IF EXISTS "refresh_tokens" CASCADE;
+DROP TABLE IF EXISTS "mfa_recovery_codes" CASCADE;
+DROP TABLE IF EXISTS "mfa_factors" CASCADE;
+DROP TABLE IF EXISTS "password_reset_tokens" CASCADE;
+DROP TABLE IF EXISTS "sms_verifications" CASCADE;
+DROP TABLE IF EXISTS "auth_providers" CASCADE;
+DROP TABLE IF EXISTS "dialog_completions" CASCADE;
+DROP TABLE IF EXISTS "customer_topic_progress" CASCADE;
+DROP TABLE IF EXISTS "dialog_statistics" CASCADE;
+DROP TABLE IF EXISTS "customer_statistics" CASCADE;
+DROP TABLE IF EXISTS "system_statistics" CASCADE;
+DROP TABLE IF EXISTS "history_log" CASCADE;
+DROP TABLE IF EXISTS "payment_logs" CASCADE;
+DROP TABLE IF EXISTS "payments" CASCADE;
+DROP TABLE IF EXISTS "customer_subscriptions" CASCADE;
+DROP TABLE IF EXISTS "subscriptions" CASCADE;
+DROP TABLE IF EXISTS "customer_achievements" CASCADE;
+DROP TABLE IF EXISTS "achievements" CASCADE;
+DROP TABLE IF EXISTS "comments" CASCADE;
+DROP TABLE IF EXISTS "fill_in_blanks" CASCADE;
+DROP TABLE IF EXISTS "character_descriptions" CASCADE;
+DROP TABLE IF EXISTS "images" CASCADE;
+DROP TABLE IF EXISTS "audios" CASCADE;
+DROP TABLE IF EXISTS "word_in_dialog" CASCADE;
+DROP TABLE IF EXISTS "words" CASCADE;
+DROP TABLE IF EXISTS "dialog_tags" CASCADE;
+DROP TABLE IF EXISTS "tags" CASCADE;
+DROP TABLE IF EXISTS "dialogs" CASCADE;
+DROP TABLE IF EXISTS "topics" CASCADE;
+DROP TABLE IF EXISTS "employees" CASCADE;
+DROP TABLE IF EXISTS "customers" CASCADE;
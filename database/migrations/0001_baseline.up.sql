@@ -0,0 +1,115 @@
+-- Baseline sinh tự động từ model GORM
+CREATE TABLE "customers" ("id" varchar(50),"name" varchar(50),"email" varchar(100),"phone_number" varchar(20),"password" varchar(255),"avatar_url" text,"last_login" timestamptz,"is_guest" boolean DEFAULT false,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_customers_is_guest" ON "customers" ("is_guest");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_customers_email" ON "customers" ("email");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_customers_name" ON "customers" ("name");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_customers_phone_number" ON "customers" ("phone_number");
+CREATE TABLE "employees" ("id" varchar(12),"name" varchar(50),"email" varchar(100),"password" varchar(255),"failed_login_attempts" bigint DEFAULT 0,"locked_until" timestamptz,"last_login" timestamptz,"password_changed_at" timestamptz,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_employees_email" ON "employees" ("email");
+CREATE TABLE "topics" ("id" varchar(12),"title" varchar(200),PRIMARY KEY ("id"));
+CREATE TABLE "dialogs" ("id" varchar(12),"topic_id" varchar(12) NOT NULL,"prev_id" varchar(12),"next_id" varchar(12),"script" text,"title" varchar(200) NOT NULL,"raw_text" text NOT NULL,"avg_rating" decimal DEFAULT 0,"result" jsonb,"author_id" varchar(12) NOT NULL,"fixer_id" varchar(12),PRIMARY KEY ("id"),CONSTRAINT "fk_dialogs_fixer" FOREIGN KEY ("fixer_id") REFERENCES "employees"("id"),CONSTRAINT "fk_dialogs_topic" FOREIGN KEY ("topic_id") REFERENCES "topics"("id"),CONSTRAINT "fk_dialogs_author" FOREIGN KEY ("author_id") REFERENCES "employees"("id"));
+CREATE INDEX IF NOT EXISTS "idx_dialogs_author_id" ON "dialogs" ("author_id");
+CREATE INDEX IF NOT EXISTS "idx_dialogs_fixer_id" ON "dialogs" ("fixer_id");
+CREATE INDEX IF NOT EXISTS "idx_dialogs_next_id" ON "dialogs" ("next_id");
+CREATE INDEX IF NOT EXISTS "idx_dialogs_prev_id" ON "dialogs" ("prev_id");
+CREATE INDEX IF NOT EXISTS "idx_dialogs_topic_id" ON "dialogs" ("topic_id");
+CREATE TABLE "tags" ("id" varchar(12),"name" varchar(100),PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_tags_name" ON "tags" ("name");
+CREATE TABLE "dialog_tags" ("id" varchar(12),"dialog_id" varchar(12),"tag_id" varchar(12),PRIMARY KEY ("id"),CONSTRAINT "fk_dialog_tags_dialog" FOREIGN KEY ("dialog_id") REFERENCES "dialogs"("id"),CONSTRAINT "fk_dialog_tags_tag" FOREIGN KEY ("tag_id") REFERENCES "tags"("id"));
+CREATE INDEX IF NOT EXISTS "idx_dialog_tags_dialog_id" ON "dialog_tags" ("dialog_id");
+CREATE INDEX IF NOT EXISTS "idx_dialog_tags_tag_id" ON "dialog_tags" ("tag_id");
+CREATE TABLE "words" ("id" varchar(12),"prompt_id" varchar(12),"text" varchar(200),"pronunciation" text,"meaning" text,"part_of_speech" text,"example" text,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_words_deleted_at" ON "words" ("deleted_at");
+CREATE TABLE "word_in_dialog" ("dialog_id" varchar(12),"word_id" varchar(12),CONSTRAINT "fk_word_in_dialog_dialog" FOREIGN KEY ("dialog_id") REFERENCES "dialogs"("id"),CONSTRAINT "fk_word_in_dialog_word" FOREIGN KEY ("word_id") REFERENCES "words"("id"));
+CREATE INDEX IF NOT EXISTS "idx_word_in_dialog_dialog_id" ON "word_in_dialog" ("dialog_id");
+CREATE INDEX IF NOT EXISTS "idx_word_in_dialog_word_id" ON "word_in_dialog" ("word_id");
+CREATE TABLE "audios" ("id" varchar(12),"dialog_id" varchar(12),"file_url" text,PRIMARY KEY ("id"),CONSTRAINT "fk_dialogs_audios" FOREIGN KEY ("dialog_id") REFERENCES "dialogs"("id"));
+CREATE INDEX IF NOT EXISTS "idx_audios_dialog_id" ON "audios" ("dialog_id");
+CREATE TABLE "images" ("id" varchar(12),"dialog_id" varchar(12),"topic_id" varchar(12),"file_url" text NOT NULL,"is_figure" boolean NOT NULL DEFAULT false,"author_id" varchar(12) NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "fk_images_author" FOREIGN KEY ("author_id") REFERENCES "employees"("id"),CONSTRAINT "fk_images_topic" FOREIGN KEY ("topic_id") REFERENCES "topics"("id"),CONSTRAINT "fk_dialogs_images" FOREIGN KEY ("dialog_id") REFERENCES "dialogs"("id"));
+CREATE INDEX IF NOT EXISTS "idx_images_author_id" ON "images" ("author_id");
+CREATE INDEX IF NOT EXISTS "idx_images_dialog_id" ON "images" ("dialog_id");
+CREATE INDEX IF NOT EXISTS "idx_images_topic_id" ON "images" ("topic_id");
+CREATE TABLE "character_descriptions" ("id" varchar(12),"description1" text  NOT NULL,"description2" text  NOT NULL,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_character_descriptions_id" ON "character_descriptions" ("id");
+CREATE TABLE "fill_in_blanks" ("id" varchar(12),"dialog_id" varchar(12),"prompt_id" varchar(12),"words_index" jsonb,PRIMARY KEY ("id"),CONSTRAINT "fk_dialogs_fill_in_blanks" FOREIGN KEY ("dialog_id") REFERENCES "dialogs"("id"));
+CREATE INDEX IF NOT EXISTS "idx_fill_in_blanks_dialog_id" ON "fill_in_blanks" ("dialog_id");
+CREATE TABLE "comments" ("id" varchar(12),"dialog_id" varchar(12),"user_id" varchar(50),"content" text,"likes" bigint DEFAULT 0,"rating" bigint,"parent_comment_id" varchar(12),"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_dialogs_comments" FOREIGN KEY ("dialog_id") REFERENCES "dialogs"("id"),CONSTRAINT "fk_comments_user" FOREIGN KEY ("user_id") REFERENCES "customers"("id"),CONSTRAINT "fk_comments_replies" FOREIGN KEY ("parent_comment_id") REFERENCES "comments"("id"));
+CREATE INDEX IF NOT EXISTS "idx_comments_dialog_id" ON "comments" ("dialog_id");
+CREATE INDEX IF NOT EXISTS "idx_comments_user_id" ON "comments" ("user_id");
+CREATE TABLE "achievements" ("id" varchar(12),"title" varchar(200),"icon_unicode" text,"condition_type" varchar(50),"condition_value" bigint DEFAULT 0,"reward_points" bigint DEFAULT 0,"group_type" varchar(50),PRIMARY KEY ("id"));
+CREATE TABLE "customer_achievements" ("customer_id" varchar(50),"achievement_id" varchar(12),"claimed" boolean DEFAULT false,"unlocked" boolean DEFAULT false,CONSTRAINT "fk_customer_achievements_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id"),CONSTRAINT "fk_customer_achievements_achievement" FOREIGN KEY ("achievement_id") REFERENCES "achievements"("id"));
+CREATE INDEX IF NOT EXISTS "idx_customer_achievements_achievement_id" ON "customer_achievements" ("achievement_id");
+CREATE INDEX IF NOT EXISTS "idx_customer_achievements_customer_id" ON "customer_achievements" ("customer_id");
+CREATE TABLE "subscriptions" ("id" varchar(12),"name" varchar(100) NOT NULL,"description" text,"price" numeric(10,2) DEFAULT 0,"duration" varchar(50) NOT NULL,"is_active" boolean DEFAULT true,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE TABLE "customer_subscriptions" ("id" varchar(50),"customer_id" varchar(50) NOT NULL,"subscription_id" varchar(32) NOT NULL,"payment_id" varchar(32),"original_transaction" varchar(255),"expired_at" timestamptz,"start_date" timestamptz NOT NULL,"end_date" timestamptz,"is_active" boolean DEFAULT true,"auto_renew" boolean DEFAULT false,"canceled_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_customer_subscriptions_customer_id" ON "customer_subscriptions" ("customer_id");
+CREATE INDEX IF NOT EXISTS "idx_customer_subscriptions_payment_id" ON "customer_subscriptions" ("payment_id");
+CREATE INDEX IF NOT EXISTS "idx_customer_subscriptions_subscription_id" ON "customer_subscriptions" ("subscription_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_customer_subscriptions_original_transaction" ON "customer_subscriptions" ("original_transaction");
+CREATE TABLE "payments" ("id" varchar(32),"customer_id" varchar(50) NOT NULL,"subscription_id" varchar(128),"amount" numeric(10,2),"status" varchar(50) DEFAULT 'pending',"method" varchar(50) NOT NULL,"transaction_id" varchar(255),"receipt_data" text,"platform" varchar(50),"verified_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_payments_customer_id" ON "payments" ("customer_id");
+CREATE INDEX IF NOT EXISTS "idx_payments_deleted_at" ON "payments" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_payments_subscription_id" ON "payments" ("subscription_id");
+CREATE TABLE "payment_logs" ("id" varchar(12),"payment_id" varchar(12),"status_before" text,"status_after" text,"raw_response" text,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_payment_logs_deleted_at" ON "payment_logs" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_payment_logs_payment_id" ON "payment_logs" ("payment_id");
+CREATE TABLE "history_log" ("id" varchar(12),"table_name" varchar(50),"record_id" varchar(12),"user_id" varchar(12),"action" varchar(50),"changes" text,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_history_log_deleted_at" ON "history_log" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_history_log_table_ref" ON "history_log" ("table_name");
+CREATE TABLE "system_statistics" ("id" varchar(12),"date" date,"new_users" bigint DEFAULT 0,"active_users" bigint DEFAULT 0,"dialogs_completed" bigint DEFAULT 0,"exercises_completed" bigint DEFAULT 0,"revenue" numeric(12,2) DEFAULT 0,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_system_statistics_date" ON "system_statistics" ("date");
+CREATE TABLE "customer_statistics" ("id" varchar(50),"customer_id" varchar(50),"total_dialogs_completed" bigint DEFAULT 0,"total_exercises_completed" bigint DEFAULT 0,"streak" bigint DEFAULT 0,"score" bigint DEFAULT 0,PRIMARY KEY ("id"),CONSTRAINT "fk_customer_statistics_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_customer_statistics_customer_id" ON "customer_statistics" ("customer_id");
+CREATE TABLE "dialog_statistics" ("id" varchar(12),"dialog_id" varchar(12),"total_attempts" bigint DEFAULT 0,"total_completions" bigint DEFAULT 0,"avg_score" numeric(4,2) DEFAULT 0,"avg_rating" numeric(3,2) DEFAULT 0,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_dialog_statistics_dialog" FOREIGN KEY ("dialog_id") REFERENCES "dialogs"("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_dialog_statistics_dialog_id" ON "dialog_statistics" ("dialog_id");
+CREATE TABLE "customer_topic_progress" ("customer_id" varchar(50) NOT NULL,"topic_id" varchar(12) NOT NULL,"completed_dialog_ids" text DEFAULT '[]',"is_completed" boolean DEFAULT false,"next_dialog_id" varchar(12),"last_updated" bigint NOT NULL,PRIMARY KEY ("customer_id","topic_id"),CONSTRAINT "fk_customer_topic_progress_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id"),CONSTRAINT "fk_customer_topic_progress_topic" FOREIGN KEY ("topic_id") REFERENCES "topics"("id"));
+CREATE TABLE "dialog_completions" ("customer_id" varchar(50) NOT NULL,"dialog_id" varchar(12) NOT NULL,"topic_id" varchar(12),"listening_completed" boolean NOT NULL DEFAULT false,"speaking_completed" boolean NOT NULL DEFAULT false,"writing_completed" boolean NOT NULL DEFAULT false,"score_speaking" bigint DEFAULT 0,"score_writing" bigint DEFAULT 0,PRIMARY KEY ("customer_id","dialog_id"),CONSTRAINT "fk_dialog_completions_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id"),CONSTRAINT "fk_dialog_completions_dialog" FOREIGN KEY ("dialog_id") REFERENCES "dialogs"("id"),CONSTRAINT "fk_dialog_completions_topic" FOREIGN KEY ("topic_id") REFERENCES "topics"("id"));
+CREATE TABLE "auth_providers" ("id" varchar(50),"customer_id" varchar(50),"provider" varchar(20) NOT NULL,"refresh_token" text,"expires_at" timestamptz,"provider_user_id" varchar(128),"email" varchar(100),"last_used_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_auth_providers_customer_id" ON "auth_providers" ("customer_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_auth_provider_uid" ON "auth_providers" ("provider","provider_user_id");
+CREATE TABLE "sms_verifications" ("id" varchar(12),"customer_id" varchar(50),"phone_number" varchar(20) NOT NULL,"code_hash" varchar(64) NOT NULL,"attempts" bigint DEFAULT 0,"verified" boolean DEFAULT false,"verified_at" timestamptz,"expires_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_sms_verifications_created_at" ON "sms_verifications" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_sms_verifications_customer_id" ON "sms_verifications" ("customer_id");
+CREATE INDEX IF NOT EXISTS "idx_sms_verifications_phone_number" ON "sms_verifications" ("phone_number");
+CREATE TABLE "password_reset_tokens" ("id" varchar(12),"employee_id" varchar(12),"token_hash" varchar(64),"expires_at" timestamptz,"used_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_password_reset_tokens_employee" FOREIGN KEY ("employee_id") REFERENCES "employees"("id"));
+CREATE INDEX IF NOT EXISTS "idx_password_reset_tokens_employee_id" ON "password_reset_tokens" ("employee_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_password_reset_tokens_token_hash" ON "password_reset_tokens" ("token_hash");
+CREATE TABLE "mfa_factors" ("id" varchar(12),"user_id" varchar(50),"type" varchar(20) NOT NULL DEFAULT 'totp',"secret_encrypted" text NOT NULL,"last_used_step" bigint DEFAULT 0,"confirmed_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_mfa_factors_user_id" ON "mfa_factors" ("user_id");
+CREATE TABLE "mfa_recovery_codes" ("id" varchar(12),"user_id" varchar(50),"code_hash" varchar(64),"used_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_mfa_recovery_codes_user_id" ON "mfa_recovery_codes" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_mfa_recovery_codes_code_hash" ON "mfa_recovery_codes" ("code_hash");
+CREATE TABLE "refresh_tokens" ("id" varchar(12),"customer_id" varchar(50) NOT NULL,"family_id" varchar(12) NOT NULL,"device_id" varchar(100),"token_hash" varchar(64) NOT NULL,"expires_at" timestamptz NOT NULL,"used_at" timestamptz,"revoked_at" timestamptz,"replaced_by_id" varchar(12),"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_refresh_tokens_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id"));
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_customer_id" ON "refresh_tokens" ("customer_id");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_device_id" ON "refresh_tokens" ("device_id");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_family_id" ON "refresh_tokens" ("family_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_token_hash" ON "refresh_tokens" ("token_hash");
+CREATE TABLE "sessions" ("id" varchar(12),"customer_id" varchar(50) NOT NULL,"device_id" varchar(100),"device_name" varchar(100),"platform" varchar(20),"app_version" varchar(20),"ip_address" varchar(45),"user_agent" varchar(255),"last_seen_at" timestamptz,"revoked_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_sessions_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id"));
+CREATE INDEX IF NOT EXISTS "idx_sessions_customer_id" ON "sessions" ("customer_id");
+CREATE INDEX IF NOT EXISTS "idx_sessions_device_id" ON "sessions" ("device_id");
+CREATE INDEX IF NOT EXISTS "idx_sessions_last_seen_at" ON "sessions" ("last_seen_at");
+CREATE TABLE "api_keys" ("id" varchar(12),"name" varchar(100) NOT NULL,"prefix" varchar(20) NOT NULL,"key_hash" varchar(64) NOT NULL,"owner_id" varchar(50) NOT NULL,"expires_at" timestamptz,"last_used_at" timestamptz,"last_used_ip" varchar(45),"revoked_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_api_keys_owner_id" ON "api_keys" ("owner_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_key_hash" ON "api_keys" ("key_hash");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_prefix" ON "api_keys" ("prefix");
+CREATE TABLE "roles" ("id" bigserial,"name" varchar(100) NOT NULL,"description" varchar(255),PRIMARY KEY ("id"),CONSTRAINT "uni_roles_name" UNIQUE ("name"));
+CREATE INDEX IF NOT EXISTS "idx_roles_name" ON "roles" ("name");
+CREATE TABLE "api_key_roles" ("api_key_id" varchar(12),"role_id" bigint,PRIMARY KEY ("api_key_id","role_id"),CONSTRAINT "fk_api_key_roles_api_key" FOREIGN KEY ("api_key_id") REFERENCES "api_keys"("id") ON DELETE CASCADE,CONSTRAINT "fk_api_key_roles_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id") ON DELETE CASCADE);
+CREATE TABLE "api_key_audit_logs" ("id" bigserial,"api_key_id" varchar(12) NOT NULL,"method" varchar(10),"path" varchar(255),"status" bigint,"ip_address" varchar(45),"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_api_key_audit_logs_api_key_id" ON "api_key_audit_logs" ("api_key_id");
+CREATE INDEX IF NOT EXISTS "idx_api_key_audit_logs_created_at" ON "api_key_audit_logs" ("created_at");
+CREATE TABLE "token_revocations" ("kind" varchar(10),"subject" varchar(64),"user_id" varchar(50),"revoked_before" timestamptz,"reason" varchar(100),"expires_at" timestamptz NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("kind","subject"));
+CREATE INDEX IF NOT EXISTS "idx_token_revocations_expires_at" ON "token_revocations" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_token_revocations_updated_at" ON "token_revocations" ("updated_at");
+CREATE INDEX IF NOT EXISTS "idx_token_revocations_user_id" ON "token_revocations" ("user_id");
+CREATE TABLE "rules" ("id" bigserial,"path" varchar(500) NOT NULL,"method" varchar(10) NOT NULL,"is_private" boolean,"service" varchar(50),"access_type" smallint DEFAULT 3,"require_mfa" boolean DEFAULT false,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_rules_is_private" ON "rules" ("is_private");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_rule_unique" ON "rules" ("path","method","service");
+CREATE TABLE "rule_roles" ("rule_id" bigint,"role_id" bigint,"allowed" boolean DEFAULT null,PRIMARY KEY ("rule_id","role_id"),CONSTRAINT "fk_rule_roles_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id") ON DELETE CASCADE,CONSTRAINT "fk_rule_roles_rule" FOREIGN KEY ("rule_id") REFERENCES "rules"("id") ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_rule_roles_role_id" ON "rule_roles" ("role_id");
+CREATE INDEX IF NOT EXISTS "idx_rule_roles_rule_id" ON "rule_roles" ("rule_id");
+CREATE TABLE "user_roles" ("user_id" varchar(50),"role_id" bigint,PRIMARY KEY ("user_id","role_id"),CONSTRAINT "fk_user_roles_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id") ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_user_roles_role_id" ON "user_roles" ("role_id");
+CREATE INDEX IF NOT EXISTS "idx_user_roles_user_id" ON "user_roles" ("user_id");
+CREATE TABLE "rate_limit_counters" ("key" varchar(191),"count" bigint NOT NULL DEFAULT 0,"expires_at" timestamptz NOT NULL,PRIMARY KEY ("key"));
+CREATE INDEX IF NOT EXISTS "idx_rate_limit_counters_expires_at" ON "rate_limit_counters" ("expires_at");
@@ -0,0 +1,15 @@
+-- Tag trong thùng rác bị xoá hẳn để tạo lại unique index; các bản ghi khác trong thùng rác sẽ hiện lại
+DELETE FROM "dialog_tags" WHERE "tag_id" IN (SELECT "id" FROM "tags" WHERE "deleted_at" IS NOT NULL);
+DELETE FROM "tags" WHERE "deleted_at" IS NOT NULL;
+DROP INDEX IF EXISTS "idx_tags_name";
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_tags_name" ON "tags" ("name");
+DROP INDEX IF EXISTS "idx_tags_deleted_at";
+ALTER TABLE "tags" DROP COLUMN IF EXISTS "deleted_at";
+DROP INDEX IF EXISTS "idx_images_deleted_at";
+ALTER TABLE "images" DROP COLUMN IF EXISTS "deleted_at";
+DROP INDEX IF EXISTS "idx_comments_deleted_at";
+ALTER TABLE "comments" DROP COLUMN IF EXISTS "deleted_at";
+DROP INDEX IF EXISTS "idx_dialogs_deleted_at";
+ALTER TABLE "dialogs" DROP COLUMN IF EXISTS "deleted_at";
+DROP INDEX IF EXISTS "idx_topics_deleted_at";
+ALTER TABLE "topics" DROP COLUMN IF EXISTS "deleted_at";
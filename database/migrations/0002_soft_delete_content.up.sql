@@ -0,0 +1,14 @@
+-- Xoá mềm cho topic, dialog, comment, tag, image
+ALTER TABLE "topics" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_topics_deleted_at" ON "topics" ("deleted_at");
+ALTER TABLE "dialogs" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_dialogs_deleted_at" ON "dialogs" ("deleted_at");
+ALTER TABLE "comments" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_comments_deleted_at" ON "comments" ("deleted_at");
+ALTER TABLE "images" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_images_deleted_at" ON "images" ("deleted_at");
+ALTER TABLE "tags" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_tags_deleted_at" ON "tags" ("deleted_at");
+-- Tên tag chỉ cần duy nhất trong các tag chưa bị xoá
+DROP INDEX IF EXISTS "idx_tags_name";
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_tags_name" ON "tags" ("name") WHERE deleted_at IS NULL;
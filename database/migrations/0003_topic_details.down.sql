@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS "idx_topics_visibility";
+DROP INDEX IF EXISTS "idx_topics_sort_order";
+ALTER TABLE "topics" DROP COLUMN IF EXISTS "updated_at";
+ALTER TABLE "topics" DROP COLUMN IF EXISTS "created_at";
+ALTER TABLE "topics" DROP COLUMN IF EXISTS "visibility";
+ALTER TABLE "topics" DROP COLUMN IF EXISTS "difficulty";
+ALTER TABLE "topics" DROP COLUMN IF EXISTS "sort_order";
+ALTER TABLE "topics" DROP COLUMN IF EXISTS "description";
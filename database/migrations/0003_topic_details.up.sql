@@ -0,0 +1,14 @@
+-- Mô tả, thứ tự, độ khó, chế độ hiển thị và thời gian cho topic
+ALTER TABLE "topics" ADD COLUMN IF NOT EXISTS "description" text;
+ALTER TABLE "topics" ADD COLUMN IF NOT EXISTS "sort_order" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "topics" ADD COLUMN IF NOT EXISTS "difficulty" smallint NOT NULL DEFAULT 0;
+ALTER TABLE "topics" ADD COLUMN IF NOT EXISTS "visibility" varchar(20) NOT NULL DEFAULT 'public';
+ALTER TABLE "topics" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "topics" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_topics_sort_order" ON "topics" ("sort_order");
+CREATE INDEX IF NOT EXISTS "idx_topics_visibility" ON "topics" ("visibility");
+-- Topic có sẵn được xếp theo tiêu đề như thứ tự mặc định trước đây
+UPDATE "topics" SET "sort_order" = ordered.position
+FROM (SELECT "id", ROW_NUMBER() OVER (ORDER BY "title", "id") AS position FROM "topics") ordered
+WHERE "topics"."id" = ordered."id";
+UPDATE "topics" SET "created_at" = now(), "updated_at" = now() WHERE "created_at" IS NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE "dialogs" DROP CONSTRAINT IF EXISTS "fk_dialogs_level";
+DROP INDEX IF EXISTS "idx_dialogs_level_id";
+ALTER TABLE "dialogs" DROP COLUMN IF EXISTS "level_id";
+ALTER TABLE "topics" DROP CONSTRAINT IF EXISTS "fk_topics_level";
+DROP INDEX IF EXISTS "idx_topics_level_id";
+ALTER TABLE "topics" DROP COLUMN IF EXISTS "level_id";
+DROP TABLE IF EXISTS "levels" CASCADE;
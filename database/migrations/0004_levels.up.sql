@@ -0,0 +1,10 @@
+-- Trình độ (CEFR và mức tự định nghĩa) gán cho topic và dialog; các mức CEFR được seed từ fixtures/base/levels.yaml
+CREATE TABLE IF NOT EXISTS "levels" ("id" varchar(12),"code" varchar(20) NOT NULL,"name" varchar(100) NOT NULL,"description" text,"rank" bigint NOT NULL DEFAULT 0,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_levels_code" ON "levels" ("code");
+CREATE INDEX IF NOT EXISTS "idx_levels_rank" ON "levels" ("rank");
+ALTER TABLE "topics" ADD COLUMN IF NOT EXISTS "level_id" varchar(12);
+CREATE INDEX IF NOT EXISTS "idx_topics_level_id" ON "topics" ("level_id");
+ALTER TABLE "topics" ADD CONSTRAINT "fk_topics_level" FOREIGN KEY ("level_id") REFERENCES "levels"("id") ON DELETE SET NULL ON UPDATE CASCADE;
+ALTER TABLE "dialogs" ADD COLUMN IF NOT EXISTS "level_id" varchar(12);
+CREATE INDEX IF NOT EXISTS "idx_dialogs_level_id" ON "dialogs" ("level_id");
+ALTER TABLE "dialogs" ADD CONSTRAINT "fk_dialogs_level" FOREIGN KEY ("level_id") REFERENCES "levels"("id") ON DELETE SET NULL ON UPDATE CASCADE;
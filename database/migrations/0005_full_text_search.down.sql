@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "idx_dialogs_raw_text_fts";
+DROP INDEX IF EXISTS "idx_dialogs_title_fts";
+DROP INDEX IF EXISTS "idx_topics_title_fts";
+DROP TEXT SEARCH CONFIGURATION IF EXISTS vn_unaccent;
@@ -0,0 +1,14 @@
+-- Tìm kiếm toàn văn cho tiêu đề topic, tiêu đề và nội dung dialog.
+-- Cấu hình vn_unaccent = simple + unaccent: không stemming (tiếng Việt không có), bỏ dấu khi đánh chỉ mục
+-- và khi phân tích truy vấn nên "xin chao" khớp "Xin chào". Cần quyền CREATE EXTENSION.
+CREATE EXTENSION IF NOT EXISTS unaccent;
+DO $$
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'vn_unaccent') THEN
+		CREATE TEXT SEARCH CONFIGURATION vn_unaccent (COPY = simple);
+		ALTER TEXT SEARCH CONFIGURATION vn_unaccent ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple;
+	END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS "idx_topics_title_fts" ON "topics" USING gin (to_tsvector('vn_unaccent', "title"));
+CREATE INDEX IF NOT EXISTS "idx_dialogs_title_fts" ON "dialogs" USING gin (to_tsvector('vn_unaccent', "title"));
+CREATE INDEX IF NOT EXISTS "idx_dialogs_raw_text_fts" ON "dialogs" USING gin (to_tsvector('vn_unaccent', "raw_text"));
@@ -0,0 +1,3 @@
+-- Đếm số lần nhập sai mã 2FA và khóa xác minh tạm thời
+ALTER TABLE "mfa_factors" ADD COLUMN IF NOT EXISTS "failed_attempts" bigint DEFAULT 0;
+ALTER TABLE "mfa_factors" ADD COLUMN IF NOT EXISTS "locked_until" timestamptz;
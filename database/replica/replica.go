@@ -0,0 +1,178 @@
+// Package replica định tuyến các truy vấn đọc nặng sang read replica.
+//
+// Khác với định tuyến tự động mọi câu SELECT, chỉ truy vấn được đánh dấu bằng Read mới chạy trên replica;
+// các truy vấn còn lại (kể cả đọc ngay sau khi ghi) luôn dùng primary. Truy vấn trong transaction,
+// truy vấn có khoá (FOR UPDATE) hoặc khi mọi replica đều đang lỗi cũng dùng primary.
+//
+// Replica bị loại khỏi định tuyến khi một truy vấn trên nó lỗi kết nối hoặc khi Check ping lỗi,
+// và được dùng lại sau lần Check thành công; Start chạy Check định kỳ.
+package replica
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// PluginName là tên đăng ký Resolver trong gorm.DB
+const PluginName = "dd_goshare:read_replica"
+
+// settingKey đánh dấu statement nên chạy trên replica
+const settingKey = "dd_goshare:use_read_replica"
+
+// indexKey lưu vị trí replica đã chọn cho statement để ghi nhận lỗi sau khi truy vấn chạy
+const indexKey = "dd_goshare:read_replica_index"
+
+// Read đánh dấu truy vấn chạy trên replica nếu Resolver đã được đăng ký cho db
+func Read(db *gorm.DB) *gorm.DB {
+	return db.Set(settingKey, true)
+}
+
+// Resolver là gorm plugin chuyển truy vấn đánh dấu bằng Read sang các replica theo vòng tròn
+type Resolver struct {
+	replicas []*gorm.DB
+	down     []atomic.Bool
+	next     atomic.Uint64
+	stop     context.CancelFunc
+}
+
+// NewResolver tạo Resolver cho các replica đã mở bằng gorm.Open
+func NewResolver(replicas ...*gorm.DB) *Resolver {
+	return &Resolver{replicas: replicas, down: make([]atomic.Bool, len(replicas))}
+}
+
+// FromDB trả về Resolver đã đăng ký cho db, nil nếu không dùng replica
+func FromDB(db *gorm.DB) *Resolver {
+	if plugin, ok := db.Config.Plugins[PluginName]; ok {
+		resolver, _ := plugin.(*Resolver)
+		return resolver
+	}
+	return nil
+}
+
+// Name implements gorm.Plugin
+func (r *Resolver) Name() string {
+	return PluginName
+}
+
+// Initialize implements gorm.Plugin
+func (r *Resolver) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register(PluginName, r.route); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().After("gorm:query").Register(PluginName+":observe", r.observe); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register(PluginName, r.route)
+}
+
+// Replicas trả về các kết nối replica (dùng cho health check, đóng kết nối)
+func (r *Resolver) Replicas() []*gorm.DB {
+	return r.replicas
+}
+
+// Check ping từng replica, đánh dấu replica lỗi để không nhận truy vấn cho tới lần Check thành công sau.
+// Kết quả theo thứ tự replica; nil nghĩa là replica hoạt động.
+func (r *Resolver) Check(ctx context.Context) []error {
+	results := make([]error, len(r.replicas))
+	for i, replica := range r.replicas {
+		sqlDB, err := replica.DB()
+		if err == nil {
+			err = sqlDB.PingContext(ctx)
+		}
+		r.down[i].Store(err != nil)
+		results[i] = err
+	}
+	return results
+}
+
+// Start chạy Check định kỳ cho tới khi ctx bị hủy hoặc Stop để replica lỗi được dùng lại khi đã hồi phục
+func (r *Resolver) Start(ctx context.Context, interval time.Duration) {
+	ctx, r.stop = context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				checkCtx, cancel := context.WithTimeout(ctx, interval)
+				for i, err := range r.Check(checkCtx) {
+					if err != nil {
+						log.Printf("read replica %d: health check failed: %v", i+1, err)
+					}
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// Stop dừng Check định kỳ đã chạy bằng Start
+func (r *Resolver) Stop() {
+	if r.stop != nil {
+		r.stop()
+	}
+}
+
+// Down cho biết replica thứ i đang bị loại khỏi định tuyến
+func (r *Resolver) Down(i int) bool {
+	return r.down[i].Load()
+}
+
+func (r *Resolver) route(db *gorm.DB) {
+	if _, ok := db.Statement.Settings.Load(settingKey); !ok {
+		return
+	}
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		return
+	}
+	if _, locking := db.Statement.Clauses["FOR"]; locking {
+		return
+	}
+	if idx := r.pick(); idx >= 0 {
+		db.Statement.ConnPool = r.replicas[idx].Statement.ConnPool
+		db.Statement.Settings.Store(indexKey, idx)
+	}
+}
+
+// observe loại replica khỏi định tuyến khi truy vấn trên nó lỗi kết nối; lỗi SQL thông thường thì giữ nguyên
+func (r *Resolver) observe(db *gorm.DB) {
+	value, ok := db.Statement.Settings.Load(indexKey)
+	if !ok || db.Error == nil {
+		return
+	}
+	if idx, ok := value.(int); ok && isConnError(db.Error) && !r.down[idx].Swap(true) {
+		log.Printf("read replica %d: marked down after query failure: %v", idx+1, db.Error)
+	}
+}
+
+// pick chọn replica kế tiếp còn hoạt động; -1 nếu không còn replica nào
+func (r *Resolver) pick() int {
+	n := len(r.replicas)
+	start := r.next.Add(1)
+	for i := 0; i < n; i++ {
+		idx := int((start + uint64(i)) % uint64(n))
+		if !r.down[idx].Load() {
+			return idx
+		}
+	}
+	return -1
+}
+
+// isConnError cho biết lỗi đến từ kết nối tới replica (không kết nối được, mất kết nối, timeout mạng)
+func isConnError(err error) bool {
+	var netErr net.Error
+	var connectErr *pgconn.ConnectError
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr) || errors.As(err, &connectErr)
+}
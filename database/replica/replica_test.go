@@ -0,0 +1,183 @@
+package replica
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeServer là trạng thái của một database giả: lỗi trả về cho mọi truy vấn/ping và số truy vấn đã nhận
+type fakeServer struct {
+	mu      sync.Mutex
+	err     error
+	queries atomic.Int32
+}
+
+func (s *fakeServer) setErr(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+func (s *fakeServer) currentErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+var (
+	fakeServers  sync.Map
+	registerOnce sync.Once
+)
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	server, _ := fakeServers.Load(name)
+	return &fakeConn{server: server.(*fakeServer)}, nil
+}
+
+type fakeConn struct{ server *fakeServer }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+func (c *fakeConn) Ping(context.Context) error          { return c.server.currentErr() }
+func (c *fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	c.server.queries.Add(1)
+	if err := c.server.currentErr(); err != nil {
+		return nil, err
+	}
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return []string{"id"} }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+// openFake mở gorm.DB trên database giả tên name
+func openFake(t *testing.T, name string) (*gorm.DB, *fakeServer) {
+	t.Helper()
+	registerOnce.Do(func() { sql.Register("replica_fake", fakeDriver{}) })
+	server := &fakeServer{}
+	dsn := fmt.Sprintf("%s/%s", t.Name(), name)
+	fakeServers.Store(dsn, server)
+	sqlDB, err := sql.Open("replica_fake", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, server
+}
+
+func readItems(db *gorm.DB) error {
+	var rows []map[string]interface{}
+	return Read(db).Table("items").Find(&rows).Error
+}
+
+func TestResolver(t *testing.T) {
+	setup := func(t *testing.T) (*gorm.DB, *Resolver, *fakeServer, *fakeServer) {
+		primary, primaryServer := openFake(t, "primary")
+		replicaDB, replicaServer := openFake(t, "replica")
+		resolver := NewResolver(replicaDB)
+		if err := primary.Use(resolver); err != nil {
+			t.Fatal(err)
+		}
+		return primary, resolver, primaryServer, replicaServer
+	}
+
+	t.Run("TestRoutesReadsToReplica", func(t *testing.T) {
+		primary, _, primaryServer, replicaServer := setup(t)
+		if err := readItems(primary); err != nil {
+			t.Fatal(err)
+		}
+		var rows []map[string]interface{}
+		if err := primary.Table("items").Find(&rows).Error; err != nil {
+			t.Fatal(err)
+		}
+		if replicaServer.queries.Load() != 1 || primaryServer.queries.Load() != 1 {
+			t.Errorf("Expected one query on each, got replica=%d primary=%d", replicaServer.queries.Load(), primaryServer.queries.Load())
+		}
+	})
+
+	t.Run("TestConnectionFailureMarksDown", func(t *testing.T) {
+		primary, resolver, primaryServer, replicaServer := setup(t)
+		replicaServer.setErr(&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")})
+		if err := readItems(primary); err == nil {
+			t.Fatal("Expected the failing query to return its error")
+		}
+		if !resolver.Down(0) {
+			t.Fatal("Expected replica to be marked down after a connection error")
+		}
+		if err := readItems(primary); err != nil {
+			t.Fatal(err)
+		}
+		if primaryServer.queries.Load() != 1 {
+			t.Errorf("Expected the next read to use primary, got %d primary queries", primaryServer.queries.Load())
+		}
+
+		// Check thành công đưa replica trở lại định tuyến
+		replicaServer.setErr(nil)
+		if errs := resolver.Check(context.Background()); errs[0] != nil {
+			t.Fatal(errs[0])
+		}
+		if resolver.Down(0) {
+			t.Error("Expected replica to be back after a successful check")
+		}
+	})
+
+	t.Run("TestQueryErrorKeepsReplica", func(t *testing.T) {
+		primary, resolver, _, replicaServer := setup(t)
+		replicaServer.setErr(&pgconn.PgError{Code: "42P01", Message: `relation "items" does not exist`})
+		if err := readItems(primary); err == nil {
+			t.Fatal("Expected query error")
+		}
+		if resolver.Down(0) {
+			t.Error("Expected an SQL error not to mark the replica down")
+		}
+	})
+}
+
+func TestIsConnError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"BadConn", driver.ErrBadConn, true},
+		{"UnexpectedEOF", fmt.Errorf("read: %w", io.ErrUnexpectedEOF), true},
+		{"NetError", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"ConnectError", &pgconn.ConnectError{}, true},
+		{"SQLError", &pgconn.PgError{Code: "23505"}, false},
+		{"NotFound", gorm.ErrRecordNotFound, false},
+		{"Canceled", context.Canceled, false},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			if got := isConnError(tc.err); got != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
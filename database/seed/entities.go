@@ -0,0 +1,15 @@
+package seed
+
+import "github.com/techmaster-vietnam/dd_goshare/pkg/models"
+
+// DefaultEntities trả về các entity seed sẵn có theo thứ tự phụ thuộc
+func DefaultEntities() []Entity {
+	return []Entity{
+		{Name: "roles", Model: func() interface{} { return &models.Role{} }, Keys: []string{"name"}},
+		{Name: "subscriptions", Model: func() interface{} { return &models.Subscription{} }, Keys: []string{"name"}, IDPrefix: "subscription"},
+		{Name: "achievements", Model: func() interface{} { return &models.Achievement{} }, Keys: []string{"title"}, IDPrefix: "achievement"},
+		{Name: "levels", Model: func() interface{} { return &models.Level{} }, Keys: []string{"code"}, IDPrefix: "level"},
+		{Name: "tags", Model: func() interface{} { return &models.Tag{} }, Keys: []string{"name"}, IDPrefix: "tag"},
+		{Name: "topics", Model: func() interface{} { return &models.Topic{} }, Keys: []string{"title"}, IDPrefix: "topic"},
+	}
+}
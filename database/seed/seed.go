@@ -0,0 +1,459 @@
+// Package seed nạp dữ liệu mẫu từ fixture JSON/YAML vào database một cách idempotent.
+//
+// Fixture được tổ chức theo môi trường:
+//
+//	fixtures/
+//	  base/roles.yaml           # dùng cho mọi môi trường
+//	  base/subscriptions.json
+//	  development/topics.yaml   # chỉ nạp khi env = development
+//
+// Mỗi file là một danh sách bản ghi, tên file là tên entity. Bản ghi được upsert theo khoá tự nhiên
+// (ví dụ roles theo name) nên chạy lại nhiều lần không tạo bản ghi trùng; bản ghi cùng khoá
+// trong thư mục môi trường ghi đè các field của bản ghi trong base.
+package seed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// BaseDir là thư mục fixture dùng chung cho mọi môi trường
+const BaseDir = "base"
+
+// fixtureExtensions là các định dạng fixture được hỗ trợ, theo thứ tự ưu tiên tìm file
+var fixtureExtensions = []string{".yaml", ".yml", ".json"}
+
+// errDryRun dùng để rollback transaction khi chạy thử
+var errDryRun = errors.New("seed: dry run")
+
+// AdminRole là role quản trị trong fixture roles; RBAC cho role cao nhất truy cập mọi route nên không cần gán rule
+const AdminRole = "admin"
+
+// Entity mô tả một loại dữ liệu có thể seed
+type Entity struct {
+	// Name là tên file fixture (không gồm phần mở rộng), ví dụ "roles"
+	Name string
+	// Model trả về con trỏ tới model rỗng, ví dụ func() interface{} { return &models.Role{} }
+	Model func() interface{}
+	// Keys là tên field JSON tạo thành khoá tự nhiên để nhận diện bản ghi đã có
+	Keys []string
+	// IDPrefix dùng sinh ID (utils.GenerateUniqueID) cho bản ghi mới không khai báo id; rỗng nếu ID tự tăng
+	IDPrefix string
+}
+
+// Action là kết quả seed của một bản ghi
+type Action string
+
+const (
+	ActionCreated   Action = "created"
+	ActionUpdated   Action = "updated"
+	ActionUnchanged Action = "unchanged"
+	// ActionTrashed: bản ghi đang nằm trong thùng rác nên được giữ nguyên, không tạo lại
+	ActionTrashed Action = "trashed"
+)
+
+// Change ghi lại thao tác trên một bản ghi fixture
+type Change struct {
+	Entity string   `json:"entity"`
+	Key    string   `json:"key"`
+	Action Action   `json:"action"`
+	Fields []string `json:"fields,omitempty"` // field được tạo/thay đổi
+}
+
+// EntitySummary tổng hợp số bản ghi theo từng entity
+type EntitySummary struct {
+	Entity    string `json:"entity"`
+	Created   int    `json:"created"`
+	Updated   int    `json:"updated"`
+	Unchanged int    `json:"unchanged"`
+	Trashed   int    `json:"trashed"`
+}
+
+// Report là kết quả một lần seed
+type Report struct {
+	Environment string   `json:"environment"`
+	DryRun      bool     `json:"dry_run"`
+	Changes     []Change `json:"changes"`
+}
+
+// Summary tổng hợp Changes theo entity, giữ thứ tự seed
+func (r *Report) Summary() []EntitySummary {
+	var summaries []EntitySummary
+	index := make(map[string]int)
+	for _, change := range r.Changes {
+		i, ok := index[change.Entity]
+		if !ok {
+			i = len(summaries)
+			index[change.Entity] = i
+			summaries = append(summaries, EntitySummary{Entity: change.Entity})
+		}
+		switch change.Action {
+		case ActionCreated:
+			summaries[i].Created++
+		case ActionUpdated:
+			summaries[i].Updated++
+		case ActionTrashed:
+			summaries[i].Trashed++
+		default:
+			summaries[i].Unchanged++
+		}
+	}
+	return summaries
+}
+
+// String in báo cáo dạng văn bản: tổng hợp theo entity và từng bản ghi được tạo/cập nhật
+func (r *Report) String() string {
+	var b strings.Builder
+	mode := ""
+	if r.DryRun {
+		mode = " (dry run)"
+	}
+	fmt.Fprintf(&b, "seed environment %q%s\n", r.Environment, mode)
+	for _, s := range r.Summary() {
+		fmt.Fprintf(&b, "  %-16s created=%d updated=%d unchanged=%d trashed=%d\n", s.Entity, s.Created, s.Updated, s.Unchanged, s.Trashed)
+	}
+	for _, change := range r.Changes {
+		if change.Action == ActionUnchanged {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s %s %q [%s]\n", change.Action, change.Entity, change.Key, strings.Join(change.Fields, ", "))
+	}
+	return b.String()
+}
+
+// Seeder nạp fixture từ fsys vào database
+type Seeder struct {
+	db          *gorm.DB
+	fsys        fs.FS
+	entities    []Entity
+	dryRun      bool
+	adminUserID string
+}
+
+// New tạo Seeder đọc fixture trong fsys (thư mục gốc chứa base/ và các thư mục môi trường).
+// Không truyền entities thì dùng DefaultEntities.
+func New(db *gorm.DB, fsys fs.FS, entities ...Entity) *Seeder {
+	if len(entities) == 0 {
+		entities = DefaultEntities()
+	}
+	return &Seeder{db: db, fsys: fsys, entities: entities}
+}
+
+// Register thêm entity do ứng dụng định nghĩa; entity được seed theo thứ tự đăng ký
+func (s *Seeder) Register(entities ...Entity) *Seeder {
+	s.entities = append(s.entities, entities...)
+	return s
+}
+
+// DryRun chạy seed trong transaction rồi rollback, chỉ trả về báo cáo
+func (s *Seeder) DryRun() *Seeder {
+	s.dryRun = true
+	return s
+}
+
+// WithAdmin gán AdminRole cho userID (thường là tài khoản quản trị đầu tiên) sau khi nạp fixture
+func (s *Seeder) WithAdmin(userID string) *Seeder {
+	s.adminUserID = userID
+	return s
+}
+
+// Run seed fixture của base và env trong một transaction; lỗi ở bất kỳ bản ghi nào sẽ rollback toàn bộ
+func (s *Seeder) Run(ctx context.Context, env string) (*Report, error) {
+	report := &Report{Environment: env, DryRun: s.dryRun}
+
+	fixtures := make([][]map[string]interface{}, len(s.entities))
+	for i, entity := range s.entities {
+		records, err := s.load(entity, env)
+		if err != nil {
+			return nil, err
+		}
+		fixtures[i] = records
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, entity := range s.entities {
+			for _, record := range fixtures[i] {
+				change, err := apply(ctx, tx, entity, record)
+				if err != nil {
+					return err
+				}
+				report.Changes = append(report.Changes, change)
+			}
+		}
+		if s.adminUserID != "" {
+			change, err := assignAdmin(tx, s.adminUserID)
+			if err != nil {
+				return err
+			}
+			report.Changes = append(report.Changes, change)
+		}
+		if s.dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return report, nil
+}
+
+// load đọc fixture của entity trong base và env, gộp các bản ghi cùng khoá tự nhiên
+func (s *Seeder) load(entity Entity, env string) ([]map[string]interface{}, error) {
+	dirs := []string{BaseDir}
+	if env != "" && env != BaseDir {
+		dirs = append(dirs, env)
+	}
+
+	var merged []map[string]interface{}
+	byKey := make(map[string]map[string]interface{})
+	for _, dir := range dirs {
+		records, file, err := s.readFixture(dir, entity.Name)
+		if err != nil {
+			return nil, err
+		}
+		for i, record := range records {
+			key, err := naturalKey(entity, record)
+			if err != nil {
+				return nil, fmt.Errorf("%s record %d: %w", file, i, err)
+			}
+			if existing, ok := byKey[key]; ok {
+				for field, value := range record {
+					existing[field] = value
+				}
+				continue
+			}
+			byKey[key] = record
+			merged = append(merged, record)
+		}
+	}
+	return merged, nil
+}
+
+// readFixture đọc file <dir>/<name>.{yaml,yml,json}; không có file thì trả về danh sách rỗng
+func (s *Seeder) readFixture(dir, name string) ([]map[string]interface{}, string, error) {
+	for _, ext := range fixtureExtensions {
+		file := path.Join(dir, name+ext)
+		content, err := fs.ReadFile(s.fsys, file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, file, err
+		}
+
+		var records []map[string]interface{}
+		if ext == ".json" {
+			err = json.Unmarshal(content, &records)
+		} else {
+			err = yaml.Unmarshal(content, &records)
+		}
+		if err != nil {
+			return nil, file, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		return records, file, nil
+	}
+	return nil, "", nil
+}
+
+// naturalKey ghép giá trị các field khoá thành chuỗi dùng để nhận diện bản ghi
+func naturalKey(entity Entity, record map[string]interface{}) (string, error) {
+	parts := make([]string, len(entity.Keys))
+	for i, key := range entity.Keys {
+		value, ok := record[key]
+		if !ok || value == nil {
+			return "", fmt.Errorf("missing natural key field %q", key)
+		}
+		parts[i] = fmt.Sprint(value)
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// apply upsert một bản ghi fixture: tạo mới nếu chưa có, chỉ cập nhật các field khai báo trong fixture và đã thay đổi
+func apply(ctx context.Context, tx *gorm.DB, entity Entity, record map[string]interface{}) (Change, error) {
+	key, _ := naturalKey(entity, record)
+	change := Change{Entity: entity.Name, Key: key}
+
+	desired, err := decode(entity, record)
+	if err != nil {
+		return change, fmt.Errorf("%s %q: %w", entity.Name, key, err)
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(desired); err != nil {
+		return change, err
+	}
+	fields := fieldsByJSONName(stmt.Schema)
+
+	names := make([]string, 0, len(record))
+	for name := range record {
+		if _, ok := fields[name]; !ok {
+			return change, fmt.Errorf("%s %q: field %q cannot be seeded", entity.Name, key, name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	desiredValue := reflect.ValueOf(desired).Elem()
+	// Unscoped để thấy cả bản ghi đã xoá mềm, nếu không mỗi lần seed sẽ tạo lại bản ghi đang trong thùng rác
+	query := tx.Unscoped().Model(entity.Model())
+	for _, name := range entity.Keys {
+		field := fields[name]
+		value, _ := field.ValueOf(ctx, desiredValue)
+		query = query.Where(clause.Eq{Column: clause.Column{Name: field.DBName}, Value: value})
+	}
+
+	existing := entity.Model()
+	err = query.Take(existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if pk := stmt.Schema.PrioritizedPrimaryField; pk != nil && entity.IDPrefix != "" {
+			if _, zero := pk.ValueOf(ctx, desiredValue); zero {
+				id, err := utils.GenerateUniqueID(entity.IDPrefix)
+				if err != nil {
+					return change, err
+				}
+				if err := pk.Set(ctx, desiredValue, id); err != nil {
+					return change, err
+				}
+			}
+		}
+		// Chỉ insert các cột khai báo trong fixture để giá trị zero (ví dụ is_active: false) không bị thay bằng default của cột
+		columns := make([]string, 0, len(names)+1)
+		for _, name := range names {
+			columns = append(columns, fields[name].DBName)
+		}
+		if pk := stmt.Schema.PrioritizedPrimaryField; pk != nil && entity.IDPrefix != "" {
+			columns = append(columns, pk.DBName)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 {
+				columns = append(columns, field.DBName)
+			}
+		}
+		if err := tx.Select(columns).Create(desired).Error; err != nil {
+			return change, fmt.Errorf("failed to create %s %q: %w", entity.Name, key, err)
+		}
+		change.Action = ActionCreated
+		change.Fields = names
+		return change, nil
+	}
+	if err != nil {
+		return change, fmt.Errorf("failed to look up %s %q: %w", entity.Name, key, err)
+	}
+
+	existingValue := reflect.ValueOf(existing).Elem()
+	if isTrashed(ctx, stmt.Schema, existingValue) {
+		// Người dùng đã xoá bản ghi: không khôi phục hay sửa, chỉ báo cáo
+		change.Action = ActionTrashed
+		return change, nil
+	}
+	updates := make(map[string]interface{})
+	for _, name := range names {
+		field := fields[name]
+		// Không đổi khoá chính của bản ghi đã có
+		if field.PrimaryKey {
+			continue
+		}
+		want, _ := field.ValueOf(ctx, desiredValue)
+		have, _ := field.ValueOf(ctx, existingValue)
+		if !reflect.DeepEqual(want, have) {
+			updates[field.DBName] = want
+			change.Fields = append(change.Fields, name)
+		}
+	}
+	if len(updates) == 0 {
+		change.Action = ActionUnchanged
+		return change, nil
+	}
+	if err := tx.Model(existing).Updates(updates).Error; err != nil {
+		return change, fmt.Errorf("failed to update %s %q: %w", entity.Name, key, err)
+	}
+	change.Action = ActionUpdated
+	return change, nil
+}
+
+// isTrashed cho biết bản ghi có trường gorm.DeletedAt đã được đặt (đang trong thùng rác)
+func isTrashed(ctx context.Context, sch *schema.Schema, value reflect.Value) bool {
+	for _, field := range sch.Fields {
+		if field.FieldType != reflect.TypeOf(gorm.DeletedAt{}) {
+			continue
+		}
+		deletedAt, _ := field.ValueOf(ctx, value)
+		if d, ok := deletedAt.(gorm.DeletedAt); ok && d.Valid {
+			return true
+		}
+	}
+	return false
+}
+
+// assignAdmin gán AdminRole cho userID nếu chưa có
+func assignAdmin(tx *gorm.DB, userID string) (Change, error) {
+	change := Change{Entity: "user_roles", Key: userID + "/" + AdminRole}
+	var role models.Role
+	if err := tx.Where("name = ?", AdminRole).Take(&role).Error; err != nil {
+		return change, fmt.Errorf("failed to find role %q (missing from roles fixture?): %w", AdminRole, err)
+	}
+	var count int64
+	if err := tx.Model(&models.UserRole{}).Where("user_id = ? AND role_id = ?", userID, role.ID).
+		Count(&count).Error; err != nil {
+		return change, err
+	}
+	if count > 0 {
+		change.Action = ActionUnchanged
+		return change, nil
+	}
+	if err := tx.Create(&models.UserRole{UserID: userID, RoleID: role.ID}).Error; err != nil {
+		return change, fmt.Errorf("failed to assign role %q to %s: %w", AdminRole, userID, err)
+	}
+	change.Action = ActionCreated
+	change.Fields = []string{"user_id", "role_id"}
+	return change, nil
+}
+
+// decode chuyển bản ghi fixture thành model; field không có trong model là lỗi để bắt lỗi chính tả
+func decode(entity Entity, record map[string]interface{}) (interface{}, error) {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	model := entity.Model()
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(model); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// fieldsByJSONName ánh xạ tên field JSON sang cột database; bỏ qua quan hệ và field không lưu DB
+func fieldsByJSONName(s *schema.Schema) map[string]*schema.Field {
+	fields := make(map[string]*schema.Field)
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
@@ -0,0 +1,131 @@
+package seed_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/database"
+	"github.com/techmaster-vietnam/dd_goshare/database/seed"
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+)
+
+func actions(report *seed.Report) map[seed.Action]int {
+	counts := map[seed.Action]int{}
+	for _, change := range report.Changes {
+		counts[change.Action]++
+	}
+	return counts
+}
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("TestIdempotent", func(t *testing.T) {
+		db := testdb.Open(t)
+		first, err := database.Seed(ctx, db, "development")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := actions(first); got[seed.ActionCreated] == 0 || got[seed.ActionUpdated] != 0 {
+			t.Fatalf("Expected only created records on first run, got %v", got)
+		}
+		var roles int64
+		db.Model(&models.Role{}).Count(&roles)
+
+		second, err := database.Seed(ctx, db, "development")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := actions(second); got[seed.ActionUnchanged] != len(first.Changes) {
+			t.Errorf("Expected all %d records unchanged on second run, got %v", len(first.Changes), got)
+		}
+		var rolesAfter int64
+		db.Model(&models.Role{}).Count(&rolesAfter)
+		if rolesAfter != roles {
+			t.Errorf("Expected %d roles after reseeding, got %d", roles, rolesAfter)
+		}
+	})
+
+	t.Run("TestTrashedLeftAlone", func(t *testing.T) {
+		db := testdb.Open(t)
+		if _, err := database.Seed(ctx, db, "development"); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Where("name = ?", "travel").Delete(&models.Tag{}).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		report, err := database.Seed(ctx, db, "development")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, change := range report.Changes {
+			if change.Entity == "tags" && change.Key == "travel" && change.Action != seed.ActionTrashed {
+				t.Errorf("Expected trashed tag to be reported as trashed, got %s", change.Action)
+			}
+		}
+		var count, active int64
+		db.Unscoped().Model(&models.Tag{}).Where("name = ?", "travel").Count(&count)
+		db.Model(&models.Tag{}).Where("name = ?", "travel").Count(&active)
+		if count != 1 || active != 0 {
+			t.Errorf("Expected the trashed tag to stay the only row and stay trashed, got %d rows, %d active", count, active)
+		}
+		for _, summary := range report.Summary() {
+			if summary.Entity == "tags" && summary.Trashed != 1 {
+				t.Errorf("Expected 1 trashed tag in summary, got %d", summary.Trashed)
+			}
+		}
+	})
+
+	t.Run("TestDryRunRollsBack", func(t *testing.T) {
+		db := testdb.Open(t)
+		report, err := seed.New(db, database.Fixtures()).DryRun().Run(ctx, "development")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !report.DryRun || len(report.Changes) == 0 {
+			t.Errorf("Expected dry-run report with changes, got %+v", report)
+		}
+		var roles int64
+		db.Model(&models.Role{}).Count(&roles)
+		if roles != 0 {
+			t.Errorf("Expected dry run to write nothing, got %d roles", roles)
+		}
+	})
+
+	t.Run("TestWithAdmin", func(t *testing.T) {
+		db := testdb.Open(t)
+		for i, want := range []seed.Action{seed.ActionCreated, seed.ActionUnchanged} {
+			report, err := seed.New(db, database.Fixtures()).WithAdmin("E_admin").Run(ctx, "production")
+			if err != nil {
+				t.Fatal(err)
+			}
+			last := report.Changes[len(report.Changes)-1]
+			if last.Entity != "user_roles" || last.Action != want {
+				t.Errorf("Run %d: expected user_roles %s, got %+v", i+1, want, last)
+			}
+		}
+		var count int64
+		db.Table("user_roles").Joins("JOIN roles ON roles.id = user_roles.role_id").
+			Where("user_roles.user_id = ? AND roles.name = ?", "E_admin", seed.AdminRole).Count(&count)
+		if count != 1 {
+			t.Errorf("Expected one admin assignment, got %d", count)
+		}
+	})
+}
+
+func TestReportSummary(t *testing.T) {
+	report := &seed.Report{Changes: []seed.Change{
+		{Entity: "tags", Action: seed.ActionCreated},
+		{Entity: "tags", Action: seed.ActionTrashed},
+		{Entity: "topics", Action: seed.ActionUnchanged},
+	}}
+	summaries := report.Summary()
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 entities, got %+v", summaries)
+	}
+	if s := summaries[0]; s.Created != 1 || s.Trashed != 1 || s.Unchanged != 0 {
+		t.Errorf("Unexpected tags summary %+v", s)
+	}
+}
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/seed"
+	"gorm.io/gorm"
+)
+
+// fixtureFiles chứa fixture mặc định của thư viện: base/ cho mọi môi trường, <env>/ cho từng môi trường
+//
+//go:embed fixtures
+var fixtureFiles embed.FS
+
+// Fixtures trả về bộ fixture mặc định (thư mục gốc chứa base/, development/...)
+func Fixtures() fs.FS {
+	sub, _ := fs.Sub(fixtureFiles, "fixtures")
+	return sub
+}
+
+// Seed nạp fixture mặc định cho môi trường env (development, staging, production); chạy lại nhiều lần không tạo bản ghi trùng
+func Seed(ctx context.Context, db *gorm.DB, env string) (*seed.Report, error) {
+	return seed.New(db, Fixtures()).Run(ctx, env)
+}
@@ -0,0 +1,82 @@
+// Package testdb mở database Postgres cho test tích hợp: mỗi test có schema tạm riêng, bị xoá khi test kết thúc.
+// Test dùng package này bị bỏ qua khi không đặt TEST_DATABASE_URL, ví dụ:
+//
+//	TEST_DATABASE_URL="host=localhost user=postgres password=postgres dbname=dd_test sslmode=disable" go test ./...
+package testdb
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// EnvDSN là biến môi trường chứa DSN của database dùng cho test
+const EnvDSN = "TEST_DATABASE_URL"
+
+// Open trả về kết nối tới schema tạm đã chạy toàn bộ migration có đánh số
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+	db := OpenEmpty(t)
+	if err := database.VersionedMigrator()(db); err != nil {
+		t.Fatalf("testdb: migrate failed: %v", err)
+	}
+	return db
+}
+
+// OpenEmpty trả về kết nối tới schema tạm chưa có bảng nào
+func OpenEmpty(t testing.TB) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv(EnvDSN)
+	if dsn == "" {
+		t.Skipf("%s is not set, skipping database test", EnvDSN)
+	}
+	cfg := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	admin, err := gorm.Open(postgres.Open(dsn), cfg)
+	if err != nil {
+		t.Fatalf("testdb: connect failed: %v", err)
+	}
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		t.Fatalf("testdb: %v", err)
+	}
+	schema := "test_" + hex.EncodeToString(buf)
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("testdb: create schema failed: %v", err)
+	}
+
+	// public đứng sau để dùng được extension (unaccent) đã cài sẵn
+	db, err := gorm.Open(postgres.Open(withSearchPath(dsn, schema+",public")), cfg)
+	if err != nil {
+		t.Fatalf("testdb: connect failed: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// withSearchPath thêm search_path vào DSN dạng URL hoặc key=value
+func withSearchPath(dsn, searchPath string) string {
+	if strings.Contains(dsn, "://") {
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		return dsn + sep + "search_path=" + strings.ReplaceAll(searchPath, ",", "%2C")
+	}
+	return dsn + " search_path=" + searchPath
+}
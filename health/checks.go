@@ -0,0 +1,143 @@
+package health
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/database"
+	"github.com/techmaster-vietnam/dd_goshare/rbac"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+	"gorm.io/gorm"
+)
+
+// Database ping primary và các read replica; chỉ primary lỗi mới làm check thất bại
+func Database(db *gorm.DB) Check {
+	return Check{
+		Name:     "database",
+		Critical: true,
+		TTL:      5 * time.Second,
+		Run: func(ctx context.Context) (any, error) {
+			report := database.Health(ctx, db)
+			if !report.Healthy {
+				return report, errors.New(report.Primary.Error)
+			}
+			return report, nil
+		},
+	}
+}
+
+// RBAC kiểm tra rule chưa gán role và rule_roles mồ côi (như rbac.QuickHealthCheck nhưng không in báo cáo).
+// Không Critical: lệch cấu hình rule không ngăn service nhận request.
+func RBAC() Check {
+	return Check{
+		Name: "rbac",
+		TTL:  time.Minute,
+		Run: func(ctx context.Context) (any, error) {
+			report, err := rbac.VerifyRuleRoleConsistency()
+			if err != nil {
+				return nil, err
+			}
+			if !report.IsHealthy {
+				return report, fmt.Errorf("%d rules without roles, %d orphaned rule_roles",
+					report.RulesWithoutRoles, report.OrphanedRuleRoles)
+			}
+			return report, nil
+		},
+	}
+}
+
+// BinaryInfo là đường dẫn và phiên bản của công cụ ngoài
+type BinaryInfo struct {
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+}
+
+// Binary kiểm tra công cụ name có trong PATH và lấy dòng đầu của `name versionArgs...` làm phiên bản
+func Binary(name string, versionArgs ...string) Check {
+	return Check{
+		Name:     name,
+		Critical: true,
+		TTL:      5 * time.Minute,
+		Timeout:  15 * time.Second,
+		Run: func(ctx context.Context) (any, error) {
+			path, err := exec.LookPath(name)
+			if err != nil {
+				return nil, fmt.Errorf("%s not found in PATH: %w", name, err)
+			}
+			info := BinaryInfo{Path: path}
+			if len(versionArgs) == 0 {
+				return info, nil
+			}
+			out, err := exec.CommandContext(ctx, path, versionArgs...).CombinedOutput()
+			if err != nil {
+				return info, fmt.Errorf("%s %s failed: %w", name, strings.Join(versionArgs, " "), err)
+			}
+			info.Version = firstLine(out)
+			return info, nil
+		},
+	}
+}
+
+// FileInfo là thông tin file dữ liệu đã kiểm tra
+type FileInfo struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// File kiểm tra path là file thường và không rỗng
+func File(name, path string) Check {
+	return Check{
+		Name:     name,
+		Critical: true,
+		TTL:      time.Minute,
+		Run: func(ctx context.Context) (any, error) {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				abs = path
+			}
+			stat, err := os.Stat(abs)
+			if err != nil {
+				return nil, err
+			}
+			if !stat.Mode().IsRegular() {
+				return nil, fmt.Errorf("%s is not a regular file", abs)
+			}
+			info := FileInfo{Path: abs, Size: stat.Size(), ModifiedAt: stat.ModTime()}
+			if stat.Size() == 0 {
+				return info, fmt.Errorf("%s is empty", abs)
+			}
+			return info, nil
+		},
+	}
+}
+
+// MediaTools là các check cho pipeline media: ffmpeg (ConvertWavToOgg), cwebp (xử lý ảnh),
+// mfa cùng từ điển và acoustic model MFA trong DATA_DIR (RunMFAAlignment)
+func MediaTools() []Check {
+	return []Check{
+		Binary("ffmpeg", "-version"),
+		Binary("cwebp", "-version"),
+		Binary("mfa", "version"),
+		File("mfa_dictionary", filepath.Join(utils.DataDir(), utils.MFADictionaryFile)),
+		File("mfa_acoustic_model", filepath.Join(utils.DataDir(), utils.MFAAcousticModelFile)),
+	}
+}
+
+func firstLine(out []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package health
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var startedAt = time.Now()
+
+// Liveness trả 200 khi process còn phục vụ request; không gọi tới phụ thuộc bên ngoài
+// để orchestrator không khởi động lại service chỉ vì database tạm lỗi
+func Liveness() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status":         StatusUp,
+			"uptime_seconds": int64(time.Since(startedAt).Seconds()),
+		})
+	}
+}
+
+// Handler trả Report của checker; 503 khi có check Critical lỗi
+func (c *Checker) Handler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		report := c.Run(ctx.Context())
+		status := fiber.StatusOK
+		if report.Status == StatusDown {
+			status = fiber.StatusServiceUnavailable
+		}
+		return ctx.Status(status).JSON(report)
+	}
+}
@@ -0,0 +1,181 @@
+// Package health chạy các kiểm tra sẵn sàng (database, RBAC, công cụ ngoài, file dữ liệu)
+// và phục vụ kết quả qua /healthz (liveness) và /readyz (readiness).
+//
+// Mỗi Check có TTL riêng: kết quả được cache để probe gọi dày không chạy lại các kiểm tra tốn kém
+// như `mfa version`. Check không Critical khi lỗi chỉ làm trạng thái thành degraded, không trả 503.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status là trạng thái của một check hoặc cả báo cáo
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+const (
+	// DefaultTTL dùng cho check không khai báo TTL
+	DefaultTTL = 10 * time.Second
+	// DefaultTimeout dùng cho check không khai báo Timeout
+	DefaultTimeout = 5 * time.Second
+)
+
+// Check là một kiểm tra có thể đăng ký vào Checker.
+// Run trả về details (được đưa vào JSON) và lỗi nếu thành phần không sẵn sàng.
+type Check struct {
+	Name     string
+	Critical bool
+	TTL      time.Duration
+	Timeout  time.Duration
+	Run      func(ctx context.Context) (details any, err error)
+}
+
+// Result là kết quả một lần chạy check
+type Result struct {
+	Name      string    `json:"name"`
+	Status    Status    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	Details   any       `json:"details,omitempty"`
+	LatencyMs int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+	Cached    bool      `json:"cached"`
+}
+
+// Report tổng hợp kết quả các check.
+// Status là down nếu có check Critical lỗi, degraded nếu chỉ check không Critical lỗi.
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Checker giữ danh sách check và cache kết quả theo TTL của từng check
+type Checker struct {
+	mu      sync.RWMutex
+	entries []*entry
+}
+
+type entry struct {
+	check Check
+
+	mu      sync.Mutex
+	result  Result
+	expires time.Time
+}
+
+// NewChecker tạo Checker với các check ban đầu
+func NewChecker(checks ...Check) *Checker {
+	c := &Checker{}
+	c.Register(checks...)
+	return c
+}
+
+// Register thêm check; check trùng tên thay thế check cũ (và bỏ cache của nó)
+func (c *Checker) Register(checks ...Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, check := range checks {
+		e := &entry{check: check}
+		replaced := false
+		for i, existing := range c.entries {
+			if existing.check.Name == check.Name {
+				c.entries[i] = e
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			c.entries = append(c.entries, e)
+		}
+	}
+}
+
+// Run chạy song song các check (dùng cache nếu còn hạn) và tổng hợp báo cáo theo thứ tự đăng ký
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	entries := append([]*entry(nil), c.entries...)
+	c.mu.RUnlock()
+
+	results := make([]Result, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i] = e.get(ctx)
+		}(i, e)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: results}
+	for _, r := range results {
+		if r.Status == StatusUp {
+			continue
+		}
+		if r.Critical {
+			report.Status = StatusDown
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+// get trả về kết quả còn hạn hoặc chạy lại check; request đồng thời chờ chung một lần chạy
+func (e *entry) get(ctx context.Context) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(e.expires) {
+		cached := e.result
+		cached.Cached = true
+		return cached
+	}
+
+	e.result = e.run(ctx)
+	ttl := e.check.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	e.expires = e.result.CheckedAt.Add(ttl)
+	return e.result
+}
+
+func (e *entry) run(ctx context.Context) (result Result) {
+	timeout := e.check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	// Kết quả được cache cho mọi probe sau nên không gắn với request hiện tại:
+	// probe bị hủy giữa chừng không được để lại lỗi "context canceled" suốt TTL
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	start := time.Now()
+	result = Result{Name: e.check.Name, Critical: e.check.Critical, CheckedAt: start}
+	defer func() {
+		if r := recover(); r != nil {
+			result.Status = StatusDown
+			result.Error = fmt.Sprintf("check panicked: %v", r)
+		}
+		result.LatencyMs = time.Since(start).Milliseconds()
+	}()
+
+	details, err := e.check.Run(ctx)
+	result.Details = details
+	result.Status = StatusUp
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
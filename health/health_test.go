@@ -0,0 +1,237 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// countingCheck trả về check đếm số lần chạy và lỗi theo err
+func countingCheck(name string, critical bool, ttl time.Duration, runs *atomic.Int32, err error) Check {
+	return Check{
+		Name:     name,
+		Critical: critical,
+		TTL:      ttl,
+		Run: func(ctx context.Context) (any, error) {
+			runs.Add(1)
+			return nil, err
+		},
+	}
+}
+
+func TestCheckerCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("TestCachedWithinTTL", func(t *testing.T) {
+		var runs atomic.Int32
+		c := NewChecker(countingCheck("db", true, time.Hour, &runs, nil))
+		first := c.Run(ctx)
+		second := c.Run(ctx)
+		if runs.Load() != 1 {
+			t.Errorf("Expected 1 run, got %d", runs.Load())
+		}
+		if first.Checks[0].Cached || !second.Checks[0].Cached {
+			t.Errorf("Expected only the second result to be cached, got %v and %v", first.Checks[0].Cached, second.Checks[0].Cached)
+		}
+	})
+
+	t.Run("TestRerunsAfterTTL", func(t *testing.T) {
+		var runs atomic.Int32
+		c := NewChecker(countingCheck("db", true, time.Millisecond, &runs, nil))
+		c.Run(ctx)
+		time.Sleep(5 * time.Millisecond)
+		if report := c.Run(ctx); report.Checks[0].Cached || runs.Load() != 2 {
+			t.Errorf("Expected a fresh run after TTL, got runs=%d cached=%v", runs.Load(), report.Checks[0].Cached)
+		}
+	})
+
+	t.Run("TestConcurrentRunsShareOneCheck", func(t *testing.T) {
+		var runs atomic.Int32
+		release := make(chan struct{})
+		c := NewChecker(Check{Name: "slow", TTL: time.Hour, Run: func(ctx context.Context) (any, error) {
+			runs.Add(1)
+			<-release
+			return nil, nil
+		}})
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.Run(ctx)
+			}()
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		if runs.Load() != 1 {
+			t.Errorf("Expected 1 run for concurrent requests, got %d", runs.Load())
+		}
+	})
+
+	t.Run("TestRegisterReplacesAndDropsCache", func(t *testing.T) {
+		var oldRuns, newRuns atomic.Int32
+		c := NewChecker(countingCheck("db", true, time.Hour, &oldRuns, nil))
+		c.Run(ctx)
+		c.Register(countingCheck("db", true, time.Hour, &newRuns, errors.New("down")))
+		report := c.Run(ctx)
+		if len(report.Checks) != 1 || newRuns.Load() != 1 || report.Checks[0].Cached {
+			t.Errorf("Expected replaced check to run once uncached, got %+v (new runs %d)", report.Checks, newRuns.Load())
+		}
+	})
+
+	t.Run("TestFailuresAreCachedToo", func(t *testing.T) {
+		var runs atomic.Int32
+		c := NewChecker(countingCheck("db", true, time.Hour, &runs, errors.New("down")))
+		c.Run(ctx)
+		if report := c.Run(ctx); report.Status != StatusDown || runs.Load() != 1 {
+			t.Errorf("Expected cached down result, got %s after %d runs", report.Status, runs.Load())
+		}
+	})
+}
+
+func TestCheckerRun(t *testing.T) {
+	ctx := context.Background()
+	var runs atomic.Int32
+	up := countingCheck("up", true, time.Hour, &runs, nil)
+	softFail := countingCheck("soft", false, time.Hour, &runs, errors.New("lagging"))
+	hardFail := countingCheck("hard", true, time.Hour, &runs, errors.New("unreachable"))
+
+	cases := []struct {
+		name   string
+		checks []Check
+		want   Status
+	}{
+		{"AllUp", []Check{up}, StatusUp},
+		{"NonCriticalFailure", []Check{up, softFail}, StatusDegraded},
+		{"CriticalFailure", []Check{softFail, hardFail}, StatusDown},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			if got := NewChecker(tc.checks...).Run(ctx).Status; got != tc.want {
+				t.Errorf("Expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+
+	t.Run("TestPanicIsDown", func(t *testing.T) {
+		c := NewChecker(Check{Name: "panics", Critical: true, Run: func(ctx context.Context) (any, error) {
+			panic("boom")
+		}})
+		result := c.Run(ctx).Checks[0]
+		if result.Status != StatusDown || result.Error == "" {
+			t.Errorf("Expected panic to be reported as down, got %+v", result)
+		}
+	})
+
+	t.Run("TestTimeout", func(t *testing.T) {
+		c := NewChecker(Check{Name: "hangs", Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}})
+		if result := c.Run(ctx).Checks[0]; result.Status != StatusDown {
+			t.Errorf("Expected timed out check to be down, got %+v", result)
+		}
+	})
+
+	t.Run("TestCanceledProbeDoesNotPoisonCache", func(t *testing.T) {
+		c := NewChecker(Check{Name: "db", TTL: time.Hour, Run: func(ctx context.Context) (any, error) {
+			return nil, ctx.Err()
+		}})
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		if result := c.Run(canceled).Checks[0]; result.Status != StatusUp {
+			t.Errorf("Expected check to ignore the probe's cancellation, got %+v", result)
+		}
+		if result := c.Run(ctx).Checks[0]; result.Status != StatusUp {
+			t.Errorf("Expected cached result to be up, got %+v", result)
+		}
+	})
+
+	t.Run("TestHandlerStatusCode", func(t *testing.T) {
+		for _, tc := range []struct {
+			checks []Check
+			want   int
+		}{
+			{[]Check{softFail}, fiber.StatusOK},
+			{[]Check{hardFail}, fiber.StatusServiceUnavailable},
+		} {
+			app := fiber.New()
+			app.Get("/readyz", NewChecker(tc.checks...).Handler())
+			resp, err := app.Test(httptest.NewRequest("GET", "/readyz", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tc.want {
+				t.Errorf("Expected status %d, got %d", tc.want, resp.StatusCode)
+			}
+		}
+	})
+}
+
+func TestFileCheck(t *testing.T) {
+	dir := t.TempDir()
+	full := filepath.Join(dir, "full.bin")
+	empty := filepath.Join(dir, "empty.bin")
+	if err := os.WriteFile(full, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"Present", full, false},
+		{"Empty", empty, true},
+		{"Missing", filepath.Join(dir, "missing.bin"), true},
+		{"Directory", dir, true},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			_, err := File("f", tc.path).Run(context.Background())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error=%v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMediaTools(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DATA_DIR", dir)
+	if err := os.WriteFile(filepath.Join(dir, "english_us_mfa.dict"), []byte("dict"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := map[string]Check{}
+	for _, check := range MediaTools() {
+		checks[check.Name] = check
+	}
+	model, ok := checks["mfa_acoustic_model"]
+	if !ok {
+		t.Fatalf("Expected an mfa_acoustic_model check, got %v", checks)
+	}
+	if _, err := checks["mfa_dictionary"].Run(context.Background()); err != nil {
+		t.Errorf("Expected dictionary check to pass, got %v", err)
+	}
+	if _, err := model.Run(context.Background()); err == nil {
+		t.Errorf("Expected missing english_mfa.zip to fail the check")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "english_mfa.zip"), []byte("model"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := model.Run(context.Background()); err != nil {
+		t.Errorf("Expected acoustic model check to pass, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
+)
+
+// APIKeyCallRecorder lưu last-used và audit log của request dùng API key,
+// ví dụ services.APIKeyService.RecordCall
+type APIKeyCallRecorder func(ctx context.Context, keyID, method, path string, status int, ip string) error
+
+// APIKeyAudit ghi lại mọi request được xác thực bằng API key sau khi handler chạy xong.
+// Đặt sau Authenticator.Middleware; request dùng JWT được bỏ qua.
+func APIKeyAudit(record APIKeyCallRecorder) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+
+		principal := GetPrincipal(c)
+		if principal == nil || principal.TokenType != pmodel.TokenTypeAPIKey {
+			return err
+		}
+		status := c.Response().StatusCode()
+		if err != nil {
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+		}
+		// Fiber tái sử dụng buffer của request nên phải copy chuỗi trước khi recorder giữ lại.
+		// Lỗi ghi audit không làm hỏng response.
+		method, path, ip := strings.Clone(c.Method()), strings.Clone(c.Path()), strings.Clone(c.IP())
+		if recErr := record(c.UserContext(), principal.ID, method, path, status, ip); recErr != nil {
+			log.Printf("api key audit: failed to record call for key %s: %v", principal.ID, recErr)
+		}
+		return err
+	}
+}
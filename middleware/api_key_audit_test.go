@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
+)
+
+func TestAPIKeyAudit(t *testing.T) {
+	lookup := func(_ context.Context, key string) (*pmodel.Principal, error) {
+		return &pmodel.Principal{ID: "K0001"}, nil
+	}
+	type call struct {
+		keyID, method, path string
+		status              int
+	}
+	var calls []call
+	record := func(_ context.Context, keyID, method, path string, status int, ip string) error {
+		calls = append(calls, call{keyID, method, path, status})
+		return nil
+	}
+
+	auth := NewAuthenticator(NewAPIKeyVerifier("", lookup))
+	app := fiber.New()
+	app.Use(auth.Optional(), APIKeyAudit(record))
+	app.Post("/jobs", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return fiber.ErrNotFound
+	})
+
+	for _, tt := range []struct {
+		method, path string
+		key          string
+	}{
+		{"POST", "/jobs", "ddk_test"},
+		{"GET", "/missing", "ddk_test"},
+		{"POST", "/jobs", ""},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.key != "" {
+			req.Header.Set("X-API-Key", tt.key)
+		}
+		if _, err := app.Test(req); err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+	}
+
+	want := []call{
+		{"K0001", "POST", "/jobs", fiber.StatusCreated},
+		{"K0001", "GET", "/missing", fiber.StatusNotFound},
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("recorded %d calls, want %d: %+v", len(calls), len(want), calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("call %d = %+v, want %+v", i, calls[i], want[i])
+		}
+	}
+}
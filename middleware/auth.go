@@ -1,124 +1,184 @@
 package middleware
 
 import (
-	"errors"
-	"strings"
+	"crypto/rand"
+	"encoding/hex"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/techmaster-vietnam/dd_goshare/config"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
 )
 
 // ClaimsStringID represents JWT claims with string user ID
 type ClaimsStringID struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	SessionID string `json:"sid,omitempty"`
+	MFA       bool   `json:"mfa,omitempty"` // session đã xác thực 2FA (step-up)
 	jwt.RegisteredClaims
 }
 
 // Claims represents JWT claims
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	SessionID string `json:"sid,omitempty"`
+	MFA       bool   `json:"mfa,omitempty"` // session đã xác thực 2FA (step-up)
 	jwt.RegisteredClaims
 }
 
 // AuthMiddleware validates JWT token
 func AuthMiddleware(cfg *config.Config) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		// Get token from Authorization header
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"error":   "Authorization header required",
-				"message": "Please provide a valid authorization token",
-			})
-		}
+	return NewAuthenticator(NewAppJWTVerifier(cfg)).Middleware()
+}
 
-		// Check if token starts with "Bearer "
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid token format",
-				"message": "Token must be in format: Bearer <token>",
-			})
-		}
+// AppJWTVerifier xác thực access token do GenerateToken/GenerateSessionToken phát hành
+type AppJWTVerifier struct {
+	cfg *config.Config
+}
 
-		// Parse and validate token (dùng ClaimsStringID)
-		token, err := jwt.ParseWithClaims(tokenString, &ClaimsStringID{}, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(cfg.JWT.Secret), nil
-		})
-
-		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid token",
-				"message": "Token is invalid or expired",
-			})
-		}
+// NewAppJWTVerifier tạo verifier cho app JWT
+func NewAppJWTVerifier(cfg *config.Config) *AppJWTVerifier {
+	return &AppJWTVerifier{cfg: cfg}
+}
 
-		// Extract claims
-		claims, ok := token.Claims.(*ClaimsStringID)
-		if !ok || !token.Valid {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid token claims",
-				"message": "Token claims are invalid",
-			})
-		}
+func (v *AppJWTVerifier) Name() string {
+	return pmodel.TokenTypeAppJWT
+}
 
-		// Check if token is expired
-		if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(time.Now()) {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"error":   "Token expired",
-				"message": "Token has expired",
-			})
-		}
+func (v *AppJWTVerifier) Verify(c *fiber.Ctx) (*pmodel.Principal, error) {
+	tokenString, err := bearerToken(c)
+	if err != nil {
+		return nil, err
+	}
 
-		// Store user info in context
-		c.Locals("user_id", claims.UserID)
-		c.Locals("user_email", claims.Email)
+	ks, err := resolveKeySet(v.cfg)
+	if err != nil {
+		return nil, &AuthError{Status: fiber.StatusInternalServerError, Err: "JWT keys not configured", Message: err.Error()}
+	}
+
+	// Parse and validate token (dùng ClaimsStringID), khóa xác thực chọn theo kid
+	token, err := jwt.ParseWithClaims(tokenString, &ClaimsStringID{}, ks.Keyfunc)
+	if err != nil {
+		return nil, newUnauthorized("Invalid token", "Token is invalid or expired")
+	}
+
+	// Extract claims
+	claims, ok := token.Claims.(*ClaimsStringID)
+	if !ok || !token.Valid {
+		return nil, newUnauthorized("Invalid token claims", "Token claims are invalid")
+	}
 
-		return c.Next()
+	// Check if token is expired
+	if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(time.Now()) {
+		return nil, newUnauthorized("Token expired", "Token has expired")
 	}
+
+	// Check if token has been revoked (logout, password change, ban...)
+	if revocationStore != nil && revocationStore.IsRevoked(claims) {
+		return nil, newUnauthorized("Token revoked", "Token has been revoked")
+	}
+
+	return &pmodel.Principal{
+		ID:        claims.UserID,
+		Email:     claims.Email,
+		Provider:  "app",
+		TokenType: pmodel.TokenTypeAppJWT,
+		SessionID: claims.SessionID,
+		TokenID:   claims.ID,
+		MFA:       claims.MFA,
+		Token:     claims,
+	}, nil
 }
 
-// GenerateToken generates a new JWT token
+// GenerateToken generates a new JWT token with a fresh session ID
 func GenerateToken(userID string, email string, cfg *config.Config) (string, error) {
+	return GenerateSessionToken(userID, email, "", cfg)
+}
+
+// GenerateSessionToken generates a new JWT token bound to a session.
+// Mỗi token có jti riêng; sessionID rỗng thì tạo session mới.
+func GenerateSessionToken(userID, email, sessionID string, cfg *config.Config) (string, error) {
+	return generateToken(userID, email, sessionID, false, cfg)
+}
+
+// GenerateMFASessionToken phát hành token có claim mfa sau khi user xác thực 2FA (step-up) trong session
+func GenerateMFASessionToken(userID, email, sessionID string, cfg *config.Config) (string, error) {
+	return generateToken(userID, email, sessionID, true, cfg)
+}
+
+func generateToken(userID, email, sessionID string, mfa bool, cfg *config.Config) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+	if sessionID == "" {
+		if sessionID, err = newTokenID(); err != nil {
+			return "", err
+		}
+	}
+
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		SessionID: sessionID,
+		MFA:       mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(cfg.JWT.Expiry) * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.JWT.Secret))
+	ks, err := resolveKeySet(cfg)
+	if err != nil {
+		return "", err
+	}
+	return ks.Sign(claims)
+}
+
+// newTokenID tạo ID ngẫu nhiên 128 bit dạng hex cho jti/sid
+func newTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
 }
 
 // GetUserIDFromContext extracts user ID from context
 func GetUserIDFromContext(c *fiber.Ctx) string {
-	if userID, ok := c.Locals("user_id").(string); ok {
-		return userID
+	if principal := GetPrincipal(c); principal != nil {
+		return principal.ID
 	}
 	return ""
 }
 
 // GetUserEmailFromContext extracts user email from context
 func GetUserEmailFromContext(c *fiber.Ctx) string {
-	if userEmail, ok := c.Locals("user_email").(string); ok {
-		return userEmail
+	if principal := GetPrincipal(c); principal != nil {
+		return principal.Email
+	}
+	return ""
+}
+
+// GetSessionIDFromContext extracts session ID (sid claim) from context
+func GetSessionIDFromContext(c *fiber.Ctx) string {
+	if principal := GetPrincipal(c); principal != nil {
+		return principal.SessionID
 	}
 	return ""
 }
+
+// GetClaimsFromContext extracts validated app JWT claims from context
+func GetClaimsFromContext(c *fiber.Ctx) *ClaimsStringID {
+	if principal := GetPrincipal(c); principal != nil {
+		if claims, ok := principal.Token.(*ClaimsStringID); ok {
+			return claims
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
+)
+
+// ErrNoCredentials được verifier trả về khi request không chứa loại credential mà verifier hỗ trợ,
+// để Authenticator thử verifier tiếp theo
+var ErrNoCredentials = errors.New("no credentials")
+
+// AuthError là lỗi xác thực kèm HTTP status và thông điệp trả về client
+type AuthError struct {
+	Status  int
+	Err     string
+	Message string
+}
+
+func (e *AuthError) Error() string {
+	return e.Err + ": " + e.Message
+}
+
+func newUnauthorized(err, message string) *AuthError {
+	return &AuthError{Status: fiber.StatusUnauthorized, Err: err, Message: message}
+}
+
+// Verifier xác thực một loại credential (app JWT, Firebase ID token, API key...)
+type Verifier interface {
+	Name() string
+	Verify(c *fiber.Ctx) (*pmodel.Principal, error)
+}
+
+// RoleLoader lấy tên các role RBAC của user, ví dụ rbac.GetUserRolesFromDB
+type RoleLoader func(ctx context.Context, userID string) ([]string, error)
+
+// Provisioner tạo hoặc liên kết tài khoản nội bộ cho principal vừa xác thực (ví dụ lần đăng nhập Firebase đầu tiên).
+// Provisioner có thể đổi principal.ID thành customer ID nội bộ.
+type Provisioner interface {
+	Provision(ctx context.Context, principal *pmodel.Principal) error
+}
+
+// Authenticator thử lần lượt các verifier theo thứ tự cấu hình và lưu Principal vào context
+type Authenticator struct {
+	verifiers   []Verifier
+	roleLoader  RoleLoader
+	provisioner Provisioner
+}
+
+// NewAuthenticator tạo chain xác thực; thứ tự verifier là thứ tự được thử
+//
+//	auth := middleware.NewAuthenticator(
+//		middleware.NewAPIKeyVerifier("X-API-Key", apiKeyService.Lookup),
+//		middleware.NewAppJWTVerifier(cfg),
+//		middleware.NewFirebaseVerifier(),
+//	).WithRoleLoader(loader)
+//	api.Use(auth.Middleware())
+func NewAuthenticator(verifiers ...Verifier) *Authenticator {
+	return &Authenticator{verifiers: verifiers}
+}
+
+// WithRoleLoader bật nạp role cho principal chưa có role sau khi xác thực
+func (a *Authenticator) WithRoleLoader(loader RoleLoader) *Authenticator {
+	a.roleLoader = loader
+	return a
+}
+
+// WithProvisioner chạy provisioner sau khi xác thực, trước khi nạp role
+func (a *Authenticator) WithProvisioner(provisioner Provisioner) *Authenticator {
+	a.provisioner = provisioner
+	return a
+}
+
+// Authenticate chạy chain và trả về principal; ErrNoCredentials nếu request không có credential nào
+func (a *Authenticator) Authenticate(c *fiber.Ctx) (*pmodel.Principal, error) {
+	var firstErr error
+	for _, verifier := range a.verifiers {
+		principal, err := verifier.Verify(c)
+		if err == nil {
+			if a.provisioner != nil {
+				if err := a.provisioner.Provision(c.UserContext(), principal); err != nil {
+					return nil, provisionError(principal, err)
+				}
+			}
+			if a.roleLoader != nil && len(principal.Roles) == 0 {
+				roles, roleErr := a.roleLoader(c.UserContext(), principal.ID)
+				if roleErr != nil {
+					log.Printf("authenticator: failed to load roles for %s: %v", principal.ID, roleErr)
+				} else {
+					principal.Roles = roles
+				}
+			}
+			return principal, nil
+		}
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		// Giữ lỗi của verifier đầu tiên nhận ra credential, nhưng vẫn thử các verifier sau
+		// (ví dụ Bearer token không phải app JWT có thể là Firebase ID token)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return nil, ErrNoCredentials
+}
+
+func provisionError(principal *pmodel.Principal, err error) error {
+	if errors.Is(err, pmodel.ErrAccountLinkConflict) {
+		return &AuthError{Status: fiber.StatusConflict, Err: "Account link required", Message: err.Error()}
+	}
+	log.Printf("authenticator: failed to provision %s: %v", principal.ID, err)
+	return &AuthError{Status: fiber.StatusInternalServerError, Err: "Account provisioning failed", Message: "Please try again later"}
+}
+
+// Middleware bắt buộc request phải xác thực thành công
+func (a *Authenticator) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, err := a.Authenticate(c)
+		if err != nil {
+			return authErrorJSON(c, err)
+		}
+		SetPrincipal(c, principal)
+		return c.Next()
+	}
+}
+
+// Optional xác thực nếu request có credential, nếu không thì cho đi tiếp như khách.
+// Credential sai vẫn bị từ chối để client biết token đã hết hạn.
+func (a *Authenticator) Optional() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, err := a.Authenticate(c)
+		if err != nil {
+			if errors.Is(err, ErrNoCredentials) {
+				return c.Next()
+			}
+			return authErrorJSON(c, err)
+		}
+		SetPrincipal(c, principal)
+		return c.Next()
+	}
+}
+
+func authErrorJSON(c *fiber.Ctx, err error) error {
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return c.Status(authErr.Status).JSON(fiber.Map{
+			"success": false,
+			"error":   authErr.Err,
+			"message": authErr.Message,
+		})
+	}
+	if errors.Is(err, ErrNoCredentials) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Authorization header required",
+			"message": "Please provide a valid authorization token",
+		})
+	}
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"success": false,
+		"error":   "Authentication failed",
+		"message": err.Error(),
+	})
+}
+
+// SetPrincipal lưu principal vào context.
+// "user_id"/"user_email" vẫn được set cho code cũ đọc Locals trực tiếp.
+func SetPrincipal(c *fiber.Ctx, principal *pmodel.Principal) {
+	c.Locals(pmodel.PrincipalLocalsKey, principal)
+	c.Locals("user_id", principal.ID)
+	c.Locals("user_email", principal.Email)
+}
+
+// GetPrincipal trả về principal đã xác thực, nil nếu request chưa xác thực
+func GetPrincipal(c *fiber.Ctx) *pmodel.Principal {
+	if principal, ok := c.Locals(pmodel.PrincipalLocalsKey).(*pmodel.Principal); ok {
+		return principal
+	}
+	return nil
+}
+
+// bearerToken lấy token từ header Authorization
+func bearerToken(c *fiber.Ctx) (string, error) {
+	authHeader := c.Get("Authorization")
+	if authHeader == "" {
+		return "", ErrNoCredentials
+	}
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	if token == authHeader {
+		return "", newUnauthorized("Invalid token format", "Token must be in format: Bearer <token>")
+	}
+	return token, nil
+}
+
+// APIKeyLookup tìm principal theo API key; trả về lỗi nếu key không hợp lệ
+type APIKeyLookup func(ctx context.Context, key string) (*pmodel.Principal, error)
+
+// APIKeyVerifier xác thực API key gửi qua header
+type APIKeyVerifier struct {
+	header string
+	lookup APIKeyLookup
+}
+
+// NewAPIKeyVerifier tạo verifier đọc API key từ header (mặc định "X-API-Key")
+func NewAPIKeyVerifier(header string, lookup APIKeyLookup) *APIKeyVerifier {
+	if header == "" {
+		header = "X-API-Key"
+	}
+	return &APIKeyVerifier{header: header, lookup: lookup}
+}
+
+func (v *APIKeyVerifier) Name() string {
+	return pmodel.TokenTypeAPIKey
+}
+
+func (v *APIKeyVerifier) Verify(c *fiber.Ctx) (*pmodel.Principal, error) {
+	key := c.Get(v.header)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	principal, err := v.lookup(c.UserContext(), key)
+	if err != nil {
+		return nil, newUnauthorized("Invalid API key", err.Error())
+	}
+	principal.TokenType = pmodel.TokenTypeAPIKey
+	if principal.Provider == "" {
+		principal.Provider = pmodel.TokenTypeAPIKey
+	}
+	return principal, nil
+}
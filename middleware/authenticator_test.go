@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
+)
+
+func TestAuthenticatorChain(t *testing.T) {
+	lookup := func(_ context.Context, key string) (*pmodel.Principal, error) {
+		if key != "good-key" {
+			return nil, errors.New("unknown key")
+		}
+		return &pmodel.Principal{ID: "svc-1"}, nil
+	}
+	auth := NewAuthenticator(NewAPIKeyVerifier("", lookup)).
+		WithRoleLoader(func(_ context.Context, userID string) ([]string, error) {
+			return []string{"service"}, nil
+		})
+
+	app := fiber.New()
+	app.Get("/private", auth.Middleware(), func(c *fiber.Ctx) error {
+		p := GetPrincipal(c)
+		if p.TokenType != pmodel.TokenTypeAPIKey || !p.HasRole("service") {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendString(c.Locals("user_id").(string))
+	})
+	app.Get("/public", auth.Optional(), func(c *fiber.Ctx) error {
+		if GetPrincipal(c) != nil {
+			return c.SendString("user")
+		}
+		return c.SendString("guest")
+	})
+
+	tests := []struct {
+		path   string
+		key    string
+		status int
+	}{
+		{"/private", "good-key", fiber.StatusOK},
+		{"/private", "bad-key", fiber.StatusUnauthorized},
+		{"/private", "", fiber.StatusUnauthorized},
+		{"/public", "", fiber.StatusOK},
+		{"/public", "bad-key", fiber.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.key != "" {
+			req.Header.Set("X-API-Key", tt.key)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s with key %q: expected %d, got %d", tt.path, tt.key, tt.status, resp.StatusCode)
+		}
+	}
+}
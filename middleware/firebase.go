@@ -4,18 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
+	"sync"
 
-	firebase "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/auth"
 	"github.com/gofiber/fiber/v2"
 	"github.com/techmaster-vietnam/dd_goshare/config"
-	"google.golang.org/api/option"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
 )
 
-var firebaseAuthClient *auth.Client
+var (
+	firebaseTokenVerifier TokenVerifier
+	firebaseVerifierMu    sync.RWMutex
+)
 
-// InitFirebaseAuth khởi tạo Firebase Auth client
+// InitFirebaseAuth khởi tạo verifier Firebase ID token theo config (firebase, emulator hoặc local)
 func InitFirebaseAuth(firebaseConfig *config.FirebaseConfig) error {
 	ctx := context.Background()
 
@@ -24,186 +26,207 @@ func InitFirebaseAuth(firebaseConfig *config.FirebaseConfig) error {
 		return fmt.Errorf("invalid firebase config: %v", err)
 	}
 
-	// Parse service account key
-	var serviceAccountKey map[string]interface{}
-	if err := json.Unmarshal([]byte(firebaseConfig.ServiceAccountKey), &serviceAccountKey); err != nil {
-		return fmt.Errorf("failed to parse service account key: %v", err)
-	}
-
-	// Initialize Firebase app
-	opt := option.WithCredentialsJSON([]byte(firebaseConfig.ServiceAccountKey))
-	firebaseAppConfig := &firebase.Config{
-		ProjectID: firebaseConfig.ProjectID,
-	}
-
-	app, err := firebase.NewApp(ctx, firebaseAppConfig, opt)
-	if err != nil {
-		return fmt.Errorf("failed to initialize Firebase app: %v", err)
+	var (
+		verifier TokenVerifier
+		err      error
+	)
+	switch firebaseConfig.Verifier {
+	case config.FirebaseVerifierEmulator:
+		verifier, err = NewEmulatorTokenVerifier(ctx, firebaseConfig.ProjectID, firebaseConfig.AuthEmulatorHost)
+	case config.FirebaseVerifierLocal:
+		local := NewLocalTokenVerifier(firebaseConfig.ProjectID)
+		err = local.loadKeyFiles(firebaseConfig.LocalKeyFiles)
+		verifier = local
+	default:
+		// Parse service account key
+		var serviceAccountKey map[string]interface{}
+		if err := json.Unmarshal([]byte(firebaseConfig.ServiceAccountKey), &serviceAccountKey); err != nil {
+			return fmt.Errorf("failed to parse service account key: %v", err)
+		}
+		verifier, err = NewFirebaseTokenVerifier(ctx, firebaseConfig.ProjectID, firebaseConfig.ServiceAccountKey)
 	}
-
-	// Get Auth client
-	authClient, err := app.Auth(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get Firebase Auth client: %v", err)
+		return err
 	}
 
-	firebaseAuthClient = authClient
+	SetFirebaseTokenVerifier(verifier)
 	return nil
 }
 
+// SetFirebaseTokenVerifier thay verifier mặc định, ví dụ LocalTokenVerifier trong test
+func SetFirebaseTokenVerifier(verifier TokenVerifier) {
+	firebaseVerifierMu.Lock()
+	firebaseTokenVerifier = verifier
+	firebaseVerifierMu.Unlock()
+}
+
+// GetFirebaseTokenVerifier trả về verifier mặc định (nil nếu chưa khởi tạo)
+func GetFirebaseTokenVerifier() TokenVerifier {
+	firebaseVerifierMu.RLock()
+	defer firebaseVerifierMu.RUnlock()
+	return firebaseTokenVerifier
+}
+
 // FirebaseAuthMiddleware validates Firebase ID token
 func FirebaseAuthMiddleware() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		// Check if Firebase Auth client is initialized
-		if firebaseAuthClient == nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"error":   "Firebase Auth not initialized",
-			})
-		}
+	return NewAuthenticator(NewFirebaseVerifier()).Middleware()
+}
 
-		// Get token from Authorization header
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"error":   "Authorization header required",
-				"message": "Please provide a Firebase ID token",
-			})
-		}
+// FirebaseVerifier xác thực Firebase ID token gửi qua header Authorization
+type FirebaseVerifier struct {
+	tokens TokenVerifier
+}
 
-		// Check if token starts with "Bearer "
-		idToken := strings.TrimPrefix(authHeader, "Bearer ")
-		if idToken == authHeader {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid token format",
-				"message": "Token must be in format: Bearer <firebase_id_token>",
-			})
-		}
+// NewFirebaseVerifier tạo verifier dùng TokenVerifier đã khởi tạo bởi InitFirebaseAuth
+func NewFirebaseVerifier() *FirebaseVerifier {
+	return &FirebaseVerifier{}
+}
 
-		// Debug: Log token details
-		fmt.Printf("🔍 DEBUG Firebase Middleware:\n")
-		fmt.Printf("  - Token length: %d\n", len(idToken))
-		fmt.Printf("  - Token starts with 'eyJ': %t\n", len(idToken) >= 3 && idToken[:3] == "eyJ")
-		fmt.Printf("  - Token first 50 chars: %.50s...\n", idToken)
-		if len(idToken) > 50 {
-			fmt.Printf("  - Token last 20 chars: ...%s\n", idToken[len(idToken)-20:])
-		}
+// WithTokenVerifier dùng TokenVerifier riêng thay cho verifier mặc định
+func (v *FirebaseVerifier) WithTokenVerifier(tokens TokenVerifier) *FirebaseVerifier {
+	v.tokens = tokens
+	return v
+}
 
-		// Count JWT segments (should be 3: header.payload.signature)
-		segments := strings.Split(idToken, ".")
-		fmt.Printf("  - JWT segments count: %d\n", len(segments))
-		if len(segments) != 3 {
-			fmt.Printf("  - ❌ Invalid JWT format - expected 3 segments, got %d\n", len(segments))
-			for i, segment := range segments {
-				fmt.Printf("    Segment %d length: %d\n", i, len(segment))
-			}
-		}
+func (v *FirebaseVerifier) Name() string {
+	return pmodel.TokenTypeFirebase
+}
 
-		// Verify Firebase ID token
-		token, err := firebaseAuthClient.VerifyIDToken(context.Background(), idToken)
-		if err != nil {
-			fmt.Printf("  - ❌ Firebase verification error: %v\n", err)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid Firebase token",
-				"message": fmt.Sprintf("Token verification failed: %v", err),
-			})
-		} // Extract user information from token
-		var email, name, picture, phone, provider string
-
-		if emailClaim, ok := token.Claims["email"].(string); ok {
-			email = emailClaim
-		}
-		if nameClaim, ok := token.Claims["name"].(string); ok {
-			name = nameClaim
-		}
-		if pictureClaim, ok := token.Claims["picture"].(string); ok {
-			picture = pictureClaim
-		}
-		if phoneClaim, ok := token.Claims["phone_number"].(string); ok {
-			phone = phoneClaim
-		}
+func (v *FirebaseVerifier) Verify(c *fiber.Ctx) (*pmodel.Principal, error) {
+	tokens := v.tokens
+	if tokens == nil {
+		tokens = GetFirebaseTokenVerifier()
+	}
+	if tokens == nil {
+		return nil, &AuthError{Status: fiber.StatusInternalServerError, Err: "Firebase Auth not initialized"}
+	}
 
-		// Extract provider from Firebase claims
-		if firebaseClaims, ok := token.Claims["firebase"].(map[string]interface{}); ok {
-			if signInProvider, ok := firebaseClaims["sign_in_provider"].(string); ok {
-				switch signInProvider {
-				case "google.com":
-					provider = "google"
-				case "apple.com":
-					provider = "apple"
-				case "facebook.com":
-					provider = "facebook"
-				case "phone":
-					provider = "phone"
-				case "password":
-					provider = "email"
-				default:
-					provider = signInProvider
-				}
-			}
-		}
+	idToken, err := bearerToken(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify Firebase ID token
+	token, err := tokens.VerifyIDToken(c.UserContext(), idToken)
+	if err != nil {
+		return nil, newUnauthorized("Invalid Firebase token", fmt.Sprintf("Token verification failed: %v", err))
+	}
+
+	return firebasePrincipal(token), nil
+}
+
+// firebasePrincipal chuyển Firebase token đã xác thực thành Principal
+func firebasePrincipal(token *auth.Token) *pmodel.Principal {
+	// Extract user information from token
+	var email, name, picture, phone, provider string
+	var emailVerified bool
+
+	if emailClaim, ok := token.Claims["email"].(string); ok {
+		email = emailClaim
+	}
+	if verifiedClaim, ok := token.Claims["email_verified"].(bool); ok {
+		emailVerified = verifiedClaim
+	}
+	if nameClaim, ok := token.Claims["name"].(string); ok {
+		name = nameClaim
+	}
+	if pictureClaim, ok := token.Claims["picture"].(string); ok {
+		picture = pictureClaim
+	}
+	if phoneClaim, ok := token.Claims["phone_number"].(string); ok {
+		phone = phoneClaim
+	}
 
-		if provider == "" {
-			provider = "firebase"
+	// Extract provider from Firebase claims
+	if firebaseClaims, ok := token.Claims["firebase"].(map[string]interface{}); ok {
+		if signInProvider, ok := firebaseClaims["sign_in_provider"].(string); ok {
+			switch signInProvider {
+			case "google.com":
+				provider = "google"
+			case "apple.com":
+				provider = "apple"
+			case "facebook.com":
+				provider = "facebook"
+			case "phone":
+				provider = "phone"
+			case "password":
+				provider = "email"
+			default:
+				provider = signInProvider
+			}
 		}
+	}
 
-		// Store Firebase user info in context
-		c.Locals("firebase_uid", token.UID)
-		c.Locals("firebase_email", email)
-		c.Locals("firebase_name", name)
-		c.Locals("firebase_picture", picture)
-		c.Locals("firebase_phone", phone)
-		c.Locals("firebase_provider", provider)
-		c.Locals("firebase_token", token)
+	if provider == "" {
+		provider = "firebase"
+	}
 
-		// Also store compatible user info for existing code
-		c.Locals("user_id", token.UID)
-		c.Locals("user_email", email)
+	return &pmodel.Principal{
+		ID:             token.UID,
+		Email:          email,
+		EmailVerified:  emailVerified,
+		ProviderUserID: token.UID,
+		Name:           name,
+		Picture:        picture,
+		Phone:          phone,
+		Provider:       provider,
+		TokenType:      pmodel.TokenTypeFirebase,
+		Token:          token,
+	}
+}
 
-		return c.Next()
+// firebasePrincipalFromContext trả về principal nếu request được xác thực bằng Firebase ID token
+func firebasePrincipalFromContext(c *fiber.Ctx) *pmodel.Principal {
+	if principal := GetPrincipal(c); principal != nil && principal.TokenType == pmodel.TokenTypeFirebase {
+		return principal
 	}
+	return nil
 }
 
-// GetFirebaseUIDFromContext extracts Firebase UID from context
+// GetFirebaseUIDFromContext extracts Firebase UID from context.
+// Sau khi provisioning, UID này có thể khác customer ID (GetUserIDFromContext).
 func GetFirebaseUIDFromContext(c *fiber.Ctx) string {
-	if uid, ok := c.Locals("firebase_uid").(string); ok {
-		return uid
+	if principal := firebasePrincipalFromContext(c); principal != nil {
+		if principal.ProviderUserID != "" {
+			return principal.ProviderUserID
+		}
+		return principal.ID
 	}
 	return ""
 }
 
 // GetFirebaseEmailFromContext extracts Firebase email from context
 func GetFirebaseEmailFromContext(c *fiber.Ctx) string {
-	if email, ok := c.Locals("firebase_email").(string); ok {
-		return email
+	if principal := firebasePrincipalFromContext(c); principal != nil {
+		return principal.Email
 	}
 	return ""
 }
 
 // GetFirebaseProviderFromContext extracts Firebase provider from context
 func GetFirebaseProviderFromContext(c *fiber.Ctx) string {
-	if provider, ok := c.Locals("firebase_provider").(string); ok {
-		return provider
+	if principal := firebasePrincipalFromContext(c); principal != nil {
+		return principal.Provider
 	}
 	return ""
 }
 
 // GetFirebaseTokenFromContext extracts Firebase token from context
 func GetFirebaseTokenFromContext(c *fiber.Ctx) *auth.Token {
-	if token, ok := c.Locals("firebase_token").(*auth.Token); ok {
-		return token
+	if principal := firebasePrincipalFromContext(c); principal != nil {
+		if token, ok := principal.Token.(*auth.Token); ok {
+			return token
+		}
 	}
 	return nil
 }
 
 // VerifyFirebaseToken verifies Firebase ID token (utility function)
 func VerifyFirebaseToken(idToken string) (*auth.Token, error) {
-	if firebaseAuthClient == nil {
+	verifier := GetFirebaseTokenVerifier()
+	if verifier == nil {
 		return nil, fmt.Errorf("Firebase Auth not initialized")
 	}
 
-	return firebaseAuthClient.VerifyIDToken(context.Background(), idToken)
+	return verifier.VerifyIDToken(context.Background(), idToken)
 }
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func mintLocalFirebaseToken(t *testing.T, key *rsa.PrivateKey, kid, projectID, uid string) string {
+	t.Helper()
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":       "https://securetoken.google.com/" + projectID,
+		"aud":       projectID,
+		"sub":       uid,
+		"iat":       now.Unix(),
+		"exp":       now.Add(time.Hour).Unix(),
+		"auth_time": now.Unix(),
+		"email":     uid + "@example.com",
+		"firebase":  map[string]interface{}{"sign_in_provider": "google.com"},
+	})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestLocalTokenVerifierWithFirebaseMiddleware(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	local := NewLocalTokenVerifier("demo-project")
+	local.AddKey("test-key", &key.PublicKey)
+
+	app := fiber.New()
+	app.Get("/me", NewAuthenticator(NewFirebaseVerifier().WithTokenVerifier(local)).Middleware(), func(c *fiber.Ctx) error {
+		if GetFirebaseProviderFromContext(c) != "google" || GetFirebaseEmailFromContext(c) != "u1@example.com" {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendString(GetFirebaseUIDFromContext(c))
+	})
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"valid", mintLocalFirebaseToken(t, key, "test-key", "demo-project", "u1"), fiber.StatusOK},
+		{"wrong project", mintLocalFirebaseToken(t, key, "test-key", "other-project", "u1"), fiber.StatusUnauthorized},
+		{"unknown kid", mintLocalFirebaseToken(t, key, "missing", "demo-project", "u1"), fiber.StatusUnauthorized},
+		{"wrong key", mintLocalFirebaseToken(t, otherKey, "test-key", "demo-project", "u1"), fiber.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", tt.name, err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/techmaster-vietnam/dd_goshare/config"
+)
+
+var (
+	keySet   *KeySet
+	keySetMu sync.Mutex
+)
+
+// InitJWTKeys nạp khóa ký/xác thực JWT từ config.
+// Trả về lỗi (và ứng dụng nên dừng) nếu dùng secret mặc định ngoài môi trường dev.
+func InitJWTKeys(jwtConfig *config.JWTConfig) error {
+	ks, err := NewKeySet(jwtConfig)
+	if err != nil {
+		return err
+	}
+	keySetMu.Lock()
+	keySet = ks
+	keySetMu.Unlock()
+	return nil
+}
+
+// GetKeySet trả về key set đang dùng (nil nếu chưa khởi tạo)
+func GetKeySet() *KeySet {
+	keySetMu.Lock()
+	defer keySetMu.Unlock()
+	return keySet
+}
+
+// resolveKeySet trả về key set đã khởi tạo, hoặc tạo từ cfg nếu ứng dụng chưa gọi InitJWTKeys
+func resolveKeySet(cfg *config.Config) (*KeySet, error) {
+	keySetMu.Lock()
+	defer keySetMu.Unlock()
+	if keySet != nil {
+		return keySet, nil
+	}
+	ks, err := NewKeySet(cfg.JWT)
+	if err != nil {
+		return nil, err
+	}
+	keySet = ks
+	return keySet, nil
+}
+
+type jwtKey struct {
+	kid    string
+	method jwt.SigningMethod
+	public crypto.PublicKey // *rsa.PublicKey hoặc ed25519.PublicKey
+}
+
+// KeySet giữ khóa ký hiện tại và các khóa xác thực theo kid để xoay vòng khóa không gián đoạn
+type KeySet struct {
+	mu         sync.RWMutex
+	signingKID string
+	method     jwt.SigningMethod
+	private    interface{} // []byte (HS256), *rsa.PrivateKey hoặc ed25519.PrivateKey
+	hmacSecret []byte      // dùng cho token HS256 không có kid
+	verify     map[string]jwtKey
+}
+
+// NewKeySet tạo key set từ JWT config
+func NewKeySet(jwtConfig *config.JWTConfig) (*KeySet, error) {
+	if err := jwtConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid JWT config: %w", err)
+	}
+
+	ks := &KeySet{verify: make(map[string]jwtKey)}
+
+	if jwtConfig.Algorithm == "HS256" || jwtConfig.AllowLegacyHS256 {
+		ks.hmacSecret = []byte(jwtConfig.Secret)
+	}
+
+	switch jwtConfig.Algorithm {
+	case "HS256":
+		ks.method = jwt.SigningMethodHS256
+		ks.private = []byte(jwtConfig.Secret)
+		ks.signingKID = jwtConfig.KeyID
+	case "RS256", "EDDSA":
+		pemData := []byte(jwtConfig.PrivateKey)
+		if jwtConfig.PrivateKeyFile != "" {
+			data, err := os.ReadFile(jwtConfig.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JWT private key file: %w", err)
+			}
+			pemData = data
+		}
+		if err := ks.setPrivateKey(jwtConfig.Algorithm, pemData, jwtConfig.KeyID); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, entry := range jwtConfig.VerifyKeyFiles {
+		kid, path := "", entry
+		if idx := strings.Index(entry, "="); idx > 0 {
+			kid, path = entry[:idx], entry[idx+1:]
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT verification key %s: %w", path, err)
+		}
+		if _, err := ks.AddVerificationKeyPEM(kid, data); err != nil {
+			return nil, fmt.Errorf("invalid JWT verification key %s: %w", path, err)
+		}
+	}
+
+	return ks, nil
+}
+
+func (ks *KeySet) setPrivateKey(algorithm string, pemData []byte, kid string) error {
+	var public crypto.PublicKey
+	switch algorithm {
+	case "RS256":
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(pemData)
+		if err != nil {
+			return fmt.Errorf("failed to parse RSA private key: %w", err)
+		}
+		ks.method = jwt.SigningMethodRS256
+		ks.private = key
+		public = &key.PublicKey
+	case "EDDSA":
+		key, err := jwt.ParseEdPrivateKeyFromPEM(pemData)
+		if err != nil {
+			return fmt.Errorf("failed to parse Ed25519 private key: %w", err)
+		}
+		edKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return fmt.Errorf("unsupported EdDSA private key type %T", key)
+		}
+		ks.method = jwt.SigningMethodEdDSA
+		ks.private = edKey
+		public = edKey.Public()
+	}
+
+	if kid == "" {
+		var err error
+		if kid, err = keyThumbprint(public); err != nil {
+			return err
+		}
+	}
+	ks.signingKID = kid
+	ks.verify[kid] = jwtKey{kid: kid, method: ks.method, public: public}
+	return nil
+}
+
+// AddVerificationKeyPEM thêm public key (RSA hoặc Ed25519, dạng PEM) được chấp nhận khi xác thực.
+// kid rỗng thì dùng thumbprint của khóa. Trả về kid đã dùng.
+func (ks *KeySet) AddVerificationKeyPEM(kid string, pemData []byte) (string, error) {
+	var (
+		public crypto.PublicKey
+		method jwt.SigningMethod
+	)
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(pemData); err == nil {
+		public, method = key, jwt.SigningMethodRS256
+	} else if key, err := jwt.ParseEdPublicKeyFromPEM(pemData); err == nil {
+		public, method = key, jwt.SigningMethodEdDSA
+	} else {
+		return "", fmt.Errorf("unsupported public key: must be RSA or Ed25519 PEM")
+	}
+	return ks.AddVerificationKey(kid, method, public)
+}
+
+// AddVerificationKey thêm public key được chấp nhận khi xác thực
+func (ks *KeySet) AddVerificationKey(kid string, method jwt.SigningMethod, public crypto.PublicKey) (string, error) {
+	if kid == "" {
+		var err error
+		if kid, err = keyThumbprint(public); err != nil {
+			return "", err
+		}
+	}
+	ks.mu.Lock()
+	ks.verify[kid] = jwtKey{kid: kid, method: method, public: public}
+	ks.mu.Unlock()
+	return kid, nil
+}
+
+// RemoveVerificationKey ngừng chấp nhận token ký bởi khóa kid (không áp dụng cho khóa ký hiện tại)
+func (ks *KeySet) RemoveVerificationKey(kid string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if kid != ks.signingKID {
+		delete(ks.verify, kid)
+	}
+}
+
+// Sign ký claims bằng khóa hiện tại, gắn kid vào header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	token := jwt.NewWithClaims(ks.method, claims)
+	if ks.signingKID != "" {
+		token.Header["kid"] = ks.signingKID
+	}
+	return token.SignedString(ks.private)
+}
+
+// Keyfunc chọn khóa xác thực theo kid và kiểm tra thuật toán khớp với khóa
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	kid, _ := token.Header["kid"].(string)
+
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if ks.hmacSecret == nil || token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method: %s", token.Method.Alg())
+		}
+		if kid != "" && ks.method == jwt.SigningMethodHS256 && kid != ks.signingKID {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return ks.hmacSecret, nil
+	}
+
+	if kid == "" {
+		return nil, fmt.Errorf("token is missing kid header")
+	}
+	key, ok := ks.verify[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if key.method.Alg() != token.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+	return key.public, nil
+}
+
+// JWK là một public key theo RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet là danh sách public key trả về ở /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS trả về các public key hiện hành; secret HMAC không bao giờ được công bố
+func (ks *KeySet) JWKS() JWKSet {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0, len(ks.verify))}
+	for _, key := range ks.verify {
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.kid,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.kid,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return set
+}
+
+// JWKSHandler phục vụ /.well-known/jwks.json để các service khác xác thực token mà không cần secret
+// Sử dụng: app.Get("/.well-known/jwks.json", middleware.JWKSHandler())
+func JWKSHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ks := GetKeySet()
+		if ks == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"success": false,
+				"error":   "JWT keys not initialized",
+			})
+		}
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(ks.JWKS())
+	}
+}
+
+// keyThumbprint sinh kid ổn định từ SHA-256 của public key (DER)
+func keyThumbprint(public crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
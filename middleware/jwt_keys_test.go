@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/techmaster-vietnam/dd_goshare/config"
+)
+
+func TestKeySet(t *testing.T) {
+	t.Run("TestDefaultSecretRejectedOutsideDev", func(t *testing.T) {
+		cfg := &config.JWTConfig{Secret: config.DefaultJWTSecret, Algorithm: "HS256", Environment: "production"}
+		if _, err := NewKeySet(cfg); err == nil {
+			t.Error("Expected default secret to be rejected in production")
+		}
+		cfg.Environment = "development"
+		if _, err := NewKeySet(cfg); err != nil {
+			t.Errorf("Expected default secret to be allowed in development, got %v", err)
+		}
+	})
+
+	t.Run("TestRS256SignAndVerify", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+		ks, err := NewKeySet(&config.JWTConfig{Algorithm: "RS256", PrivateKey: string(pemKey), KeyID: "k1", Environment: "production"})
+		if err != nil {
+			t.Fatalf("failed to create key set: %v", err)
+		}
+
+		signed, err := ks.Sign(&ClaimsStringID{UserID: "u1"})
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+
+		claims := &ClaimsStringID{}
+		token, err := jwt.ParseWithClaims(signed, claims, ks.Keyfunc)
+		if err != nil || !token.Valid {
+			t.Fatalf("Expected token to verify, got %v", err)
+		}
+		if token.Header["kid"] != "k1" || claims.UserID != "u1" {
+			t.Errorf("Unexpected kid %v or user %s", token.Header["kid"], claims.UserID)
+		}
+
+		jwks := ks.JWKS()
+		if len(jwks.Keys) != 1 || jwks.Keys[0].Kty != "RSA" || jwks.Keys[0].Kid != "k1" {
+			t.Errorf("Unexpected JWKS: %+v", jwks)
+		}
+
+		// HS256 token không được chấp nhận khi chưa bật AllowLegacyHS256
+		hmacToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &ClaimsStringID{UserID: "u1"}).SignedString([]byte("secret"))
+		if _, err := jwt.ParseWithClaims(hmacToken, &ClaimsStringID{}, ks.Keyfunc); err == nil {
+			t.Error("Expected HS256 token to be rejected")
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+// RateLimitStorage lưu bộ đếm request cho rate limiter.
+// Increment phải atomic: tăng bộ đếm của key trong cửa sổ hiện tại (tạo cửa sổ mới
+// nếu cửa sổ cũ đã hết hạn) và trả về số request đã đếm cùng thời điểm reset.
+type RateLimitStorage interface {
+	Increment(ctx context.Context, key string, window time.Duration) (count int, resetAt time.Time, err error)
+	Decrement(ctx context.Context, key string) error
+}
+
+type memoryCounter struct {
+	count     int
+	expiresAt time.Time
+}
+
+// MemoryRateLimitStorage giữ bộ đếm trong bộ nhớ process, phù hợp khi chạy một instance
+type MemoryRateLimitStorage struct {
+	mu       sync.Mutex
+	counters map[string]*memoryCounter
+	now      func() time.Time
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMemoryRateLimitStorage tạo storage in-memory và chạy goroutine dọn các key đã hết hạn.
+// Gọi Close khi không dùng nữa để dừng goroutine dọn dẹp.
+func NewMemoryRateLimitStorage(gcInterval time.Duration) *MemoryRateLimitStorage {
+	s := &MemoryRateLimitStorage{
+		counters: make(map[string]*memoryCounter),
+		now:      time.Now,
+		done:     make(chan struct{}),
+	}
+	if gcInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(gcInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-s.done:
+					return
+				case <-ticker.C:
+					s.gc()
+				}
+			}
+		}()
+	}
+	return s
+}
+
+// Close dừng goroutine dọn dẹp; gọi nhiều lần không lỗi
+func (s *MemoryRateLimitStorage) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	return nil
+}
+
+// Increment tăng bộ đếm của key trong cửa sổ hiện tại
+func (s *MemoryRateLimitStorage) Increment(_ context.Context, key string, window time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	counter, ok := s.counters[key]
+	if !ok || !now.Before(counter.expiresAt) {
+		counter = &memoryCounter{expiresAt: now.Add(window)}
+		s.counters[key] = counter
+	}
+	counter.count++
+	return counter.count, counter.expiresAt, nil
+}
+
+// Decrement giảm bộ đếm của key (dùng cho SkipFailedRequests/SkipSuccessfulRequests)
+func (s *MemoryRateLimitStorage) Decrement(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if counter, ok := s.counters[key]; ok && counter.count > 0 {
+		counter.count--
+	}
+	return nil
+}
+
+func (s *MemoryRateLimitStorage) gc() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, counter := range s.counters {
+		if !now.Before(counter.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// PostgresRateLimitStorage lưu bộ đếm trong bảng rate_limit_counters để nhiều instance dùng chung
+type PostgresRateLimitStorage struct {
+	db *gorm.DB
+}
+
+// NewPostgresRateLimitStorage tạo storage dùng Postgres.
+// Bảng rate_limit_counters được tạo bởi database.DBMigrator.
+func NewPostgresRateLimitStorage(db *gorm.DB) *PostgresRateLimitStorage {
+	return &PostgresRateLimitStorage{db: db}
+}
+
+// Increment dùng một câu UPSERT duy nhất nên an toàn khi nhiều instance cùng ghi
+func (s *PostgresRateLimitStorage) Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	now := time.Now()
+	var row models.RateLimitCounter
+	err := s.db.WithContext(ctx).Raw(`
+		INSERT INTO rate_limit_counters (key, count, expires_at)
+		VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			count = CASE WHEN rate_limit_counters.expires_at <= ? THEN 1 ELSE rate_limit_counters.count + 1 END,
+			expires_at = CASE WHEN rate_limit_counters.expires_at <= ? THEN EXCLUDED.expires_at ELSE rate_limit_counters.expires_at END
+		RETURNING key, count, expires_at
+	`, key, now.Add(window), now, now).Scan(&row).Error
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to increment rate limit counter: %w", err)
+	}
+	return row.Count, row.ExpiresAt, nil
+}
+
+// Decrement giảm bộ đếm của key
+func (s *PostgresRateLimitStorage) Decrement(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Exec(
+		"UPDATE rate_limit_counters SET count = count - 1 WHERE key = ? AND count > 0", key,
+	).Error
+}
+
+// Cleanup xóa các bộ đếm đã hết hạn, nên gọi định kỳ
+func (s *PostgresRateLimitStorage) Cleanup(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&models.RateLimitCounter{})
+	return result.RowsAffected, result.Error
+}
@@ -1,10 +1,19 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
+	"gorm.io/gorm"
 )
 
 // RateLimiterConfig returns rate limiter configuration
@@ -70,3 +79,303 @@ func AuthRateLimiterConfig() limiter.Config {
 	}
 }
 
+// Loại subject dùng làm key cho rate limiter
+const (
+	RateLimitSubjectUser   = "user"
+	RateLimitSubjectAPIKey = "apikey"
+	RateLimitSubjectIP     = "ip"
+)
+
+// RateLimitSubject là đối tượng bị giới hạn: user đã đăng nhập, API key hoặc IP
+type RateLimitSubject struct {
+	Kind string
+	ID   string
+}
+
+// Key trả về key lưu trong storage, ví dụ "user:abc123"
+func (s RateLimitSubject) Key() string {
+	return s.Kind + ":" + s.ID
+}
+
+// RateLimitResolver xác định số request tối đa trong một cửa sổ cho từng subject
+type RateLimitResolver interface {
+	ResolveLimit(ctx context.Context, subject RateLimitSubject) (int, error)
+}
+
+// RateLimitResolverFunc cho phép dùng function làm RateLimitResolver
+type RateLimitResolverFunc func(ctx context.Context, subject RateLimitSubject) (int, error)
+
+// ResolveLimit implements RateLimitResolver
+func (f RateLimitResolverFunc) ResolveLimit(ctx context.Context, subject RateLimitSubject) (int, error) {
+	return f(ctx, subject)
+}
+
+// UserRateLimiterConfig cấu hình rate limiter theo user/API key/IP
+type UserRateLimiterConfig struct {
+	// Storage lưu bộ đếm, mặc định là in-memory
+	Storage RateLimitStorage
+	// Resolver xác định limit cho subject, mặc định trả về DefaultLimit
+	Resolver RateLimitResolver
+	// DefaultLimit dùng khi Resolver lỗi hoặc không có limit riêng
+	DefaultLimit int
+	// Window là độ dài cửa sổ đếm
+	Window time.Duration
+	// KeyPrefix phân tách bộ đếm giữa các nhóm route (ví dụ "auth", "api")
+	KeyPrefix string
+	// SubjectExtractor cho phép tùy biến cách xác định subject
+	SubjectExtractor func(c *fiber.Ctx) RateLimitSubject
+
+	Next                   func(c *fiber.Ctx) bool
+	LimitReached           fiber.Handler
+	SkipFailedRequests     bool
+	SkipSuccessfulRequests bool
+}
+
+// DefaultRateLimitSubject ưu tiên principal đã xác thực (user hoặc API key), sau đó tới user_id rồi mới tới IP.
+// Header API key chưa được xác thực không tạo bộ đếm riêng, nếu không client chỉ cần đổi giá trị header
+// mỗi request là thoát limit theo IP. Middleware xác thực phải chạy trước.
+func DefaultRateLimitSubject(c *fiber.Ctx) RateLimitSubject {
+	if principal := GetPrincipal(c); principal != nil && principal.ID != "" {
+		if principal.TokenType == pmodel.TokenTypeAPIKey {
+			return RateLimitSubject{Kind: RateLimitSubjectAPIKey, ID: principal.ID}
+		}
+		return RateLimitSubject{Kind: RateLimitSubjectUser, ID: principal.ID}
+	}
+	if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+		return RateLimitSubject{Kind: RateLimitSubjectUser, ID: userID}
+	}
+	return RateLimitSubject{Kind: RateLimitSubjectIP, ID: c.IP()}
+}
+
+// UserRateLimiter trả về rate limiter với limit động theo role/gói cước của user.
+// Khác với RateLimiterConfig (luôn key theo IP), user sau NAT không dùng chung bộ đếm.
+func UserRateLimiter(cfg UserRateLimiterConfig) fiber.Handler {
+	if cfg.Window <= 0 {
+		cfg.Window = 1 * time.Minute
+	}
+	if cfg.DefaultLimit <= 0 {
+		cfg.DefaultLimit = 100
+	}
+	if cfg.Storage == nil {
+		cfg.Storage = NewMemoryRateLimitStorage(cfg.Window)
+	}
+	if cfg.SubjectExtractor == nil {
+		cfg.SubjectExtractor = DefaultRateLimitSubject
+	}
+	if cfg.LimitReached == nil {
+		cfg.LimitReached = func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"success": false,
+				"error":   "Rate limit exceeded",
+				"message": "Too many requests. Please try again later.",
+			})
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		ctx := c.UserContext()
+		subject := cfg.SubjectExtractor(c)
+
+		limit := cfg.DefaultLimit
+		if cfg.Resolver != nil {
+			resolved, err := cfg.Resolver.ResolveLimit(ctx, subject)
+			if err != nil {
+				log.Printf("rate limiter: failed to resolve limit for %s: %v", subject.Key(), err)
+			} else if resolved > 0 {
+				limit = resolved
+			}
+		}
+
+		key := subject.Key()
+		if cfg.KeyPrefix != "" {
+			key = cfg.KeyPrefix + ":" + key
+		}
+
+		count, resetAt, err := cfg.Storage.Increment(ctx, key, cfg.Window)
+		if err != nil {
+			// Storage lỗi thì cho request đi qua thay vì chặn toàn bộ hệ thống
+			log.Printf("rate limiter: storage error for %s: %v", key, err)
+			return c.Next()
+		}
+
+		resetInSec := int(math.Ceil(time.Until(resetAt).Seconds()))
+		if resetInSec < 0 {
+			resetInSec = 0
+		}
+		remaining := limit - count
+
+		if remaining < 0 {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(resetInSec))
+			return cfg.LimitReached(c)
+		}
+
+		err = c.Next()
+
+		status := c.Response().StatusCode()
+		if (cfg.SkipSuccessfulRequests && status < fiber.StatusBadRequest) ||
+			(cfg.SkipFailedRequests && status >= fiber.StatusBadRequest) {
+			if decErr := cfg.Storage.Decrement(ctx, key); decErr != nil {
+				log.Printf("rate limiter: failed to decrement %s: %v", key, decErr)
+			} else {
+				remaining++
+			}
+		}
+
+		c.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Set("X-RateLimit-Reset", strconv.Itoa(resetInSec))
+
+		return err
+	}
+}
+
+// RateLimitPolicy mô tả limit theo role RBAC và gói cước (Subscription)
+type RateLimitPolicy struct {
+	// DefaultLimit cho user đã đăng nhập nhưng không có role/gói nào khớp
+	DefaultLimit int
+	// AnonymousLimit cho request chỉ xác định được theo IP
+	AnonymousLimit int
+	// APIKeyLimit cho request dùng API key
+	APIKeyLimit int
+	// RoleLimits theo tên role (viết thường), ví dụ {"admin": 1000}
+	RoleLimits map[string]int
+	// PlanLimits theo ID hoặc tên của Subscription đang active, ví dụ {"premium": 600}
+	PlanLimits map[string]int
+	// CacheTTL thời gian cache limit của từng user để tránh query DB mỗi request
+	CacheTTL time.Duration
+	// CacheSize số user tối đa giữ trong cache, mặc định 10000
+	CacheSize int
+}
+
+type cachedRateLimit struct {
+	limit     int
+	expiresAt time.Time
+}
+
+// DBRateLimitResolver lấy limit từ role trong user_roles và gói đang active trong customer_subscriptions.
+// Khi user có nhiều role/gói, limit lớn nhất được áp dụng.
+type DBRateLimitResolver struct {
+	db     *gorm.DB
+	policy RateLimitPolicy
+
+	mu    sync.RWMutex
+	cache map[string]cachedRateLimit
+}
+
+// NewDBRateLimitResolver tạo resolver đọc role và gói cước từ database
+func NewDBRateLimitResolver(db *gorm.DB, policy RateLimitPolicy) *DBRateLimitResolver {
+	if policy.CacheTTL <= 0 {
+		policy.CacheTTL = 1 * time.Minute
+	}
+	if policy.CacheSize <= 0 {
+		policy.CacheSize = 10000
+	}
+	return &DBRateLimitResolver{
+		db:     db,
+		policy: policy,
+		cache:  make(map[string]cachedRateLimit),
+	}
+}
+
+// ResolveLimit implements RateLimitResolver
+func (r *DBRateLimitResolver) ResolveLimit(ctx context.Context, subject RateLimitSubject) (int, error) {
+	switch subject.Kind {
+	case RateLimitSubjectIP:
+		return r.policy.AnonymousLimit, nil
+	case RateLimitSubjectAPIKey:
+		return r.policy.APIKeyLimit, nil
+	}
+
+	r.mu.RLock()
+	cached, ok := r.cache[subject.ID]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.limit, nil
+	}
+
+	limit, err := r.lookupUserLimit(ctx, subject.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	r.remember(subject.ID, limit, time.Now())
+	return limit, nil
+}
+
+// remember lưu limit vào cache; khi đầy thì bỏ các mục hết hạn, nếu vẫn đầy thì bỏ mục sắp hết hạn nhất
+func (r *DBRateLimitResolver) remember(userID string, limit int, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.cache[userID]; !ok && len(r.cache) >= r.policy.CacheSize {
+		for id, cached := range r.cache {
+			if !now.Before(cached.expiresAt) {
+				delete(r.cache, id)
+			}
+		}
+		for len(r.cache) >= r.policy.CacheSize {
+			oldest := ""
+			for id, cached := range r.cache {
+				if oldest == "" || cached.expiresAt.Before(r.cache[oldest].expiresAt) {
+					oldest = id
+				}
+			}
+			delete(r.cache, oldest)
+		}
+	}
+	r.cache[userID] = cachedRateLimit{limit: limit, expiresAt: now.Add(r.policy.CacheTTL)}
+}
+
+// Invalidate xóa limit đã cache của user (gọi khi đổi role hoặc gói cước)
+func (r *DBRateLimitResolver) Invalidate(userID string) {
+	r.mu.Lock()
+	delete(r.cache, userID)
+	r.mu.Unlock()
+}
+
+func (r *DBRateLimitResolver) lookupUserLimit(ctx context.Context, userID string) (int, error) {
+	limit := r.policy.DefaultLimit
+	db := r.db.WithContext(ctx)
+
+	if len(r.policy.RoleLimits) > 0 {
+		var roleNames []string
+		if err := db.Table("roles").
+			Joins("JOIN user_roles ON roles.id = user_roles.role_id").
+			Where("user_roles.user_id = ?", userID).
+			Pluck("roles.name", &roleNames).Error; err != nil {
+			return 0, fmt.Errorf("failed to load user roles: %w", err)
+		}
+		for _, name := range roleNames {
+			if l, ok := r.policy.RoleLimits[strings.ToLower(name)]; ok && l > limit {
+				limit = l
+			}
+		}
+	}
+
+	if len(r.policy.PlanLimits) > 0 {
+		var plans []struct {
+			ID   string
+			Name string
+		}
+		if err := db.Table("customer_subscriptions cs").
+			Select("s.id, s.name").
+			Joins("JOIN subscriptions s ON s.id = cs.subscription_id").
+			Where("cs.customer_id = ? AND cs.is_active = ? AND cs.expired_at > ?", userID, true, time.Now()).
+			Scan(&plans).Error; err != nil {
+			return 0, fmt.Errorf("failed to load active subscriptions: %w", err)
+		}
+		for _, plan := range plans {
+			if l, ok := r.policy.PlanLimits[plan.ID]; ok && l > limit {
+				limit = l
+			}
+			if l, ok := r.policy.PlanLimits[strings.ToLower(plan.Name)]; ok && l > limit {
+				limit = l
+			}
+		}
+	}
+
+	return limit, nil
+}
@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
+)
+
+func TestMemoryRateLimitStorage(t *testing.T) {
+	storage := NewMemoryRateLimitStorage(0)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	storage.now = func() time.Time { return now }
+
+	for i := 1; i <= 3; i++ {
+		count, _, err := storage.Increment(context.Background(), "user:a", time.Minute)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != i {
+			t.Errorf("Expected count %d, got %d", i, count)
+		}
+	}
+
+	// Key khác có bộ đếm riêng
+	if count, _, _ := storage.Increment(context.Background(), "user:b", time.Minute); count != 1 {
+		t.Errorf("Expected separate counter for user:b, got %d", count)
+	}
+
+	// Hết cửa sổ thì bộ đếm reset
+	now = now.Add(time.Minute)
+	if count, _, _ := storage.Increment(context.Background(), "user:a", time.Minute); count != 1 {
+		t.Errorf("Expected counter to reset after window, got %d", count)
+	}
+}
+
+func TestMemoryRateLimitStorageClose(t *testing.T) {
+	before := runtime.NumGoroutine()
+	storage := NewMemoryRateLimitStorage(time.Millisecond)
+	if err := storage.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := storage.Close(); err != nil {
+		t.Errorf("Expected second Close to be a no-op, got %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("Expected GC goroutine to exit after Close, goroutines %d > %d", n, before)
+	}
+}
+
+func TestDBRateLimitResolverCache(t *testing.T) {
+	now := time.Now()
+
+	t.Run("TestBounded", func(t *testing.T) {
+		r := NewDBRateLimitResolver(nil, RateLimitPolicy{CacheTTL: time.Minute, CacheSize: 2})
+		r.remember("u0", 1, now)
+		r.remember("u1", 1, now.Add(time.Second))
+		r.remember("u2", 1, now.Add(2*time.Second))
+		if len(r.cache) != 2 {
+			t.Fatalf("Expected 2 cached limits, got %d", len(r.cache))
+		}
+		if _, ok := r.cache["u0"]; ok {
+			t.Errorf("Expected oldest limit to be evicted")
+		}
+	})
+
+	t.Run("TestEvictsExpiredFirst", func(t *testing.T) {
+		r := NewDBRateLimitResolver(nil, RateLimitPolicy{CacheTTL: time.Minute, CacheSize: 3})
+		r.remember("u0", 1, now)
+		r.remember("u1", 1, now)
+		r.remember("u2", 1, now.Add(90*time.Second))
+		r.remember("u3", 1, now.Add(2*time.Minute))
+		if len(r.cache) != 2 {
+			t.Errorf("Expected expired limits to be dropped, got %d cached", len(r.cache))
+		}
+		if _, ok := r.cache["u2"]; !ok {
+			t.Errorf("Expected unexpired limit to be kept")
+		}
+	})
+
+	t.Run("TestDefaultSize", func(t *testing.T) {
+		r := NewDBRateLimitResolver(nil, RateLimitPolicy{})
+		if r.policy.CacheSize <= 0 {
+			t.Errorf("Expected default cache size, got %d", r.policy.CacheSize)
+		}
+	})
+}
+
+func TestUserRateLimiter(t *testing.T) {
+	limits := map[string]int{"premium": 3, "free": 1}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if uid := c.Get("X-Test-User"); uid != "" {
+			c.Locals("user_id", uid)
+		}
+		return c.Next()
+	})
+	app.Use(UserRateLimiter(UserRateLimiterConfig{
+		Storage:      NewMemoryRateLimitStorage(0),
+		DefaultLimit: 2,
+		Window:       time.Minute,
+		Resolver: RateLimitResolverFunc(func(_ context.Context, s RateLimitSubject) (int, error) {
+			return limits[s.ID], nil
+		}),
+	}))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	do := func(user string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	t.Run("TestPlanLimit", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			if code := do("premium"); code != fiber.StatusOK {
+				t.Fatalf("request %d: expected 200, got %d", i+1, code)
+			}
+		}
+		if code := do("premium"); code != fiber.StatusTooManyRequests {
+			t.Errorf("Expected 429 after limit, got %d", code)
+		}
+	})
+
+	t.Run("TestSeparateUsers", func(t *testing.T) {
+		if code := do("free"); code != fiber.StatusOK {
+			t.Errorf("Expected 200, got %d", code)
+		}
+		if code := do("free"); code != fiber.StatusTooManyRequests {
+			t.Errorf("Expected 429 for free user, got %d", code)
+		}
+	})
+
+	t.Run("TestIPFallbackUsesDefault", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if code := do(""); code != fiber.StatusOK {
+				t.Fatalf("request %d: expected 200, got %d", i+1, code)
+			}
+		}
+		if code := do(""); code != fiber.StatusTooManyRequests {
+			t.Errorf("Expected 429 for anonymous IP, got %d", code)
+		}
+	})
+}
+
+func TestRateLimitAPIKeySubject(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		// Giả lập authenticator: chỉ key "valid" được xác thực thành principal API key
+		if c.Get("X-API-Key") == "valid" {
+			c.Locals(pmodel.PrincipalLocalsKey, &pmodel.Principal{ID: "key_1", TokenType: pmodel.TokenTypeAPIKey})
+		}
+		return c.Next()
+	})
+	app.Use(UserRateLimiter(UserRateLimiterConfig{
+		Storage:      NewMemoryRateLimitStorage(0),
+		DefaultLimit: 2,
+		Window:       time.Minute,
+	}))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	do := func(apiKey string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	t.Run("TestRotatedBogusKeyIsIPLimited", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if code := do(fmt.Sprintf("bogus-%d", i)); code != fiber.StatusOK {
+				t.Fatalf("request %d: expected 200, got %d", i+1, code)
+			}
+		}
+		if code := do("bogus-2"); code != fiber.StatusTooManyRequests {
+			t.Errorf("Expected 429 for rotated unverified keys from the same IP, got %d", code)
+		}
+	})
+
+	t.Run("TestVerifiedKeyHasOwnBucket", func(t *testing.T) {
+		if code := do("valid"); code != fiber.StatusOK {
+			t.Errorf("Expected verified API key to use its own bucket, got %d", code)
+		}
+	})
+}
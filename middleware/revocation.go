@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+)
+
+var revocationStore *TokenRevocationStore
+
+// SetRevocationStore bật kiểm tra thu hồi token trong AuthMiddleware
+func SetRevocationStore(store *TokenRevocationStore) {
+	revocationStore = store
+}
+
+// GetRevocationStore trả về store đang được AuthMiddleware sử dụng (nil nếu chưa bật)
+func GetRevocationStore() *TokenRevocationStore {
+	return revocationStore
+}
+
+// TokenRevocationStore là denylist access token: cache trong bộ nhớ, bền vững trong bảng token_revocations.
+// Các instance khác nhận thay đổi qua Sync định kỳ (xem Start).
+type TokenRevocationStore struct {
+	repo     *repositories.TokenRevocationRepository
+	tokenTTL time.Duration // thời gian sống tối đa của access token
+
+	mu       sync.RWMutex
+	tokens   map[string]time.Time // jti -> hết hạn
+	sessions map[string]time.Time // session ID -> hết hạn
+	users    map[string]time.Time // user ID -> thu hồi token phát hành trước mốc này
+	global   time.Time            // thu hồi mọi token phát hành trước mốc này
+	lastSync time.Time
+}
+
+// NewTokenRevocationStore tạo store; tokenTTL nên bằng JWT expiry để bản ghi được dọn đúng lúc
+func NewTokenRevocationStore(repo *repositories.TokenRevocationRepository, tokenTTL time.Duration) *TokenRevocationStore {
+	return &TokenRevocationStore{
+		repo:     repo,
+		tokenTTL: tokenTTL,
+		tokens:   make(map[string]time.Time),
+		sessions: make(map[string]time.Time),
+		users:    make(map[string]time.Time),
+	}
+}
+
+// Load nạp toàn bộ bản ghi còn hiệu lực từ database vào cache
+func (s *TokenRevocationStore) Load(ctx context.Context) error {
+	return s.syncSince(ctx, time.Time{})
+}
+
+// Sync nạp các bản ghi mới/cập nhật kể từ lần đồng bộ trước
+func (s *TokenRevocationStore) Sync(ctx context.Context) error {
+	s.mu.RLock()
+	since := s.lastSync
+	s.mu.RUnlock()
+	return s.syncSince(ctx, since)
+}
+
+func (s *TokenRevocationStore) syncSince(ctx context.Context, since time.Time) error {
+	startedAt := time.Now()
+	revocations, err := s.repo.ListActive(ctx, since)
+	if err != nil {
+		return fmt.Errorf("failed to load token revocations: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range revocations {
+		s.apply(&revocations[i])
+	}
+	// Lùi lại một chút để không bỏ sót bản ghi ghi cùng lúc với query
+	s.lastSync = startedAt.Add(-time.Second)
+	return nil
+}
+
+// Start chạy đồng bộ và dọn dẹp định kỳ cho tới khi ctx bị hủy
+func (s *TokenRevocationStore) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Sync(ctx); err != nil {
+					log.Printf("token revocation: sync failed: %v", err)
+				}
+				if err := s.Cleanup(ctx); err != nil {
+					log.Printf("token revocation: cleanup failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Cleanup xóa bản ghi đã hết hạn khỏi cache và database
+func (s *TokenRevocationStore) Cleanup(ctx context.Context) error {
+	s.evictExpired(time.Now())
+	_, err := s.repo.DeleteExpired(ctx)
+	return err
+}
+
+// evictExpired bỏ khỏi cache các bản ghi đã hết hạn tại thời điểm now
+func (s *TokenRevocationStore) evictExpired(now time.Time) {
+	cutoff := now.Add(-s.tokenTTL)
+
+	s.mu.Lock()
+	for jti, exp := range s.tokens {
+		if !now.Before(exp) {
+			delete(s.tokens, jti)
+		}
+	}
+	for sid, exp := range s.sessions {
+		if !now.Before(exp) {
+			delete(s.sessions, sid)
+		}
+	}
+	// Token phát hành trước (now - tokenTTL) đã hết hạn nên mốc thu hồi cũ hơn không còn tác dụng
+	for userID, before := range s.users {
+		if before.Before(cutoff) {
+			delete(s.users, userID)
+		}
+	}
+	s.mu.Unlock()
+}
+
+// IsRevoked kiểm tra access token đã bị thu hồi chưa
+func (s *TokenRevocationStore) IsRevoked(claims *ClaimsStringID) bool {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if claims.ID != "" {
+		if _, ok := s.tokens[claims.ID]; ok {
+			return true
+		}
+	}
+	if claims.SessionID != "" {
+		if _, ok := s.sessions[claims.SessionID]; ok {
+			return true
+		}
+	}
+	if before, ok := s.users[claims.UserID]; ok && issuedAt.Before(before) {
+		return true
+	}
+	if !s.global.IsZero() && issuedAt.Before(s.global) {
+		return true
+	}
+	return false
+}
+
+// RevokeToken thu hồi một access token theo jti cho tới khi nó hết hạn
+func (s *TokenRevocationStore) RevokeToken(ctx context.Context, jti, userID string, expiresAt time.Time, reason string) error {
+	if jti == "" {
+		return fmt.Errorf("jti is required")
+	}
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(s.tokenTTL)
+	}
+	return s.save(ctx, &models.TokenRevocation{
+		Kind:      models.RevocationKindToken,
+		Subject:   jti,
+		UserID:    userID,
+		Reason:    reason,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// RevokeSession thu hồi mọi access token mang session ID này
+func (s *TokenRevocationStore) RevokeSession(ctx context.Context, sessionID, userID, reason string) error {
+	if sessionID == "" {
+		return fmt.Errorf("session ID is required")
+	}
+	return s.save(ctx, &models.TokenRevocation{
+		Kind:      models.RevocationKindSession,
+		Subject:   sessionID,
+		UserID:    userID,
+		Reason:    reason,
+		ExpiresAt: time.Now().Add(s.tokenTTL),
+	})
+}
+
+// RevokeUser thu hồi mọi access token hiện có của user (đăng xuất mọi thiết bị, đổi mật khẩu, khóa tài khoản)
+func (s *TokenRevocationStore) RevokeUser(ctx context.Context, userID, reason string) error {
+	return s.RevokeIssuedBefore(ctx, userID, time.Now(), reason)
+}
+
+// RevokeIssuedBefore thu hồi token phát hành trước mốc before; userID rỗng nghĩa là áp dụng cho mọi user.
+// iat trong JWT chỉ chính xác tới giây nên mốc được làm tròn xuống giây,
+// nhờ đó token cấp lại ngay sau khi thu hồi (cùng giây) vẫn hợp lệ.
+func (s *TokenRevocationStore) RevokeIssuedBefore(ctx context.Context, userID string, before time.Time, reason string) error {
+	if before.IsZero() {
+		return fmt.Errorf("revocation time is required")
+	}
+	revocation := &models.TokenRevocation{
+		Kind:          models.RevocationKindGlobal,
+		Subject:       "*",
+		RevokedBefore: before.Truncate(time.Second),
+		Reason:        reason,
+		ExpiresAt:     before.Add(s.tokenTTL),
+	}
+	if userID != "" {
+		revocation.Kind = models.RevocationKindUser
+		revocation.Subject = userID
+		revocation.UserID = userID
+	}
+	return s.save(ctx, revocation)
+}
+
+func (s *TokenRevocationStore) save(ctx context.Context, revocation *models.TokenRevocation) error {
+	if err := s.repo.Upsert(ctx, revocation); err != nil {
+		return fmt.Errorf("failed to save token revocation: %w", err)
+	}
+	s.mu.Lock()
+	s.apply(revocation)
+	s.mu.Unlock()
+	return nil
+}
+
+// apply cập nhật cache; caller phải giữ s.mu
+func (s *TokenRevocationStore) apply(revocation *models.TokenRevocation) {
+	switch revocation.Kind {
+	case models.RevocationKindToken:
+		s.tokens[revocation.Subject] = revocation.ExpiresAt
+	case models.RevocationKindSession:
+		s.sessions[revocation.Subject] = revocation.ExpiresAt
+	case models.RevocationKindUser:
+		s.users[revocation.Subject] = revocation.RevokedBefore
+	case models.RevocationKindGlobal:
+		if revocation.RevokedBefore.After(s.global) {
+			s.global = revocation.RevokedBefore
+		}
+	}
+}
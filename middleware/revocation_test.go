@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/techmaster-vietnam/dd_goshare/config"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+)
+
+// useTestKeys đặt key set và revocation store cho test, khôi phục giá trị cũ khi test xong
+func useTestKeys(t *testing.T, store *TokenRevocationStore) *config.Config {
+	t.Helper()
+	cfg := &config.Config{JWT: &config.JWTConfig{Algorithm: "HS256", Secret: "test-secret", Expiry: 1}}
+	prevKeys, prevStore := GetKeySet(), GetRevocationStore()
+	if err := InitJWTKeys(cfg.JWT); err != nil {
+		t.Fatal(err)
+	}
+	SetRevocationStore(store)
+	t.Cleanup(func() {
+		keySetMu.Lock()
+		keySet = prevKeys
+		keySetMu.Unlock()
+		SetRevocationStore(prevStore)
+	})
+	return cfg
+}
+
+// newStore tạo store chỉ có cache, không cần database
+func newStore(revocations ...models.TokenRevocation) *TokenRevocationStore {
+	s := NewTokenRevocationStore(nil, time.Hour)
+	for i := range revocations {
+		s.apply(&revocations[i])
+	}
+	return s
+}
+
+func tokenClaims(t *testing.T, token string) *ClaimsStringID {
+	t.Helper()
+	claims := &ClaimsStringID{}
+	if _, err := jwt.ParseWithClaims(token, claims, GetKeySet().Keyfunc); err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+func authStatus(t *testing.T, cfg *config.Config, token string) int {
+	t.Helper()
+	app := fiber.New()
+	app.Get("/private", AuthMiddleware(cfg), func(c *fiber.Ctx) error {
+		return c.SendString(GetUserIDFromContext(c))
+	})
+	req := httptest.NewRequest("GET", "/private", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestTokenRevocation(t *testing.T) {
+	t.Run("TestRevokedJTIRejected", func(t *testing.T) {
+		store := newStore()
+		cfg := useTestKeys(t, store)
+
+		revoked, err := GenerateToken("u1", "u1@example.com", cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := GenerateToken("u1", "u1@example.com", cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims := tokenClaims(t, revoked)
+		store.apply(&models.TokenRevocation{
+			Kind:      models.RevocationKindToken,
+			Subject:   claims.ID,
+			ExpiresAt: claims.ExpiresAt.Time,
+		})
+
+		if status := authStatus(t, cfg, revoked); status != fiber.StatusUnauthorized {
+			t.Errorf("Expected revoked token to be rejected with 401, got %d", status)
+		}
+		if status := authStatus(t, cfg, other); status != fiber.StatusOK {
+			t.Errorf("Expected other token of the same user to pass, got %d", status)
+		}
+	})
+
+	t.Run("TestRevokedSessionAndUser", func(t *testing.T) {
+		issuedAt := jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		store := newStore(
+			models.TokenRevocation{Kind: models.RevocationKindSession, Subject: "s1", ExpiresAt: time.Now().Add(time.Hour)},
+			models.TokenRevocation{Kind: models.RevocationKindUser, Subject: "u2", RevokedBefore: time.Now()},
+		)
+		cases := []struct {
+			name   string
+			claims *ClaimsStringID
+			want   bool
+		}{
+			{"Session", &ClaimsStringID{UserID: "u1", SessionID: "s1"}, true},
+			{"OtherSession", &ClaimsStringID{UserID: "u1", SessionID: "s2"}, false},
+			{"UserIssuedBefore", &ClaimsStringID{UserID: "u2", RegisteredClaims: jwt.RegisteredClaims{IssuedAt: issuedAt}}, true},
+			{"UserIssuedAfter", &ClaimsStringID{UserID: "u2", RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}, false},
+		}
+		for _, tc := range cases {
+			if got := store.IsRevoked(tc.claims); got != tc.want {
+				t.Errorf("%s: expected revoked=%v, got %v", tc.name, tc.want, got)
+			}
+		}
+	})
+
+	t.Run("TestExpiredEntriesEvicted", func(t *testing.T) {
+		now := time.Now()
+		store := newStore(
+			models.TokenRevocation{Kind: models.RevocationKindToken, Subject: "old-jti", ExpiresAt: now.Add(-time.Second)},
+			models.TokenRevocation{Kind: models.RevocationKindToken, Subject: "new-jti", ExpiresAt: now.Add(time.Minute)},
+			models.TokenRevocation{Kind: models.RevocationKindSession, Subject: "old-sid", ExpiresAt: now.Add(-time.Second)},
+			models.TokenRevocation{Kind: models.RevocationKindUser, Subject: "old-user", RevokedBefore: now.Add(-2 * time.Hour)},
+			models.TokenRevocation{Kind: models.RevocationKindUser, Subject: "new-user", RevokedBefore: now.Add(-time.Minute)},
+		)
+		store.evictExpired(now)
+
+		if _, ok := store.tokens["old-jti"]; ok {
+			t.Errorf("Expected expired jti to be evicted")
+		}
+		if _, ok := store.tokens["new-jti"]; !ok {
+			t.Errorf("Expected unexpired jti to be kept")
+		}
+		if _, ok := store.sessions["old-sid"]; ok {
+			t.Errorf("Expected expired session to be evicted")
+		}
+		if _, ok := store.users["old-user"]; ok {
+			t.Errorf("Expected user revocation older than token TTL to be evicted")
+		}
+		if _, ok := store.users["new-user"]; !ok {
+			t.Errorf("Expected recent user revocation to be kept")
+		}
+		if store.IsRevoked(&ClaimsStringID{RegisteredClaims: jwt.RegisteredClaims{ID: "old-jti"}}) {
+			t.Errorf("Expected evicted jti not to be revoked")
+		}
+	})
+
+	t.Run("TestMissingStore", func(t *testing.T) {
+		cfg := useTestKeys(t, nil)
+		token, err := GenerateToken("u1", "u1@example.com", cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := authStatus(t, cfg, token); status != fiber.StatusOK {
+			t.Errorf("Expected token to pass without a revocation store, got %d", status)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/services"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// Create tạo API key mới; key gốc chỉ được trả về trong response này
+// @Summary Create API key
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body models.CreateAPIKeyRequest true "API key"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/admin/api-keys [post]
+func (h *APIKeyHandler) Create(c *fiber.Ctx) error {
+	ownerID := middleware.GetUserIDFromContext(c)
+	if ownerID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	result, err := h.apiKeyService.Create(c.UserContext(), ownerID, req)
+	if err != nil {
+		return apiKeyErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(SuccessResponse{
+		Code:    fiber.StatusCreated,
+		Message: "API key created. Copy the key now, it will not be shown again",
+		Data:    result,
+	})
+}
+
+// List liệt kê API key; lọc theo owner_id, include_revoked=true để xem cả key đã thu hồi
+// @Summary List API keys
+// @Tags admin
+// @Produce json
+// @Param owner_id query string false "Owner ID"
+// @Param include_revoked query bool false "Include revoked keys"
+// @Success 200 {object} SuccessResponse
+// @Router /api/admin/api-keys [get]
+func (h *APIKeyHandler) List(c *fiber.Ctx) error {
+	keys, err := h.apiKeyService.List(c.UserContext(), c.Query("owner_id"), c.QueryBool("include_revoked", false))
+	if err != nil {
+		return apiKeyErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "API keys retrieved successfully",
+		Data:    keys,
+	})
+}
+
+// Revoke thu hồi API key
+// @Summary Revoke API key
+// @Tags admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *fiber.Ctx) error {
+	if err := h.apiKeyService.Revoke(c.UserContext(), c.Params("id")); err != nil {
+		return apiKeyErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "API key revoked successfully",
+	})
+}
+
+// AuditLogs trả về lịch sử gọi API của key
+// @Summary List API key audit logs
+// @Tags admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Param page query int false "Page"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/admin/api-keys/{id}/audit-logs [get]
+func (h *APIKeyHandler) AuditLogs(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", 50)
+	logs, total, err := h.apiKeyService.ListAuditLogs(c.UserContext(), c.Params("id"), page, pageSize)
+	if err != nil {
+		return apiKeyErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Audit logs retrieved successfully",
+		Data:    fiber.Map{"items": logs, "total": total, "page": page},
+	})
+}
+
+func apiKeyErrorJSON(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrAPIKeyNameRequired),
+		errors.Is(err, services.ErrAPIKeyUnknownRole),
+		errors.Is(err, services.ErrAPIKeyRoleRequired),
+		errors.Is(err, services.ErrInvalidAPIKeyExpiry):
+		return errorJSON(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrAPIKeyOwnerRequired):
+		return errorJSON(c, fiber.StatusUnauthorized, err.Error())
+	case errors.Is(err, services.ErrAPIKeyRoleNotHeld):
+		return errorJSON(c, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		return errorJSON(c, fiber.StatusNotFound, err.Error())
+	default:
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to process API key: "+err.Error())
+	}
+}
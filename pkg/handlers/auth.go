@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/services"
+)
+
+type AuthHandler struct {
+	tokenService *services.TokenService
+}
+
+func NewAuthHandler(tokenService *services.TokenService) *AuthHandler {
+	return &AuthHandler{tokenService: tokenService}
+}
+
+// RefreshToken đổi refresh token lấy cặp token mới
+// @Summary Refresh access token
+// @Description Rotate a refresh token and issue a new access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} RefreshTokenResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/auth/refresh [post]
+func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
+	var req models.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	device := DeviceInfoFromRequest(c)
+	if req.DeviceID != "" {
+		device.DeviceID = req.DeviceID
+	}
+	if req.DeviceName != "" {
+		device.DeviceName = req.DeviceName
+	}
+	if req.Platform != "" {
+		device.Platform = req.Platform
+	}
+	if req.AppVersion != "" {
+		device.AppVersion = req.AppVersion
+	}
+
+	pair, err := h.tokenService.Refresh(c.UserContext(), req.RefreshToken, device)
+	if err != nil {
+		return refreshErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(RefreshTokenResponse{
+		StatusCode:            fiber.StatusOK,
+		Message:               "Token refreshed successfully",
+		AccessToken:           pair.AccessToken,
+		RefreshToken:          pair.RefreshToken,
+		RefreshTokenExpiresAt: pair.RefreshTokenExpiresAt,
+	})
+}
+
+// Logout thu hồi refresh token family hiện tại
+// @Summary Logout
+// @Description Revoke the refresh token family of the current device
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} LogoutResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/auth/logout [post]
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req models.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.tokenService.Logout(c.UserContext(), req.RefreshToken); err != nil {
+		return refreshErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(LogoutResponse{
+		StatusCode: fiber.StatusOK,
+		Message:    "Logged out successfully",
+	})
+}
+
+// DeviceInfoFromRequest đọc thông tin thiết bị từ header X-Device-ID, X-Device-Name, X-Platform,
+// X-App-Version cùng IP và User-Agent; dùng khi đăng nhập để truyền vào TokenService.IssueTokens
+func DeviceInfoFromRequest(c *fiber.Ctx) models.DeviceInfo {
+	return models.DeviceInfo{
+		DeviceID:   c.Get("X-Device-ID"),
+		DeviceName: c.Get("X-Device-Name"),
+		Platform:   c.Get("X-Platform"),
+		AppVersion: c.Get("X-App-Version"),
+		IPAddress:  c.IP(),
+		UserAgent:  truncate(c.Get(fiber.HeaderUserAgent), 255),
+	}
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
+
+func refreshErrorJSON(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrRefreshTokenRequired):
+		return errorJSON(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrInvalidRefreshToken),
+		errors.Is(err, services.ErrRefreshTokenExpired),
+		errors.Is(err, services.ErrRefreshTokenReused),
+		errors.Is(err, services.ErrRefreshTokenDeviceMismatch),
+		errors.Is(err, services.ErrUserNotFound):
+		return errorJSON(c, fiber.StatusUnauthorized, err.Error())
+	default:
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to process refresh token: "+err.Error())
+	}
+}
+
+func errorJSON(c *fiber.Ctx, code int, message string) error {
+	return c.Status(code).JSON(ErrorResponse{
+		Errors: ErrorItem{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
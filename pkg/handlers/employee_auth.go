@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/services"
+)
+
+type EmployeeAuthHandler struct {
+	authService *services.EmployeeAuthService
+}
+
+func NewEmployeeAuthHandler(authService *services.EmployeeAuthService) *EmployeeAuthHandler {
+	return &EmployeeAuthHandler{authService: authService}
+}
+
+// Login đăng nhập nhân viên bằng email/mật khẩu
+// @Summary Employee login
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.EmployeeLoginRequest true "Credentials"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Router /api/admin/auth/login [post]
+func (h *EmployeeAuthHandler) Login(c *fiber.Ctx) error {
+	var req models.EmployeeLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	result, err := h.authService.Login(c.UserContext(), req.Email, req.Password)
+	if err != nil {
+		return employeeAuthErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Login successful",
+		Data:    result,
+	})
+}
+
+// ChangePassword đổi mật khẩu của nhân viên đang đăng nhập
+// @Summary Change employee password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.ChangePasswordRequest true "Passwords"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/admin/auth/change-password [post]
+func (h *EmployeeAuthHandler) ChangePassword(c *fiber.Ctx) error {
+	employeeID := middleware.GetUserIDFromContext(c)
+	if employeeID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.authService.ChangePassword(c.UserContext(), employeeID, req.CurrentPassword, req.NewPassword); err != nil {
+		return employeeAuthErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Password changed successfully",
+	})
+}
+
+// RequestPasswordReset gửi link đặt lại mật khẩu; luôn trả về thành công để không lộ email
+// @Summary Request password reset
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.PasswordResetRequest true "Email"
+// @Success 200 {object} SuccessResponse
+// @Router /api/admin/auth/forgot-password [post]
+func (h *EmployeeAuthHandler) RequestPasswordReset(c *fiber.Ctx) error {
+	var req models.PasswordResetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.authService.RequestPasswordReset(c.UserContext(), req.Email); err != nil {
+		return employeeAuthErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "If the email exists, a password reset link has been sent",
+	})
+}
+
+// ResetPassword đặt mật khẩu mới bằng reset token
+// @Summary Reset employee password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.ResetPasswordRequest true "Token and new password"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/admin/auth/reset-password [post]
+func (h *EmployeeAuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.authService.ResetPassword(c.UserContext(), req.Token, req.NewPassword); err != nil {
+		return employeeAuthErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Password has been reset",
+	})
+}
+
+func employeeAuthErrorJSON(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrEmailRequired),
+		errors.Is(err, services.ErrPasswordRequired),
+		errors.Is(err, services.ErrPasswordTooWeak),
+		errors.Is(err, services.ErrInvalidResetToken),
+		errors.Is(err, services.ErrInvalidPassword):
+		return errorJSON(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrInvalidCredentials),
+		errors.Is(err, services.ErrUserNotFound):
+		return errorJSON(c, fiber.StatusUnauthorized, services.ErrInvalidCredentials.Error())
+	case errors.Is(err, services.ErrAccountLocked):
+		return errorJSON(c, fiber.StatusLocked, err.Error())
+	default:
+		return errorJSON(c, fiber.StatusInternalServerError, "Authentication failed: "+err.Error())
+	}
+}
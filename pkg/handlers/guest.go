@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/services"
+)
+
+type GuestHandler struct {
+	guestService *services.GuestService
+}
+
+func NewGuestHandler(guestService *services.GuestService) *GuestHandler {
+	return &GuestHandler{guestService: guestService}
+}
+
+// CreateGuest tạo tài khoản khách cho thiết bị (header X-Device-ID bắt buộc)
+// @Summary Create guest account
+// @Tags auth
+// @Produce json
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/auth/guest [post]
+func (h *GuestHandler) CreateGuest(c *fiber.Ctx) error {
+	session, err := h.guestService.CreateGuest(c.UserContext(), DeviceInfoFromRequest(c))
+	if err != nil {
+		return guestErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(SuccessResponse{
+		Code:    fiber.StatusCreated,
+		Message: "Guest account created successfully",
+		Data:    session,
+	})
+}
+
+// Upgrade gộp tiến độ của tài khoản khách vào tài khoản đang đăng nhập
+// @Summary Merge a guest account into the signed-in customer
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.GuestUpgradeRequest true "Guest refresh token"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/auth/guest/upgrade [post]
+func (h *GuestHandler) Upgrade(c *fiber.Ctx) error {
+	customerID := middleware.GetUserIDFromContext(c)
+	if customerID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.GuestUpgradeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if req.DeviceID == "" {
+		req.DeviceID = c.Get("X-Device-ID")
+	}
+
+	if err := h.guestService.Upgrade(c.UserContext(), customerID, req.GuestRefreshToken, req.DeviceID); err != nil {
+		return guestErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Guest progress merged successfully",
+	})
+}
+
+func guestErrorJSON(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrDeviceIDRequired),
+		errors.Is(err, services.ErrRefreshTokenRequired),
+		errors.Is(err, services.ErrGuestUpgradeSelf):
+		return errorJSON(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrInvalidGuestToken),
+		errors.Is(err, services.ErrRefreshTokenReused),
+		errors.Is(err, services.ErrRefreshTokenDeviceMismatch):
+		return errorJSON(c, fiber.StatusUnauthorized, err.Error())
+	case errors.Is(err, services.ErrUserNotFound):
+		return errorJSON(c, fiber.StatusNotFound, err.Error())
+	default:
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to process guest account: "+err.Error())
+	}
+}
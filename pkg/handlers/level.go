@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	service "github.com/techmaster-vietnam/dd_goshare/pkg/services"
+)
+
+// LevelHandler cung cấp CRUD level và tiến độ học theo level
+type LevelHandler struct {
+	levelService *service.LevelService
+}
+
+func NewLevelHandler(levelService *service.LevelService) *LevelHandler {
+	return &LevelHandler{levelService: levelService}
+}
+
+// ListLevels liệt kê level từ dễ tới khó
+// @Summary List levels
+// @Tags levels
+// @Produce json
+// @Success 200 {object} SuccessResponse
+// @Router /api/levels [get]
+func (h *LevelHandler) ListLevels(c *fiber.Ctx) error {
+	levels, err := h.levelService.GetAllLevels(c.UserContext())
+	if err != nil {
+		return levelErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: levels,
+	})
+}
+
+// GetLevel trả về một level
+// @Summary Get level
+// @Tags levels
+// @Produce json
+// @Param id path string true "Level ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/levels/{id} [get]
+func (h *LevelHandler) GetLevel(c *fiber.Ctx) error {
+	level, err := h.levelService.GetLevel(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return levelErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: level,
+	})
+}
+
+// CreateLevel tạo level tự định nghĩa
+// @Summary Create level
+// @Tags levels
+// @Accept json
+// @Produce json
+// @Param body body models.CreateLevelRequest true "Level"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/levels [post]
+func (h *LevelHandler) CreateLevel(c *fiber.Ctx) error {
+	var req models.CreateLevelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	level, err := h.levelService.CreateLevel(c.UserContext(), req)
+	if err != nil {
+		return levelErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(SuccessResponse{
+		Code:    fiber.StatusCreated,
+		Message: "Level created",
+		Data:    level,
+	})
+}
+
+// UpdateLevel cập nhật level; mã của level CEFR không đổi được
+// @Summary Update level
+// @Tags levels
+// @Accept json
+// @Produce json
+// @Param id path string true "Level ID"
+// @Param body body models.UpdateLevelRequest true "Fields to update"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/levels/{id} [put]
+func (h *LevelHandler) UpdateLevel(c *fiber.Ctx) error {
+	var req models.UpdateLevelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	level, err := h.levelService.UpdateLevel(c.UserContext(), c.Params("id"), req)
+	if err != nil {
+		return levelErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Level updated",
+		Data:    level,
+	})
+}
+
+// DeleteLevel xoá level tự định nghĩa, topic và dialog đang dùng nó được bỏ gán level
+// @Summary Delete level
+// @Tags levels
+// @Produce json
+// @Param id path string true "Level ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/levels/{id} [delete]
+func (h *LevelHandler) DeleteLevel(c *fiber.Ctx) error {
+	if err := h.levelService.DeleteLevel(c.UserContext(), c.Params("id")); err != nil {
+		return levelErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Level deleted",
+	})
+}
+
+// GetMyLevelProgress trả về tiến độ học của người dùng hiện tại theo từng level
+// @Summary Learner progress by level
+// @Tags levels
+// @Produce json
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/levels/progress [get]
+func (h *LevelHandler) GetMyLevelProgress(c *fiber.Ctx) error {
+	customerID := middleware.GetUserIDFromContext(c)
+	if customerID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+	progress, err := h.levelService.GetLevelProgress(c.UserContext(), customerID)
+	if err != nil {
+		return levelErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: progress,
+	})
+}
+
+func levelErrorJSON(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrLevelNotFound):
+		return errorJSON(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrLevelExists), errors.Is(err, service.ErrLevelBuiltIn):
+		return errorJSON(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrLevelIDRequired), errors.Is(err, service.ErrLevelCodeRequired),
+		errors.Is(err, service.ErrLevelNameRequired):
+		return errorJSON(c, fiber.StatusBadRequest, err.Error())
+	}
+	return errorJSON(c, fiber.StatusInternalServerError, err.Error())
+}
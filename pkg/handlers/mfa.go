@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/services"
+)
+
+type MFAHandler struct {
+	mfaService *services.MFAService
+}
+
+func NewMFAHandler(mfaService *services.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+// Status trả về trạng thái 2FA của user đang đăng nhập
+// @Summary Get 2FA status
+// @Tags auth
+// @Produce json
+// @Success 200 {object} SuccessResponse
+// @Router /api/auth/mfa [get]
+func (h *MFAHandler) Status(c *fiber.Ctx) error {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	status, err := h.mfaService.Status(c.UserContext(), userID)
+	if err != nil {
+		return mfaErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "MFA status retrieved successfully",
+		Data:    status,
+	})
+}
+
+// Enroll bắt đầu đăng ký TOTP, trả về secret và otpauth URI
+// @Summary Start TOTP enrollment
+// @Tags auth
+// @Produce json
+// @Success 200 {object} SuccessResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/auth/mfa/enroll [post]
+func (h *MFAHandler) Enroll(c *fiber.Ctx) error {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	enrollment, err := h.mfaService.BeginEnrollment(c.UserContext(), userID, middleware.GetUserEmailFromContext(c))
+	if err != nil {
+		return mfaErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Scan the QR code and confirm with a code from your authenticator app",
+		Data:    enrollment,
+	})
+}
+
+// Confirm kích hoạt 2FA bằng mã TOTP đầu tiên; trả về recovery code
+// @Summary Confirm TOTP enrollment
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.MFACodeRequest true "TOTP code"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/auth/mfa/confirm [post]
+func (h *MFAHandler) Confirm(c *fiber.Ctx) error {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	codes, err := h.mfaService.ConfirmEnrollment(c.UserContext(), userID, req.Code)
+	if err != nil {
+		return mfaErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Two-factor authentication enabled. Store the recovery codes in a safe place",
+		Data:    fiber.Map{"recovery_codes": codes},
+	})
+}
+
+// StepUp xác minh mã 2FA và trả về access token có claim mfa cho session hiện tại
+// @Summary Step up to a 2FA-verified session
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/auth/mfa/verify [post]
+func (h *MFAHandler) StepUp(c *fiber.Ctx) error {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	token, err := h.mfaService.StepUp(c.UserContext(), userID, middleware.GetUserEmailFromContext(c),
+		middleware.GetSessionIDFromContext(c), req.Code)
+	if err != nil {
+		return mfaErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Two-factor authentication verified",
+		Data:    fiber.Map{"access_token": token},
+	})
+}
+
+// Disable tắt 2FA sau khi xác minh mã hiện tại
+// @Summary Disable two-factor authentication
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/auth/mfa/disable [post]
+func (h *MFAHandler) Disable(c *fiber.Ctx) error {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.mfaService.Disable(c.UserContext(), userID, req.Code); err != nil {
+		return mfaErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes tạo bộ recovery code mới, vô hiệu bộ cũ
+// @Summary Regenerate recovery codes
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.UserContext(), userID, req.Code)
+	if err != nil {
+		return mfaErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Recovery codes regenerated",
+		Data:    fiber.Map{"recovery_codes": codes},
+	})
+}
+
+func mfaErrorJSON(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrMFACodeRequired),
+		errors.Is(err, services.ErrMFANotEnrolled):
+		return errorJSON(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrInvalidMFACode):
+		return errorJSON(c, fiber.StatusUnauthorized, err.Error())
+	case errors.Is(err, services.ErrMFAAlreadyEnrolled):
+		return errorJSON(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrMFALocked):
+		return errorJSON(c, fiber.StatusLocked, err.Error())
+	default:
+		return errorJSON(c, fiber.StatusInternalServerError, "Two-factor authentication failed: "+err.Error())
+	}
+}
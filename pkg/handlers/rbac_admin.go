@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/rbac"
+)
+
+// RBACAdminHandler cung cấp các endpoint quản trị RBAC (thống kê, reload, kiểm tra rule)
+type RBACAdminHandler struct{}
+
+func NewRBACAdminHandler() *RBACAdminHandler {
+	return &RBACAdminHandler{}
+}
+
+type setRuleMFARequest struct {
+	RequireMFA bool `json:"require_mfa"`
+}
+
+// Stats trả về thống kê role/rule đang nạp trong bộ nhớ và trong database
+// @Summary RBAC statistics
+// @Tags rbac
+// @Produce json
+// @Success 200 {object} SuccessResponse
+// @Router /api/admin/rbac/stats [get]
+func (h *RBACAdminHandler) Stats(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: rbac.GetSystemStats(),
+	})
+}
+
+// Consistency kiểm tra rule chưa gán role và rule_roles mồ côi
+// @Summary Verify rule-role consistency
+// @Tags rbac
+// @Produce json
+// @Success 200 {object} SuccessResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/rbac/consistency [get]
+func (h *RBACAdminHandler) Consistency(c *fiber.Ctx) error {
+	report, err := rbac.VerifyRuleRoleConsistency()
+	if err != nil {
+		return errorJSON(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: report,
+	})
+}
+
+// Reload nạp lại role và rule từ database sau khi sửa trực tiếp trong DB
+// @Summary Reload roles and rules
+// @Tags rbac
+// @Produce json
+// @Success 200 {object} SuccessResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/rbac/reload [post]
+func (h *RBACAdminHandler) Reload(c *fiber.Ctx) error {
+	if err := rbac.ReloadRoles(); err != nil {
+		return errorJSON(c, fiber.StatusInternalServerError, err.Error())
+	}
+	if err := rbac.ReloadRules(); err != nil {
+		return errorJSON(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "RBAC roles and rules reloaded",
+	})
+}
+
+// SetRuleRequireMFA bật/tắt yêu cầu 2FA cho một rule
+// @Summary Toggle MFA requirement on a rule
+// @Tags rbac
+// @Accept json
+// @Produce json
+// @Param id path int true "Rule ID"
+// @Param body body setRuleMFARequest true "require_mfa"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/rbac/rules/{id}/mfa [put]
+func (h *RBACAdminHandler) SetRuleRequireMFA(c *fiber.Ctx) error {
+	ruleID, err := c.ParamsInt("id")
+	if err != nil || ruleID <= 0 {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid rule ID")
+	}
+	var req setRuleMFARequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := rbac.SetRuleRequireMFA(ruleID, req.RequireMFA); err != nil {
+		if errors.Is(err, rbac.ErrRuleNotFound) {
+			return errorJSON(c, fiber.StatusNotFound, "Rule not found")
+		}
+		log.Printf("rbac admin: failed to update rule %d: %v", ruleID, err)
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to update rule")
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Rule updated",
+	})
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSetRuleRequireMFA(t *testing.T) {
+	app := fiber.New()
+	app.Put("/rules/:id/mfa", NewRBACAdminHandler().SetRuleRequireMFA)
+
+	cases := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{"InvalidID", "/rules/abc/mfa", `{"require_mfa":true}`, fiber.StatusBadRequest},
+		{"InvalidBody", "/rules/1/mfa", `{`, fiber.StatusBadRequest},
+		// RBAC chưa có database: lỗi nội bộ trả 500 và không lộ chi tiết
+		{"InternalError", "/rules/1/mfa", `{"require_mfa":false}`, fiber.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tc.want {
+				t.Errorf("Expected status %d, got %d", tc.want, resp.StatusCode)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if strings.Contains(string(body), "database") {
+				t.Errorf("Expected internal error text not to leak, got %s", body)
+			}
+		})
+	}
+}
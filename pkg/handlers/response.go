@@ -1,5 +1,7 @@
 package handlers
 
+import "time"
+
 type ErrorItem struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -19,9 +21,11 @@ type SuccessResponse struct {
 }
 
 type RefreshTokenResponse struct {
-	StatusCode  int    `json:"status_code"`
-	Message     string `json:"message"`
-	AccessToken string `json:"access_token"`
+	StatusCode            int       `json:"status_code"`
+	Message               string    `json:"message"`
+	AccessToken           string    `json:"access_token"`
+	RefreshToken          string    `json:"refresh_token,omitempty"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at,omitempty"`
 }
 
 type LogoutResponse struct {
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/services"
+)
+
+type SessionHandler struct {
+	tokenService *services.TokenService
+}
+
+func NewSessionHandler(tokenService *services.TokenService) *SessionHandler {
+	return &SessionHandler{tokenService: tokenService}
+}
+
+// ListMySessions liệt kê các thiết bị đang đăng nhập của customer hiện tại
+// @Summary List my sessions
+// @Tags auth
+// @Produce json
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/auth/sessions [get]
+func (h *SessionHandler) ListMySessions(c *fiber.Ctx) error {
+	customerID := middleware.GetUserIDFromContext(c)
+	if customerID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	sessions, err := h.tokenService.ListSessions(c.UserContext(), customerID, middleware.GetSessionIDFromContext(c), false)
+	if err != nil {
+		return sessionErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Sessions retrieved successfully",
+		Data:    sessions,
+	})
+}
+
+// RevokeMySession đăng xuất một thiết bị của customer hiện tại
+// @Summary Revoke one of my sessions
+// @Tags auth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/auth/sessions/{id} [delete]
+func (h *SessionHandler) RevokeMySession(c *fiber.Ctx) error {
+	customerID := middleware.GetUserIDFromContext(c)
+	if customerID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	if err := h.tokenService.RevokeSession(c.UserContext(), customerID, c.Params("id")); err != nil {
+		return sessionErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Session revoked successfully",
+	})
+}
+
+// RevokeAllMySessions đăng xuất mọi thiết bị; keep_current=true giữ lại thiết bị đang gọi
+// @Summary Revoke all my sessions
+// @Tags auth
+// @Produce json
+// @Param keep_current query bool false "Keep the current session"
+// @Success 200 {object} SuccessResponse
+// @Router /api/auth/sessions [delete]
+func (h *SessionHandler) RevokeAllMySessions(c *fiber.Ctx) error {
+	customerID := middleware.GetUserIDFromContext(c)
+	if customerID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var err error
+	if c.QueryBool("keep_current", false) {
+		err = h.tokenService.RevokeOtherSessions(c.UserContext(), customerID, middleware.GetSessionIDFromContext(c))
+	} else {
+		err = h.tokenService.LogoutAll(c.UserContext(), customerID)
+	}
+	if err != nil {
+		return sessionErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Sessions revoked successfully",
+	})
+}
+
+// ListCustomerSessions liệt kê session của một customer, kể cả session đã thu hồi (admin)
+// @Summary List sessions of a customer
+// @Tags admin
+// @Produce json
+// @Param customerId path string true "Customer ID"
+// @Success 200 {object} SuccessResponse
+// @Router /api/admin/customers/{customerId}/sessions [get]
+func (h *SessionHandler) ListCustomerSessions(c *fiber.Ctx) error {
+	sessions, err := h.tokenService.ListSessions(c.UserContext(), c.Params("customerId"), "", c.QueryBool("include_revoked", true))
+	if err != nil {
+		return sessionErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Sessions retrieved successfully",
+		Data:    sessions,
+	})
+}
+
+// RevokeCustomerSession thu hồi một session của customer (admin)
+// @Summary Revoke a customer session
+// @Tags admin
+// @Produce json
+// @Param customerId path string true "Customer ID"
+// @Param id path string true "Session ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/admin/customers/{customerId}/sessions/{id} [delete]
+func (h *SessionHandler) RevokeCustomerSession(c *fiber.Ctx) error {
+	if err := h.tokenService.RevokeSession(c.UserContext(), c.Params("customerId"), c.Params("id")); err != nil {
+		return sessionErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Session revoked successfully",
+	})
+}
+
+func sessionErrorJSON(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrSessionNotFound):
+		return errorJSON(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrSessionTrackingDisabled):
+		return errorJSON(c, fiber.StatusNotImplemented, err.Error())
+	default:
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to process sessions: "+err.Error())
+	}
+}
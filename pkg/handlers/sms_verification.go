@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/services"
+)
+
+type SMSVerificationHandler struct {
+	smsService *services.SMSVerificationService
+}
+
+func NewSMSVerificationHandler(smsService *services.SMSVerificationService) *SMSVerificationHandler {
+	return &SMSVerificationHandler{smsService: smsService}
+}
+
+// SendCode gửi mã OTP tới số điện thoại của customer đang đăng nhập
+// @Summary Send phone verification code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.SendSMSCodeRequest true "Phone number"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /api/auth/phone/send-code [post]
+func (h *SMSVerificationHandler) SendCode(c *fiber.Ctx) error {
+	customerID := middleware.GetUserIDFromContext(c)
+	if customerID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.SendSMSCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	info, err := h.smsService.SendCode(c.UserContext(), customerID, req.PhoneNumber)
+	if err != nil {
+		return smsErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Verification code sent",
+		Data:    info,
+	})
+}
+
+// VerifyCode xác thực mã OTP và gắn số điện thoại cho customer
+// @Summary Verify phone number
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.VerifySMSCodeRequest true "Phone number and code"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/auth/phone/verify [post]
+func (h *SMSVerificationHandler) VerifyCode(c *fiber.Ctx) error {
+	customerID := middleware.GetUserIDFromContext(c)
+	if customerID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.VerifySMSCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.smsService.VerifyCode(c.UserContext(), customerID, req.PhoneNumber, req.Code); err != nil {
+		return smsErrorJSON(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Phone number verified",
+	})
+}
+
+func smsErrorJSON(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrPhoneNumberRequired),
+		errors.Is(err, services.ErrInvalidPhoneNumber),
+		errors.Is(err, services.ErrSMSCodeRequired),
+		errors.Is(err, services.ErrSMSCodeInvalid),
+		errors.Is(err, services.ErrSMSCodeExpired):
+		return errorJSON(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrPhoneNumberInUse):
+		return errorJSON(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrSMSTooManyAttempts),
+		errors.Is(err, services.ErrSMSResendCooldown),
+		errors.Is(err, services.ErrSMSTooManyRequests):
+		return errorJSON(c, fiber.StatusTooManyRequests, err.Error())
+	default:
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to verify phone number: "+err.Error())
+	}
+}
@@ -27,7 +27,7 @@ func NewSubscriptionHandler(subscriptionService *services.SubscriptionService) *
 // @Failure 500 {object} models.ErrorResponse
 // @Router /api/subscriptions [get]
 func (h *SubscriptionHandler) GetAllSubscriptions(c *fiber.Ctx) error {
-	subscriptions, err := h.subscriptionService.GetAllSubscriptions()
+	subscriptions, err := h.subscriptionService.GetAllSubscriptions(c.UserContext())
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(models.ErrorResponse{
 			Message: "Failed to get subscriptions",
@@ -56,7 +56,7 @@ func (h *SubscriptionHandler) GetSubscriptionByID(c *fiber.Ctx) error {
 		})
 	}
 
-	subscription, err := h.subscriptionService.GetSubscriptionByID(id)
+	subscription, err := h.subscriptionService.GetSubscriptionByID(c.UserContext(), id)
 	if err != nil {
 		return c.Status(http.StatusNotFound).JSON(models.ErrorResponse{
 			Message: "Subscription not found",
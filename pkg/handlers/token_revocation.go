@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+)
+
+type TokenRevocationHandler struct {
+	store *middleware.TokenRevocationStore
+}
+
+func NewTokenRevocationHandler(store *middleware.TokenRevocationStore) *TokenRevocationHandler {
+	return &TokenRevocationHandler{store: store}
+}
+
+// RevokeCurrentToken thu hồi access token đang dùng cho request này
+// @Summary Revoke current access token
+// @Tags auth
+// @Produce json
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/auth/revoke [post]
+func (h *TokenRevocationHandler) RevokeCurrentToken(c *fiber.Ctx) error {
+	claims := middleware.GetClaimsFromContext(c)
+	if claims == nil || claims.ID == "" {
+		return errorJSON(c, fiber.StatusUnauthorized, "Token does not support revocation")
+	}
+
+	var expiresAt time.Time
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if err := h.store.RevokeToken(c.UserContext(), claims.ID, claims.UserID, expiresAt, "self revoke"); err != nil {
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to revoke token: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Token revoked successfully",
+	})
+}
+
+// RevokeToken thu hồi một token theo jti hoặc một session theo session_id (admin)
+// @Summary Revoke a token or session
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.RevokeTokenRequest true "jti or session_id"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/admin/tokens/revoke [post]
+func (h *TokenRevocationHandler) RevokeToken(c *fiber.Ctx) error {
+	var req models.RevokeTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	var err error
+	switch {
+	case req.JTI != "":
+		err = h.store.RevokeToken(c.UserContext(), req.JTI, req.UserID, time.Time{}, req.Reason)
+	case req.SessionID != "":
+		err = h.store.RevokeSession(c.UserContext(), req.SessionID, req.UserID, req.Reason)
+	default:
+		return errorJSON(c, fiber.StatusBadRequest, "jti or session_id is required")
+	}
+	if err != nil {
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to revoke token: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Token revoked successfully",
+	})
+}
+
+// RevokeUserSessions thu hồi mọi token hiện có của một user (admin)
+// @Summary Revoke all sessions of a user
+// @Tags auth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} SuccessResponse
+// @Router /api/admin/users/{id}/tokens/revoke [post]
+func (h *TokenRevocationHandler) RevokeUserSessions(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if userID == "" {
+		return errorJSON(c, fiber.StatusBadRequest, "User ID is required")
+	}
+
+	if err := h.store.RevokeUser(c.UserContext(), userID, c.Query("reason", "admin revoke")); err != nil {
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to revoke sessions: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "All sessions revoked successfully",
+	})
+}
+
+// RevokeIssuedBefore thu hồi mọi token phát hành trước một thời điểm, cho một user hoặc toàn hệ thống (admin)
+// @Summary Revoke tokens issued before a time
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.RevokeTokenRequest true "before (RFC3339) and optional user_id"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/admin/tokens/revoke-before [post]
+func (h *TokenRevocationHandler) RevokeIssuedBefore(c *fiber.Ctx) error {
+	var req models.RevokeTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if req.Before.IsZero() {
+		return errorJSON(c, fiber.StatusBadRequest, "before is required")
+	}
+
+	if err := h.store.RevokeIssuedBefore(c.UserContext(), req.UserID, req.Before, req.Reason); err != nil {
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to revoke tokens: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Tokens revoked successfully",
+	})
+}
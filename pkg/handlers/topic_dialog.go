@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/middleware"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
 	service "github.com/techmaster-vietnam/dd_goshare/pkg/services"
+	"github.com/techmaster-vietnam/dd_goshare/rbac"
 )
 
 type TopicDialogResponse struct {
@@ -21,15 +26,52 @@ type DialogResponse struct {
 type TopicDialogHandler struct {
 	topicService  *service.TopicService
 	dialogService *service.DialogService
+	// hiddenTopicRoles là các role được xem topic private/draft
+	hiddenTopicRoles []string
 }
 
 func NewTopicDialogHandler(topicService *service.TopicService, dialogService *service.DialogService) *TopicDialogHandler {
-	return &TopicDialogHandler{topicService: topicService, dialogService: dialogService}
+	return &TopicDialogHandler{
+		topicService:     topicService,
+		dialogService:    dialogService,
+		hiddenTopicRoles: []string{rbac.DEFAULT_HIGHEST_ROLE},
+	}
+}
+
+// WithHiddenTopicRoles thay danh sách role được xem topic private/draft (mặc định chỉ admin)
+func (h *TopicDialogHandler) WithHiddenTopicRoles(roles ...string) *TopicDialogHandler {
+	h.hiddenTopicRoles = roles
+	return h
+}
+
+// canViewHiddenTopics cho biết principal của request có role được xem topic private/draft không.
+// Request ẩn danh hoặc không tra được role luôn chỉ thấy topic public.
+func (h *TopicDialogHandler) canViewHiddenTopics(c *fiber.Ctx) bool {
+	principal := middleware.GetPrincipal(c)
+	if principal == nil || principal.ID == "" {
+		return false
+	}
+	roles := principal.Roles
+	if len(roles) == 0 && principal.TokenType != pmodel.TokenTypeAPIKey {
+		var err error
+		if roles, err = rbac.GetUserRolesFromDB(principal.ID); err != nil {
+			return false
+		}
+	}
+	for _, role := range roles {
+		for _, allowed := range h.hiddenTopicRoles {
+			if strings.EqualFold(role, allowed) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func (h *TopicDialogHandler) GetAllTopicsDialogs(c *fiber.Ctx) error {
 	title := c.Query("title")
 	tagsParam := c.Query("tags")
+	levelsParam := c.Query("levels")
 	page := c.QueryInt("page", 1)
 	limit := c.QueryInt("limit", 20)
 	sort := c.Query("sort", "title")
@@ -47,7 +89,15 @@ func (h *TopicDialogHandler) GetAllTopicsDialogs(c *fiber.Ctx) error {
 		}
 	}
 
-	result, total, err := h.topicService.SearchTopicList(title, tags, page, limit, sort, asc)
+	// levels là danh sách mã level cách nhau bởi dấu phẩy, ví dụ levels=A1,A2
+	var levels []string
+	for _, l := range strings.Split(levelsParam, ",") {
+		if ll := strings.ToUpper(strings.TrimSpace(l)); ll != "" {
+			levels = append(levels, ll)
+		}
+	}
+
+	result, total, err := h.topicService.SearchTopicList(c.UserContext(), title, tags, levels, page, limit, sort, asc)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Errors: ErrorItem{
@@ -80,3 +130,399 @@ func (h *TopicDialogHandler) GetAllTopicsDialogs(c *fiber.Ctx) error {
 		Data: responseData,
 	})
 }
+
+// Search tìm full-text theo tiêu đề topic, tiêu đề dialog và nội dung dialog; khớp theo tiền tố nên dùng được cho autocomplete
+// @Summary Search topics and dialogs
+// @Tags topics
+// @Produce json
+// @Param q query string true "Keyword, accents optional"
+// @Param limit query int false "Max results per group" default(10)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/search [get]
+func (h *TopicDialogHandler) Search(c *fiber.Ctx) error {
+	keyword := strings.TrimSpace(c.Query("q"))
+	if keyword == "" {
+		return errorJSON(c, fiber.StatusBadRequest, "q is required")
+	}
+	result, err := h.topicService.SearchKeyword(c.UserContext(), keyword, c.QueryInt("limit", service.DefaultSearchLimit))
+	if err != nil {
+		return errorJSON(c, fiber.StatusInternalServerError, "Failed to search: "+err.Error())
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: result,
+	})
+}
+
+// ListTopics liệt kê topic theo thứ tự hiển thị; include_hidden=true trả cả topic private/draft
+// nhưng chỉ có tác dụng với role được xem topic ẩn (xem WithHiddenTopicRoles)
+// @Summary List topics
+// @Tags topics
+// @Produce json
+// @Param include_hidden query bool false "Include private and draft topics (staff only)"
+// @Success 200 {object} SuccessResponse
+// @Router /api/topics [get]
+func (h *TopicDialogHandler) ListTopics(c *fiber.Ctx) error {
+	includeHidden := c.QueryBool("include_hidden") && h.canViewHiddenTopics(c)
+	items, err := h.topicService.ListTopics(c.UserContext(), includeHidden)
+	if err != nil {
+		return topicErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: models.TopicListResponse{TopicList: items},
+	})
+}
+
+// GetTopic trả về topic kèm số dialog, dialog đầu tiên và ảnh bìa; topic private/draft chỉ trả cho role được xem topic ẩn
+// @Summary Get topic
+// @Tags topics
+// @Produce json
+// @Param id path string true "Topic ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/topics/{id} [get]
+func (h *TopicDialogHandler) GetTopic(c *fiber.Ctx) error {
+	item, err := h.topicService.GetTopicItem(c.UserContext(), c.Params("id"), h.canViewHiddenTopics(c))
+	if err != nil {
+		return topicErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: item,
+	})
+}
+
+// CreateTopic tạo topic
+// @Summary Create topic
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Param body body models.CreateTopicRequest true "Topic"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/topics [post]
+func (h *TopicDialogHandler) CreateTopic(c *fiber.Ctx) error {
+	var req models.CreateTopicRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	topic, err := h.topicService.CreateTopic(c.UserContext(), req)
+	if err != nil {
+		return topicErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(SuccessResponse{
+		Code:    fiber.StatusCreated,
+		Message: "Topic created",
+		Data:    topic,
+	})
+}
+
+// UpdateTopic cập nhật topic
+// @Summary Update topic
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Param id path string true "Topic ID"
+// @Param body body models.UpdateTopicRequest true "Fields to update"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/topics/{id} [put]
+func (h *TopicDialogHandler) UpdateTopic(c *fiber.Ctx) error {
+	var req models.UpdateTopicRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	topic, err := h.topicService.UpdateTopic(c.UserContext(), c.Params("id"), req)
+	if err != nil {
+		return topicErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: topic,
+	})
+}
+
+// DeleteTopic chuyển topic cùng dialog của nó vào thùng rác
+// @Summary Delete topic
+// @Tags topics
+// @Produce json
+// @Param id path string true "Topic ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/topics/{id} [delete]
+func (h *TopicDialogHandler) DeleteTopic(c *fiber.Ctx) error {
+	if err := h.topicService.DeleteTopic(c.UserContext(), c.Params("id")); err != nil {
+		return topicErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Topic moved to trash",
+	})
+}
+
+// ReorderTopics sắp xếp lại các topic theo thứ tự topic_ids
+// @Summary Reorder topics
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Param body body models.ReorderTopicsRequest true "Topic IDs in the new order"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/topics/order [put]
+func (h *TopicDialogHandler) ReorderTopics(c *fiber.Ctx) error {
+	var req models.ReorderTopicsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := h.topicService.ReorderTopics(c.UserContext(), req.TopicIDs); err != nil {
+		return topicErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Topics reordered",
+	})
+}
+
+// SetTopicCover đặt ảnh bìa cho topic
+// @Summary Set topic cover image
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Param id path string true "Topic ID"
+// @Param body body models.SetTopicCoverRequest true "Cover image URL"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/topics/{id}/cover [put]
+func (h *TopicDialogHandler) SetTopicCover(c *fiber.Ctx) error {
+	var req models.SetTopicCoverRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	image, err := h.topicService.SetCoverImage(c.UserContext(), c.Params("id"), req.FileURL, middleware.GetUserIDFromContext(c))
+	if err != nil {
+		return topicErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: image,
+	})
+}
+
+// DeleteTopicCover chuyển ảnh bìa của topic vào thùng rác
+// @Summary Remove topic cover image
+// @Tags topics
+// @Produce json
+// @Param id path string true "Topic ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/topics/{id}/cover [delete]
+func (h *TopicDialogHandler) DeleteTopicCover(c *fiber.Ctx) error {
+	if err := h.topicService.RemoveCoverImage(c.UserContext(), c.Params("id")); err != nil {
+		return topicErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Cover image removed",
+	})
+}
+
+// CreateDialog tạo dialog, mặc định nối vào cuối topic; after_id/before_id chọn vị trí chèn
+// @Summary Create dialog
+// @Tags dialogs
+// @Accept json
+// @Produce json
+// @Param body body models.CreateDialogRequest true "Dialog"
+// @Success 201 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/dialogs [post]
+func (h *TopicDialogHandler) CreateDialog(c *fiber.Ctx) error {
+	var req models.CreateDialogRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	dialog := &models.Dialog{
+		TopicID:  req.TopicID,
+		Title:    req.Title,
+		RawText:  req.RawText,
+		Script:   req.Script,
+		Result:   req.Result,
+		AuthorID: middleware.GetUserIDFromContext(c),
+	}
+	if req.LevelID != "" {
+		dialog.LevelID = &req.LevelID
+	}
+	pos := models.DialogPosition{AfterID: req.AfterID, BeforeID: req.BeforeID}
+	if err := h.dialogService.CreateDialog(c.UserContext(), dialog, pos); err != nil {
+		return dialogErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(SuccessResponse{
+		Code:    fiber.StatusCreated,
+		Message: "Dialog created",
+		Data:    dialog,
+	})
+}
+
+// UpdateDialog cập nhật nội dung dialog
+// @Summary Update dialog
+// @Tags dialogs
+// @Accept json
+// @Produce json
+// @Param id path string true "Dialog ID"
+// @Param body body models.UpdateDialogRequest true "Fields to update"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/dialogs/{id} [put]
+func (h *TopicDialogHandler) UpdateDialog(c *fiber.Ctx) error {
+	var req models.UpdateDialogRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	dialog, err := h.dialogService.UpdateDialog(c.UserContext(), c.Params("id"), middleware.GetUserIDFromContext(c), req)
+	if err != nil {
+		return dialogErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: dialog,
+	})
+}
+
+// DeleteDialog chuyển dialog vào thùng rác
+// @Summary Delete dialog
+// @Tags dialogs
+// @Produce json
+// @Param id path string true "Dialog ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/dialogs/{id} [delete]
+func (h *TopicDialogHandler) DeleteDialog(c *fiber.Ctx) error {
+	if err := h.dialogService.DeleteDialog(c.UserContext(), c.Params("id")); err != nil {
+		return dialogErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Dialog moved to trash",
+	})
+}
+
+// MoveDialog đổi vị trí dialog trong topic hoặc chuyển sang topic khác
+// @Summary Move dialog
+// @Tags dialogs
+// @Accept json
+// @Produce json
+// @Param id path string true "Dialog ID"
+// @Param body body models.DialogPosition true "Target position"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/dialogs/{id}/position [put]
+func (h *TopicDialogHandler) MoveDialog(c *fiber.Ctx) error {
+	var pos models.DialogPosition
+	if err := c.BodyParser(&pos); err != nil {
+		return errorJSON(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := h.dialogService.MoveDialog(c.UserContext(), c.Params("id"), pos); err != nil {
+		return dialogErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Dialog moved",
+	})
+}
+
+// ValidateDialogChain kiểm tra danh sách liên kết dialog của topic
+// @Summary Validate dialog chain of a topic
+// @Tags dialogs
+// @Produce json
+// @Param id path string true "Topic ID"
+// @Success 200 {object} SuccessResponse
+// @Router /api/topics/{id}/dialog-chain [get]
+func (h *TopicDialogHandler) ValidateDialogChain(c *fiber.Ctx) error {
+	report, err := h.dialogService.ValidateTopicChain(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return dialogErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: report,
+	})
+}
+
+// FindBrokenDialogChains liệt kê các topic có danh sách liên kết dialog hỏng
+// @Summary List topics with broken dialog chains
+// @Tags dialogs
+// @Produce json
+// @Success 200 {object} SuccessResponse
+// @Router /api/admin/dialog-chains/broken [get]
+func (h *TopicDialogHandler) FindBrokenDialogChains(c *fiber.Ctx) error {
+	reports, err := h.dialogService.FindBrokenChains(c.UserContext())
+	if err != nil {
+		return dialogErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: reports,
+	})
+}
+
+// RepairDialogChain dựng lại danh sách liên kết dialog của topic
+// @Summary Repair dialog chain of a topic
+// @Tags dialogs
+// @Produce json
+// @Param id path string true "Topic ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/topics/{id}/dialog-chain/repair [post]
+func (h *TopicDialogHandler) RepairDialogChain(c *fiber.Ctx) error {
+	report, err := h.dialogService.RepairTopicChain(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return dialogErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Dialog chain repaired",
+		Data:    report,
+	})
+}
+
+func dialogErrorJSON(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrDialogNotFound), errors.Is(err, service.ErrTopicNotFound):
+		return errorJSON(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrDialogChainBroken):
+		return errorJSON(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrDialogIDRequired), errors.Is(err, service.ErrTopicIDRequired),
+		errors.Is(err, service.ErrDialogTitleRequired), errors.Is(err, service.ErrDialogRawTextRequired),
+		errors.Is(err, service.ErrDialogAuthorRequired), errors.Is(err, service.ErrDialogPositionInvalid),
+		errors.Is(err, service.ErrDialogAnchorNotFound), errors.Is(err, service.ErrLevelNotFound):
+		return errorJSON(c, fiber.StatusBadRequest, err.Error())
+	}
+	return errorJSON(c, fiber.StatusInternalServerError, err.Error())
+}
+
+func topicErrorJSON(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrTopicNotFound), errors.Is(err, service.ErrTopicCoverNotFound):
+		return errorJSON(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrTopicExists):
+		return errorJSON(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrTopicIDRequired), errors.Is(err, service.ErrTopicNameRequired),
+		errors.Is(err, service.ErrTopicVisibilityInvalid), errors.Is(err, service.ErrTopicDifficultyInvalid),
+		errors.Is(err, service.ErrTopicReorderInvalid), errors.Is(err, service.ErrTopicCoverURLRequired),
+		errors.Is(err, service.ErrLevelNotFound):
+		return errorJSON(c, fiber.StatusBadRequest, err.Error())
+	}
+	return errorJSON(c, fiber.StatusInternalServerError, err.Error())
+}
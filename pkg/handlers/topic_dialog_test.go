@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
+)
+
+func TestCanViewHiddenTopics(t *testing.T) {
+	cases := []struct {
+		name      string
+		principal *pmodel.Principal
+		want      bool
+	}{
+		{"Anonymous", nil, false},
+		{"Learner", &pmodel.Principal{ID: "c1", Roles: []string{"customer"}, TokenType: pmodel.TokenTypeFirebase}, false},
+		{"Admin", &pmodel.Principal{ID: "e1", Roles: []string{"Admin"}, TokenType: pmodel.TokenTypeAppJWT}, true},
+		{"APIKeyWithoutRoles", &pmodel.Principal{ID: "k1", TokenType: pmodel.TokenTypeAPIKey}, false},
+	}
+	h := NewTopicDialogHandler(nil, nil)
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			app := fiber.New()
+			var got bool
+			app.Get("/", func(c *fiber.Ctx) error {
+				if tc.principal != nil {
+					c.Locals(pmodel.PrincipalLocalsKey, tc.principal)
+				}
+				got = h.canViewHiddenTopics(c)
+				return nil
+			})
+			if _, err := app.Test(httptest.NewRequest("GET", "/", nil)); err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	t.Run("TestCustomRoles", func(t *testing.T) {
+		editor := NewTopicDialogHandler(nil, nil).WithHiddenTopicRoles("editor")
+		app := fiber.New()
+		var got bool
+		app.Get("/", func(c *fiber.Ctx) error {
+			c.Locals(pmodel.PrincipalLocalsKey, &pmodel.Principal{ID: "e2", Roles: []string{"editor"}})
+			got = editor.canViewHiddenTopics(c)
+			return nil
+		})
+		if _, err := app.Test(httptest.NewRequest("GET", "/", nil)); err != nil {
+			t.Fatal(err)
+		}
+		if !got {
+			t.Errorf("Expected editor to view hidden topics")
+		}
+	})
+}
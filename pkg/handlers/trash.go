@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	service "github.com/techmaster-vietnam/dd_goshare/pkg/services"
+)
+
+// TrashHandler cung cấp endpoint quản trị thùng rác cho topic, dialog, comment, tag, image
+type TrashHandler struct {
+	trashService *service.TrashService
+}
+
+func NewTrashHandler(trashService *service.TrashService) *TrashHandler {
+	return &TrashHandler{trashService: trashService}
+}
+
+// Delete chuyển bản ghi cùng cây con của nó vào thùng rác
+// @Summary Move content to trash
+// @Tags trash
+// @Produce json
+// @Param type path string true "topic, dialog, comment, tag or image"
+// @Param id path string true "ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/admin/content/{type}/{id} [delete]
+func (h *TrashHandler) Delete(c *fiber.Ctx) error {
+	changes, err := h.trashService.SoftDelete(c.UserContext(), models.TrashKind(c.Params("type")), c.Params("id"))
+	if err != nil {
+		return trashErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Moved to trash",
+		Data:    changes,
+	})
+}
+
+// Restore khôi phục bản ghi cùng các bản ghi con bị xoá theo nó
+// @Summary Restore content from trash
+// @Tags trash
+// @Produce json
+// @Param type path string true "topic, dialog, comment, tag or image"
+// @Param id path string true "ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/admin/trash/{type}/{id}/restore [post]
+func (h *TrashHandler) Restore(c *fiber.Ctx) error {
+	changes, err := h.trashService.Restore(c.UserContext(), models.TrashKind(c.Params("type")), c.Params("id"))
+	if err != nil {
+		return trashErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Restored",
+		Data:    changes,
+	})
+}
+
+// List liệt kê thùng rác, mới xoá trước
+// @Summary List trashed content
+// @Tags trash
+// @Produce json
+// @Param type query string false "topic, dialog, comment, tag or image"
+// @Param page query int false "Page" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/admin/trash [get]
+func (h *TrashHandler) List(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	items, total, err := h.trashService.ListTrash(c.UserContext(), models.TrashKind(c.Query("type")), page, limit)
+	if err != nil {
+		return trashErrorJSON(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code: fiber.StatusOK,
+		Data: map[string]interface{}{
+			"items": items,
+			"total": total,
+		},
+	})
+}
+
+// Purge xoá hẳn bản ghi đã quá thời gian giữ trong thùng rác
+// @Summary Purge expired trash
+// @Tags trash
+// @Produce json
+// @Success 200 {object} SuccessResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/trash/purge [post]
+func (h *TrashHandler) Purge(c *fiber.Ctx) error {
+	changes, err := h.trashService.Purge(c.UserContext())
+	if err != nil {
+		return errorJSON(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return c.Status(fiber.StatusOK).JSON(SuccessResponse{
+		Code:    fiber.StatusOK,
+		Message: "Trash purged",
+		Data:    changes,
+	})
+}
+
+func trashErrorJSON(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrTrashKindInvalid), errors.Is(err, service.ErrTrashIDRequired):
+		return errorJSON(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrTrashItemNotFound):
+		return errorJSON(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrTrashParentDeleted), errors.Is(err, service.ErrTrashNameConflict):
+		return errorJSON(c, fiber.StatusConflict, err.Error())
+	}
+	return errorJSON(c, fiber.StatusInternalServerError, err.Error())
+}
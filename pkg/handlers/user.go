@@ -21,7 +21,7 @@ func (h *UserHandler) GetInfoByID(c *fiber.Ctx, tableName string) error {
 	if tableName == "" || id == "" {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid table or id"})
 	}
-	acc, err := h.service.GetByUserID(c.Context(), tableName, id)
+	acc, err := h.service.GetByUserID(c.UserContext(), tableName, id)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -33,7 +33,7 @@ func (h *UserHandler) GetInfoByID(c *fiber.Ctx, tableName string) error {
 
 func (h *UserHandler) GetByUsername(c *fiber.Ctx, tableName string) error {
 	username := c.Params("username")
-	acc, err := h.service.GetByUsername(c.Context(), tableName, username)
+	acc, err := h.service.GetByUsername(c.UserContext(), tableName, username)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// APIKey là khóa truy cập cho service/worker/CI, thay cho JWT của người dùng.
+// Chỉ lưu SHA-256 của key; Prefix là phần đầu key để nhận diện khi liệt kê.
+type APIKey struct {
+	ID         string     `gorm:"primaryKey;size:12" json:"id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Prefix     string     `gorm:"size:20;uniqueIndex;not null" json:"prefix"`
+	KeyHash    string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	OwnerID    string     `gorm:"size:50;index;not null" json:"owner_id"` // employee tạo/chịu trách nhiệm key
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `gorm:"size:45" json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// Các role RBAC gắn với key
+	Roles []Role `gorm:"many2many:api_key_roles;constraint:OnDelete:CASCADE;" json:"roles"`
+}
+
+// TableName chỉ định tên bảng cho APIKey
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// APIKeyAuditLog ghi lại từng request dùng API key
+type APIKeyAuditLog struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	APIKeyID  string    `gorm:"size:12;index;not null" json:"api_key_id"`
+	Method    string    `gorm:"size:10" json:"method"`
+	Path      string    `gorm:"size:255" json:"path"`
+	Status    int       `json:"status"`
+	IPAddress string    `gorm:"size:45" json:"ip_address"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// TableName chỉ định tên bảng cho APIKeyAuditLog
+func (APIKeyAuditLog) TableName() string {
+	return "api_key_audit_logs"
+}
+
+// CreateAPIKeyRequest là body tạo API key
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Roles         []string `json:"roles"`           // tên role RBAC
+	ExpiresInDays int      `json:"expires_in_days"` // 0 = không hết hạn
+}
+
+// CreateAPIKeyResponse trả về key gốc đúng một lần khi tạo
+type CreateAPIKeyResponse struct {
+	Key    string  `json:"key"`
+	APIKey *APIKey `json:"api_key"`
+}
@@ -6,18 +6,40 @@ import "time"
 type AuthProvider struct {
 	ID           string     `gorm:"primaryKey;size:50" json:"id"`
 	CustomerID       string     `gorm:"size:50;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"customer_id"` // Updated to match Customer.ID size
-	Provider     string     `gorm:"size:20;not null" json:"provider"`                                           // google, apple, sms
+	Provider     string     `gorm:"size:20;not null;uniqueIndex:idx_auth_provider_uid" json:"provider"`         // google, apple, sms
 	RefreshToken string     `gorm:"type:text" json:"-"`
 	ExpiresAt    *time.Time `json:"expires_at"`
+
+	// ProviderUserID là UID phía provider (Firebase UID, số điện thoại...); nullable để không đụng các bản ghi cũ
+	ProviderUserID *string    `gorm:"size:128;uniqueIndex:idx_auth_provider_uid" json:"provider_user_id"`
+	Email          string     `gorm:"size:100" json:"email"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
 }
 
-// SMSVerification đại diện cho việc xác thực SMS
+// SMSVerification đại diện cho việc xác thực SMS.
+// Chỉ lưu HMAC của mã OTP, không bao giờ lưu mã gốc.
 type SMSVerification struct {
-	ID          string    `gorm:"primaryKey;size:12" json:"id"`
-	PhoneNumber string    `gorm:"size:20;not null" json:"phone_number"`
-	Code        string    `gorm:"size:6;not null" json:"code"`
-	Verified    bool      `gorm:"default:false" json:"verified"`
-	ExpiresAt   time.Time `json:"expires_at"`
+	ID          string     `gorm:"primaryKey;size:12" json:"id"`
+	CustomerID  string     `gorm:"size:50;index" json:"customer_id"`
+	PhoneNumber string     `gorm:"size:20;not null;index" json:"phone_number"`
+	CodeHash    string     `gorm:"size:64;not null" json:"-"`
+	Attempts    int        `gorm:"default:0" json:"attempts"`
+	Verified    bool       `gorm:"default:false" json:"verified"`
+	VerifiedAt  *time.Time `json:"verified_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// SendSMSCodeRequest là body yêu cầu gửi mã OTP
+type SendSMSCodeRequest struct {
+	PhoneNumber string `json:"phone_number"`
+}
+
+// VerifySMSCodeRequest là body xác thực mã OTP
+type VerifySMSCodeRequest struct {
+	PhoneNumber string `json:"phone_number"`
+	Code        string `json:"code"`
 }
 
 // TableName chỉ định tên bảng cho OAuthProvider
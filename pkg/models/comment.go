@@ -1,16 +1,21 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Comment struct {
-	ID              string    `gorm:"primaryKey;size:12" json:"id"`
-	DialogID        string    `gorm:"size:12;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"dialog_id"`
-	UserID          string    `gorm:"size:50;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user_id"`
-	Content         string    `gorm:"type:text" json:"content"`
-	Likes           int       `gorm:"default:0" json:"likes"`
-	Rating          *int      `json:"rating"`
-	ParentCommentID *string   `gorm:"size:12;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"parent_comment_id"`
-	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID              string         `gorm:"primaryKey;size:12" json:"id"`
+	DialogID        string         `gorm:"size:12;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"dialog_id"`
+	UserID          string         `gorm:"size:50;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user_id"`
+	Content         string         `gorm:"type:text" json:"content"`
+	Likes           int            `gorm:"default:0" json:"likes"`
+	Rating          *int           `json:"rating"`
+	ParentCommentID *string        `gorm:"size:12;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"parent_comment_id"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 	// Quan hệ với các bảng khác
 	Dialog  Dialog    `gorm:"foreignKey:DialogID;references:ID" json:"-"`
 	User    Customer  `gorm:"foreignKey:UserID;references:ID" json:"-"`
@@ -8,11 +8,12 @@ import (
 type Customer struct {
 	ID          string     `gorm:"primaryKey;size:50" json:"id"` // Increased size for Firebase UID
 	Name        string     `gorm:"size:50;uniqueIndex" json:"name"`
-	Email       string     `gorm:"size:100;uniqueIndex" json:"email"`
+	Email       *string    `gorm:"size:100;uniqueIndex" json:"email"` // nullable: tài khoản đăng nhập bằng số điện thoại có thể không có email
 	PhoneNumber *string    `gorm:"size:20;uniqueIndex" json:"phone_number"` // ✅ Thêm unique constraint, nullable
 	Password    string     `gorm:"size:255" json:"-"`
 	AvatarURL   string     `json:"avatar_url"`
 	LastLogin   *time.Time `json:"last_login"`
+	IsGuest     bool       `gorm:"default:false;index" json:"is_guest"` // tài khoản khách chưa đăng ký, gắn với một thiết bị
 	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
 }
 
@@ -71,3 +72,9 @@ func (CustomerAchievement) TableName() string {
 func (Customer) TableName() string {
 	return "customers"
 }
+
+// GuestUpgradeRequest là body gộp tài khoản khách vào tài khoản vừa đăng nhập
+type GuestUpgradeRequest struct {
+	GuestRefreshToken string `json:"guest_refresh_token"`
+	DeviceID          string `json:"device_id"`
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"encoding/json"
+
+	"gorm.io/gorm"
 )
 
 // Dialog đại diện cho một đoạn hội thoại
@@ -17,6 +19,8 @@ type Dialog struct {
 	Result    json.RawMessage `gorm:"type:jsonb" json:"result"`
 	AuthorID  string          `gorm:"size:12;index;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"author_id"`
 	FixerID   *string         `gorm:"size:12;index;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"fixed_id"`
+	LevelID   *string         `gorm:"size:12;index" json:"level_id"`
+	DeletedAt gorm.DeletedAt  `gorm:"index" json:"-"`
 
 	// Quan hệ với các bảng khác
 	Topic        Topic         `gorm:"foreignKey:TopicID;references:ID" json:"-"`
@@ -28,6 +32,7 @@ type Dialog struct {
 	Comments     []Comment     `gorm:"foreignKey:DialogID" json:"-"`
 	Author       Employee      `gorm:"foreignKey:AuthorID;references:ID" json:"-"`
 	Fixer        Employee      `gorm:"foreignKey:FixerID;references:ID" json:"-"`
+	Level        *Level        `gorm:"foreignKey:LevelID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
 }
 
 // TableName overrides the table name used by Dialog to `dialogs`
@@ -79,4 +84,61 @@ type DialogResponse struct {
 	PrevID     string `json:"prev_id"`
 	NextID     string `json:"next_id"`
 	DialogName string `json:"dialog_name"`
+	// Chỉ có ở kết quả tìm kiếm
+	TopicID string  `json:"topic_id,omitempty"`
+	Snippet string  `json:"snippet,omitempty"`
+	Rank    float64 `json:"rank,omitempty"`
+}
+
+// DialogPosition chỉ vị trí của dialog trong danh sách liên kết PrevID/NextID của topic.
+// Chỉ đặt một trong AfterID, BeforeID; để trống cả hai là cuối danh sách. TopicID rỗng là topic hiện tại.
+type DialogPosition struct {
+	TopicID  string `json:"topic_id,omitempty"`
+	AfterID  string `json:"after_id,omitempty"`
+	BeforeID string `json:"before_id,omitempty"`
+}
+
+type CreateDialogRequest struct {
+	TopicID  string          `json:"topic_id"`
+	Title    string          `json:"title"`
+	RawText  string          `json:"raw_text"`
+	Script   string          `json:"script"`
+	Result   json.RawMessage `json:"result"`
+	LevelID  string          `json:"level_id"` // rỗng thì dialog theo level của topic
+	AfterID  string          `json:"after_id"`
+	BeforeID string          `json:"before_id"`
+}
+
+// UpdateDialogRequest chỉ cập nhật trường khác nil; vị trí trong topic đổi qua MoveDialog
+type UpdateDialogRequest struct {
+	Title   *string         `json:"title"`
+	RawText *string         `json:"raw_text"`
+	Script  *string         `json:"script"`
+	Result  json.RawMessage `json:"result"`
+	LevelID *string         `json:"level_id"` // chuỗi rỗng thì bỏ gán level
+}
+
+// Loại lỗi của danh sách liên kết dialog trong topic
+const (
+	DialogChainNoHead         = "no_head"
+	DialogChainMultipleHeads  = "multiple_heads"
+	DialogChainDanglingPrev   = "dangling_prev"
+	DialogChainDanglingNext   = "dangling_next"
+	DialogChainAsymmetricLink = "asymmetric_link"
+	DialogChainCycle          = "cycle"
+	DialogChainUnreachable    = "unreachable"
+)
+
+type DialogChainIssue struct {
+	Type     string `json:"type"`
+	DialogID string `json:"dialog_id,omitempty"`
+	Detail   string `json:"detail"`
+}
+
+// DialogChainReport là kết quả kiểm tra danh sách liên kết của một topic; Order là thứ tự đi từ dialog đầu
+type DialogChainReport struct {
+	TopicID string             `json:"topic_id"`
+	Valid   bool               `json:"valid"`
+	Order   []string           `json:"order"`
+	Issues  []DialogChainIssue `json:"issues,omitempty"`
 }
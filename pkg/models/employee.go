@@ -1,13 +1,60 @@
 package models
 
+import "time"
+
 type Employee struct {
 	ID       string `gorm:"primaryKey;size:12" json:"id"`
 	Name     string `gorm:"size:50" json:"name"`
 	Email    string `gorm:"size:100;uniqueIndex" json:"email"`
 	Password string `gorm:"size:255" json:"-"`
 	// Không lưu trực tiếp role ở đây, dùng bảng UserRole để phân quyền
+
+	// Khóa tài khoản sau nhiều lần đăng nhập sai
+	FailedLoginAttempts int        `gorm:"default:0" json:"-"`
+	LockedUntil         *time.Time `json:"locked_until,omitempty"`
+	LastLogin           *time.Time `json:"last_login"`
+	PasswordChangedAt   *time.Time `json:"password_changed_at,omitempty"`
 }
 
 func (Employee) TableName() string {
 	return "employees"
 }
+
+// PasswordResetToken là token đặt lại mật khẩu một lần cho employee; chỉ lưu SHA-256 của token
+type PasswordResetToken struct {
+	ID         string     `gorm:"primaryKey;size:12" json:"id"`
+	EmployeeID string     `gorm:"size:12;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"employee_id"`
+	TokenHash  string     `gorm:"size:64;uniqueIndex" json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	UsedAt     *time.Time `json:"used_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	Employee Employee `gorm:"foreignKey:EmployeeID;references:ID" json:"-"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// EmployeeLoginRequest là body đăng nhập bằng email/mật khẩu cho nhân viên
+type EmployeeLoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// PasswordResetRequest yêu cầu gửi link đặt lại mật khẩu
+type PasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest đặt mật khẩu mới bằng reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// ChangePasswordRequest đổi mật khẩu khi đã đăng nhập
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
@@ -1,13 +1,16 @@
 package models
 
+import "gorm.io/gorm"
+
 // Image represents an image file entity
 type Image struct {
-	ID       string  `gorm:"primaryKey;size:12" json:"id"`
-	DialogID *string `gorm:"size:12;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"dialog_id"`
-	TopicID  *string `gorm:"size:12;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"topic_id"`
-	FileURL  string  `json:"file_url" gorm:"type:text; not null"`
-	IsFigure bool    `json:"is_figure" gorm:"type:boolean; not null;default:false"`
-	AuthorID string  `gorm:"size:12;index;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"author_id"`
+	ID        string         `gorm:"primaryKey;size:12" json:"id"`
+	DialogID  *string        `gorm:"size:12;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"dialog_id"`
+	TopicID   *string        `gorm:"size:12;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"topic_id"`
+	FileURL   string         `json:"file_url" gorm:"type:text; not null"`
+	IsFigure  bool           `json:"is_figure" gorm:"type:boolean; not null;default:false"`
+	AuthorID  string         `gorm:"size:12;index;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"author_id"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Quan hệ với các bảng khác
 	Author Employee `gorm:"foreignKey:AuthorID;references:ID" json:"-"`
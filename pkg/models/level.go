@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// CEFRLevels là các mức CEFR chuẩn theo thứ tự từ dễ tới khó
+var CEFRLevels = []string{"A1", "A2", "B1", "B2", "C1", "C2"}
+
+// Level là trình độ (CEFR hoặc mức tự định nghĩa) gán cho topic và dialog.
+// Độ khó chi tiết trong cùng một level là Topic.Difficulty.
+type Level struct {
+	ID          string    `gorm:"primaryKey;size:12" json:"id"`
+	Code        string    `gorm:"size:20;not null;uniqueIndex" json:"code"`
+	Name        string    `gorm:"size:100;not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	Rank        int       `gorm:"not null;default:0;index" json:"rank"` // thứ tự từ dễ tới khó
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName chỉ định tên bảng cho Level
+func (Level) TableName() string {
+	return "levels"
+}
+
+// IsCEFR cho biết level là một mức CEFR chuẩn (không xoá hoặc đổi mã được)
+func (l Level) IsCEFR() bool {
+	for _, code := range CEFRLevels {
+		if l.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateLevelRequest struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Rank        int    `json:"rank"`
+}
+
+// UpdateLevelRequest chỉ cập nhật trường khác nil
+type UpdateLevelRequest struct {
+	Code        *string `json:"code"`
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Rank        *int    `json:"rank"`
+}
+
+// LevelProgress là tiến độ của người học ở một level.
+// Dialog thuộc level của chính nó, nếu không có thì theo level của topic; chỉ tính topic public.
+type LevelProgress struct {
+	LevelID           string  `json:"level_id"`
+	Code              string  `json:"code"`
+	Name              string  `json:"name"`
+	Rank              int     `json:"rank"`
+	TotalDialogs      int     `json:"total_dialogs"`
+	CompletedDialogs  int     `json:"completed_dialogs"`   // đã xong cả nghe, nói, viết
+	InProgressDialogs int     `json:"in_progress_dialogs"` // đã làm ít nhất một phần
+	CompletionPercent float64 `json:"completion_percent"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// MFATypeTOTP là loại factor dùng ứng dụng authenticator (RFC 6238)
+const MFATypeTOTP = "totp"
+
+// MFAFactor là factor xác thực thứ hai của employee/admin.
+// Secret được mã hóa AES-GCM trước khi lưu; factor chỉ có hiệu lực sau khi ConfirmedAt được set.
+type MFAFactor struct {
+	ID              string     `gorm:"primaryKey;size:12" json:"id"`
+	UserID          string     `gorm:"size:50;uniqueIndex" json:"user_id"`
+	Type            string     `gorm:"size:20;not null;default:totp" json:"type"`
+	SecretEncrypted string     `gorm:"type:text;not null" json:"-"`
+	LastUsedStep    int64      `gorm:"default:0" json:"-"` // chặn dùng lại cùng một mã TOTP
+	FailedAttempts  int        `gorm:"default:0" json:"-"`
+	LockedUntil     *time.Time `json:"locked_until,omitempty"` // khóa xác minh sau quá nhiều lần nhập sai
+	ConfirmedAt     *time.Time `json:"confirmed_at"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (MFAFactor) TableName() string {
+	return "mfa_factors"
+}
+
+// MFARecoveryCode là mã khôi phục dùng một lần khi mất thiết bị authenticator; chỉ lưu SHA-256
+type MFARecoveryCode struct {
+	ID        string     `gorm:"primaryKey;size:12" json:"id"`
+	UserID    string     `gorm:"size:50;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;uniqueIndex" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// MFACodeRequest chứa mã TOTP hoặc mã khôi phục
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
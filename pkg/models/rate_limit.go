@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RateLimitCounter lưu bộ đếm request theo cửa sổ thời gian cố định,
+// dùng chung giữa nhiều instance khi rate limiter chạy với Postgres storage
+type RateLimitCounter struct {
+	Key       string    `gorm:"primaryKey;size:191" json:"key"`
+	Count     int       `gorm:"not null;default:0" json:"count"`
+	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at"`
+}
+
+// TableName chỉ định tên bảng cho RateLimitCounter
+func (RateLimitCounter) TableName() string {
+	return "rate_limit_counters"
+}
@@ -29,6 +29,7 @@ type Rule struct {
 	IsPrivate  bool   `gorm:"index" json:"is_private"`
 	Service    string `gorm:"size:50;uniqueIndex:idx_rule_unique" json:"service"`
 	AccessType int    `gorm:"type:smallint;default:3" json:"access_type"` // 1: allow, 2: forbid, 3: allow_all, 4: forbid_all
+	RequireMFA bool   `gorm:"default:false" json:"require_mfa"`           // bắt buộc session đã xác thực 2FA (claim mfa)
 	// Relationships
 	Roles []Role `gorm:"many2many:rule_roles;" json:"roles,omitempty"`
 }
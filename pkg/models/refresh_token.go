@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// RefreshToken lưu refresh token đã hash, gắn với customer và thiết bị.
+// Các token sinh ra từ cùng một lần đăng nhập thuộc cùng một family (FamilyID = ID token đầu tiên).
+type RefreshToken struct {
+	ID           string     `gorm:"primaryKey;size:12" json:"id"`
+	CustomerID   string     `gorm:"size:50;index;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"customer_id"`
+	FamilyID     string     `gorm:"size:12;index;not null" json:"family_id"`
+	DeviceID     string     `gorm:"size:100;index" json:"device_id"`
+	TokenHash    string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt       *time.Time `json:"used_at"`    // thời điểm token bị đổi lấy token mới
+	RevokedAt    *time.Time `json:"revoked_at"` // thời điểm family bị thu hồi (logout hoặc phát hiện reuse)
+	ReplacedByID *string    `gorm:"size:12" json:"replaced_by_id"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// Quan hệ với các bảng khác
+	Customer Customer `gorm:"foreignKey:CustomerID;references:ID" json:"-"`
+}
+
+// TableName chỉ định tên bảng cho RefreshToken
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// RefreshTokenRequest là body của API refresh/logout
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	DeviceID     string `json:"device_id"`
+	DeviceName   string `json:"device_name"`
+	Platform     string `json:"platform"`
+	AppVersion   string `json:"app_version"`
+}
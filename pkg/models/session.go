@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Session là một phiên đăng nhập của customer trên một thiết bị.
+// ID trùng với FamilyID của refresh token và claim sid của access token.
+type Session struct {
+	ID         string     `gorm:"primaryKey;size:12" json:"id"`
+	CustomerID string     `gorm:"size:50;index;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"customer_id"`
+	DeviceID   string     `gorm:"size:100;index" json:"device_id"`
+	DeviceName string     `gorm:"size:100" json:"device_name"`
+	Platform   string     `gorm:"size:20" json:"platform"` // ios, android, web...
+	AppVersion string     `gorm:"size:20" json:"app_version"`
+	IPAddress  string     `gorm:"size:45" json:"ip_address"`
+	UserAgent  string     `gorm:"size:255" json:"user_agent"`
+	LastSeenAt time.Time  `gorm:"index" json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// Quan hệ với các bảng khác
+	Customer Customer `gorm:"foreignKey:CustomerID;references:ID" json:"-"`
+}
+
+// TableName chỉ định tên bảng cho Session
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// DeviceInfo là thông tin thiết bị client gửi lên khi đăng nhập/refresh
+type DeviceInfo struct {
+	DeviceID   string `json:"device_id"`
+	DeviceName string `json:"device_name"`
+	Platform   string `json:"platform"`
+	AppVersion string `json:"app_version"`
+	IPAddress  string `json:"-"`
+	UserAgent  string `json:"-"`
+}
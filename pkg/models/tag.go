@@ -1,9 +1,12 @@
 package models
 
+import "gorm.io/gorm"
+
 // Tag đại diện cho một thẻ gắn nhãn
 type Tag struct {
 	ID        string         `gorm:"primaryKey;size:12" json:"id"`
-	Name      string         `gorm:"size:100;uniqueIndex" json:"name"`
+	Name      string         `gorm:"size:100;uniqueIndex:idx_tags_name,where:deleted_at IS NULL" json:"name"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // TableName chỉ định tên bảng cho Tag
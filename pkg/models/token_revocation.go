@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Loại thu hồi access token
+const (
+	RevocationKindToken   = "jti"     // thu hồi một token theo jti
+	RevocationKindSession = "session" // thu hồi mọi token của một session (sid)
+	RevocationKindUser    = "user"    // thu hồi mọi token của user phát hành trước RevokedBefore
+	RevocationKindGlobal  = "global"  // thu hồi mọi token phát hành trước RevokedBefore
+)
+
+// TokenRevocation lưu danh sách access token bị thu hồi trước khi hết hạn.
+// ExpiresAt là thời điểm bản ghi không còn cần thiết (mọi token liên quan đã hết hạn) và có thể xóa.
+type TokenRevocation struct {
+	Kind          string    `gorm:"primaryKey;size:10" json:"kind"`
+	Subject       string    `gorm:"primaryKey;size:64" json:"subject"` // jti, session ID, user ID hoặc "*"
+	UserID        string    `gorm:"size:50;index" json:"user_id,omitempty"`
+	RevokedBefore time.Time `json:"revoked_before"`
+	Reason        string    `gorm:"size:100" json:"reason,omitempty"`
+	ExpiresAt     time.Time `gorm:"index;not null" json:"expires_at"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime;index" json:"updated_at"`
+}
+
+// TableName chỉ định tên bảng cho TokenRevocation
+func (TokenRevocation) TableName() string {
+	return "token_revocations"
+}
+
+// RevokeTokenRequest là body của API thu hồi token
+type RevokeTokenRequest struct {
+	JTI       string    `json:"jti"`
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id"`
+	Before    time.Time `json:"before"`
+	Reason    string    `json:"reason"`
+}
@@ -1,9 +1,37 @@
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Chế độ hiển thị của topic
+const (
+	TopicVisibilityPublic  = "public"  // mọi người học đều thấy
+	TopicVisibilityPrivate = "private" // chỉ nhân viên thấy
+	TopicVisibilityDraft   = "draft"   // đang soạn
+)
+
+// MaxTopicDifficulty là độ khó lớn nhất; 0 là chưa đánh giá.
+// Difficulty là điểm độ khó tương đối (1–5) giữa các topic cùng trình độ, dùng để xếp thứ tự trong một level;
+// còn trình độ người học (A1–C2, lọc và tiến độ theo level) nằm ở LevelID, không suy ra từ Difficulty.
+const MaxTopicDifficulty = 5
+
 // Topic đại diện cho chủ đề
 type Topic struct {
-	ID        string         `gorm:"primaryKey;size:12" json:"id"`
-	Title     string         `gorm:"size:200" json:"title"`
+	ID          string         `gorm:"primaryKey;size:12" json:"id"`
+	Title       string         `gorm:"size:200" json:"title"`
+	Description string         `gorm:"type:text" json:"description"`
+	SortOrder   int            `gorm:"not null;default:0;index" json:"sort_order"`
+	Difficulty  int16          `gorm:"not null;default:0" json:"difficulty"` // độ khó trong level, xem MaxTopicDifficulty
+	Visibility  string         `gorm:"size:20;not null;default:'public';index" json:"visibility"`
+	LevelID     *string        `gorm:"size:12;index" json:"level_id"` // trình độ của topic
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Level *Level `gorm:"foreignKey:LevelID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
 }
 
 // TableName chỉ định tên bảng cho Topic
@@ -13,10 +41,22 @@ func (Topic) TableName() string {
 
 // TopicListItem represents a topic item in topic list responses
 type TopicListItem struct {
-	ID            string `json:"id"`
-	Title         string `json:"title"`
-	DialogNum     int    `json:"dialog_num"`
-	FirstDialogID string `json:"first_dialog_id"`
+	ID            string    `json:"id"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description"`
+	SortOrder     int       `json:"sort_order"`
+	Difficulty    int16     `json:"difficulty"`
+	Visibility    string    `json:"visibility"`
+	LevelID       *string   `json:"level_id"`
+	LevelCode     string    `json:"level_code,omitempty"`
+	CoverURL      string    `json:"cover_url,omitempty"`
+	DialogNum     int       `json:"dialog_num"`
+	FirstDialogID string    `json:"first_dialog_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	// Chỉ có ở kết quả tìm kiếm
+	Snippet string  `json:"snippet,omitempty"`
+	Rank    float64 `json:"rank,omitempty"`
 }
 type TopicListResponse struct {
 	TopicList []TopicListItem `json:"topic_list"`
@@ -27,3 +67,33 @@ type TitleDialogResponse struct {
 	TopicName string           `json:"topic_name"`
 	Dialogs   []DialogResponse `json:"dialogs"`
 }
+
+type CreateTopicRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Difficulty  int16  `json:"difficulty"`
+	Visibility  string `json:"visibility"`
+	LevelID     string `json:"level_id"`
+	// SortOrder rỗng thì topic được xếp cuối
+	SortOrder *int `json:"sort_order"`
+}
+
+// UpdateTopicRequest chỉ cập nhật trường khác nil
+type UpdateTopicRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Difficulty  *int16  `json:"difficulty"`
+	Visibility  *string `json:"visibility"`
+	SortOrder   *int    `json:"sort_order"`
+	// LevelID là chuỗi rỗng thì bỏ gán level
+	LevelID *string `json:"level_id"`
+}
+
+// ReorderTopicsRequest là danh sách topic theo thứ tự mới
+type ReorderTopicsRequest struct {
+	TopicIDs []string `json:"topic_ids"`
+}
+
+type SetTopicCoverRequest struct {
+	FileURL string `json:"file_url"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// TrashKind là loại nội dung hỗ trợ xoá mềm và khôi phục
+type TrashKind string
+
+const (
+	TrashTopic   TrashKind = "topic"
+	TrashDialog  TrashKind = "dialog"
+	TrashComment TrashKind = "comment"
+	TrashTag     TrashKind = "tag"
+	TrashImage   TrashKind = "image"
+)
+
+// TrashKinds liệt kê các loại theo thứ tự hiển thị
+var TrashKinds = []TrashKind{TrashTopic, TrashDialog, TrashComment, TrashTag, TrashImage}
+
+// Valid kiểm tra k có phải loại được hỗ trợ
+func (k TrashKind) Valid() bool {
+	for _, kind := range TrashKinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// TrashItem là một bản ghi trong thùng rác
+type TrashItem struct {
+	Kind      TrashKind `json:"kind"`
+	ID        string    `json:"id"`
+	Label     string    `json:"label"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// TrashChanges đếm số bản ghi bị ảnh hưởng theo loại sau khi xoá mềm, khôi phục hoặc purge
+type TrashChanges map[TrashKind]int64
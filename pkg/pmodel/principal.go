@@ -0,0 +1,48 @@
+package pmodel
+
+import "errors"
+
+// ErrAccountLinkConflict: email đã thuộc tài khoản khác nhưng provider chưa xác minh email nên không tự liên kết
+var ErrAccountLinkConflict = errors.New("email belongs to another account and is not verified by the provider")
+
+// Loại token đã xác thực principal
+const (
+	TokenTypeAppJWT   = "app_jwt"
+	TokenTypeFirebase = "firebase"
+	TokenTypeAPIKey   = "api_key"
+)
+
+// PrincipalLocalsKey là key lưu *Principal trong fiber.Ctx.Locals
+const PrincipalLocalsKey = "principal"
+
+// Principal là danh tính đã xác thực của request, dùng chung cho mọi cách đăng nhập
+type Principal struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	// EmailVerified cho biết provider đã xác minh email (dùng khi liên kết tài khoản theo email)
+	EmailVerified bool `json:"email_verified"`
+	// ProviderUserID là UID phía provider (ví dụ Firebase UID); ID có thể là customer ID sau khi provisioning
+	ProviderUserID string   `json:"provider_user_id,omitempty"`
+	Name           string   `json:"name,omitempty"`
+	Picture        string   `json:"picture,omitempty"`
+	Phone          string   `json:"phone,omitempty"`
+	Provider       string   `json:"provider"` // app, google, apple, facebook, phone, email, api_key...
+	Roles          []string `json:"roles"`
+	TokenType      string   `json:"token_type"`
+	SessionID      string   `json:"session_id,omitempty"`
+	TokenID        string   `json:"token_id,omitempty"`
+	MFA            bool     `json:"mfa"` // token đã qua xác thực 2FA (step-up)
+
+	// Token là token đã xác thực gốc (*middleware.ClaimsStringID, *auth.Token...)
+	Token interface{} `json:"-"`
+}
+
+// HasRole kiểm tra principal có role không
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -7,5 +7,6 @@ type Rule struct {
 	Name       string `json:"name"`
 	IsPrivate  bool   `json:"is_private"`
 	AccessType int    `json:"access_type"` // 1: allow, 2: forbid, 3: allow_all, 4: forbid_all
-	Roles      []int  `json:"roles"`       // Danh sách role IDs
+	RequireMFA bool   `json:"require_mfa"`
+	Roles      []int  `json:"roles"` // Danh sách role IDs
 }
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository handles database operations for API keys
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository instance
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// GetRolesByNames tìm role theo tên (không phân biệt hoa thường)
+func (r *APIKeyRepository) GetRolesByNames(ctx context.Context, names []string) ([]models.Role, error) {
+	lower := make([]string, 0, len(names))
+	for _, name := range names {
+		lower = append(lower, strings.ToLower(strings.TrimSpace(name)))
+	}
+	var roles []models.Role
+	err := conn(ctx, r.db).Where("LOWER(name) IN ?", lower).Find(&roles).Error
+	return roles, err
+}
+
+// GetUserRoleIDs trả về ID các role đang gán cho user trong user_roles
+func (r *APIKeyRepository) GetUserRoleIDs(ctx context.Context, userID string) ([]int, error) {
+	var ids []int
+	err := conn(ctx, r.db).Model(&models.UserRole{}).Where("user_id = ?", userID).Pluck("role_id", &ids).Error
+	return ids, err
+}
+
+// Create lưu API key cùng các role gắn kèm
+func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	return conn(ctx, r.db).Omit("Roles.*").Create(key).Error
+}
+
+// GetByHash tìm API key theo hash, kèm role; nil nếu không có
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	return r.find(ctx, "key_hash = ?", keyHash)
+}
+
+// GetByID tìm API key theo ID, kèm role; nil nếu không có
+func (r *APIKeyRepository) GetByID(ctx context.Context, id string) (*models.APIKey, error) {
+	return r.find(ctx, "id = ?", id)
+}
+
+func (r *APIKeyRepository) find(ctx context.Context, query string, args ...interface{}) (*models.APIKey, error) {
+	var key models.APIKey
+	tx := conn(ctx, r.db).Preload("Roles").Where(query, args...).First(&key)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &key, nil
+}
+
+// List trả về API key, mới nhất trước; ownerID rỗng thì lấy tất cả
+func (r *APIKeyRepository) List(ctx context.Context, ownerID string, includeRevoked bool) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	query := conn(ctx, r.db).Preload("Roles")
+	if ownerID != "" {
+		query = query.Where("owner_id = ?", ownerID)
+	}
+	if !includeRevoked {
+		query = query.Where("revoked_at IS NULL")
+	}
+	err := query.Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke thu hồi API key; trả về false nếu key không tồn tại hoặc đã bị thu hồi
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// RecordUsage cập nhật last-used và ghi audit log của một request trong cùng transaction
+func (r *APIKeyRepository) RecordUsage(ctx context.Context, entry *models.APIKeyAuditLog) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.APIKey{}).Where("id = ?", entry.APIKeyID).Updates(map[string]interface{}{
+			"last_used_at": time.Now(),
+			"last_used_ip": entry.IPAddress,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(entry).Error
+	})
+}
+
+// ListAuditLogs trả về audit log của key, mới nhất trước
+func (r *APIKeyRepository) ListAuditLogs(ctx context.Context, keyID string, limit, offset int) ([]models.APIKeyAuditLog, int64, error) {
+	var logs []models.APIKeyAuditLog
+	var total int64
+	query := readConn(ctx, r.db).Model(&models.APIKeyAuditLog{}).Where("api_key_id = ?", keyID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&logs).Error
+	return logs, total, err
+}
+
+// DeleteAuditLogsBefore xóa audit log cũ hơn before
+func (r *APIKeyRepository) DeleteAuditLogsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("created_at < ?", before).Delete(&models.APIKeyAuditLog{})
+	return result.RowsAffected, result.Error
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+
 	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
 
 	"gorm.io/gorm"
@@ -17,9 +19,9 @@ func NewAudioRepository(db *gorm.DB) *AudioRepository {
 }
 
 // GetAudiosByDialogID retrieves all audios for a specific dialog
-func (r *AudioRepository) GetAudiosByDialogID(dialogID string) ([]models.Audio, error) {
+func (r *AudioRepository) GetAudiosByDialogID(ctx context.Context, dialogID string) ([]models.Audio, error) {
 	var audios []models.Audio
-	err := r.db.Where("dialog_id = ?", dialogID).Find(&audios).Error
+	err := conn(ctx, r.db).Where("dialog_id = ?", dialogID).Find(&audios).Error
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuthProviderRepository handles database operations for customer sign-in providers
+type AuthProviderRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthProviderRepository creates a new AuthProviderRepository instance
+func NewAuthProviderRepository(db *gorm.DB) *AuthProviderRepository {
+	return &AuthProviderRepository{db: db}
+}
+
+// GetByProviderUserID tìm liên kết theo provider và UID phía provider; nil nếu chưa có
+func (r *AuthProviderRepository) GetByProviderUserID(ctx context.Context, provider, providerUserID string) (*models.AuthProvider, error) {
+	var link models.AuthProvider
+	tx := conn(ctx, r.db).Where("provider = ? AND provider_user_id = ?", provider, providerUserID).First(&link)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &link, nil
+}
+
+// ListByCustomer trả về các provider đã liên kết với customer
+func (r *AuthProviderRepository) ListByCustomer(ctx context.Context, customerID string) ([]models.AuthProvider, error) {
+	var links []models.AuthProvider
+	err := conn(ctx, r.db).Where("customer_id = ?", customerID).Order("created_at").Find(&links).Error
+	return links, err
+}
+
+// GetCustomerByID tìm customer theo ID; nil nếu không có
+func (r *AuthProviderRepository) GetCustomerByID(ctx context.Context, id string) (*models.Customer, error) {
+	return r.findCustomer(ctx, "id = ?", id)
+}
+
+// GetCustomerByEmail tìm customer theo email (không phân biệt hoa thường); nil nếu không có
+func (r *AuthProviderRepository) GetCustomerByEmail(ctx context.Context, email string) (*models.Customer, error) {
+	return r.findCustomer(ctx, "LOWER(email) = LOWER(?)", email)
+}
+
+// CustomerNameExists kiểm tra tên hiển thị đã được dùng chưa (customers.name là unique)
+func (r *AuthProviderRepository) CustomerNameExists(ctx context.Context, name string) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&models.Customer{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *AuthProviderRepository) findCustomer(ctx context.Context, query string, args ...interface{}) (*models.Customer, error) {
+	var customer models.Customer
+	tx := conn(ctx, r.db).Where(query, args...).First(&customer)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &customer, nil
+}
+
+// Link thêm provider cho customer đã có. Bỏ qua nếu liên kết (provider, provider_user_id) đã tồn tại.
+func (r *AuthProviderRepository) Link(ctx context.Context, link *models.AuthProvider) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "provider"}, {Name: "provider_user_id"}},
+		DoNothing: true,
+	}).Create(link).Error
+}
+
+// CreateCustomer tạo customer, liên kết provider đầu tiên và gán role mặc định trong một transaction.
+// roleName rỗng hoặc role chưa tồn tại thì bỏ qua bước gán role.
+func (r *AuthProviderRepository) CreateCustomer(ctx context.Context, customer *models.Customer, link *models.AuthProvider, roleName string) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(customer).Error; err != nil {
+			return err
+		}
+		link.CustomerID = customer.ID
+		if err := tx.Create(link).Error; err != nil {
+			return err
+		}
+		if roleName == "" {
+			return nil
+		}
+		var role models.Role
+		if err := tx.Where("name = ?", roleName).First(&role).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserRole{UserID: customer.ID, RoleID: role.ID}).Error
+	})
+}
+
+// TouchLastUsed cập nhật thời điểm đăng nhập gần nhất qua provider và của customer
+func (r *AuthProviderRepository) TouchLastUsed(ctx context.Context, linkID, customerID string) error {
+	now := time.Now()
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AuthProvider{}).Where("id = ?", linkID).Update("last_used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Customer{}).Where("id = ?", customerID).Update("last_login", now).Error
+	})
+}
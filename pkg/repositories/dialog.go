@@ -1,76 +1,228 @@
 package repositories
 
 import (
+	"context"
+	"fmt"
+	"slices"
+
 	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DialogRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	trashRepo *TrashRepository
 }
 
-// NewDialogRepository creates a new DialogRepository instance
-func NewDialogRepository(db *gorm.DB) *DialogRepository {
-	return &DialogRepository{db: db}
+// NewDialogRepository creates a new DialogRepository instance; trashRepo dùng cho xoá mềm dialog
+func NewDialogRepository(db *gorm.DB, trashRepo *TrashRepository) *DialogRepository {
+	return &DialogRepository{db: db, trashRepo: trashRepo}
 }
 
 // GetDialog retrieves a dialog by ID
-func (r *DialogRepository) GetDialog(id string) (*models.Dialog, error) {
+func (r *DialogRepository) GetDialog(ctx context.Context, id string) (*models.Dialog, error) {
 	var dialog models.Dialog
-	err := r.db.First(&dialog, "id = ?", id).Error
+	err := conn(ctx, r.db).First(&dialog, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &dialog, nil
 }
 // GetAllDialogs retrieves all dialogs
-func (r *DialogRepository) GetAllDialogs() ([]models.Dialog, error) {
+func (r *DialogRepository) GetAllDialogs(ctx context.Context) ([]models.Dialog, error) {
 	var dialogs []models.Dialog
-	err := r.db.Find(&dialogs).Error
+	err := conn(ctx, r.db).Find(&dialogs).Error
 	if err != nil {
 		return nil, err
 	}
 	return dialogs, nil
 }
-func (r *DialogRepository) GetFirstDialogByTopicID(topicID string) (*models.Dialog, error) {
+func (r *DialogRepository) GetFirstDialogByTopicID(ctx context.Context, topicID string) (*models.Dialog, error) {
 	var dialog models.Dialog
-	err := r.db.Where("topic_id = ? AND (prev_id IS NULL OR prev_id = '')", topicID).First(&dialog).Error
+	err := conn(ctx, r.db).Where("topic_id = ? AND (prev_id IS NULL OR prev_id = '')", topicID).First(&dialog).Error
 	if err != nil {
 		return nil, err
 	}
 	return &dialog, nil
 }
-func (r *DialogRepository) GetDialogTitleByKeyword(keyword string) ([]models.Dialog, error) {
+func (r *DialogRepository) GetAllDialogsByTopicID(ctx context.Context, topicID string) ([]models.Dialog, error) {
 	var dialogs []models.Dialog
-	err := r.db.Where("LOWER(title) LIKE LOWER(?)", "%"+keyword+"%").Limit(10).Order("title ASC").Find(&dialogs).Error
+	err := conn(ctx, r.db).Where("topic_id = ?", topicID).Find(&dialogs).Error
 	if err != nil {
 		return nil, err
 	}
 	return dialogs, nil
 }
-// GetDialogsRawTextKeyword retrieves all dialogs containing the keyword in raw_text (case-insensitive)
-func (r *DialogRepository) GetDialogsRawTextKeyword(keyword string) ([]models.Dialog, error) {
-	var dialogs []models.Dialog
-	err := r.db.Where("LOWER(raw_text) LIKE LOWER(?)", "%"+keyword+"%").Find(&dialogs).Error
+func (r *DialogRepository) GetTagsByDialogID(ctx context.Context, dialogID string) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := conn(ctx, r.db).Joins("JOIN dialog_tags ON dialog_tags.tag_id = tags.id").
+		Where("dialog_tags.dialog_id = ?", dialogID).Find(&tags).Error
 	if err != nil {
 		return nil, err
 	}
-	return dialogs, nil
+	return tags, nil
 }
-func (r *DialogRepository) GetAllDialogsByTopicID(topicID string) ([]models.Dialog, error) {
-	var dialogs []models.Dialog
-	err := r.db.Where("topic_id = ?", topicID).Find(&dialogs).Error
+
+// CreateDialog inserts a new dialog
+func (r *DialogRepository) CreateDialog(ctx context.Context, dialog *models.Dialog) error {
+	return conn(ctx, r.db).Create(dialog).Error
+}
+
+// UpdateDialogFields cập nhật các cột của dialog; gorm.ErrRecordNotFound nếu dialog không tồn tại
+func (r *DialogRepository) UpdateDialogFields(ctx context.Context, id string, fields map[string]interface{}) error {
+	res := conn(ctx, r.db).Model(&models.Dialog{}).Where("id = ?", id).Updates(fields)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateDialogLinks ghi prev_id/next_id của dialog (nil là không có)
+func (r *DialogRepository) UpdateDialogLinks(ctx context.Context, id string, prevID, nextID *string) error {
+	return r.UpdateDialogFields(ctx, id, map[string]interface{}{"prev_id": prevID, "next_id": nextID})
+}
+
+// MoveDialogToTopic đổi topic của dialog từ sourceID sang targetID, cập nhật cột topic_id sao chép
+// trong dialog_completions và chuyển dialog trong completed_dialog_ids của tiến độ topic
+func (r *DialogRepository) MoveDialogToTopic(ctx context.Context, id, sourceID, targetID string) error {
+	if err := r.UpdateDialogFields(ctx, id, map[string]interface{}{"topic_id": targetID}); err != nil {
+		return err
+	}
+	if err := conn(ctx, r.db).Model(&models.DialogCompletion{}).Where("dialog_id = ?", id).
+		Update("topic_id", targetID).Error; err != nil {
+		return err
+	}
+	return r.moveCompletedDialog(ctx, id, sourceID, targetID)
+}
+
+// moveCompletedDialog bỏ dialog khỏi tiến độ topic nguồn của mọi customer đã học xong nó và thêm vào
+// tiến độ topic đích (tạo mới nếu customer chưa có, giữ last_updated của bản nguồn)
+func (r *DialogRepository) moveCompletedDialog(ctx context.Context, id, sourceID, targetID string) error {
+	db := conn(ctx, r.db)
+	var sources []models.CustomerTopicProgress
+	// Lọc thô trên JSON rồi kiểm tra chính xác bên dưới
+	if err := db.Where("topic_id = ? AND completed_dialog_ids LIKE ?", sourceID, `%"`+id+`"%`).
+		Find(&sources).Error; err != nil {
+		return err
+	}
+	for _, source := range sources {
+		if !slices.Contains(source.CompletedDialogIDs, id) {
+			continue
+		}
+		source.CompletedDialogIDs = slices.DeleteFunc(source.CompletedDialogIDs, func(v string) bool { return v == id })
+		if err := db.Model(&source).Select("completed_dialog_ids").Updates(&source).Error; err != nil {
+			return err
+		}
+
+		var targets []models.CustomerTopicProgress
+		if err := db.Where("customer_id = ? AND topic_id = ?", source.CustomerID, targetID).
+			Limit(1).Find(&targets).Error; err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			target := models.CustomerTopicProgress{CustomerID: source.CustomerID, TopicID: targetID,
+				CompletedDialogIDs: []string{id}, LastUpdated: source.LastUpdated}
+			if err := db.Create(&target).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		target := targets[0]
+		if slices.Contains(target.CompletedDialogIDs, id) {
+			continue
+		}
+		target.CompletedDialogIDs = append(target.CompletedDialogIDs, id)
+		if err := db.Model(&target).Select("completed_dialog_ids").Updates(&target).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SoftDeleteDialog chuyển dialog cùng image, comment của nó vào thùng rác và nối dialog trước với dialog sau
+func (r *DialogRepository) SoftDeleteDialog(ctx context.Context, id string) error {
+	_, err := r.trashRepo.SoftDelete(ctx, models.TrashDialog, id)
+	return err
+}
+
+// chainColumns là các cột cần để dựng danh sách liên kết
+var chainColumns = []string{"id", "topic_id", "prev_id", "next_id"}
+
+// LockTopicChain khoá topic và các dialog của nó (FOR UPDATE) rồi trả về liên kết của các dialog.
+// Phải gọi trong transaction; gorm.ErrRecordNotFound nếu topic không tồn tại.
+func (r *DialogRepository) LockTopicChain(ctx context.Context, topicID string) ([]models.Dialog, error) {
+	db := conn(ctx, r.db)
+	var topic models.Topic
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Take(&topic, "id = ?", topicID).Error; err != nil {
+		return nil, err
+	}
+	var links []models.Dialog
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Select(chainColumns).
+		Where("topic_id = ?", topicID).Order("id").Find(&links).Error
 	if err != nil {
 		return nil, err
 	}
-	return dialogs, nil
+	return links, nil
 }
-func (r *DialogRepository) GetTagsByDialogID(dialogID string) ([]models.Tag, error) {
-	var tags []models.Tag
-	err := r.db.Joins("JOIN dialog_tags ON dialog_tags.tag_id = tags.id").
-		Where("dialog_tags.dialog_id = ?", dialogID).Find(&tags).Error
+
+// GetTopicChain trả về liên kết của các dialog trong topic (không khoá)
+func (r *DialogRepository) GetTopicChain(ctx context.Context, topicID string) ([]models.Dialog, error) {
+	var links []models.Dialog
+	err := conn(ctx, r.db).Select(chainColumns).Where("topic_id = ?", topicID).Order("id").Find(&links).Error
 	if err != nil {
 		return nil, err
 	}
-	return tags, nil
+	return links, nil
+}
+
+// GetAllChains trả về liên kết của mọi dialog, sắp theo topic
+func (r *DialogRepository) GetAllChains(ctx context.Context) ([]models.Dialog, error) {
+	var links []models.Dialog
+	err := readConn(ctx, r.db).Select(chainColumns).Order("topic_id, id").Find(&links).Error
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// LevelExists kiểm tra level gán cho dialog có tồn tại
+func (r *DialogRepository) LevelExists(ctx context.Context, id string) (bool, error) {
+	return NewLevelRepository(r.db).LevelExists(ctx, id)
+}
+
+// dialogSearchQuery tìm dialog thuộc topic public theo một cột (title hoặc raw_text), kèm độ liên quan và snippet
+const dialogSearchQuery = `SELECT dialogs.id AS dialog_id, COALESCE(dialogs.prev_id, '') AS prev_id,
+	COALESCE(dialogs.next_id, '') AS next_id, dialogs.title AS dialog_name, dialogs.topic_id,
+	ts_rank(to_tsvector('%[1]s', dialogs.%[2]s), q) AS rank,
+	ts_headline('%[1]s', dialogs.%[2]s, q, ?) AS snippet
+FROM dialogs
+JOIN topics ON topics.id = dialogs.topic_id AND topics.deleted_at IS NULL AND topics.visibility = ?
+CROSS JOIN to_tsquery('%[1]s', ?) q
+WHERE dialogs.deleted_at IS NULL AND to_tsvector('%[1]s', dialogs.%[2]s) @@ to_tsquery('%[1]s', ?)
+ORDER BY rank DESC, dialogs.title
+LIMIT ?`
+
+// SearchDialogTitles tìm dialog theo tiêu đề (khớp tiền tố, không phân biệt dấu), liên quan nhất trước
+func (r *DialogRepository) SearchDialogTitles(ctx context.Context, keyword string, limit int) ([]models.DialogResponse, error) {
+	return r.searchDialogs(ctx, "title", titleHeadlineOptions, keyword, limit)
+}
+
+// SearchDialogRawText tìm dialog theo nội dung hội thoại; snippet là các đoạn quanh từ khớp
+func (r *DialogRepository) SearchDialogRawText(ctx context.Context, keyword string, limit int) ([]models.DialogResponse, error) {
+	return r.searchDialogs(ctx, "raw_text", textHeadlineOptions, keyword, limit)
+}
+
+func (r *DialogRepository) searchDialogs(ctx context.Context, column, headline, keyword string, limit int) ([]models.DialogResponse, error) {
+	results := []models.DialogResponse{}
+	query := prefixQuery(keyword)
+	if query == "" {
+		return results, nil
+	}
+	sql := fmt.Sprintf(dialogSearchQuery, searchConfig, column)
+	err := readConn(ctx, r.db).Raw(sql, headline, models.TopicVisibilityPublic, query, query, limit).Scan(&results).Error
+	return results, err
 }
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+)
+
+func TestMoveDialogToTopic(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	repo := NewDialogRepository(db, NewTrashRepository(db))
+	seedTopic(t, db, "T_src", "D_move", "D_stay")
+	seedTopic(t, db, "T_dst", "D_dst")
+	mustCreate(t, db,
+		&models.Customer{ID: "C_both", Name: "Both"},
+		&models.Customer{ID: "C_src", Name: "Source only"},
+		&models.Customer{ID: "C_other", Name: "Not completed"},
+		&models.CustomerTopicProgress{CustomerID: "C_both", TopicID: "T_src", CompletedDialogIDs: []string{"D_move", "D_stay"}, LastUpdated: 5},
+		&models.CustomerTopicProgress{CustomerID: "C_both", TopicID: "T_dst", CompletedDialogIDs: []string{"D_dst"}, LastUpdated: 6},
+		&models.CustomerTopicProgress{CustomerID: "C_src", TopicID: "T_src", CompletedDialogIDs: []string{"D_move"}, LastUpdated: 7},
+		&models.CustomerTopicProgress{CustomerID: "C_other", TopicID: "T_src", CompletedDialogIDs: []string{"D_stay"}, LastUpdated: 8},
+		&models.DialogCompletion{CustomerID: "C_src", DialogID: "D_move", TopicID: "T_src", ListeningCompleted: true},
+	)
+
+	if err := repo.MoveDialogToTopic(ctx, "D_move", "T_src", "T_dst"); err != nil {
+		t.Fatal(err)
+	}
+
+	progress := func(customerID, topicID string) *models.CustomerTopicProgress {
+		t.Helper()
+		var rows []models.CustomerTopicProgress
+		if err := db.Where("customer_id = ? AND topic_id = ?", customerID, topicID).Find(&rows).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return &rows[0]
+	}
+	cases := []struct {
+		customer, topic string
+		want            []string
+	}{
+		{"C_both", "T_src", []string{"D_stay"}},
+		{"C_both", "T_dst", []string{"D_dst", "D_move"}},
+		{"C_src", "T_src", []string{}},
+		{"C_src", "T_dst", []string{"D_move"}},
+		{"C_other", "T_src", []string{"D_stay"}},
+	}
+	for _, tc := range cases {
+		p := progress(tc.customer, tc.topic)
+		if p == nil {
+			t.Errorf("Expected progress for %s in %s", tc.customer, tc.topic)
+			continue
+		}
+		if !reflect.DeepEqual(p.CompletedDialogIDs, tc.want) {
+			t.Errorf("Expected %s completed dialogs in %s to be %v, got %v", tc.customer, tc.topic, tc.want, p.CompletedDialogIDs)
+		}
+	}
+	if p := progress("C_src", "T_dst"); p != nil && p.LastUpdated != 7 {
+		t.Errorf("Expected created progress to keep last_updated 7, got %d", p.LastUpdated)
+	}
+	if p := progress("C_other", "T_dst"); p != nil {
+		t.Errorf("Expected no progress created for a customer who had not completed the dialog, got %+v", p)
+	}
+
+	var completion models.DialogCompletion
+	if err := db.Where("customer_id = ? AND dialog_id = ?", "C_src", "D_move").First(&completion).Error; err != nil {
+		t.Fatal(err)
+	}
+	if completion.TopicID != "T_dst" {
+		t.Errorf("Expected dialog completion topic T_dst, got %s", completion.TopicID)
+	}
+}
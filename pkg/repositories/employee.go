@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+// EmployeeRepository handles database operations for employee authentication
+type EmployeeRepository struct {
+	db *gorm.DB
+}
+
+// NewEmployeeRepository creates a new EmployeeRepository instance
+func NewEmployeeRepository(db *gorm.DB) *EmployeeRepository {
+	return &EmployeeRepository{db: db}
+}
+
+// GetByEmail tìm employee theo email (không phân biệt hoa thường); nil nếu không có
+func (r *EmployeeRepository) GetByEmail(ctx context.Context, email string) (*models.Employee, error) {
+	return r.find(ctx, "LOWER(email) = LOWER(?)", email)
+}
+
+// GetByID tìm employee theo ID; nil nếu không có
+func (r *EmployeeRepository) GetByID(ctx context.Context, id string) (*models.Employee, error) {
+	return r.find(ctx, "id = ?", id)
+}
+
+func (r *EmployeeRepository) find(ctx context.Context, query string, args ...interface{}) (*models.Employee, error) {
+	var employee models.Employee
+	tx := conn(ctx, r.db).Where(query, args...).First(&employee)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &employee, nil
+}
+
+// RecordFailedLogin tăng bộ đếm đăng nhập sai; đạt maxAttempts thì khóa tới lockUntil và reset bộ đếm.
+// Cập nhật bằng một câu lệnh để các request song song không làm mất lượt đếm.
+func (r *EmployeeRepository) RecordFailedLogin(ctx context.Context, id string, maxAttempts int, lockUntil time.Time) error {
+	return conn(ctx, r.db).Exec(`
+		UPDATE employees SET
+			locked_until = CASE WHEN failed_login_attempts + 1 >= ? THEN ? ELSE locked_until END,
+			failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= ? THEN 0 ELSE failed_login_attempts + 1 END
+		WHERE id = ?
+	`, maxAttempts, lockUntil, maxAttempts, id).Error
+}
+
+// RecordSuccessfulLogin xóa trạng thái khóa và cập nhật last_login; passwordHash khác rỗng thì lưu hash mới (rehash)
+func (r *EmployeeRepository) RecordSuccessfulLogin(ctx context.Context, id, passwordHash string) error {
+	updates := map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+		"last_login":            time.Now(),
+	}
+	if passwordHash != "" {
+		updates["password"] = passwordHash
+	}
+	return conn(ctx, r.db).Model(&models.Employee{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UpdatePassword lưu hash mật khẩu mới và mở khóa tài khoản
+func (r *EmployeeRepository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	return conn(ctx, r.db).Model(&models.Employee{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"password":              passwordHash,
+		"password_changed_at":   time.Now(),
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+	}).Error
+}
+
+// CreateResetToken lưu reset token mới và vô hiệu các token chưa dùng trước đó của employee
+func (r *EmployeeRepository) CreateResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("employee_id = ? AND used_at IS NULL", token.EmployeeID).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// ConsumeResetToken đánh dấu token đã dùng và đặt mật khẩu mới trong cùng transaction.
+// Trả về employee ID, hoặc "" nếu token không tồn tại, đã dùng hoặc đã hết hạn.
+func (r *EmployeeRepository) ConsumeResetToken(ctx context.Context, tokenHash, passwordHash string) (string, error) {
+	var employeeID string
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var token models.PasswordResetToken
+		if err := tx.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+		now := time.Now()
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.Employee{}).Where("id = ?", token.EmployeeID).Updates(map[string]interface{}{
+			"password":              passwordHash,
+			"password_changed_at":   now,
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+		}).Error; err != nil {
+			return err
+		}
+		employeeID = token.EmployeeID
+		return nil
+	})
+	return employeeID, err
+}
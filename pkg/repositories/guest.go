@@ -0,0 +1,248 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProgressSnapshot là toàn bộ tiến độ học của một customer
+type ProgressSnapshot struct {
+	TopicProgress     []models.CustomerTopicProgress
+	DialogCompletions []models.DialogCompletion
+	Achievements      []models.CustomerAchievement
+	Statistics        *models.CustomerStatistics
+}
+
+// ProgressMergeFunc gộp tiến độ của guest vào tiến độ của customer đích
+type ProgressMergeFunc func(guest, target *ProgressSnapshot) *ProgressSnapshot
+
+// GuestRepository handles database operations for guest accounts
+type GuestRepository struct {
+	db *gorm.DB
+}
+
+// NewGuestRepository creates a new GuestRepository instance
+func NewGuestRepository(db *gorm.DB) *GuestRepository {
+	return &GuestRepository{db: db}
+}
+
+// ErrGuestRoleMissing trả về khi role gán cho tài khoản khách chưa có trong bảng roles
+var ErrGuestRoleMissing = errors.New("guest role does not exist")
+
+// CreateGuest tạo customer khách và gán role trong một transaction.
+// Role bắt buộc phải tồn tại: guest không có role sẽ không bị RBAC giới hạn đúng cách.
+func (r *GuestRepository) CreateGuest(ctx context.Context, customer *models.Customer, roleName string) error {
+	if roleName == "" {
+		return ErrGuestRoleMissing
+	}
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var role models.Role
+		if err := tx.Where("name = ?", roleName).First(&role).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrGuestRoleMissing
+			}
+			return err
+		}
+		if err := tx.Create(customer).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserRole{UserID: customer.ID, RoleID: role.ID}).Error
+	})
+}
+
+// GetCustomer tìm customer theo ID; nil nếu không có
+func (r *GuestRepository) GetCustomer(ctx context.Context, id string) (*models.Customer, error) {
+	var customer models.Customer
+	tx := conn(ctx, r.db).Where("id = ?", id).First(&customer)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &customer, nil
+}
+
+// MergeInto gộp tiến độ, thống kê và thành tựu của guest vào targetID rồi xóa tài khoản guest.
+// Mọi thay đổi nằm trong một transaction; trả về false nếu guestID không còn là tài khoản khách.
+func (r *GuestRepository) MergeInto(ctx context.Context, guestID, targetID string, merge ProgressMergeFunc) (bool, error) {
+	merged := false
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var guest models.Customer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND is_guest = ?", guestID, true).First(&guest).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+
+		guestData, err := loadProgress(tx, guestID)
+		if err != nil {
+			return err
+		}
+		targetData, err := loadProgress(tx, targetID)
+		if err != nil {
+			return err
+		}
+		result := merge(guestData, targetData)
+
+		if err := deleteProgress(tx, guestID); err != nil {
+			return err
+		}
+		if err := deleteProgress(tx, targetID); err != nil {
+			return err
+		}
+		if err := saveProgress(tx, targetID, result); err != nil {
+			return err
+		}
+		if err := saveStatistics(tx, targetID, guestData.Statistics, targetData.Statistics, result.Statistics); err != nil {
+			return err
+		}
+
+		// Comment của guest chuyển sang customer đích thay vì bị xoá
+		if err := tx.Unscoped().Model(&models.Comment{}).Where("user_id = ?", guestID).
+			Update("user_id", targetID).Error; err != nil {
+			return err
+		}
+		if _, err := deleteAccounts(tx, []string{guestID}); err != nil {
+			return err
+		}
+		merged = true
+		return nil
+	})
+	return merged, err
+}
+
+// DeleteInactiveGuests xóa tài khoản khách không hoạt động từ trước before
+func (r *GuestRepository) DeleteInactiveGuests(ctx context.Context, before time.Time) (int64, error) {
+	var ids []string
+	if err := conn(ctx, r.db).Model(&models.Customer{}).
+		Where("is_guest = ? AND COALESCE(last_login, created_at) < ?", true, before).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var deleted int64
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			if err := deleteProgress(tx, id); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("customer_id IN ?", ids).Delete(&models.CustomerStatistics{}).Error; err != nil {
+			return err
+		}
+		// Giữ comment (có thể đã có trả lời) nhưng bỏ liên kết tới tài khoản bị xoá
+		if err := tx.Unscoped().Model(&models.Comment{}).Where("user_id IN ?", ids).
+			Update("user_id", nil).Error; err != nil {
+			return err
+		}
+		var err error
+		deleted, err = deleteAccounts(tx, ids)
+		return err
+	})
+	return deleted, err
+}
+
+// deleteAccounts xoá tài khoản khách cùng refresh token, session và role của nó.
+// Khoá ngoại tới customers không có ON DELETE nên các bảng phụ phải xoá trước.
+func deleteAccounts(tx *gorm.DB, ids []string) (int64, error) {
+	if err := tx.Where("customer_id IN ?", ids).Delete(&models.RefreshToken{}).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Where("customer_id IN ?", ids).Delete(&models.Session{}).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Where("user_id IN ?", ids).Delete(&models.UserRole{}).Error; err != nil {
+		return 0, err
+	}
+	result := tx.Where("id IN ? AND is_guest = ?", ids, true).Delete(&models.Customer{})
+	return result.RowsAffected, result.Error
+}
+
+func loadProgress(tx *gorm.DB, customerID string) (*ProgressSnapshot, error) {
+	data := &ProgressSnapshot{}
+	if err := tx.Where("customer_id = ?", customerID).Find(&data.TopicProgress).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("customer_id = ?", customerID).Find(&data.DialogCompletions).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("customer_id = ?", customerID).Find(&data.Achievements).Error; err != nil {
+		return nil, err
+	}
+	var stats models.CustomerStatistics
+	if err := tx.Where("customer_id = ?", customerID).Limit(1).Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	if stats.ID != "" {
+		data.Statistics = &stats
+	}
+	return data, nil
+}
+
+func deleteProgress(tx *gorm.DB, customerID string) error {
+	if err := tx.Where("customer_id = ?", customerID).Delete(&models.CustomerTopicProgress{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("customer_id = ?", customerID).Delete(&models.DialogCompletion{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("customer_id = ?", customerID).Delete(&models.CustomerAchievement{}).Error
+}
+
+func saveProgress(tx *gorm.DB, customerID string, data *ProgressSnapshot) error {
+	for i := range data.TopicProgress {
+		data.TopicProgress[i].CustomerID = customerID
+	}
+	for i := range data.DialogCompletions {
+		data.DialogCompletions[i].CustomerID = customerID
+	}
+	for i := range data.Achievements {
+		data.Achievements[i].CustomerID = customerID
+	}
+	if len(data.TopicProgress) > 0 {
+		if err := tx.Omit(clause.Associations).Create(&data.TopicProgress).Error; err != nil {
+			return err
+		}
+	}
+	if len(data.DialogCompletions) > 0 {
+		if err := tx.Omit(clause.Associations).Create(&data.DialogCompletions).Error; err != nil {
+			return err
+		}
+	}
+	if len(data.Achievements) > 0 {
+		if err := tx.Omit(clause.Associations).Create(&data.Achievements).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveStatistics giữ nguyên hàng thống kê của customer đích nếu có, ngược lại chuyển hàng của guest sang
+func saveStatistics(tx *gorm.DB, targetID string, guest, target, merged *models.CustomerStatistics) error {
+	if merged == nil {
+		return nil
+	}
+	if target == nil && guest != nil {
+		merged.ID = guest.ID
+	} else if target != nil {
+		merged.ID = target.ID
+		if guest != nil {
+			if err := tx.Delete(&models.CustomerStatistics{}, "id = ?", guest.ID).Error; err != nil {
+				return err
+			}
+		}
+	}
+	merged.CustomerID = targetID
+	return tx.Omit(clause.Associations).Save(merged).Error
+}
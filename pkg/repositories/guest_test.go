@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+// seedGuest tạo guest có refresh token, session, comment và tiến độ ở topic như sau khi CreateGuest cấp token
+func seedGuest(t *testing.T, db *gorm.DB, id, topicID, dialogID string, lastLogin time.Time) {
+	t.Helper()
+	mustCreate(t, db,
+		&models.Customer{ID: id, Name: id, IsGuest: true, LastLogin: &lastLogin},
+		&models.Session{ID: "S" + id[len(id)-4:], CustomerID: id, DeviceID: "device-" + id},
+		&models.RefreshToken{ID: "R" + id[len(id)-4:], CustomerID: id, FamilyID: "F" + id[len(id)-4:],
+			TokenHash: "hash-" + id, ExpiresAt: time.Now().Add(time.Hour)},
+		&models.Comment{ID: "C" + id[len(id)-4:], DialogID: dialogID, UserID: id, Content: "hello"},
+		&models.CustomerTopicProgress{CustomerID: id, TopicID: topicID, CompletedDialogIDs: []string{dialogID}, LastUpdated: 2},
+	)
+}
+
+// keepBoth gộp bằng cách giữ tiến độ của target và thêm tiến độ guest ở topic target chưa có
+func keepBoth(guest, target *ProgressSnapshot) *ProgressSnapshot {
+	result := &ProgressSnapshot{TopicProgress: target.TopicProgress}
+	seen := map[string]bool{}
+	for _, p := range target.TopicProgress {
+		seen[p.TopicID] = true
+	}
+	for _, p := range guest.TopicProgress {
+		if !seen[p.TopicID] {
+			result.TopicProgress = append(result.TopicProgress, p)
+		}
+	}
+	return result
+}
+
+func count(t *testing.T, db *gorm.DB, model interface{}, query string, args ...interface{}) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Unscoped().Model(model).Where(query, args...).Count(&n).Error; err != nil {
+		t.Fatalf("count %T: %v", model, err)
+	}
+	return n
+}
+
+func TestGuestMergeInto(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	repo := NewGuestRepository(db)
+	seedTopic(t, db, "T1", "D1")
+	mustCreate(t, db, &models.Customer{ID: "user_1", Name: "User 1"})
+	seedGuest(t, db, "guest_0001", "T1", "D1", time.Now())
+
+	merged, err := repo.MergeInto(ctx, "guest_0001", "user_1", keepBoth)
+	if err != nil {
+		t.Fatalf("MergeInto: %v", err)
+	}
+	if !merged {
+		t.Fatal("Expected guest to be merged")
+	}
+
+	if n := count(t, db, &models.Customer{}, "id = ?", "guest_0001"); n != 0 {
+		t.Errorf("Expected guest account deleted, found %d", n)
+	}
+	if n := count(t, db, &models.RefreshToken{}, "customer_id = ?", "guest_0001"); n != 0 {
+		t.Errorf("Expected guest refresh tokens deleted, found %d", n)
+	}
+	if n := count(t, db, &models.Session{}, "customer_id = ?", "guest_0001"); n != 0 {
+		t.Errorf("Expected guest sessions deleted, found %d", n)
+	}
+	if n := count(t, db, &models.Comment{}, "user_id = ?", "user_1"); n != 1 {
+		t.Errorf("Expected guest comment reassigned to target, found %d", n)
+	}
+	if n := count(t, db, &models.CustomerTopicProgress{}, "customer_id = ? AND topic_id = ?", "user_1", "T1"); n != 1 {
+		t.Errorf("Expected guest progress moved to target, found %d", n)
+	}
+
+	// Guest đã gộp thì không gộp lại được
+	if merged, err := repo.MergeInto(ctx, "guest_0001", "user_1", keepBoth); err != nil || merged {
+		t.Errorf("Expected second merge to be a no-op, got merged=%v err=%v", merged, err)
+	}
+}
+
+func TestGuestDeleteInactive(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	repo := NewGuestRepository(db)
+	seedTopic(t, db, "T1", "D1")
+	now := time.Now()
+	seedGuest(t, db, "guest_0001", "T1", "D1", now.Add(-48*time.Hour))
+	seedGuest(t, db, "guest_0002", "T1", "D1", now)
+
+	deleted, err := repo.DeleteInactiveGuests(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("DeleteInactiveGuests: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected 1 guest deleted, got %d", deleted)
+	}
+	if n := count(t, db, &models.Customer{}, "id = ?", "guest_0002"); n != 1 {
+		t.Error("Expected active guest to be kept")
+	}
+	for _, model := range []interface{}{&models.RefreshToken{}, &models.Session{}, &models.CustomerTopicProgress{}} {
+		if n := count(t, db, model, "customer_id = ?", "guest_0001"); n != 0 {
+			t.Errorf("Expected %T of inactive guest deleted, found %d", model, n)
+		}
+	}
+	// Comment được giữ lại nhưng không còn gắn với tài khoản đã xoá
+	if n := count(t, db, &models.Comment{}, "id = ? AND user_id IS NULL", "C0001"); n != 1 {
+		t.Error("Expected comment of inactive guest to be kept without user")
+	}
+}
+
+func TestCreateGuest(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	repo := NewGuestRepository(db)
+
+	t.Run("TestMissingRole", func(t *testing.T) {
+		err := repo.CreateGuest(ctx, &models.Customer{ID: "guest_0001", Name: "guest 1", IsGuest: true}, "no-such-role")
+		if !errors.Is(err, ErrGuestRoleMissing) {
+			t.Fatalf("Expected ErrGuestRoleMissing, got %v", err)
+		}
+		if n := count(t, db, &models.Customer{}, "id = ?", "guest_0001"); n != 0 {
+			t.Error("Expected no guest to be created without its role")
+		}
+	})
+
+	t.Run("TestAssignsRole", func(t *testing.T) {
+		role := models.Role{Name: "guest"}
+		if err := db.Where("name = ?", role.Name).FirstOrCreate(&role).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.CreateGuest(ctx, &models.Customer{ID: "guest_0002", Name: "guest 2", IsGuest: true}, "guest"); err != nil {
+			t.Fatalf("CreateGuest: %v", err)
+		}
+		if n := count(t, db, &models.UserRole{}, "user_id = ? AND role_id = ?", "guest_0002", role.ID); n != 1 {
+			t.Error("Expected guest role to be assigned")
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+// mustCreate lưu lần lượt các bản ghi, dừng test nếu lỗi
+func mustCreate(t *testing.T, db *gorm.DB, values ...interface{}) {
+	t.Helper()
+	for _, v := range values {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatalf("create %T: %v", v, err)
+		}
+	}
+}
+
+// seedTopic tạo một employee làm tác giả, topic topicID và các dialog nối theo thứ tự dialogIDs
+func seedTopic(t *testing.T, db *gorm.DB, topicID string, dialogIDs ...string) {
+	t.Helper()
+	author := models.Employee{ID: "E_" + topicID, Name: "Author " + topicID, Email: topicID + "@example.com"}
+	if err := db.FirstOrCreate(&author, "id = ?", author.ID).Error; err != nil {
+		t.Fatalf("create author: %v", err)
+	}
+	mustCreate(t, db, &models.Topic{ID: topicID, Title: "Topic " + topicID, Visibility: models.TopicVisibilityPublic})
+	for i, id := range dialogIDs {
+		d := &models.Dialog{ID: id, TopicID: topicID, Title: "Dialog " + id, RawText: "text of " + id, AuthorID: author.ID}
+		if i > 0 {
+			d.PrevID = &dialogIDs[i-1]
+		}
+		if i < len(dialogIDs)-1 {
+			d.NextID = &dialogIDs[i+1]
+		}
+		mustCreate(t, db, d)
+	}
+}
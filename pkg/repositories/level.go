@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+type LevelRepository struct {
+	db *gorm.DB
+}
+
+// NewLevelRepository creates a new LevelRepository instance
+func NewLevelRepository(db *gorm.DB) *LevelRepository {
+	return &LevelRepository{db: db}
+}
+
+// GetAllLevels lấy tất cả level theo thứ tự từ dễ tới khó
+func (r *LevelRepository) GetAllLevels(ctx context.Context) ([]models.Level, error) {
+	levels := []models.Level{}
+	err := conn(ctx, r.db).Order("rank, code").Find(&levels).Error
+	return levels, err
+}
+
+// GetLevel lấy level theo ID
+func (r *LevelRepository) GetLevel(ctx context.Context, id string) (*models.Level, error) {
+	var level models.Level
+	if err := conn(ctx, r.db).First(&level, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &level, nil
+}
+
+// LevelExists kiểm tra level có tồn tại
+func (r *LevelRepository) LevelExists(ctx context.Context, id string) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&models.Level{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// LevelCodeExists kiểm tra mã level đã được level khác dùng chưa
+func (r *LevelRepository) LevelCodeExists(ctx context.Context, code, excludeID string) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&models.Level{}).Where("code = ? AND id <> ?", code, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateLevel inserts a new level
+func (r *LevelRepository) CreateLevel(ctx context.Context, level *models.Level) error {
+	return conn(ctx, r.db).Create(level).Error
+}
+
+// UpdateLevelFields cập nhật các cột của level; gorm.ErrRecordNotFound nếu level không tồn tại
+func (r *LevelRepository) UpdateLevelFields(ctx context.Context, id string, fields map[string]interface{}) error {
+	res := conn(ctx, r.db).Model(&models.Level{}).Where("id = ?", id).Updates(fields)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteLevel xoá level và bỏ gán level khỏi topic, dialog (kể cả bản ghi trong thùng rác)
+func (r *LevelRepository) DeleteLevel(ctx context.Context, id string) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.Topic{}, &models.Dialog{}} {
+			if err := tx.Unscoped().Model(model).Where("level_id = ?", id).Update("level_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		res := tx.Delete(&models.Level{}, "id = ?", id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// levelProgressQuery đếm dialog theo level và số dialog customer đã học xong / đang học.
+// Level của dialog là level riêng của nó, nếu không có thì theo topic; chỉ tính topic public chưa xoá.
+const levelProgressQuery = `SELECT levels.id AS level_id, levels.code, levels.name, levels.rank,
+	COUNT(d.id) AS total_dialogs,
+	COUNT(dc.dialog_id) FILTER (WHERE dc.listening_completed AND dc.speaking_completed AND dc.writing_completed) AS completed_dialogs,
+	COUNT(dc.dialog_id) FILTER (WHERE NOT (dc.listening_completed AND dc.speaking_completed AND dc.writing_completed)
+		AND (dc.listening_completed OR dc.speaking_completed OR dc.writing_completed)) AS in_progress_dialogs
+FROM levels
+LEFT JOIN (
+	SELECT dialogs.id, COALESCE(dialogs.level_id, topics.level_id) AS level_id
+	FROM dialogs
+	JOIN topics ON topics.id = dialogs.topic_id AND topics.deleted_at IS NULL AND topics.visibility = ?
+	WHERE dialogs.deleted_at IS NULL
+) d ON d.level_id = levels.id
+LEFT JOIN dialog_completions dc ON dc.dialog_id = d.id AND dc.customer_id = ?
+GROUP BY levels.id
+ORDER BY levels.rank, levels.code`
+
+// GetLevelProgress trả về tiến độ của customer ở từng level (CompletionPercent do service tính)
+func (r *LevelRepository) GetLevelProgress(ctx context.Context, customerID string) ([]models.LevelProgress, error) {
+	progress := []models.LevelProgress{}
+	err := readConn(ctx, r.db).Raw(levelProgressQuery, models.TopicVisibilityPublic, customerID).Scan(&progress).Error
+	return progress, err
+}
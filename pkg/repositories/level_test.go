@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+// seedLevels tạo A1, A2, B1 và gán level cho topic: T_a1 (độ khó 3), T_a1_easy (độ khó 1), T_b1, T_none chưa gán.
+// Dialog D_b1_a2 thuộc topic B1 nhưng tự gán level A2.
+func seedLevels(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	mustCreate(t, db,
+		&models.Level{ID: "L_a1", Code: "A1", Name: "Beginner", Rank: 1},
+		&models.Level{ID: "L_a2", Code: "A2", Name: "Elementary", Rank: 2},
+		&models.Level{ID: "L_b1", Code: "B1", Name: "Intermediate", Rank: 3},
+	)
+	seedTopic(t, db, "T_a1", "D_a1")
+	seedTopic(t, db, "T_a1_easy", "D_a1_easy")
+	seedTopic(t, db, "T_b1", "D_b1", "D_b1_a2")
+	seedTopic(t, db, "T_none", "D_none")
+	for id, level := range map[string]string{"T_a1": "L_a1", "T_a1_easy": "L_a1", "T_b1": "L_b1"} {
+		if err := db.Model(&models.Topic{}).Where("id = ?", id).Update("level_id", level).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Model(&models.Topic{}).Where("id = ?", "T_a1").Update("difficulty", 3).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&models.Topic{}).Where("id = ?", "T_a1_easy").Update("difficulty", 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&models.Dialog{}).Where("id = ?", "D_b1_a2").Update("level_id", "L_a2").Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func topicIDs(items []models.TitleDialogResponse) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.TopicID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLevelRepository(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	repo := NewLevelRepository(db)
+	seedLevels(t, db)
+
+	t.Run("TestCRUD", func(t *testing.T) {
+		level := &models.Level{ID: "L_custom", Code: "KIDS", Name: "Kids", Rank: 10}
+		if err := repo.CreateLevel(ctx, level); err != nil {
+			t.Fatal(err)
+		}
+		if exists, err := repo.LevelCodeExists(ctx, "KIDS", ""); err != nil || !exists {
+			t.Errorf("Expected code KIDS to exist, got %v, %v", exists, err)
+		}
+		if exists, err := repo.LevelCodeExists(ctx, "KIDS", "L_custom"); err != nil || exists {
+			t.Errorf("Expected code check to exclude the level itself, got %v, %v", exists, err)
+		}
+		if err := repo.UpdateLevelFields(ctx, "L_custom", map[string]interface{}{"name": "Young learners"}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.GetLevel(ctx, "L_custom")
+		if err != nil || got.Name != "Young learners" {
+			t.Errorf("Expected updated name, got %+v, %v", got, err)
+		}
+		if err := repo.UpdateLevelFields(ctx, "L_missing", map[string]interface{}{"name": "x"}); err != gorm.ErrRecordNotFound {
+			t.Errorf("Expected gorm.ErrRecordNotFound, got %v", err)
+		}
+
+		levels, err := repo.GetAllLevels(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(levels) != 4 || levels[0].Code != "A1" || levels[3].Code != "KIDS" {
+			t.Errorf("Expected levels ordered by rank, got %+v", levels)
+		}
+	})
+
+	t.Run("TestDeleteUnassigns", func(t *testing.T) {
+		mustCreate(t, db, &models.Level{ID: "L_tmp", Code: "TMP", Name: "Temp", Rank: 20})
+		seedTopic(t, db, "T_tmp", "D_tmp")
+		if err := db.Model(&models.Topic{}).Where("id = ?", "T_tmp").Update("level_id", "L_tmp").Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Model(&models.Dialog{}).Where("id = ?", "D_tmp").Update("level_id", "L_tmp").Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.DeleteLevel(ctx, "L_tmp"); err != nil {
+			t.Fatal(err)
+		}
+		if n := count(t, db, &models.Topic{}, "level_id = ?", "L_tmp") + count(t, db, &models.Dialog{}, "level_id = ?", "L_tmp"); n != 0 {
+			t.Errorf("Expected topics and dialogs to be unassigned, got %d still assigned", n)
+		}
+		if err := repo.DeleteLevel(ctx, "L_tmp"); err != gorm.ErrRecordNotFound {
+			t.Errorf("Expected gorm.ErrRecordNotFound, got %v", err)
+		}
+	})
+
+	t.Run("TestLevelProgress", func(t *testing.T) {
+		mustCreate(t, db,
+			&models.Customer{ID: "C_level", Name: "Learner"},
+			&models.DialogCompletion{CustomerID: "C_level", DialogID: "D_a1", TopicID: "T_a1",
+				ListeningCompleted: true, SpeakingCompleted: true, WritingCompleted: true},
+			&models.DialogCompletion{CustomerID: "C_level", DialogID: "D_a1_easy", TopicID: "T_a1_easy", ListeningCompleted: true},
+		)
+		progress, err := repo.GetLevelProgress(ctx, "C_level")
+		if err != nil {
+			t.Fatal(err)
+		}
+		byCode := map[string]models.LevelProgress{}
+		for _, p := range progress {
+			byCode[p.Code] = p
+		}
+		cases := []struct {
+			code                         string
+			total, completed, inProgress int
+		}{
+			{"A1", 2, 1, 1},
+			{"A2", 1, 0, 0}, // dialog tự gán A2 trong topic B1
+			{"B1", 1, 0, 0},
+		}
+		for _, tc := range cases {
+			p := byCode[tc.code]
+			if p.TotalDialogs != tc.total || p.CompletedDialogs != tc.completed || p.InProgressDialogs != tc.inProgress {
+				t.Errorf("Expected %s total=%d completed=%d in_progress=%d, got %+v",
+					tc.code, tc.total, tc.completed, tc.inProgress, p)
+			}
+		}
+	})
+}
+
+func TestSearchTopicListLevels(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	repo := NewTopicRepository(db, NewTrashRepository(db))
+	seedLevels(t, db)
+
+	cases := []struct {
+		name   string
+		levels []string
+		sort   string
+		asc    bool
+		want   []string
+		total  int64
+	}{
+		{"FilterByTopicLevel", []string{"A1"}, "title", true, []string{"T_a1", "T_a1_easy"}, 2},
+		{"FilterByDialogLevel", []string{"A2"}, "title", true, []string{"T_b1"}, 1},
+		{"SortByLevelThenDifficulty", nil, "level", true, []string{"T_a1_easy", "T_a1", "T_b1", "T_none"}, 4},
+		{"SortByLevelDesc", nil, "level", false, []string{"T_b1", "T_a1", "T_a1_easy", "T_none"}, 4},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			items, total, err := repo.SearchTopicList(ctx, "", nil, tc.levels, 1, 10, tc.sort, tc.asc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := topicIDs(items); !equalIDs(got, tc.want) {
+				t.Errorf("Expected topics %v, got %v", tc.want, got)
+			}
+			if total != tc.total {
+				t.Errorf("Expected total %d, got %d", tc.total, total)
+			}
+		})
+	}
+
+	t.Run("TestFilterKeepsOnlyMatchingDialogs", func(t *testing.T) {
+		items, _, err := repo.SearchTopicList(ctx, "", nil, []string{"B1"}, 1, 10, "title", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 || len(items[0].Dialogs) != 1 || items[0].Dialogs[0].DialogID != "D_b1" {
+			t.Errorf("Expected only D_b1 under B1, got %+v", items)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+// MFARepository handles database operations for two-factor authentication
+type MFARepository struct {
+	db *gorm.DB
+}
+
+// NewMFARepository creates a new MFARepository instance
+func NewMFARepository(db *gorm.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// GetFactor trả về factor của user (đã hoặc chưa xác nhận); nil nếu chưa đăng ký
+func (r *MFARepository) GetFactor(ctx context.Context, userID string) (*models.MFAFactor, error) {
+	var factor models.MFAFactor
+	tx := conn(ctx, r.db).Where("user_id = ?", userID).First(&factor)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &factor, nil
+}
+
+// SavePendingFactor thay factor chưa xác nhận (nếu có) bằng factor mới.
+// Factor đã xác nhận không bị ghi đè; trả về false trong trường hợp đó.
+func (r *MFARepository) SavePendingFactor(ctx context.Context, factor *models.MFAFactor) (bool, error) {
+	saved := false
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.MFAFactor{}).
+			Where("user_id = ? AND confirmed_at IS NOT NULL", factor.UserID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		if err := tx.Where("user_id = ?", factor.UserID).Delete(&models.MFAFactor{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(factor).Error; err != nil {
+			return err
+		}
+		saved = true
+		return nil
+	})
+	return saved, err
+}
+
+// ConfirmFactor kích hoạt factor và thay toàn bộ recovery code trong một transaction
+func (r *MFARepository) ConfirmFactor(ctx context.Context, factorID string, step int64, codes []models.MFARecoveryCode) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var factor models.MFAFactor
+		if err := tx.Where("id = ?", factorID).First(&factor).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&factor).Updates(map[string]interface{}{
+			"confirmed_at":   time.Now(),
+			"last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, factor.UserID, codes)
+	})
+}
+
+// UseStep ghi nhận bước TOTP đã dùng; trả về false nếu bước này (hoặc bước mới hơn) đã được dùng để chặn replay
+func (r *MFARepository) UseStep(ctx context.Context, factorID string, step int64) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.MFAFactor{}).
+		Where("id = ? AND last_used_step < ?", factorID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// RecordFailedAttempt tăng bộ đếm nhập sai; đạt maxAttempts thì khóa tới lockUntil và reset bộ đếm.
+// Cập nhật bằng một câu lệnh để các request song song không làm mất lượt đếm.
+func (r *MFARepository) RecordFailedAttempt(ctx context.Context, factorID string, maxAttempts int, lockUntil time.Time) error {
+	return conn(ctx, r.db).Exec(`
+		UPDATE mfa_factors SET
+			locked_until = CASE WHEN failed_attempts + 1 >= ? THEN ? ELSE locked_until END,
+			failed_attempts = CASE WHEN failed_attempts + 1 >= ? THEN 0 ELSE failed_attempts + 1 END
+		WHERE id = ?
+	`, maxAttempts, lockUntil, maxAttempts, factorID).Error
+}
+
+// ResetFailedAttempts xóa bộ đếm nhập sai và trạng thái khóa sau khi xác minh thành công
+func (r *MFARepository) ResetFailedAttempts(ctx context.Context, factorID string) error {
+	return conn(ctx, r.db).Model(&models.MFAFactor{}).
+		Where("id = ? AND (failed_attempts > 0 OR locked_until IS NOT NULL)", factorID).
+		Updates(map[string]interface{}{"failed_attempts": 0, "locked_until": nil}).Error
+}
+
+// UseRecoveryCode đánh dấu recovery code đã dùng; trả về false nếu không tồn tại hoặc đã dùng
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountUnusedRecoveryCodes đếm recovery code còn dùng được
+func (r *MFARepository) CountUnusedRecoveryCodes(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// ReplaceRecoveryCodes xóa recovery code cũ và lưu bộ mới
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []models.MFARecoveryCode) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// DeleteFactor tắt 2FA: xóa factor và recovery code của user
+func (r *MFARepository) DeleteFactor(ctx context.Context, userID string) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.MFAFactor{}).Error
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID string, codes []models.MFARecoveryCode) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
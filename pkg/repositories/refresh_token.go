@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository handles database operations for refresh tokens
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository creates a new RefreshTokenRepository instance
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create lưu refresh token mới
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	return conn(ctx, r.db).Create(token).Error
+}
+
+// GetByHash tìm refresh token theo hash
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate đánh dấu token cũ đã dùng và lưu token thay thế trong cùng transaction.
+// Trả về false nếu token cũ đã bị dùng/thu hồi trước đó (ví dụ hai request refresh chạy song song).
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, oldID string, next *models.RefreshToken) (bool, error) {
+	rotated := false
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", oldID).
+			Updates(map[string]interface{}{
+				"used_at":        now,
+				"replaced_by_id": next.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+// RevokeFamily thu hồi toàn bộ token trong một family
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return conn(ctx, r.db).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForCustomer thu hồi toàn bộ refresh token của customer
+func (r *RefreshTokenRepository) RevokeAllForCustomer(ctx context.Context, customerID string) error {
+	return conn(ctx, r.db).Model(&models.RefreshToken{}).
+		Where("customer_id = ? AND revoked_at IS NULL", customerID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpired xóa các token đã hết hạn trước thời điểm before
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("expires_at < ?", before).Delete(&models.RefreshToken{})
+	return result.RowsAffected, result.Error
+}
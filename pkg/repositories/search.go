@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// searchConfig là cấu hình text search tạo ở migration 0005 (simple + unaccent): không phân biệt hoa thường và dấu
+const searchConfig = "vn_unaccent"
+
+// Tuỳ chọn ts_headline: tiêu đề ngắn nên tô sáng toàn bộ, nội dung dialog chỉ lấy vài đoạn quanh từ khớp.
+// Snippet chứa nguyên văn nội dung kèm thẻ <mark>, phía hiển thị phải escape phần còn lại.
+const (
+	titleHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	textHeadlineOptions  = `StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=" … "`
+)
+
+// prefixQuery chuyển từ khoá người dùng nhập thành tsquery "xin:* & chao:*": mọi từ đều phải có và
+// được khớp theo tiền tố để gợi ý khi đang gõ. Chỉ giữ chữ và số nên không thể chèn toán tử tsquery;
+// trả về chuỗi rỗng nếu không còn từ nào
+func prefixQuery(keyword string) string {
+	terms := strings.FieldsFunc(keyword, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+	for i := range terms {
+		terms[i] += ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// ftsVector là biểu thức tsvector của cột, trùng với biểu thức của GIN index để truy vấn dùng được index
+func ftsVector(column string) string {
+	return fmt.Sprintf("to_tsvector('%s', %s)", searchConfig, column)
+}
+
+// ftsMatch là điều kiện cột khớp tsquery (tham số là kết quả của prefixQuery)
+func ftsMatch(column string) string {
+	return fmt.Sprintf("%s @@ to_tsquery('%s', ?)", ftsVector(column), searchConfig)
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import "testing"
+
+func TestPrefixQuery(t *testing.T) {
+	cases := []struct {
+		name    string
+		keyword string
+		want    string
+	}{
+		{"Empty", "", ""},
+		{"OnlySpaces", "   ", ""},
+		{"SingleWord", "hello", "hello:*"},
+		{"MultipleWords", "xin  chào", "xin:* & chào:*"},
+		{"Digits", "lesson 12", "lesson:* & 12:*"},
+		{"CombiningMarks", "cha\u0300o", "cha\u0300o:*"},
+		{"StripsOperators", "a & b | !c:*", "a:* & b:* & c:*"},
+		{"StripsQuotesAndParens", "'(tiếng) việt'", "tiếng:* & việt:*"},
+		{"OnlyOperators", "&|!():*", ""},
+		{"Punctuation", "don't-stop", "don:* & t:* & stop:*"},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			if got := prefixQuery(tc.keyword); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+// SessionRepository handles database operations for customer sessions
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new SessionRepository instance
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create lưu session mới khi đăng nhập và cập nhật customers.last_login trong cùng transaction
+func (r *SessionRepository) Create(ctx context.Context, session *models.Session) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Customer{}).Where("id = ?", session.CustomerID).
+			Update("last_login", session.LastSeenAt).Error
+	})
+}
+
+// Touch cập nhật last-seen, IP và phiên bản app khi refresh; trường rỗng giữ nguyên giá trị cũ.
+// customers.last_login cũng được cập nhật để phản ánh lần hoạt động gần nhất.
+func (r *SessionRepository) Touch(ctx context.Context, sessionID, customerID string, device models.DeviceInfo, seenAt time.Time) error {
+	updates := map[string]interface{}{"last_seen_at": seenAt}
+	if device.DeviceName != "" {
+		updates["device_name"] = device.DeviceName
+	}
+	if device.Platform != "" {
+		updates["platform"] = device.Platform
+	}
+	if device.AppVersion != "" {
+		updates["app_version"] = device.AppVersion
+	}
+	if device.IPAddress != "" {
+		updates["ip_address"] = device.IPAddress
+	}
+	if device.UserAgent != "" {
+		updates["user_agent"] = device.UserAgent
+	}
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Session{}).Where("id = ?", sessionID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Customer{}).Where("id = ?", customerID).
+			Update("last_login", seenAt).Error
+	})
+}
+
+// GetByID tìm session theo ID; nil nếu không có
+func (r *SessionRepository) GetByID(ctx context.Context, sessionID string) (*models.Session, error) {
+	var session models.Session
+	tx := conn(ctx, r.db).Where("id = ?", sessionID).First(&session)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &session, nil
+}
+
+// ListByCustomer trả về session của customer, mới hoạt động gần nhất trước
+func (r *SessionRepository) ListByCustomer(ctx context.Context, customerID string, includeRevoked bool) ([]models.Session, error) {
+	var sessions []models.Session
+	query := conn(ctx, r.db).Where("customer_id = ?", customerID)
+	if !includeRevoked {
+		query = query.Where("revoked_at IS NULL")
+	}
+	err := query.Order("last_seen_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// MarkRevoked đánh dấu session đã bị thu hồi
+func (r *SessionRepository) MarkRevoked(ctx context.Context, sessionID string) error {
+	return conn(ctx, r.db).Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// MarkAllRevoked đánh dấu mọi session đang hoạt động của customer đã bị thu hồi
+func (r *SessionRepository) MarkAllRevoked(ctx context.Context, customerID string) error {
+	return conn(ctx, r.db).Model(&models.Session{}).
+		Where("customer_id = ? AND revoked_at IS NULL", customerID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteRevokedBefore xóa các session đã thu hồi trước thời điểm before
+func (r *SessionRepository) DeleteRevokedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("revoked_at < ?", before).Delete(&models.Session{})
+	return result.RowsAffected, result.Error
+}
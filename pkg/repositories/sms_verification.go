@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPhoneNumberInUse được trả về khi số điện thoại đã gắn với customer khác
+var ErrPhoneNumberInUse = errors.New("phone number is already used by another customer")
+
+// SMSVerificationRepository handles database operations for SMS verifications
+type SMSVerificationRepository struct {
+	db *gorm.DB
+}
+
+// NewSMSVerificationRepository creates a new SMSVerificationRepository instance
+func NewSMSVerificationRepository(db *gorm.DB) *SMSVerificationRepository {
+	return &SMSVerificationRepository{db: db}
+}
+
+// Create lưu mã xác thực mới
+func (r *SMSVerificationRepository) Create(ctx context.Context, verification *models.SMSVerification) error {
+	return conn(ctx, r.db).Create(verification).Error
+}
+
+// GetLatest trả về mã gửi gần nhất cho số điện thoại (của customer nếu customerID khác rỗng); nil nếu chưa có
+func (r *SMSVerificationRepository) GetLatest(ctx context.Context, customerID, phoneNumber string) (*models.SMSVerification, error) {
+	var verification models.SMSVerification
+	query := conn(ctx, r.db).Where("phone_number = ?", phoneNumber)
+	if customerID != "" {
+		query = query.Where("customer_id = ?", customerID)
+	}
+	tx := query.Order("created_at DESC").First(&verification)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &verification, nil
+}
+
+// CountSince đếm số mã đã gửi tới số điện thoại kể từ since
+func (r *SMSVerificationRepository) CountSince(ctx context.Context, phoneNumber string, since time.Time) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&models.SMSVerification{}).
+		Where("phone_number = ? AND created_at >= ?", phoneNumber, since).
+		Count(&count).Error
+	return count, err
+}
+
+// IncrementAttempts tăng số lần nhập sai nếu chưa vượt maxAttempts.
+// Trả về false khi mã đã hết lượt (hoặc đã được xác thực) để các request song song không vượt giới hạn.
+func (r *SMSVerificationRepository) IncrementAttempts(ctx context.Context, id string, maxAttempts int) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.SMSVerification{}).
+		Where("id = ? AND verified = false AND attempts < ?", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return result.RowsAffected > 0, result.Error
+}
+
+// CompleteVerification đánh dấu mã đã dùng, gán số điện thoại cho customer và tạo liên kết provider "sms"
+// trong một transaction. Trả về false nếu mã đã được dùng bởi request khác.
+func (r *SMSVerificationRepository) CompleteVerification(ctx context.Context, verification *models.SMSVerification, link *models.AuthProvider) (bool, error) {
+	completed := false
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.SMSVerification{}).
+			Where("id = ? AND verified = false", verification.ID).
+			Updates(map[string]interface{}{"verified": true, "verified_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		var owner int64
+		if err := tx.Model(&models.Customer{}).
+			Where("phone_number = ? AND id <> ?", verification.PhoneNumber, verification.CustomerID).
+			Count(&owner).Error; err != nil {
+			return err
+		}
+		if owner > 0 {
+			return ErrPhoneNumberInUse
+		}
+
+		if err := tx.Model(&models.Customer{}).Where("id = ?", verification.CustomerID).
+			Update("phone_number", verification.PhoneNumber).Error; err != nil {
+			return err
+		}
+
+		// Customer đổi số: liên kết sms cũ trỏ sang số mới
+		if err := tx.Where("customer_id = ? AND provider = ?", verification.CustomerID, link.Provider).
+			Delete(&models.AuthProvider{}).Error; err != nil {
+			return err
+		}
+		link.LastUsedAt = &now
+		created := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "provider"}, {Name: "provider_user_id"}},
+			DoNothing: true,
+		}).Create(link)
+		if created.Error != nil {
+			return created.Error
+		}
+		// Liên kết sms của số này vẫn thuộc customer khác (ví dụ customer đó đã bỏ số nhưng chưa gỡ liên kết)
+		if created.RowsAffected == 0 {
+			return ErrPhoneNumberInUse
+		}
+		completed = true
+		return nil
+	})
+	return completed, err
+}
+
+// DeleteExpired xóa các mã đã hết hạn trước thời điểm before
+func (r *SMSVerificationRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("expires_at < ?", before).Delete(&models.SMSVerification{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+)
+
+func TestCompleteVerification(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	repo := NewSMSVerificationRepository(db)
+	phone := "+84900000001"
+	// user_a đã bỏ số trên hồ sơ nhưng liên kết sms của số đó vẫn còn
+	mustCreate(t, db,
+		&models.Customer{ID: "user_a", Name: "User A"},
+		&models.Customer{ID: "user_b", Name: "User B"},
+		&models.AuthProvider{ID: "AP_A", CustomerID: "user_a", Provider: "sms", ProviderUserID: &phone},
+		&models.SMSVerification{ID: "V1", CustomerID: "user_b", PhoneNumber: phone, CodeHash: "hash",
+			ExpiresAt: time.Now().Add(time.Minute)},
+	)
+
+	verification := &models.SMSVerification{ID: "V1", CustomerID: "user_b", PhoneNumber: phone}
+	link := &models.AuthProvider{ID: "AP_B", CustomerID: "user_b", Provider: "sms", ProviderUserID: &phone}
+	completed, err := repo.CompleteVerification(ctx, verification, link)
+	if !errors.Is(err, ErrPhoneNumberInUse) {
+		t.Fatalf("Expected ErrPhoneNumberInUse, got completed=%v err=%v", completed, err)
+	}
+	if completed {
+		t.Error("Expected verification not to be completed")
+	}
+	if n := count(t, db, &models.Customer{}, "id = ? AND phone_number = ?", "user_b", phone); n != 0 {
+		t.Error("Expected phone number not to be assigned to user_b")
+	}
+	if n := count(t, db, &models.SMSVerification{}, "id = ? AND verified = true", "V1"); n != 0 {
+		t.Error("Expected verification to stay unused after rollback")
+	}
+	if n := count(t, db, &models.AuthProvider{}, "id = ? AND customer_id = ?", "AP_A", "user_a"); n != 1 {
+		t.Error("Expected existing sms link to stay with user_a")
+	}
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+
 	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
 	"gorm.io/gorm"
 )
@@ -14,9 +16,9 @@ func NewSubscriptionRepository(db *gorm.DB) *SubscriptionRepository {
 }
 
 // GetSubscriptionByID lấy subscription theo ID
-func (r *SubscriptionRepository) GetSubscriptionByID(id string) (*models.Subscription, error) {
+func (r *SubscriptionRepository) GetSubscriptionByID(ctx context.Context, id string) (*models.Subscription, error) {
 	var subscription models.Subscription
-	err := r.db.Where("id = ?", id).First(&subscription).Error
+	err := conn(ctx, r.db).Where("id = ?", id).First(&subscription).Error
 	if err != nil {
 		return nil, err
 	}
@@ -24,18 +26,28 @@ func (r *SubscriptionRepository) GetSubscriptionByID(id string) (*models.Subscri
 }
 
 // GetAllSubscriptions lấy tất cả subscriptions
-func (r *SubscriptionRepository) GetAllSubscriptions() ([]models.Subscription, error) {
+func (r *SubscriptionRepository) GetAllSubscriptions(ctx context.Context) ([]models.Subscription, error) {
 	var subscriptions []models.Subscription
-	err := r.db.Find(&subscriptions).Error
+	err := conn(ctx, r.db).Find(&subscriptions).Error
 	return subscriptions, err
 }
 
 // GetSubscriptionByName lấy subscription theo tên
-func (r *SubscriptionRepository) GetSubscriptionByName(name string) (*models.Subscription, error) {
+func (r *SubscriptionRepository) GetSubscriptionByName(ctx context.Context, name string) (*models.Subscription, error) {
 	var subscription models.Subscription
-	err := r.db.Where("name = ?", name).First(&subscription).Error
+	err := conn(ctx, r.db).Where("name = ?", name).First(&subscription).Error
 	if err != nil {
 		return nil, err
 	}
 	return &subscription, nil
 }
+
+// CreatePayment lưu bản ghi thanh toán
+func (r *SubscriptionRepository) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	return conn(ctx, r.db).Create(payment).Error
+}
+
+// CreateCustomerSubscription lưu gói đăng ký của customer
+func (r *SubscriptionRepository) CreateCustomerSubscription(ctx context.Context, subscription *models.CustomerSubscription) error {
+	return conn(ctx, r.db).Create(subscription).Error
+}
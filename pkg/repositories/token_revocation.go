@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenRevocationRepository handles database operations for token revocations
+type TokenRevocationRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRevocationRepository creates a new TokenRevocationRepository instance
+func NewTokenRevocationRepository(db *gorm.DB) *TokenRevocationRepository {
+	return &TokenRevocationRepository{db: db}
+}
+
+// Upsert lưu bản ghi thu hồi, ghi đè nếu đã tồn tại (ví dụ thu hồi user lần hai với mốc thời gian mới)
+func (r *TokenRevocationRepository) Upsert(ctx context.Context, revocation *models.TokenRevocation) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "subject"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "revoked_before", "reason", "expires_at", "updated_at"}),
+	}).Create(revocation).Error
+}
+
+// ListActive lấy các bản ghi chưa hết hạn và được cập nhật sau mốc since
+func (r *TokenRevocationRepository) ListActive(ctx context.Context, since time.Time) ([]models.TokenRevocation, error) {
+	var revocations []models.TokenRevocation
+	err := conn(ctx, r.db).
+		Where("expires_at > ? AND updated_at >= ?", time.Now(), since).
+		Find(&revocations).Error
+	return revocations, err
+}
+
+// DeleteExpired xóa các bản ghi đã hết hạn
+func (r *TokenRevocationRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := conn(ctx, r.db).Where("expires_at <= ?", time.Now()).Delete(&models.TokenRevocation{})
+	return result.RowsAffected, result.Error
+}
@@ -1,48 +1,51 @@
 package repositories
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
 	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
 	"gorm.io/gorm"
 )
 
 type TopicRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	trashRepo *TrashRepository
 }
 
-// NewTopicRepository creates a new TopicRepository instance
-func NewTopicRepository(db *gorm.DB) *TopicRepository {
-	return &TopicRepository{db: db}
+// NewTopicRepository creates a new TopicRepository instance; trashRepo dùng cho xoá mềm topic và ảnh bìa
+func NewTopicRepository(db *gorm.DB, trashRepo *TrashRepository) *TopicRepository {
+	return &TopicRepository{db: db, trashRepo: trashRepo}
 }
 
 // GetTopic retrieves a topic by ID
-func (r *TopicRepository) GetTopic(id string) (*models.Topic, error) {
+func (r *TopicRepository) GetTopic(ctx context.Context, id string) (*models.Topic, error) {
 	var topic models.Topic
-	err := r.db.First(&topic, "id = ?", id).Error
+	err := conn(ctx, r.db).First(&topic, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &topic, nil
 }
-func (r *TopicRepository) GetTopicByKeyword(keyword string) ([]models.Topic, error) {
-	var topics []models.Topic
-	err := r.db.Where("LOWER(title) LIKE LOWER(?)", "%"+keyword+"%").Find(&topics).Error
-	if err != nil {
-		return nil, err
-	}
-	return topics, nil
-}
 // GetAllTopics retrieves all topics
-func (r *TopicRepository) GetAllTopics() ([]models.Topic, error) {
+func (r *TopicRepository) GetAllTopics(ctx context.Context) ([]models.Topic, error) {
 	var topics []models.Topic
-	err := r.db.Find(&topics).Error
+	err := conn(ctx, r.db).Find(&topics).Error
 	if err != nil {
 		return nil, err
 	}
 	return topics, nil
 }
 
-// SearchTopicList returns dialogs grouped by topic in TitleDialogResponse format
-func (r *TopicRepository) SearchTopicList(title string, tags []string, page, limit int, sort string, asc bool) ([]models.TitleDialogResponse, int64, error) {
+// dialogLevelFilter lọc dialog theo mã level; dialog không gán level thì lấy level của topic
+const dialogLevelFilter = `COALESCE(dialogs.level_id, (SELECT tl.level_id FROM topics tl WHERE tl.id = dialogs.topic_id))
+	IN (SELECT levels.id FROM levels WHERE levels.code IN ?)`
+
+// SearchTopicList returns dialogs grouped by topic in TitleDialogResponse format.
+// levels là danh sách mã level (A1, B2...), chỉ giữ dialog thuộc các level đó.
+// Đây là danh mục cho người học nên chỉ gồm topic public.
+func (r *TopicRepository) SearchTopicList(ctx context.Context, title string, tags, levels []string, page, limit int, sort string, asc bool) ([]models.TitleDialogResponse, int64, error) {
 	if limit <= 0 {
 		limit = 20
 	}
@@ -62,16 +65,27 @@ func (r *TopicRepository) SearchTopicList(title string, tags []string, page, lim
 		sortColumn = "topics.created_at"
 	case "updated_at":
 		sortColumn = "topics.updated_at"
+	case "sort_order":
+		sortColumn = "topics.sort_order"
+	case "level":
+		sortColumn = "(SELECT levels.rank FROM levels WHERE levels.id = topics.level_id)"
 	}
 	order := "ASC"
 	if !asc {
 		order = "DESC"
 	}
+	orderBy := sortColumn + " " + order
+	if sort == "level" {
+		// Topic chưa gán level luôn xếp cuối; cùng level thì xếp theo độ khó
+		orderBy += " NULLS LAST, topics.difficulty " + order
+	}
 
-	// Build base query for topics
-	base := r.db.Model(&models.Topic{}).
+	// Build base query for topics; group theo khoá chính để mỗi topic chỉ một dòng dù có nhiều dialog
+	base := readConn(ctx, r.db).Model(&models.Topic{}).
 		Select("topics.id, topics.title").
-		Joins("JOIN dialogs ON dialogs.topic_id = topics.id")
+		Joins("JOIN dialogs ON dialogs.topic_id = topics.id AND dialogs.deleted_at IS NULL").
+		Where("topics.visibility = ?", models.TopicVisibilityPublic).
+		Group("topics.id")
 
 	// Filter by topic title
 	if title != "" {
@@ -81,32 +95,51 @@ func (r *TopicRepository) SearchTopicList(title string, tags []string, page, lim
 	// Filter by tags (if any tags specified)
 	if len(tags) > 0 {
 		base = base.Joins("JOIN dialog_tags dt ON dt.dialog_id = dialogs.id").
-			Joins("JOIN tags t ON t.id = dt.tag_id").
-			Where("t.name IN ?", tags).
-			Group("topics.id, topics.title")
+			Joins("JOIN tags t ON t.id = dt.tag_id AND t.deleted_at IS NULL").
+			Where("t.name IN ?", tags)
+	}
+
+	// Filter by level
+	if len(levels) > 0 {
+		base = base.Where(dialogLevelFilter, levels)
 	}
 
 	// Count total distinct topics
 	var total int64
 	if len(tags) > 0 {
 		// For tag filtering, we need to count distinct topics that have dialogs with the specified tags
-		countQuery := r.db.Model(&models.Topic{}).
+		countQuery := readConn(ctx, r.db).Model(&models.Topic{}).
 			Select("DISTINCT topics.id").
-			Joins("JOIN dialogs ON dialogs.topic_id = topics.id").
+			Joins("JOIN dialogs ON dialogs.topic_id = topics.id AND dialogs.deleted_at IS NULL").
 			Joins("JOIN dialog_tags dt ON dt.dialog_id = dialogs.id").
-			Joins("JOIN tags t ON t.id = dt.tag_id").
-			Where("t.name IN ?", tags)
+			Joins("JOIN tags t ON t.id = dt.tag_id AND t.deleted_at IS NULL").
+			Where("t.name IN ?", tags).
+			Where("topics.visibility = ?", models.TopicVisibilityPublic)
 
 		if title != "" {
 			countQuery = countQuery.Where("topics.title LIKE ?", "%"+title+"%")
 		}
+		if len(levels) > 0 {
+			countQuery = countQuery.Where(dialogLevelFilter, levels)
+		}
 
 		if err := countQuery.Count(&total).Error; err != nil {
 			return nil, 0, err
 		}
 	} else {
 		// No tags filter, simple count
-		if err := base.Distinct("topics.id").Count(&total).Error; err != nil {
+		exists := "EXISTS (SELECT 1 FROM dialogs WHERE dialogs.topic_id = topics.id AND dialogs.deleted_at IS NULL)"
+		var existsArgs []interface{}
+		if len(levels) > 0 {
+			exists = "EXISTS (SELECT 1 FROM dialogs WHERE dialogs.topic_id = topics.id AND dialogs.deleted_at IS NULL AND " + dialogLevelFilter + ")"
+			existsArgs = append(existsArgs, levels)
+		}
+		countQuery := readConn(ctx, r.db).Model(&models.Topic{}).Where(exists, existsArgs...).
+			Where("topics.visibility = ?", models.TopicVisibilityPublic)
+		if title != "" {
+			countQuery = countQuery.Where("topics.title LIKE ?", "%"+title+"%")
+		}
+		if err := countQuery.Count(&total).Error; err != nil {
 			return nil, 0, err
 		}
 	}
@@ -119,7 +152,7 @@ func (r *TopicRepository) SearchTopicList(title string, tags []string, page, lim
 
 	// Build final query with proper grouping and ordering
 	query := base.Select("topics.id, topics.title").
-		Order(sortColumn + " " + order).
+		Order(orderBy + ", topics.id").
 		Limit(limit).
 		Offset(offset)
 
@@ -132,16 +165,19 @@ func (r *TopicRepository) SearchTopicList(title string, tags []string, page, lim
 	for _, topicRow := range topicRows {
 		// Get dialogs for this topic
 		var dialogs []models.Dialog
-		dialogQuery := r.db.Where("topic_id = ?", topicRow.ID)
+		dialogQuery := readConn(ctx, r.db).Where("topic_id = ?", topicRow.ID)
 
 		// Apply tag filter to dialogs if specified
 		if len(tags) > 0 {
 			dialogQuery = dialogQuery.Joins("JOIN dialog_tags dt ON dt.dialog_id = dialogs.id").
-				Joins("JOIN tags t ON t.id = dt.tag_id").
+				Joins("JOIN tags t ON t.id = dt.tag_id AND t.deleted_at IS NULL").
 				Where("t.name IN ?", tags).
 				Group("dialogs.id").
 				Select("dialogs.*")
 		}
+		if len(levels) > 0 {
+			dialogQuery = dialogQuery.Where(dialogLevelFilter, levels)
+		}
 
 		if err := dialogQuery.Find(&dialogs).Error; err != nil {
 			return nil, 0, err
@@ -165,3 +201,168 @@ func (r *TopicRepository) SearchTopicList(title string, tags []string, page, lim
 
 	return result, total, nil
 }
+
+// CreateTopic inserts a new topic
+func (r *TopicRepository) CreateTopic(ctx context.Context, topic *models.Topic) error {
+	return conn(ctx, r.db).Create(topic).Error
+}
+
+// UpdateTopicFields cập nhật các cột của topic; gorm.ErrRecordNotFound nếu topic không tồn tại
+func (r *TopicRepository) UpdateTopicFields(ctx context.Context, id string, fields map[string]interface{}) error {
+	res := conn(ctx, r.db).Model(&models.Topic{}).Where("id = ?", id).Updates(fields)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SoftDeleteTopic chuyển topic cùng dialog, image, comment của nó vào thùng rác
+func (r *TopicRepository) SoftDeleteTopic(ctx context.Context, id string) error {
+	_, err := r.trashRepo.SoftDelete(ctx, models.TrashTopic, id)
+	return err
+}
+
+// TopicTitleExists kiểm tra tiêu đề (không phân biệt hoa thường) đã được topic khác dùng chưa
+func (r *TopicRepository) TopicTitleExists(ctx context.Context, title, excludeID string) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&models.Topic{}).
+		Where("LOWER(title) = LOWER(?) AND id <> ?", title, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// NextSortOrder trả về sort_order để xếp topic mới xuống cuối
+func (r *TopicRepository) NextSortOrder(ctx context.Context) (int, error) {
+	var max int
+	err := conn(ctx, r.db).Model(&models.Topic{}).Select("COALESCE(MAX(sort_order), 0)").Scan(&max).Error
+	return max + 1, err
+}
+
+// GetSortOrders trả về sort_order hiện tại của các topic theo ID
+func (r *TopicRepository) GetSortOrders(ctx context.Context, ids []string) (map[string]int, error) {
+	var rows []struct {
+		ID        string
+		SortOrder int
+	}
+	if err := conn(ctx, r.db).Model(&models.Topic{}).Select("id, sort_order").
+		Where("id IN ?", ids).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	orders := make(map[string]int, len(rows))
+	for _, row := range rows {
+		orders[row.ID] = row.SortOrder
+	}
+	return orders, nil
+}
+
+// UpdateSortOrders ghi sort_order cho nhiều topic bằng một câu UPDATE
+func (r *TopicRepository) UpdateSortOrders(ctx context.Context, orders map[string]int) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	var caseSQL strings.Builder
+	args := make([]interface{}, 0, len(orders)*2+1)
+	ids := make([]string, 0, len(orders))
+	caseSQL.WriteString("CASE id")
+	for id, order := range orders {
+		// Ép kiểu để Postgres không suy ra tham số THEN là text
+		caseSQL.WriteString(" WHEN ? THEN CAST(? AS bigint)")
+		args = append(args, id, order)
+		ids = append(ids, id)
+	}
+	caseSQL.WriteString(" END")
+	return conn(ctx, r.db).Model(&models.Topic{}).Where("id IN ?", ids).
+		Update("sort_order", gorm.Expr(caseSQL.String(), args...)).Error
+}
+
+// topicItemQuery lấy topic kèm số dialog, dialog đầu tiên và ảnh bìa trong một truy vấn
+const topicItemQuery = `SELECT topics.id, topics.title, COALESCE(topics.description, '') AS description,
+	topics.sort_order, topics.difficulty, topics.visibility, topics.level_id,
+	COALESCE((SELECT levels.code FROM levels WHERE levels.id = topics.level_id), '') AS level_code,
+	topics.created_at, topics.updated_at,
+	COUNT(dialogs.id) AS dialog_num,
+	COALESCE(MIN(CASE WHEN dialogs.prev_id IS NULL OR dialogs.prev_id = '' THEN dialogs.id END), '') AS first_dialog_id,
+	COALESCE((SELECT images.file_url FROM images
+		WHERE images.topic_id = topics.id AND images.dialog_id IS NULL AND images.deleted_at IS NULL
+		ORDER BY images.id LIMIT 1), '') AS cover_url
+FROM topics
+LEFT JOIN dialogs ON dialogs.topic_id = topics.id AND dialogs.deleted_at IS NULL
+WHERE topics.deleted_at IS NULL %s
+GROUP BY topics.id
+ORDER BY topics.sort_order, topics.title`
+
+// ListTopicItems liệt kê topic theo sort_order; visibilities rỗng là mọi chế độ hiển thị
+func (r *TopicRepository) ListTopicItems(ctx context.Context, visibilities []string) ([]models.TopicListItem, error) {
+	where, args := "", []interface{}{}
+	if len(visibilities) > 0 {
+		where, args = "AND topics.visibility IN ?", append(args, visibilities)
+	}
+	items := []models.TopicListItem{}
+	err := readConn(ctx, r.db).Raw(fmt.Sprintf(topicItemQuery, where), args...).Scan(&items).Error
+	return items, err
+}
+
+// GetTopicItem trả về topic kèm số dialog, dialog đầu tiên và ảnh bìa; gorm.ErrRecordNotFound nếu không có
+// hoặc chế độ hiển thị không nằm trong visibilities (rỗng là mọi chế độ)
+func (r *TopicRepository) GetTopicItem(ctx context.Context, id string, visibilities []string) (*models.TopicListItem, error) {
+	where, args := "AND topics.id = ?", []interface{}{id}
+	if len(visibilities) > 0 {
+		where, args = where+" AND topics.visibility IN ?", append(args, visibilities)
+	}
+	var items []models.TopicListItem
+	if err := conn(ctx, r.db).Raw(fmt.Sprintf(topicItemQuery, where), args...).Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &items[0], nil
+}
+
+// GetCoverImage trả về ảnh bìa của topic (image có topic_id và không thuộc dialog), nil nếu chưa có
+func (r *TopicRepository) GetCoverImage(ctx context.Context, topicID string) (*models.Image, error) {
+	var images []models.Image
+	err := conn(ctx, r.db).Where("topic_id = ? AND dialog_id IS NULL", topicID).Order("id").Limit(1).Find(&images).Error
+	if err != nil || len(images) == 0 {
+		return nil, err
+	}
+	return &images[0], nil
+}
+
+// SoftDeleteImage chuyển image vào thùng rác
+func (r *TopicRepository) SoftDeleteImage(ctx context.Context, id string) error {
+	_, err := r.trashRepo.SoftDelete(ctx, models.TrashImage, id)
+	return err
+}
+
+// SaveImage tạo hoặc cập nhật image
+func (r *TopicRepository) SaveImage(ctx context.Context, image *models.Image) error {
+	return conn(ctx, r.db).Save(image).Error
+}
+
+// LevelExists kiểm tra level gán cho topic có tồn tại
+func (r *TopicRepository) LevelExists(ctx context.Context, id string) (bool, error) {
+	return NewLevelRepository(r.db).LevelExists(ctx, id)
+}
+
+// topicSearchQuery tìm topic public theo tiêu đề, kèm độ liên quan và tiêu đề đã tô sáng từ khớp
+var topicSearchQuery = `SELECT items.*, ts_rank(to_tsvector('` + searchConfig + `', items.title), q) AS rank,
+	ts_headline('` + searchConfig + `', items.title, q, ?) AS snippet
+FROM (` + fmt.Sprintf(topicItemQuery, "AND topics.visibility = ? AND "+ftsMatch("topics.title")) + `) items
+CROSS JOIN to_tsquery('` + searchConfig + `', ?) q
+ORDER BY rank DESC, items.sort_order
+LIMIT ?`
+
+// SearchTopics tìm topic public theo từ khoá (khớp tiền tố, không phân biệt dấu), liên quan nhất trước
+func (r *TopicRepository) SearchTopics(ctx context.Context, keyword string, limit int) ([]models.TopicListItem, error) {
+	items := []models.TopicListItem{}
+	query := prefixQuery(keyword)
+	if query == "" {
+		return items, nil
+	}
+	err := readConn(ctx, r.db).Raw(topicSearchQuery, titleHeadlineOptions, models.TopicVisibilityPublic, query, query, limit).
+		Scan(&items).Error
+	return items, err
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+func TestTopicVisibility(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	repo := NewTopicRepository(db, NewTrashRepository(db))
+	seedTopic(t, db, "T_pub", "D_pub")
+	seedTopic(t, db, "T_draft", "D_draft")
+	if err := db.Model(&models.Topic{}).Where("id = ?", "T_draft").
+		Update("visibility", models.TopicVisibilityDraft).Error; err != nil {
+		t.Fatal(err)
+	}
+	public := []string{models.TopicVisibilityPublic}
+
+	t.Run("TestGetTopicItem", func(t *testing.T) {
+		if _, err := repo.GetTopicItem(ctx, "T_draft", public); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("Expected draft topic hidden from learners, got %v", err)
+		}
+		item, err := repo.GetTopicItem(ctx, "T_draft", nil)
+		if err != nil || item.ID != "T_draft" {
+			t.Errorf("Expected draft topic visible without filter, got %v, %v", item, err)
+		}
+		if _, err := repo.GetTopicItem(ctx, "T_pub", public); err != nil {
+			t.Errorf("Expected public topic, got %v", err)
+		}
+	})
+
+	t.Run("TestSearchTopicList", func(t *testing.T) {
+		result, total, err := repo.SearchTopicList(ctx, "", nil, nil, 1, 20, "title", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 1 || len(result) != 1 || result[0].TopicID != "T_pub" {
+			t.Errorf("Expected only the public topic, got total=%d %+v", total, result)
+		}
+	})
+}
@@ -0,0 +1,384 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTrashKindInvalid   = errors.New("unsupported trash kind")
+	ErrTrashParentDeleted = errors.New("parent record is in trash, restore it first")
+	ErrTrashNameConflict  = errors.New("an active record with the same name already exists")
+)
+
+// trashRelation là quan hệ cha/con qua cột column của bảng con
+type trashRelation struct {
+	kind   models.TrashKind
+	column string
+}
+
+// trashSpec mô tả cách xoá mềm một loại nội dung.
+// Bản ghi con trong children bị xoá mềm cùng deleted_at với cha, nhờ đó khôi phục cha chỉ đưa lại
+// đúng các bản ghi bị xoá theo nó (không đụng tới bản ghi con đã bị xoá riêng trước đó).
+type trashSpec struct {
+	table    string
+	label    string          // biểu thức SQL hiển thị trong danh sách thùng rác
+	parents  []trashRelation // cha phải còn hoạt động khi khôi phục
+	children []trashRelation
+}
+
+var trashSpecs = map[models.TrashKind]trashSpec{
+	models.TrashTopic: {
+		table:    "topics",
+		label:    "title",
+		children: []trashRelation{{models.TrashDialog, "topic_id"}, {models.TrashImage, "topic_id"}},
+	},
+	models.TrashDialog: {
+		table:    "dialogs",
+		label:    "title",
+		parents:  []trashRelation{{models.TrashTopic, "topic_id"}},
+		children: []trashRelation{{models.TrashImage, "dialog_id"}, {models.TrashComment, "dialog_id"}},
+	},
+	models.TrashComment: {
+		table:    "comments",
+		label:    "LEFT(content, 100)",
+		parents:  []trashRelation{{models.TrashDialog, "dialog_id"}, {models.TrashComment, "parent_comment_id"}},
+		children: []trashRelation{{models.TrashComment, "parent_comment_id"}},
+	},
+	models.TrashTag: {
+		table: "tags",
+		label: "name",
+	},
+	models.TrashImage: {
+		table:   "images",
+		label:   "file_url",
+		parents: []trashRelation{{models.TrashDialog, "dialog_id"}, {models.TrashTopic, "topic_id"}},
+	},
+}
+
+type TrashRepository struct {
+	db *gorm.DB
+}
+
+// NewTrashRepository creates a new TrashRepository instance
+func NewTrashRepository(db *gorm.DB) *TrashRepository {
+	return &TrashRepository{db: db}
+}
+
+// SoftDelete xoá mềm bản ghi cùng cây con của nó; gorm.ErrRecordNotFound nếu bản ghi không tồn tại hoặc đã bị xoá.
+// Xoá một dialog riêng lẻ sẽ nối dialog trước và sau nó với nhau.
+func (r *TrashRepository) SoftDelete(ctx context.Context, kind models.TrashKind, id string) (models.TrashChanges, error) {
+	spec, ok := trashSpecs[kind]
+	if !ok {
+		return nil, ErrTrashKindInvalid
+	}
+	// Postgres lưu timestamptz tới micro giây; cắt trước để so sánh bằng khi khôi phục
+	at := time.Now().UTC().Truncate(time.Microsecond)
+	changes := models.TrashChanges{}
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		res := tx.Table(spec.table).Where("id = ? AND deleted_at IS NULL", id).Update("deleted_at", at)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		changes[kind] += res.RowsAffected
+
+		if kind == models.TrashDialog {
+			if err := unlinkDialog(tx, id); err != nil {
+				return err
+			}
+		}
+		return softDeleteChildren(tx, spec, []string{id}, at, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func softDeleteChildren(tx *gorm.DB, spec trashSpec, ids []string, at time.Time, changes models.TrashChanges) error {
+	for _, child := range spec.children {
+		childSpec := trashSpecs[child.kind]
+		var childIDs []string
+		if err := tx.Table(childSpec.table).
+			Where(child.column+" IN ? AND deleted_at IS NULL", ids).
+			Pluck("id", &childIDs).Error; err != nil {
+			return err
+		}
+		if len(childIDs) == 0 {
+			continue
+		}
+		res := tx.Table(childSpec.table).Where("id IN ?", childIDs).Update("deleted_at", at)
+		if res.Error != nil {
+			return res.Error
+		}
+		changes[child.kind] += res.RowsAffected
+		if err := softDeleteChildren(tx, childSpec, childIDs, at, changes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restore khôi phục bản ghi cùng các bản ghi con bị xoá theo nó.
+// ErrTrashParentDeleted nếu cha (topic của dialog, dialog của comment...) vẫn đang trong thùng rác.
+func (r *TrashRepository) Restore(ctx context.Context, kind models.TrashKind, id string) (models.TrashChanges, error) {
+	spec, ok := trashSpecs[kind]
+	if !ok {
+		return nil, ErrTrashKindInvalid
+	}
+	changes := models.TrashChanges{}
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var row struct{ DeletedAt time.Time }
+		err := tx.Table(spec.table).Select("deleted_at").
+			Where("id = ? AND deleted_at IS NOT NULL", id).Take(&row).Error
+		if err != nil {
+			return err
+		}
+
+		for _, parent := range spec.parents {
+			parentSpec := trashSpecs[parent.kind]
+			var deletedParents int64
+			if err := tx.Table(parentSpec.table+" p").
+				Joins(fmt.Sprintf("JOIN %s c ON c.%s = p.id", spec.table, parent.column)).
+				Where("c.id = ? AND p.deleted_at IS NOT NULL", id).
+				Count(&deletedParents).Error; err != nil {
+				return err
+			}
+			if deletedParents > 0 {
+				return fmt.Errorf("%w: %s", ErrTrashParentDeleted, parent.kind)
+			}
+		}
+		if kind == models.TrashTag {
+			var conflicts int64
+			if err := tx.Table("tags").
+				Where("deleted_at IS NULL AND name = (SELECT name FROM tags WHERE id = ?)", id).
+				Count(&conflicts).Error; err != nil {
+				return err
+			}
+			if conflicts > 0 {
+				return ErrTrashNameConflict
+			}
+		}
+
+		res := tx.Table(spec.table).Where("id = ?", id).Update("deleted_at", nil)
+		if res.Error != nil {
+			return res.Error
+		}
+		changes[kind] += res.RowsAffected
+
+		if kind == models.TrashDialog {
+			if err := relinkDialog(tx, id); err != nil {
+				return err
+			}
+		}
+		return restoreChildren(tx, spec, []string{id}, row.DeletedAt, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func restoreChildren(tx *gorm.DB, spec trashSpec, ids []string, at time.Time, changes models.TrashChanges) error {
+	for _, child := range spec.children {
+		childSpec := trashSpecs[child.kind]
+		var childIDs []string
+		if err := tx.Table(childSpec.table).
+			Where(child.column+" IN ? AND deleted_at = ?", ids, at).
+			Pluck("id", &childIDs).Error; err != nil {
+			return err
+		}
+		if len(childIDs) == 0 {
+			continue
+		}
+		res := tx.Table(childSpec.table).Where("id IN ?", childIDs).Update("deleted_at", nil)
+		if res.Error != nil {
+			return res.Error
+		}
+		changes[child.kind] += res.RowsAffected
+		if err := restoreChildren(tx, childSpec, childIDs, at, changes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unlinkDialog nối dialog trước và sau của dialog vừa bị xoá mềm; prev_id/next_id của chính nó được giữ lại để khôi phục
+func unlinkDialog(tx *gorm.DB, id string) error {
+	var d models.Dialog
+	if err := tx.Unscoped().Select("id", "prev_id", "next_id").Take(&d, "id = ?", id).Error; err != nil {
+		return err
+	}
+	if d.PrevID != nil && *d.PrevID != "" {
+		if err := tx.Table("dialogs").Where("id = ? AND deleted_at IS NULL", *d.PrevID).
+			Update("next_id", d.NextID).Error; err != nil {
+			return err
+		}
+	}
+	if d.NextID != nil && *d.NextID != "" {
+		if err := tx.Table("dialogs").Where("id = ? AND deleted_at IS NULL", *d.NextID).
+			Update("prev_id", d.PrevID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relinkDialog chèn dialog vừa khôi phục vào lại danh sách liên kết của topic: sau dialog đứng trước cũ nếu nó
+// còn hoạt động, lên đầu nếu trước đây là dialog đầu tiên, ngược lại xuống cuối danh sách
+func relinkDialog(tx *gorm.DB, id string) error {
+	var d models.Dialog
+	if err := tx.Select("id", "topic_id", "prev_id").Take(&d, "id = ?", id).Error; err != nil {
+		return err
+	}
+	active := tx.Table("dialogs").Select("id").Where("topic_id = ? AND id <> ? AND deleted_at IS NULL", d.TopicID, id)
+
+	var prevID *string
+	if d.PrevID != nil && *d.PrevID != "" {
+		var ids []string
+		if err := active.Session(&gorm.Session{}).Where("id = ?", *d.PrevID).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			// Dialog trước cũ không còn: nối vào cuối
+			if err := active.Session(&gorm.Session{}).Where("next_id IS NULL OR next_id = ''").Limit(1).Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+		}
+		if len(ids) > 0 {
+			prevID = &ids[0]
+		}
+	}
+
+	var nextIDs []string
+	next := active.Session(&gorm.Session{})
+	if prevID != nil {
+		next = next.Where("prev_id = ?", *prevID)
+	} else {
+		next = next.Where("prev_id IS NULL OR prev_id = ''")
+	}
+	if err := next.Limit(1).Pluck("id", &nextIDs).Error; err != nil {
+		return err
+	}
+	var nextID *string
+	if len(nextIDs) > 0 {
+		nextID = &nextIDs[0]
+	}
+
+	if err := tx.Table("dialogs").Where("id = ?", id).
+		Updates(map[string]interface{}{"prev_id": prevID, "next_id": nextID}).Error; err != nil {
+		return err
+	}
+	if prevID != nil {
+		if err := tx.Table("dialogs").Where("id = ?", *prevID).Update("next_id", id).Error; err != nil {
+			return err
+		}
+	}
+	if nextID != nil {
+		if err := tx.Table("dialogs").Where("id = ?", *nextID).Update("prev_id", id).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListTrash liệt kê bản ghi trong thùng rác, mới xoá trước; kind rỗng là mọi loại
+func (r *TrashRepository) ListTrash(ctx context.Context, kind models.TrashKind, page, limit int) ([]models.TrashItem, int64, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if page <= 0 {
+		page = 1
+	}
+	kinds := models.TrashKinds
+	if kind != "" {
+		if _, ok := trashSpecs[kind]; !ok {
+			return nil, 0, ErrTrashKindInvalid
+		}
+		kinds = []models.TrashKind{kind}
+	}
+
+	parts := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		spec := trashSpecs[k]
+		parts = append(parts, fmt.Sprintf(
+			"SELECT '%s' AS kind, id, %s AS label, deleted_at FROM %s WHERE deleted_at IS NOT NULL",
+			k, spec.label, spec.table))
+	}
+	union := strings.Join(parts, " UNION ALL ")
+
+	db := conn(ctx, r.db)
+	var total int64
+	if err := db.Raw("SELECT COUNT(*) FROM (" + union + ") trash").Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var items []models.TrashItem
+	if err := db.Raw(union+" ORDER BY deleted_at DESC, id LIMIT ? OFFSET ?", limit, (page-1)*limit).
+		Scan(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// Purge xoá hẳn bản ghi đã nằm trong thùng rác trước before, cùng mọi dữ liệu phụ thuộc
+// (audio, fill-in-blank, tag của dialog, tiến trình học...). Foreign key trong database không có
+// ON DELETE CASCADE nên bảng phụ thuộc được xoá trước theo thứ tự.
+func (r *TrashRepository) Purge(ctx context.Context, before time.Time) (models.TrashChanges, error) {
+	changes := models.TrashChanges{}
+	args := map[string]interface{}{"before": before}
+
+	const (
+		topics   = "SELECT id FROM topics WHERE deleted_at < @before"
+		dialogs  = "SELECT id FROM dialogs WHERE deleted_at < @before OR topic_id IN (" + topics + ")"
+		comments = "SELECT id FROM comments WHERE deleted_at < @before OR dialog_id IN (" + dialogs + ")"
+		tags     = "SELECT id FROM tags WHERE deleted_at < @before"
+	)
+	steps := []struct {
+		kind models.TrashKind // rỗng: bảng phụ thuộc, không đếm
+		sql  string
+	}{
+		{"", "UPDATE comments SET parent_comment_id = NULL WHERE parent_comment_id IN (" + comments + ") AND id NOT IN (" + comments + ")"},
+		{models.TrashComment, "DELETE FROM comments WHERE id IN (" + comments + ")"},
+		{models.TrashImage, "DELETE FROM images WHERE deleted_at < @before OR dialog_id IN (" + dialogs + ") OR topic_id IN (" + topics + ")"},
+		{"", "DELETE FROM dialog_tags WHERE dialog_id IN (" + dialogs + ") OR tag_id IN (" + tags + ")"},
+		{"", "DELETE FROM word_in_dialog WHERE dialog_id IN (" + dialogs + ")"},
+		{"", "DELETE FROM audios WHERE dialog_id IN (" + dialogs + ")"},
+		{"", "DELETE FROM fill_in_blanks WHERE dialog_id IN (" + dialogs + ")"},
+		{"", "DELETE FROM dialog_statistics WHERE dialog_id IN (" + dialogs + ")"},
+		{"", "DELETE FROM dialog_completions WHERE dialog_id IN (" + dialogs + ") OR topic_id IN (" + topics + ")"},
+		{"", "DELETE FROM customer_topic_progress WHERE topic_id IN (" + topics + ")"},
+		{"", "UPDATE dialogs SET prev_id = NULL WHERE prev_id IN (" + dialogs + ") AND id NOT IN (" + dialogs + ")"},
+		{"", "UPDATE dialogs SET next_id = NULL WHERE next_id IN (" + dialogs + ") AND id NOT IN (" + dialogs + ")"},
+		{models.TrashDialog, "DELETE FROM dialogs WHERE id IN (" + dialogs + ")"},
+		{models.TrashTag, "DELETE FROM tags WHERE id IN (" + tags + ")"},
+		{models.TrashTopic, "DELETE FROM topics WHERE id IN (" + topics + ")"},
+	}
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, step := range steps {
+			res := tx.Exec(step.sql, args)
+			if res.Error != nil {
+				return res.Error
+			}
+			if step.kind != "" {
+				changes[step.kind] += res.RowsAffected
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+)
+
+func TestTrash(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	repo := NewTrashRepository(db)
+	seedTopic(t, db, "T1", "D1", "D2")
+	mustCreate(t, db,
+		&models.Customer{ID: "C1", Name: "C1"},
+		&models.Comment{ID: "CM1", DialogID: "D1", UserID: "C1", Content: "first"},
+		&models.Comment{ID: "CM2", DialogID: "D1", UserID: "C1", Content: "second"},
+		&models.CustomerTopicProgress{CustomerID: "C1", TopicID: "T1", CompletedDialogIDs: []string{"D1"}},
+	)
+	// Comment bị xoá riêng trước khi xoá topic
+	if _, err := repo.SoftDelete(ctx, models.TrashComment, "CM2"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("TestCascadeSoftDelete", func(t *testing.T) {
+		changes, err := repo.SoftDelete(ctx, models.TrashTopic, "T1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changes[models.TrashTopic] != 1 || changes[models.TrashDialog] != 2 || changes[models.TrashComment] != 1 {
+			t.Errorf("Expected topic, 2 dialogs and 1 comment deleted, got %v", changes)
+		}
+		if n := count(t, db, &models.Dialog{}, "topic_id = ? AND deleted_at IS NULL", "T1"); n != 0 {
+			t.Errorf("Expected dialogs soft deleted, found %d active", n)
+		}
+		if _, err := repo.Restore(ctx, models.TrashDialog, "D1"); !errors.Is(err, ErrTrashParentDeleted) {
+			t.Errorf("Expected ErrTrashParentDeleted restoring dialog of deleted topic, got %v", err)
+		}
+	})
+
+	t.Run("TestRestore", func(t *testing.T) {
+		changes, err := repo.Restore(ctx, models.TrashTopic, "T1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changes[models.TrashDialog] != 2 || changes[models.TrashComment] != 1 {
+			t.Errorf("Expected 2 dialogs and 1 comment restored, got %v", changes)
+		}
+		if n := count(t, db, &models.Comment{}, "id = ? AND deleted_at IS NOT NULL", "CM2"); n != 1 {
+			t.Errorf("Expected separately deleted comment to stay in trash")
+		}
+	})
+
+	t.Run("TestTagNameConflict", func(t *testing.T) {
+		mustCreate(t, db, &models.Tag{ID: "TG1", Name: "travel"})
+		if _, err := repo.SoftDelete(ctx, models.TrashTag, "TG1"); err != nil {
+			t.Fatal(err)
+		}
+		mustCreate(t, db, &models.Tag{ID: "TG2", Name: "travel"})
+		if _, err := repo.Restore(ctx, models.TrashTag, "TG1"); !errors.Is(err, ErrTrashNameConflict) {
+			t.Errorf("Expected ErrTrashNameConflict, got %v", err)
+		}
+	})
+
+	t.Run("TestPurge", func(t *testing.T) {
+		if _, err := repo.SoftDelete(ctx, models.TrashTopic, "T1"); err != nil {
+			t.Fatal(err)
+		}
+		changes, err := repo.Purge(ctx, time.Now().Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changes[models.TrashTopic] != 1 || changes[models.TrashDialog] != 2 || changes[models.TrashComment] != 2 {
+			t.Errorf("Expected topic, dialogs and comments purged, got %v", changes)
+		}
+		if n := count(t, db, &models.CustomerTopicProgress{}, "topic_id = ?", "T1"); n != 0 {
+			t.Errorf("Expected topic progress purged, found %d", n)
+		}
+		if n := count(t, db, &models.Tag{}, "id = ?", "TG1"); n != 0 {
+			t.Errorf("Expected deleted tag purged, found %d", n)
+		}
+		if n := count(t, db, &models.Tag{}, "id = ?", "TG2"); n != 1 {
+			t.Errorf("Expected active tag kept, found %d", n)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/replica"
+	"gorm.io/gorm"
+)
+
+// txKey là khoá lưu transaction đang chạy trong context
+type txKey struct{}
+
+// UnitOfWork chạy nhiều thao tác repository trong cùng một transaction mang theo context
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork creates a new UnitOfWork instance
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// WithinTx chạy fn trong transaction; mọi repository được gọi với ctx truyền vào fn dùng chung transaction đó.
+// fn trả lỗi thì rollback. Gọi lồng nhau (ctx đã có transaction) sẽ tạo savepoint trong transaction hiện tại.
+func (u *UnitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, u.db).Transaction(func(tx *gorm.DB) error {
+		return fn(ContextWithTx(ctx, tx))
+	})
+}
+
+// ContextWithTx gắn transaction vào context (dùng khi transaction được mở bên ngoài UnitOfWork)
+func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext trả về transaction đang chạy trong context, nếu có
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// conn trả về transaction trong ctx nếu có, ngược lại là db gắn ctx; mọi repository truy vấn qua hàm này
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// readConn giống conn nhưng cho phép truy vấn đọc nặng chạy trên read replica (nếu có và không nằm trong transaction).
+// Chỉ dùng cho truy vấn chấp nhận dữ liệu trễ vài giây so với primary.
+func readConn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return replica.Read(conn(ctx, db))
+}
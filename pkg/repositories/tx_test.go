@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+)
+
+func TestUnitOfWork(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	uow := NewUnitOfWork(db)
+	repo := NewTopicRepository(db, NewTrashRepository(db))
+	boom := errors.New("boom")
+
+	t.Run("TestCommit", func(t *testing.T) {
+		err := uow.WithinTx(ctx, func(ctx context.Context) error {
+			if _, ok := TxFromContext(ctx); !ok {
+				t.Error("Expected transaction in context")
+			}
+			return repo.CreateTopic(ctx, &models.Topic{ID: "T_commit", Title: "Commit"})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := count(t, db, &models.Topic{}, "id = ?", "T_commit"); n != 1 {
+			t.Errorf("Expected committed topic, found %d", n)
+		}
+	})
+
+	t.Run("TestRollback", func(t *testing.T) {
+		err := uow.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repo.CreateTopic(ctx, &models.Topic{ID: "T_rollback", Title: "Rollback"}); err != nil {
+				return err
+			}
+			return boom
+		})
+		if !errors.Is(err, boom) {
+			t.Fatalf("Expected fn error, got %v", err)
+		}
+		if n := count(t, db, &models.Topic{}, "id = ?", "T_rollback"); n != 0 {
+			t.Errorf("Expected rolled back topic, found %d", n)
+		}
+	})
+
+	t.Run("TestNestedSavepoint", func(t *testing.T) {
+		err := uow.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repo.CreateTopic(ctx, &models.Topic{ID: "T_outer", Title: "Outer"}); err != nil {
+				return err
+			}
+			inner := uow.WithinTx(ctx, func(ctx context.Context) error {
+				if err := repo.CreateTopic(ctx, &models.Topic{ID: "T_inner", Title: "Inner"}); err != nil {
+					return err
+				}
+				return boom
+			})
+			if !errors.Is(inner, boom) {
+				t.Errorf("Expected inner error, got %v", inner)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := count(t, db, &models.Topic{}, "id IN ?", []string{"T_outer", "T_inner"}); n != 1 {
+			t.Errorf("Expected only the outer topic committed, found %d", n)
+		}
+	})
+}
@@ -28,7 +28,7 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 
 func (r *UserRepository) GetByUserID(ctx context.Context, tableName string, id string) (*User, error) {
     var user User
-    tx := r.db.WithContext(ctx).Table(tableName).Where("id = ?", id).First(&user)
+    tx := conn(ctx, r.db).Table(tableName).Where("id = ?", id).First(&user)
     if tx.Error != nil {
         if tx.Error == gorm.ErrRecordNotFound {
             return nil, nil
@@ -40,7 +40,7 @@ func (r *UserRepository) GetByUserID(ctx context.Context, tableName string, id s
 
 func (r *UserRepository) GetByUsername(ctx context.Context, tableName, username string) (*User, error) {
 	var user User
-	tx := r.db.WithContext(ctx).Table(tableName).Where("username = ?", username).First(&user)
+	tx := conn(ctx, r.db).Table(tableName).Where("username = ?", username).First(&user)
 	if tx.Error != nil {
 		if tx.Error == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -53,7 +53,7 @@ func (r *UserRepository) GetByUsername(ctx context.Context, tableName, username
 
 func (r *UserRepository) GetByEmail(ctx context.Context, tableName, email string) (*User, error) {
     var user User
-    tx := r.db.WithContext(ctx).Table(tableName).Where("email = ?", email).First(&user)
+    tx := conn(ctx, r.db).Table(tableName).Where("email = ?", email).First(&user)
     if tx.Error != nil {
         if tx.Error == gorm.ErrRecordNotFound {
             return nil, nil
@@ -64,7 +64,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, tableName, email string
 }
 
 func (r *UserRepository) Create(ctx context.Context, tableName string, user *User) (string, error) {
-	tx := r.db.WithContext(ctx).Table(tableName).Create(user)
+	tx := conn(ctx, r.db).Table(tableName).Create(user)
 	if tx.Error != nil {
 		return "", tx.Error
 	}
@@ -72,10 +72,10 @@ func (r *UserRepository) Create(ctx context.Context, tableName string, user *Use
 }
 
 func (r *UserRepository) UpdateUserInfor(ctx context.Context, tableName string, user *User) error {
-	return r.db.Table(tableName).Save(user).Error
+	return conn(ctx, r.db).Table(tableName).Save(user).Error
 }
 
 func (r *UserRepository) Delete(ctx context.Context, tableName string, id int64) error {
-	tx := r.db.WithContext(ctx).Table(tableName).Delete(&User{}, id)
+	tx := conn(ctx, r.db).Table(tableName).Delete(&User{}, id)
 	return tx.Error
 }
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+)
+
+// APIKeyPrefix đứng đầu mọi API key để dễ nhận diện khi bị lộ (secret scanning)
+const APIKeyPrefix = "ddk_"
+
+// APIKeyService tạo, xác thực và thu hồi API key dùng cho service-to-service
+type APIKeyService struct {
+	repo *repositories.APIKeyRepository
+}
+
+// NewAPIKeyService creates a new APIKeyService instance
+func NewAPIKeyService(repo *repositories.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// Create sinh API key mới gắn với ownerID và các role RBAC.
+// Key phải có ít nhất một role và chỉ được mang role mà owner đang có.
+// Key gốc chỉ được trả về một lần; DB chỉ lưu hash.
+func (s *APIKeyService) Create(ctx context.Context, ownerID string, req models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrAPIKeyNameRequired
+	}
+	if ownerID == "" {
+		return nil, ErrAPIKeyOwnerRequired
+	}
+	if req.ExpiresInDays < 0 {
+		return nil, ErrInvalidAPIKeyExpiry
+	}
+
+	if len(uniqueLower(req.Roles)) == 0 {
+		return nil, ErrAPIKeyRoleRequired
+	}
+	roles, err := s.repo.GetRolesByNames(ctx, req.Roles)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) != len(uniqueLower(req.Roles)) {
+		return nil, ErrAPIKeyUnknownRole
+	}
+	held, err := s.repo.GetUserRoleIDs(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if !holdsAll(held, roles) {
+		return nil, ErrAPIKeyRoleNotHeld
+	}
+
+	publicPart := make([]byte, 5)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(publicPart); err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	prefix := APIKeyPrefix + hex.EncodeToString(publicPart)
+	rawKey := prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	id, err := utils.GenerateUniqueID("api_key")
+	if err != nil {
+		return nil, err
+	}
+	key := &models.APIKey{
+		ID:      id,
+		Name:    name,
+		Prefix:  prefix,
+		KeyHash: hashRefreshToken(rawKey),
+		OwnerID: ownerID,
+		Roles:   roles,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to store API key: %w", err)
+	}
+	return &models.CreateAPIKeyResponse{Key: rawKey, APIKey: key}, nil
+}
+
+// Lookup xác thực API key và trả về principal mang role của key.
+// Dùng làm middleware.APIKeyLookup cho middleware.NewAPIKeyVerifier.
+func (s *APIKeyService) Lookup(ctx context.Context, rawKey string) (*pmodel.Principal, error) {
+	if !strings.HasPrefix(rawKey, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.repo.GetByHash(ctx, hashRefreshToken(rawKey))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	roles := make([]string, 0, len(key.Roles))
+	for _, role := range key.Roles {
+		roles = append(roles, role.Name)
+	}
+	return &pmodel.Principal{
+		ID:        key.ID,
+		Name:      key.Name,
+		Provider:  pmodel.TokenTypeAPIKey,
+		Roles:     roles,
+		TokenType: pmodel.TokenTypeAPIKey,
+	}, nil
+}
+
+// RecordCall ghi last-used và audit log cho một request dùng API key (middleware.APIKeyAudit)
+func (s *APIKeyService) RecordCall(ctx context.Context, keyID, method, path string, status int, ip string) error {
+	if len(path) > 255 {
+		path = path[:255]
+	}
+	return s.repo.RecordUsage(ctx, &models.APIKeyAuditLog{
+		APIKeyID:  keyID,
+		Method:    method,
+		Path:      path,
+		Status:    status,
+		IPAddress: ip,
+	})
+}
+
+// List trả về API key; ownerID rỗng thì lấy tất cả
+func (s *APIKeyService) List(ctx context.Context, ownerID string, includeRevoked bool) ([]models.APIKey, error) {
+	return s.repo.List(ctx, ownerID, includeRevoked)
+}
+
+// Revoke thu hồi API key ngay lập tức
+func (s *APIKeyService) Revoke(ctx context.Context, id string) error {
+	revoked, err := s.repo.Revoke(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// ListAuditLogs trả về lịch sử gọi API của key
+func (s *APIKeyService) ListAuditLogs(ctx context.Context, id string, page, pageSize int) ([]models.APIKeyAuditLog, int64, error) {
+	key, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if key == nil {
+		return nil, 0, ErrAPIKeyNotFound
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+	return s.repo.ListAuditLogs(ctx, id, pageSize, (page-1)*pageSize)
+}
+
+// holdsAll kiểm tra mọi role trong roles đều nằm trong held
+func holdsAll(held []int, roles []models.Role) bool {
+	set := make(map[int]bool, len(held))
+	for _, id := range held {
+		set[id] = true
+	}
+	for _, role := range roles {
+		if !set[role.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+func uniqueLower(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			set[v] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+)
+
+func TestHoldsAll(t *testing.T) {
+	roles := []models.Role{{ID: 1}, {ID: 3}}
+	cases := []struct {
+		name string
+		held []int
+		want bool
+	}{
+		{"None", nil, false},
+		{"Subset", []int{1}, false},
+		{"Exact", []int{1, 3}, true},
+		{"Superset", []int{1, 2, 3}, true},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			if got := holdsAll(tc.held, roles); got != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAPIKeyCreateRoles(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	admin := models.Role{Name: "apikey-admin"}
+	editor := models.Role{Name: "apikey-editor"}
+	for _, role := range []*models.Role{&admin, &editor} {
+		if err := db.Create(role).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Create(&models.UserRole{UserID: "E_editor", RoleID: editor.ID}).Error; err != nil {
+		t.Fatal(err)
+	}
+	s := NewAPIKeyService(repositories.NewAPIKeyRepository(db))
+
+	cases := []struct {
+		name  string
+		roles []string
+		want  error
+	}{
+		{"NoRoles", nil, ErrAPIKeyRoleRequired},
+		{"BlankRoles", []string{" "}, ErrAPIKeyRoleRequired},
+		{"UnknownRole", []string{"no-such-role"}, ErrAPIKeyUnknownRole},
+		{"RoleNotHeld", []string{"apikey-admin"}, ErrAPIKeyRoleNotHeld},
+		{"MixedRoles", []string{"apikey-editor", "apikey-admin"}, ErrAPIKeyRoleNotHeld},
+		{"HeldRole", []string{"APIKEY-EDITOR"}, nil},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			_, err := s.Create(ctx, "E_editor", models.CreateAPIKeyRequest{Name: tc.name, Roles: tc.roles})
+			if !errors.Is(err, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
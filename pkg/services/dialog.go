@@ -1,30 +1,310 @@
 package services
 
 import (
-	repo "github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"context"
+	"errors"
+	"sort"
+
 	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	repo "github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+	"gorm.io/gorm"
 )
 
 // DialogService handles business logic for dialogs
 type DialogService struct {
+	unitOfWork
 	dialogRepo *repo.DialogRepository
 }
 
-// NewDialogService creates a new DialogService instance
-
-func NewDialogService(dialogRepo *repo.DialogRepository, 
-	) *DialogService {
+// NewDialogService creates a new DialogService instance.
+// tx (thường là repositories.NewUnitOfWork(db)) bắt buộc cho các thao tác ghi giữ danh sách liên kết PrevID/NextID.
+func NewDialogService(dialogRepo *repo.DialogRepository, tx Transactor) *DialogService {
 	return &DialogService{
+		unitOfWork: unitOfWork{tx: tx},
 		dialogRepo: dialogRepo,
 	}
 }
 
 // GetAllDialogs retrieves all dialogs
-func (s *DialogService) GetAllDialogs() ([]models.Dialog, error) {
-	return s.dialogRepo.GetAllDialogs()
+func (s *DialogService) GetAllDialogs(ctx context.Context) ([]models.Dialog, error) {
+	return s.dialogRepo.GetAllDialogs(ctx)
 }
 
 // GetDialog retrieves a dialog by ID
-func (s *DialogService) GetDialog(dialogID string) (*models.Dialog, error) {
-	return s.dialogRepo.GetDialog(dialogID)
+func (s *DialogService) GetDialog(ctx context.Context, dialogID string) (*models.Dialog, error) {
+	return s.dialogRepo.GetDialog(ctx, dialogID)
+}
+
+// CreateDialog tạo dialog và chèn vào danh sách liên kết của topic tại pos (mặc định cuối danh sách)
+func (s *DialogService) CreateDialog(ctx context.Context, dialog *models.Dialog, pos models.DialogPosition) error {
+	if dialog.TopicID == "" {
+		dialog.TopicID = pos.TopicID
+	}
+	switch {
+	case dialog.TopicID == "":
+		return ErrTopicIDRequired
+	case dialog.Title == "":
+		return ErrDialogTitleRequired
+	case dialog.RawText == "":
+		return ErrDialogRawTextRequired
+	case dialog.AuthorID == "":
+		return ErrDialogAuthorRequired
+	case pos.AfterID != "" && pos.BeforeID != "":
+		return ErrDialogPositionInvalid
+	}
+	if dialog.ID == "" {
+		id, err := utils.GenerateUniqueID("dialog")
+		if err != nil {
+			return err
+		}
+		dialog.ID = id
+	}
+	if dialog.LevelID != nil {
+		if err := ensureLevel(ctx, s.dialogRepo.LevelExists, *dialog.LevelID); err != nil {
+			return err
+		}
+	}
+
+	return s.WithinTx(ctx, func(ctx context.Context) error {
+		links, order, err := s.lockChain(ctx, dialog.TopicID)
+		if err != nil {
+			return err
+		}
+		idx, err := insertIndex(order, pos)
+		if err != nil {
+			return err
+		}
+		order = insertAt(order, idx, dialog.ID)
+		dialog.PrevID, dialog.NextID = neighbours(order, idx)
+		if err := s.dialogRepo.CreateDialog(ctx, dialog); err != nil {
+			return err
+		}
+		links = append(links, models.Dialog{ID: dialog.ID, PrevID: dialog.PrevID, NextID: dialog.NextID})
+		return s.writeChain(ctx, links, order)
+	})
+}
+
+// UpdateDialog cập nhật nội dung dialog; fixerID (nếu có) được ghi là người sửa
+func (s *DialogService) UpdateDialog(ctx context.Context, id, fixerID string, req models.UpdateDialogRequest) (*models.Dialog, error) {
+	if id == "" {
+		return nil, ErrDialogIDRequired
+	}
+	fields := map[string]interface{}{}
+	if req.Title != nil {
+		if *req.Title == "" {
+			return nil, ErrDialogTitleRequired
+		}
+		fields["title"] = *req.Title
+	}
+	if req.RawText != nil {
+		if *req.RawText == "" {
+			return nil, ErrDialogRawTextRequired
+		}
+		fields["raw_text"] = *req.RawText
+	}
+	if req.Script != nil {
+		fields["script"] = *req.Script
+	}
+	if req.Result != nil {
+		fields["result"] = req.Result
+	}
+	if req.LevelID != nil {
+		if err := ensureLevel(ctx, s.dialogRepo.LevelExists, *req.LevelID); err != nil {
+			return nil, err
+		}
+		fields["level_id"] = levelRef(*req.LevelID)
+	}
+	if len(fields) > 0 {
+		if fixerID != "" {
+			fields["fixer_id"] = fixerID
+		}
+		if err := s.dialogRepo.UpdateDialogFields(ctx, id, fields); err != nil {
+			return nil, mapDialogNotFound(err)
+		}
+	}
+	dialog, err := s.dialogRepo.GetDialog(ctx, id)
+	return dialog, mapDialogNotFound(err)
+}
+
+// DeleteDialog chuyển dialog vào thùng rác và nối dialog trước với dialog sau nó
+func (s *DialogService) DeleteDialog(ctx context.Context, id string) error {
+	if id == "" {
+		return ErrDialogIDRequired
+	}
+	return s.WithinTx(ctx, func(ctx context.Context) error {
+		dialog, err := s.dialogRepo.GetDialog(ctx, id)
+		if err != nil {
+			return mapDialogNotFound(err)
+		}
+		if _, _, err := s.lockChain(ctx, dialog.TopicID); err != nil {
+			return err
+		}
+		return mapDialogNotFound(s.dialogRepo.SoftDeleteDialog(ctx, id))
+	})
+}
+
+// MoveDialog chuyển dialog tới vị trí pos, có thể sang topic khác (pos.TopicID);
+// danh sách liên kết của topic cũ và topic mới được cập nhật trong cùng transaction
+func (s *DialogService) MoveDialog(ctx context.Context, id string, pos models.DialogPosition) error {
+	switch {
+	case id == "":
+		return ErrDialogIDRequired
+	case pos.AfterID != "" && pos.BeforeID != "":
+		return ErrDialogPositionInvalid
+	case pos.AfterID == id || pos.BeforeID == id:
+		return ErrDialogPositionInvalid
+	}
+
+	return s.WithinTx(ctx, func(ctx context.Context) error {
+		dialog, err := s.dialogRepo.GetDialog(ctx, id)
+		if err != nil {
+			return mapDialogNotFound(err)
+		}
+		source, target := dialog.TopicID, pos.TopicID
+		if target == "" {
+			target = source
+		}
+
+		// Khoá hai topic theo thứ tự ID để hai lần chuyển ngược chiều không deadlock
+		topicIDs := []string{source}
+		if target != source {
+			topicIDs = append(topicIDs, target)
+			sort.Strings(topicIDs)
+		}
+		links := map[string][]models.Dialog{}
+		orders := map[string][]string{}
+		for _, topicID := range topicIDs {
+			if links[topicID], orders[topicID], err = s.lockChain(ctx, topicID); err != nil {
+				return err
+			}
+		}
+
+		sourceOrder := removeID(orders[source], id)
+		targetOrder := sourceOrder
+		if target != source {
+			targetOrder = orders[target]
+		}
+		idx, err := insertIndex(targetOrder, pos)
+		if err != nil {
+			return err
+		}
+		targetOrder = insertAt(targetOrder, idx, id)
+
+		if target == source {
+			return s.writeChain(ctx, links[source], targetOrder)
+		}
+		if err := s.dialogRepo.MoveDialogToTopic(ctx, id, source, target); err != nil {
+			return err
+		}
+		if err := s.writeChain(ctx, links[source], sourceOrder); err != nil {
+			return err
+		}
+		moved := models.Dialog{ID: id, PrevID: dialog.PrevID, NextID: dialog.NextID}
+		return s.writeChain(ctx, append(links[target], moved), targetOrder)
+	})
+}
+
+// ValidateTopicChain kiểm tra danh sách liên kết dialog của topic
+func (s *DialogService) ValidateTopicChain(ctx context.Context, topicID string) (*models.DialogChainReport, error) {
+	if topicID == "" {
+		return nil, ErrTopicIDRequired
+	}
+	links, err := s.dialogRepo.GetTopicChain(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	report := inspectChain(topicID, links)
+	return &report, nil
+}
+
+// FindBrokenChains kiểm tra mọi topic và trả về báo cáo của các topic có danh sách liên kết hỏng
+func (s *DialogService) FindBrokenChains(ctx context.Context) ([]models.DialogChainReport, error) {
+	links, err := s.dialogRepo.GetAllChains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reports := []models.DialogChainReport{}
+	for start := 0; start < len(links); {
+		end := start
+		for end < len(links) && links[end].TopicID == links[start].TopicID {
+			end++
+		}
+		if report := inspectChain(links[start].TopicID, links[start:end]); !report.Valid {
+			reports = append(reports, report)
+		}
+		start = end
+	}
+	return reports, nil
+}
+
+// RepairTopicChain dựng lại danh sách liên kết của topic (giữ các đoạn còn nối đúng) và trả về báo cáo sau khi sửa
+func (s *DialogService) RepairTopicChain(ctx context.Context, topicID string) (*models.DialogChainReport, error) {
+	if topicID == "" {
+		return nil, ErrTopicIDRequired
+	}
+	var report models.DialogChainReport
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		links, err := s.dialogRepo.LockTopicChain(ctx, topicID)
+		if err != nil {
+			return mapTopicNotFound(err)
+		}
+		if err := s.writeChain(ctx, links, repairedOrder(links)); err != nil {
+			return err
+		}
+		if links, err = s.dialogRepo.GetTopicChain(ctx, topicID); err != nil {
+			return err
+		}
+		report = inspectChain(topicID, links)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// lockChain khoá topic và trả về liên kết cùng thứ tự hiện tại; ErrDialogChainBroken nếu cần repair trước
+func (s *DialogService) lockChain(ctx context.Context, topicID string) ([]models.Dialog, []string, error) {
+	links, err := s.dialogRepo.LockTopicChain(ctx, topicID)
+	if err != nil {
+		return nil, nil, mapTopicNotFound(err)
+	}
+	order, err := chainOrder(topicID, links)
+	if err != nil {
+		return nil, nil, err
+	}
+	return links, order, nil
+}
+
+// writeChain ghi prev_id/next_id theo order, chỉ với dialog có liên kết thay đổi so với links
+func (s *DialogService) writeChain(ctx context.Context, links []models.Dialog, order []string) error {
+	current := make(map[string]*models.Dialog, len(links))
+	for i := range links {
+		current[links[i].ID] = &links[i]
+	}
+	for i, id := range order {
+		prev, next := neighbours(order, i)
+		if d, ok := current[id]; ok && linkID(d.PrevID) == linkID(prev) && linkID(d.NextID) == linkID(next) {
+			continue
+		}
+		if err := s.dialogRepo.UpdateDialogLinks(ctx, id, prev, next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mapDialogNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDialogNotFound
+	}
+	return err
+}
+
+func mapTopicNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTopicNotFound
+	}
+	return err
 }
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+)
+
+// Các dialog trong một topic tạo danh sách liên kết đôi qua PrevID/NextID.
+// Hàm trong file này chỉ làm việc trên liên kết đã nạp (id, prev_id, next_id), không truy cập database.
+
+func linkID(id *string) string {
+	if id == nil {
+		return ""
+	}
+	return *id
+}
+
+// inspectChain kiểm tra danh sách liên kết của topic: đúng một dialog đầu, liên kết hai chiều khớp nhau,
+// không có vòng và mọi dialog đều đi tới được từ dialog đầu
+func inspectChain(topicID string, links []models.Dialog) models.DialogChainReport {
+	report := models.DialogChainReport{TopicID: topicID, Order: []string{}}
+	byID := make(map[string]*models.Dialog, len(links))
+	for i := range links {
+		byID[links[i].ID] = &links[i]
+	}
+	addIssue := func(kind, dialogID, format string, args ...interface{}) {
+		report.Issues = append(report.Issues, models.DialogChainIssue{
+			Type: kind, DialogID: dialogID, Detail: fmt.Sprintf(format, args...),
+		})
+	}
+
+	var heads []string
+	for i := range links {
+		d := &links[i]
+		if prev := linkID(d.PrevID); prev == "" {
+			heads = append(heads, d.ID)
+		} else if p, ok := byID[prev]; !ok {
+			addIssue(models.DialogChainDanglingPrev, d.ID, "prev_id %s is not an active dialog of this topic", prev)
+		} else if linkID(p.NextID) != d.ID {
+			addIssue(models.DialogChainAsymmetricLink, d.ID, "prev_id is %s but %s.next_id is %q", prev, prev, linkID(p.NextID))
+		}
+		if next := linkID(d.NextID); next != "" {
+			if n, ok := byID[next]; !ok {
+				addIssue(models.DialogChainDanglingNext, d.ID, "next_id %s is not an active dialog of this topic", next)
+			} else if linkID(n.PrevID) != d.ID {
+				addIssue(models.DialogChainAsymmetricLink, d.ID, "next_id is %s but %s.prev_id is %q", next, next, linkID(n.PrevID))
+			}
+		}
+	}
+	if len(links) > 0 && len(heads) == 0 {
+		addIssue(models.DialogChainNoHead, "", "no dialog has an empty prev_id")
+	}
+	if len(heads) > 1 {
+		addIssue(models.DialogChainMultipleHeads, "", "dialogs %s all have an empty prev_id", strings.Join(heads, ", "))
+	}
+
+	// Mỗi dialog có tối đa một next nên vòng được tìm bằng cách đi theo next và đánh dấu đường đi
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make(map[string]int, len(links))
+	for i := range links {
+		var path []string
+		cur := links[i].ID
+		for cur != "" && state[cur] == unvisited {
+			state[cur] = onPath
+			path = append(path, cur)
+			next := linkID(byID[cur].NextID)
+			if _, ok := byID[next]; !ok {
+				break
+			}
+			if state[next] == onPath {
+				start := 0
+				for path[start] != next {
+					start++
+				}
+				addIssue(models.DialogChainCycle, next, "next_id links form a cycle: %s", strings.Join(path[start:], " -> "))
+				break
+			}
+			cur = next
+		}
+		for _, id := range path {
+			state[id] = done
+		}
+	}
+
+	if len(heads) > 0 {
+		report.Order = walkChain(byID, heads[0], map[string]bool{})
+	}
+	reached := make(map[string]bool, len(report.Order))
+	for _, id := range report.Order {
+		reached[id] = true
+	}
+	for i := range links {
+		if !reached[links[i].ID] {
+			addIssue(models.DialogChainUnreachable, links[i].ID, "not reachable from the first dialog")
+		}
+	}
+
+	report.Valid = len(report.Issues) == 0
+	return report
+}
+
+// walkChain đi theo next_id từ start, dừng khi gặp dialog đã đi qua hoặc liên kết hỏng
+func walkChain(byID map[string]*models.Dialog, start string, visited map[string]bool) []string {
+	var order []string
+	for cur := start; !visited[cur]; {
+		d, ok := byID[cur]
+		if !ok {
+			break
+		}
+		visited[cur] = true
+		order = append(order, cur)
+		cur = linkID(d.NextID)
+	}
+	return order
+}
+
+// repairedOrder dựng lại thứ tự hợp lệ: giữ các đoạn liên kết còn dùng được, bắt đầu từ các dialog đầu
+// (prev_id rỗng hoặc trỏ tới dialog không còn), sau đó tới các dialog còn lại theo ID
+func repairedOrder(links []models.Dialog) []string {
+	byID := make(map[string]*models.Dialog, len(links))
+	for i := range links {
+		byID[links[i].ID] = &links[i]
+	}
+	visited := make(map[string]bool, len(links))
+	order := make([]string, 0, len(links))
+	for i := range links {
+		if _, ok := byID[linkID(links[i].PrevID)]; !ok {
+			order = append(order, walkChain(byID, links[i].ID, visited)...)
+		}
+	}
+	for i := range links {
+		order = append(order, walkChain(byID, links[i].ID, visited)...)
+	}
+	return order
+}
+
+// chainOrder trả về thứ tự dialog của topic, ErrDialogChainBroken nếu danh sách liên kết hỏng
+func chainOrder(topicID string, links []models.Dialog) ([]string, error) {
+	report := inspectChain(topicID, links)
+	if !report.Valid {
+		return nil, fmt.Errorf("%w: topic %s (%s)", ErrDialogChainBroken, topicID, report.Issues[0].Detail)
+	}
+	return report.Order, nil
+}
+
+// insertIndex tính vị trí chèn trong order theo pos; order không chứa dialog đang chèn
+func insertIndex(order []string, pos models.DialogPosition) (int, error) {
+	anchor, offset := pos.AfterID, 1
+	if pos.BeforeID != "" {
+		anchor, offset = pos.BeforeID, 0
+	}
+	if anchor == "" {
+		return len(order), nil
+	}
+	for i, id := range order {
+		if id == anchor {
+			return i + offset, nil
+		}
+	}
+	return 0, ErrDialogAnchorNotFound
+}
+
+func insertAt(order []string, idx int, id string) []string {
+	order = append(order, "")
+	copy(order[idx+1:], order[idx:])
+	order[idx] = id
+	return order
+}
+
+func removeID(order []string, id string) []string {
+	result := make([]string, 0, len(order))
+	for _, v := range order {
+		if v != id {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// neighbours trả về dialog trước và sau vị trí i trong order
+func neighbours(order []string, i int) (prev, next *string) {
+	if i > 0 {
+		prev = &order[i-1]
+	}
+	if i < len(order)-1 {
+		next = &order[i+1]
+	}
+	return prev, next
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+)
+
+// link tạo dialog chỉ có liên kết; prev, next rỗng là không có
+func link(id, prev, next string) models.Dialog {
+	d := models.Dialog{ID: id}
+	if prev != "" {
+		d.PrevID = &prev
+	}
+	if next != "" {
+		d.NextID = &next
+	}
+	return d
+}
+
+func issueTypes(report models.DialogChainReport) []string {
+	types := []string{}
+	for _, issue := range report.Issues {
+		types = append(types, issue.Type)
+	}
+	return types
+}
+
+func TestInspectChain(t *testing.T) {
+	cases := []struct {
+		name   string
+		links  []models.Dialog
+		order  []string
+		issues []string
+	}{
+		{"Empty", nil, []string{}, []string{}},
+		{"Single", []models.Dialog{link("a", "", "")}, []string{"a"}, []string{}},
+		{"Valid", []models.Dialog{link("c", "b", ""), link("a", "", "b"), link("b", "a", "c")},
+			[]string{"a", "b", "c"}, []string{}},
+		{"DanglingPrev", []models.Dialog{link("a", "", ""), link("b", "x", "")},
+			[]string{"a"}, []string{models.DialogChainDanglingPrev, models.DialogChainUnreachable}},
+		{"DanglingNext", []models.Dialog{link("a", "", "x")},
+			[]string{"a"}, []string{models.DialogChainDanglingNext}},
+		{"Asymmetric", []models.Dialog{link("a", "", "b"), link("b", "", "")},
+			[]string{"a", "b"}, []string{models.DialogChainAsymmetricLink, models.DialogChainMultipleHeads}},
+		{"NoHeadCycle", []models.Dialog{link("a", "b", "b"), link("b", "a", "a")},
+			[]string{}, []string{models.DialogChainNoHead, models.DialogChainCycle,
+				models.DialogChainUnreachable, models.DialogChainUnreachable}},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			report := inspectChain("T1", tc.links)
+			if !reflect.DeepEqual(report.Order, tc.order) {
+				t.Errorf("Expected order %v, got %v", tc.order, report.Order)
+			}
+			if got := issueTypes(report); !reflect.DeepEqual(got, tc.issues) {
+				t.Errorf("Expected issues %v, got %v", tc.issues, got)
+			}
+			if report.Valid != (len(tc.issues) == 0) {
+				t.Errorf("Expected valid=%v, got %v", len(tc.issues) == 0, report.Valid)
+			}
+		})
+	}
+}
+
+func TestRepairedOrder(t *testing.T) {
+	cases := []struct {
+		name  string
+		links []models.Dialog
+		want  []string
+	}{
+		{"Valid", []models.Dialog{link("b", "a", ""), link("a", "", "b")}, []string{"a", "b"}},
+		{"TwoSegments", []models.Dialog{link("c", "x", "d"), link("d", "c", ""), link("a", "", "b"), link("b", "a", "")},
+			[]string{"c", "d", "a", "b"}},
+		{"Cycle", []models.Dialog{link("a", "b", "b"), link("b", "a", "a")}, []string{"a", "b"}},
+		{"CycleAfterHead", []models.Dialog{link("a", "", "b"), link("b", "a", "c"), link("c", "b", "b")},
+			[]string{"a", "b", "c"}},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			got := repairedOrder(tc.links)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+			if report := inspectChain("T1", chainLinks(got)); !report.Valid {
+				t.Errorf("Expected repaired order to form a valid chain, got %+v", report.Issues)
+			}
+		})
+	}
+}
+
+// chainLinks dựng liên kết đúng cho order, như writeChain ghi xuống database
+func chainLinks(order []string) []models.Dialog {
+	links := make([]models.Dialog, len(order))
+	for i, id := range order {
+		prev, next := neighbours(order, i)
+		links[i] = models.Dialog{ID: id, PrevID: prev, NextID: next}
+	}
+	return links
+}
+
+func TestInsertIndex(t *testing.T) {
+	order := []string{"a", "b", "c"}
+	cases := []struct {
+		name    string
+		pos     models.DialogPosition
+		want    int
+		wantErr error
+	}{
+		{"Default", models.DialogPosition{}, 3, nil},
+		{"AfterFirst", models.DialogPosition{AfterID: "a"}, 1, nil},
+		{"AfterLast", models.DialogPosition{AfterID: "c"}, 3, nil},
+		{"BeforeFirst", models.DialogPosition{BeforeID: "a"}, 0, nil},
+		{"BeforeLast", models.DialogPosition{BeforeID: "c"}, 2, nil},
+		{"BeforeWins", models.DialogPosition{AfterID: "c", BeforeID: "a"}, 0, nil},
+		{"UnknownAnchor", models.DialogPosition{AfterID: "x"}, 0, ErrDialogAnchorNotFound},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			got, err := insertIndex(order, tc.pos)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected index %d, got %d", tc.want, got)
+			}
+		})
+	}
+
+	t.Run("TestEmptyOrder", func(t *testing.T) {
+		if got, err := insertIndex(nil, models.DialogPosition{}); err != nil || got != 0 {
+			t.Errorf("Expected 0, got %d, %v", got, err)
+		}
+	})
+}
+
+func TestInsertAt(t *testing.T) {
+	cases := []struct {
+		name  string
+		order []string
+		idx   int
+		want  []string
+	}{
+		{"Empty", []string{}, 0, []string{"x"}},
+		{"Front", []string{"a", "b"}, 0, []string{"x", "a", "b"}},
+		{"Middle", []string{"a", "b"}, 1, []string{"a", "x", "b"}},
+		{"End", []string{"a", "b"}, 2, []string{"a", "b", "x"}},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			if got := insertAt(tc.order, tc.idx, "x"); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNeighbours(t *testing.T) {
+	order := []string{"a", "b", "c"}
+	cases := []struct {
+		name       string
+		order      []string
+		i          int
+		prev, next string
+	}{
+		{"Single", []string{"a"}, 0, "", ""},
+		{"First", order, 0, "", "b"},
+		{"Middle", order, 1, "a", "c"},
+		{"Last", order, 2, "b", ""},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			prev, next := neighbours(tc.order, tc.i)
+			if linkID(prev) != tc.prev || linkID(next) != tc.next {
+				t.Errorf("Expected (%q, %q), got (%q, %q)", tc.prev, tc.next, linkID(prev), linkID(next))
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+)
+
+// PasswordResetNotifier gửi reset token tới employee (thường qua email)
+type PasswordResetNotifier interface {
+	SendPasswordReset(ctx context.Context, email, token string, expiresAt time.Time) error
+}
+
+// EmployeeAuthConfig cấu hình đăng nhập bằng mật khẩu cho nhân viên
+type EmployeeAuthConfig struct {
+	MaxFailedAttempts int           // số lần sai liên tiếp trước khi khóa, mặc định 5
+	LockoutDuration   time.Duration // thời gian khóa, mặc định 15 phút
+	ResetTokenTTL     time.Duration // hiệu lực reset token, mặc định 1 giờ
+	MinPasswordLength int           // mặc định 8
+	Argon2            utils.Argon2Params
+}
+
+// EmployeeLoginResult là kết quả đăng nhập thành công
+type EmployeeLoginResult struct {
+	AccessToken string           `json:"access_token"`
+	SessionID   string           `json:"session_id"`
+	Employee    *models.Employee `json:"employee"`
+}
+
+// EmployeeAuthService xác thực nhân viên bằng email/mật khẩu (không qua Firebase)
+type EmployeeAuthService struct {
+	repo        *repositories.EmployeeRepository
+	hasher      *utils.PasswordHasher
+	issueAccess AccessTokenIssuer
+	revoker     SessionRevoker
+	notifier    PasswordResetNotifier
+	cfg         EmployeeAuthConfig
+
+	// dummyHash dùng khi email không tồn tại để thời gian phản hồi không lộ email nào có tài khoản
+	dummyHash string
+}
+
+// NewEmployeeAuthService creates a new EmployeeAuthService instance.
+// revoker có thể nil; khi có, đổi/đặt lại mật khẩu sẽ thu hồi mọi access token cũ của employee.
+func NewEmployeeAuthService(repo *repositories.EmployeeRepository, issueAccess AccessTokenIssuer,
+	revoker SessionRevoker, notifier PasswordResetNotifier, cfg EmployeeAuthConfig) *EmployeeAuthService {
+	if cfg.MaxFailedAttempts <= 0 {
+		cfg.MaxFailedAttempts = 5
+	}
+	if cfg.LockoutDuration <= 0 {
+		cfg.LockoutDuration = 15 * time.Minute
+	}
+	if cfg.ResetTokenTTL <= 0 {
+		cfg.ResetTokenTTL = time.Hour
+	}
+	if cfg.MinPasswordLength <= 0 {
+		cfg.MinPasswordLength = 8
+	}
+	if cfg.Argon2 == (utils.Argon2Params{}) {
+		cfg.Argon2 = utils.DefaultArgon2Params
+	}
+	hasher := utils.NewPasswordHasher(cfg.Argon2)
+	dummyHash, _ := hasher.Hash("dummy-password")
+	return &EmployeeAuthService{
+		repo:        repo,
+		hasher:      hasher,
+		issueAccess: issueAccess,
+		revoker:     revoker,
+		notifier:    notifier,
+		cfg:         cfg,
+		dummyHash:   dummyHash,
+	}
+}
+
+// HashPassword băm mật khẩu theo cấu hình hiện tại, dùng khi tạo employee
+func (s *EmployeeAuthService) HashPassword(password string) (string, error) {
+	if err := s.validatePassword(password); err != nil {
+		return "", err
+	}
+	return s.hasher.Hash(password)
+}
+
+// Login kiểm tra email/mật khẩu, áp dụng khóa tài khoản và phát access token qua JWT hiện có
+func (s *EmployeeAuthService) Login(ctx context.Context, email, password string) (*EmployeeLoginResult, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, ErrEmailRequired
+	}
+	if password == "" {
+		return nil, ErrPasswordRequired
+	}
+
+	employee, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if employee == nil || employee.Password == "" {
+		_, _, _ = s.hasher.Verify(password, s.dummyHash)
+		return nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	if employee.LockedUntil != nil && now.Before(*employee.LockedUntil) {
+		return nil, ErrAccountLocked
+	}
+
+	ok, needsRehash, err := s.hasher.Verify(password, employee.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify password: %w", err)
+	}
+	if !ok {
+		if err := s.repo.RecordFailedLogin(ctx, employee.ID, s.cfg.MaxFailedAttempts, now.Add(s.cfg.LockoutDuration)); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	var newHash string
+	if needsRehash {
+		// Lỗi rehash không chặn đăng nhập; lần sau sẽ thử lại
+		newHash, _ = s.hasher.Hash(password)
+	}
+	if err := s.repo.RecordSuccessfulLogin(ctx, employee.ID, newHash); err != nil {
+		return nil, err
+	}
+
+	sessionID, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := s.issueAccess(employee.ID, employee.Email, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue access token: %w", err)
+	}
+	employee.LastLogin = &now
+	return &EmployeeLoginResult{AccessToken: accessToken, SessionID: sessionID, Employee: employee}, nil
+}
+
+// ChangePassword đổi mật khẩu khi đã đăng nhập và thu hồi các access token cũ
+func (s *EmployeeAuthService) ChangePassword(ctx context.Context, employeeID, currentPassword, newPassword string) error {
+	employee, err := s.repo.GetByID(ctx, employeeID)
+	if err != nil {
+		return err
+	}
+	if employee == nil {
+		return ErrUserNotFound
+	}
+	ok, _, err := s.hasher.Verify(currentPassword, employee.Password)
+	if err != nil || !ok {
+		return ErrInvalidPassword
+	}
+	hash, err := s.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(ctx, employeeID, hash); err != nil {
+		return err
+	}
+	return s.revokeSessions(ctx, employeeID, "password changed")
+}
+
+// RequestPasswordReset tạo reset token và gửi qua notifier.
+// Email không tồn tại hay lỗi lưu/gửi token đều trả về nil (lỗi chỉ được log) để phản hồi
+// không lộ email nào có tài khoản.
+func (s *EmployeeAuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return ErrEmailRequired
+	}
+	if err := s.sendPasswordReset(ctx, email); err != nil {
+		log.Printf("password reset: %v", err)
+	}
+	return nil
+}
+
+// sendPasswordReset tạo và gửi reset token nếu email thuộc một employee
+func (s *EmployeeAuthService) sendPasswordReset(ctx context.Context, email string) error {
+	employee, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to look up employee: %w", err)
+	}
+	if employee == nil {
+		return nil
+	}
+	if s.notifier == nil {
+		return fmt.Errorf("password reset notifier is not configured")
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	rawToken := base64.RawURLEncoding.EncodeToString(buf)
+	id, err := utils.GenerateUniqueID("password_reset")
+	if err != nil {
+		return err
+	}
+	token := &models.PasswordResetToken{
+		ID:         id,
+		EmployeeID: employee.ID,
+		TokenHash:  hashRefreshToken(rawToken),
+		ExpiresAt:  time.Now().Add(s.cfg.ResetTokenTTL),
+	}
+	if err := s.repo.CreateResetToken(ctx, token); err != nil {
+		return fmt.Errorf("failed to store reset token for employee %s: %w", employee.ID, err)
+	}
+	if err := s.notifier.SendPasswordReset(ctx, employee.Email, rawToken, token.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to send reset token to employee %s: %w", employee.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword đặt mật khẩu mới bằng reset token, mở khóa tài khoản và thu hồi các access token cũ
+func (s *EmployeeAuthService) ResetPassword(ctx context.Context, rawToken, newPassword string) error {
+	if rawToken == "" {
+		return ErrInvalidResetToken
+	}
+	hash, err := s.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	employeeID, err := s.repo.ConsumeResetToken(ctx, hashRefreshToken(rawToken), hash)
+	if err != nil {
+		return err
+	}
+	if employeeID == "" {
+		return ErrInvalidResetToken
+	}
+	return s.revokeSessions(ctx, employeeID, "password reset")
+}
+
+func (s *EmployeeAuthService) validatePassword(password string) error {
+	if len([]rune(password)) < s.cfg.MinPasswordLength {
+		return ErrPasswordTooWeak
+	}
+	return nil
+}
+
+func (s *EmployeeAuthService) revokeSessions(ctx context.Context, employeeID, reason string) error {
+	if s.revoker == nil {
+		return nil
+	}
+	if err := s.revoker.RevokeUser(ctx, employeeID, reason); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return nil
+}
+
+func newSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
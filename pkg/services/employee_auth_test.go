@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+)
+
+// fakeResetNotifier ghi lại các email đã gửi; err khác nil thì mọi lần gửi đều lỗi
+type fakeResetNotifier struct {
+	sent []string
+	err  error
+}
+
+func (n *fakeResetNotifier) SendPasswordReset(ctx context.Context, email, token string, expiresAt time.Time) error {
+	n.sent = append(n.sent, email)
+	return n.err
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	repo := repositories.NewEmployeeRepository(db)
+	if err := db.Create(&models.Employee{ID: "E_reset", Name: "Reset", Email: "staff@example.com"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	newService := func(notifier PasswordResetNotifier) *EmployeeAuthService {
+		return NewEmployeeAuthService(repo, nil, nil, notifier, EmployeeAuthConfig{Argon2: utils.Argon2Params{
+			Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32,
+		}})
+	}
+	countTokens := func() int64 {
+		var n int64
+		db.Model(&models.PasswordResetToken{}).Where("employee_id = ?", "E_reset").Count(&n)
+		return n
+	}
+
+	t.Run("TestEmptyEmail", func(t *testing.T) {
+		if err := newService(&fakeResetNotifier{}).RequestPasswordReset(ctx, " "); !errors.Is(err, ErrEmailRequired) {
+			t.Errorf("Expected ErrEmailRequired, got %v", err)
+		}
+	})
+
+	t.Run("TestUnknownEmail", func(t *testing.T) {
+		notifier := &fakeResetNotifier{}
+		if err := newService(notifier).RequestPasswordReset(ctx, "nobody@example.com"); err != nil {
+			t.Errorf("Expected nil for unknown email, got %v", err)
+		}
+		if len(notifier.sent) != 0 {
+			t.Errorf("Expected no email sent, got %v", notifier.sent)
+		}
+	})
+
+	t.Run("TestNotifierFailure", func(t *testing.T) {
+		notifier := &fakeResetNotifier{err: errors.New("smtp down")}
+		if err := newService(notifier).RequestPasswordReset(ctx, "staff@example.com"); err != nil {
+			t.Errorf("Expected notifier failure to be hidden, got %v", err)
+		}
+		if len(notifier.sent) != 1 {
+			t.Errorf("Expected one send attempt, got %d", len(notifier.sent))
+		}
+	})
+
+	t.Run("TestNotifierMissing", func(t *testing.T) {
+		before := countTokens()
+		if err := newService(nil).RequestPasswordReset(ctx, "staff@example.com"); err != nil {
+			t.Errorf("Expected missing notifier to be hidden, got %v", err)
+		}
+		if after := countTokens(); after != before {
+			t.Errorf("Expected no token stored without notifier, got %d new", after-before)
+		}
+	})
+
+	t.Run("TestSent", func(t *testing.T) {
+		notifier := &fakeResetNotifier{}
+		before := countTokens()
+		if err := newService(notifier).RequestPasswordReset(ctx, "staff@example.com"); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+		if len(notifier.sent) != 1 || notifier.sent[0] != "staff@example.com" {
+			t.Errorf("Expected reset email sent, got %v", notifier.sent)
+		}
+		if after := countTokens(); after != before+1 {
+			t.Errorf("Expected one reset token stored, got %d new", after-before)
+		}
+	})
+}
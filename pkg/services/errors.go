@@ -9,6 +9,12 @@ var (
 	ErrTopicNotFound     = errors.New("topic not found")
 	ErrTopicExists       = errors.New("topic already exists")
 
+	ErrTopicVisibilityInvalid = errors.New("visibility must be one of public, private, draft")
+	ErrTopicDifficultyInvalid = errors.New("difficulty must be between 0 and 5")
+	ErrTopicReorderInvalid    = errors.New("topic_ids must be a non-empty list of distinct IDs")
+	ErrTopicCoverURLRequired  = errors.New("cover image file_url is required")
+	ErrTopicCoverNotFound     = errors.New("topic has no cover image")
+
 	// Dialog errors
 	ErrDialogRawTextRequired = errors.New("dialog raw text is required")
 	ErrDialogScriptRequired  = errors.New("dialog script is required")
@@ -16,6 +22,10 @@ var (
 	ErrDialogLevelIDRequired = errors.New("dialog level ID is required")
 	ErrDialogIDRequired      = errors.New("dialog ID is required")
 	ErrDialogNotFound        = errors.New("dialog not found")
+	ErrDialogAuthorRequired  = errors.New("dialog author is required")
+	ErrDialogPositionInvalid = errors.New("set only one of after_id and before_id, and not the dialog itself")
+	ErrDialogAnchorNotFound  = errors.New("after_id/before_id is not a dialog of the target topic")
+	ErrDialogChainBroken     = errors.New("dialog chain of the topic is broken, repair it first")
 
 	// Audio errors
 	ErrAudioPathRequired = errors.New("audio path is required")
@@ -27,6 +37,9 @@ var (
 	ErrLevelNameRequired = errors.New("level name is required")
 	ErrLevelIDRequired   = errors.New("level ID is required")
 	ErrLevelNotFound     = errors.New("level not found")
+	ErrLevelCodeRequired = errors.New("level code is required")
+	ErrLevelExists       = errors.New("level code already exists")
+	ErrLevelBuiltIn      = errors.New("CEFR levels cannot be deleted or have their code changed")
 
 	// User errors
 	ErrEmailRequired     = errors.New("email is required")
@@ -37,12 +50,76 @@ var (
 	ErrInvalidUserData   = errors.New("invalid user data")
 	ErrInvalidPassword   = errors.New("invalid password")
 
+	// Employee login errors
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrAccountLocked      = errors.New("account is temporarily locked due to too many failed login attempts")
+	ErrPasswordTooWeak    = errors.New("password is too short")
+	ErrInvalidResetToken  = errors.New("invalid or expired password reset token")
+
+	// Two-factor authentication errors
+	ErrMFANotEnrolled     = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnrolled = errors.New("two-factor authentication is already enabled")
+	ErrMFACodeRequired    = errors.New("two-factor authentication code is required")
+	ErrInvalidMFACode     = errors.New("invalid two-factor authentication code")
+	ErrMFALocked          = errors.New("two-factor authentication is temporarily locked due to too many failed attempts")
+
+	// Refresh token errors
+	ErrRefreshTokenRequired       = errors.New("refresh token is required")
+	ErrInvalidRefreshToken        = errors.New("invalid refresh token")
+	ErrRefreshTokenExpired        = errors.New("refresh token expired")
+	ErrRefreshTokenReused         = errors.New("refresh token reuse detected")
+	ErrRefreshTokenDeviceMismatch = errors.New("refresh token was issued to another device")
+
+	// API key errors
+	ErrAPIKeyNameRequired  = errors.New("API key name is required")
+	ErrAPIKeyOwnerRequired = errors.New("API key owner is required")
+	ErrAPIKeyUnknownRole   = errors.New("one or more roles do not exist")
+	ErrAPIKeyRoleRequired  = errors.New("at least one role is required")
+	ErrAPIKeyRoleNotHeld   = errors.New("cannot grant a role the owner does not hold")
+	ErrInvalidAPIKeyExpiry = errors.New("expires_in_days must not be negative")
+	ErrInvalidAPIKey       = errors.New("invalid API key")
+	ErrAPIKeyExpired       = errors.New("API key expired")
+	ErrAPIKeyNotFound      = errors.New("API key not found")
+
+	// Guest account errors
+	ErrDeviceIDRequired  = errors.New("device ID is required")
+	ErrInvalidGuestToken = errors.New("invalid or expired guest token")
+	ErrGuestUpgradeSelf  = errors.New("guest account cannot be merged into itself or another guest")
+
+	// Session errors
+	ErrSessionNotFound         = errors.New("session not found")
+	ErrSessionTrackingDisabled = errors.New("session tracking is not configured")
+
+	// SMS verification errors
+	ErrPhoneNumberRequired = errors.New("phone number is required")
+	ErrInvalidPhoneNumber  = errors.New("invalid phone number")
+	ErrPhoneNumberInUse    = errors.New("phone number is already used by another account")
+	ErrSMSCodeRequired     = errors.New("verification code is required")
+	ErrSMSCodeInvalid      = errors.New("invalid verification code")
+	ErrSMSCodeExpired      = errors.New("verification code expired")
+	ErrSMSTooManyAttempts  = errors.New("too many incorrect attempts, request a new code")
+	ErrSMSResendCooldown   = errors.New("please wait before requesting another code")
+	ErrSMSTooManyRequests  = errors.New("too many verification codes requested, try again later")
+
 	// Comment errors
 	ErrParentCommentIDRequired       = errors.New("parent comment ID is required")
 	ErrParentCommentDialogIDNotMatch = errors.New("parent comment dialog ID does not match")
 
+	// Subscription errors
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+
+	// Transaction errors
+	ErrUnitOfWorkNotConfigured = errors.New("unit of work is not configured for this service")
+
 	// Tag errors
 	ErrTagNameRequired  = errors.New("tag name is required")
 	ErrTagNotFound      = errors.New("tag not found")
 	ErrTagAlreadyExists = errors.New("tag already exists")
+
+	// Trash errors
+	ErrTrashKindInvalid   = errors.New("type must be one of topic, dialog, comment, tag, image")
+	ErrTrashIDRequired    = errors.New("ID is required")
+	ErrTrashItemNotFound  = errors.New("item not found")
+	ErrTrashParentDeleted = errors.New("parent item is in trash, restore it first")
+	ErrTrashNameConflict  = errors.New("an active item with the same name already exists")
 )
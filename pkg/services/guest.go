@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+)
+
+// DefaultGuestRole là role RBAC giới hạn gán cho tài khoản khách
+const DefaultGuestRole = "guest"
+
+// GuestSession là kết quả tạo tài khoản khách
+type GuestSession struct {
+	Customer *models.Customer `json:"customer"`
+	*TokenPair
+}
+
+// GuestService tạo tài khoản khách gắn với thiết bị và gộp tiến độ khi khách đăng ký thật
+type GuestService struct {
+	repo        *repositories.GuestRepository
+	refreshRepo *repositories.RefreshTokenRepository
+	tokens      *TokenService
+	guestRole   string
+}
+
+// NewGuestService creates a new GuestService instance; guestRole rỗng thì dùng DefaultGuestRole
+func NewGuestService(repo *repositories.GuestRepository, refreshRepo *repositories.RefreshTokenRepository,
+	tokens *TokenService, guestRole string) *GuestService {
+	if guestRole == "" {
+		guestRole = DefaultGuestRole
+	}
+	return &GuestService{repo: repo, refreshRepo: refreshRepo, tokens: tokens, guestRole: guestRole}
+}
+
+// CreateGuest tạo tài khoản khách và cấp cặp token ràng buộc với device.DeviceID
+func (s *GuestService) CreateGuest(ctx context.Context, device models.DeviceInfo) (*GuestSession, error) {
+	if device.DeviceID == "" {
+		return nil, ErrDeviceIDRequired
+	}
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate guest id: %w", err)
+	}
+	suffix := hex.EncodeToString(buf)
+	now := time.Now()
+	customer := &models.Customer{
+		ID:        "guest_" + suffix,
+		Name:      "Guest " + suffix[:8],
+		IsGuest:   true,
+		LastLogin: &now,
+	}
+	if err := s.repo.CreateGuest(ctx, customer, s.guestRole); err != nil {
+		return nil, fmt.Errorf("failed to create guest: %w", err)
+	}
+	pair, err := s.tokens.IssueTokens(ctx, customer.ID, "", device)
+	if err != nil {
+		return nil, err
+	}
+	return &GuestSession{Customer: customer, TokenPair: pair}, nil
+}
+
+// Upgrade gộp tiến độ, thống kê và thành tựu của tài khoản khách vào customerID
+// (customer vừa đăng nhập qua Firebase) rồi xóa tài khoản khách.
+// guestRefreshToken chứng minh người gọi đang giữ thiết bị của tài khoản khách.
+func (s *GuestService) Upgrade(ctx context.Context, customerID, guestRefreshToken, deviceID string) error {
+	if guestRefreshToken == "" {
+		return ErrRefreshTokenRequired
+	}
+	token, err := s.refreshRepo.GetByHash(ctx, hashRefreshToken(guestRefreshToken))
+	if err != nil || token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return ErrInvalidGuestToken
+	}
+	// Token đã bị xoay vòng: giống Refresh, coi là reuse và thu hồi cả family
+	if token.UsedAt != nil {
+		if err := s.tokens.revokeFamily(ctx, token.FamilyID, token.CustomerID, "refresh token reuse"); err != nil {
+			return err
+		}
+		return ErrRefreshTokenReused
+	}
+	if token.DeviceID != "" && token.DeviceID != deviceID {
+		return ErrRefreshTokenDeviceMismatch
+	}
+	if token.CustomerID == customerID {
+		return ErrGuestUpgradeSelf
+	}
+
+	guest, err := s.repo.GetCustomer(ctx, token.CustomerID)
+	if err != nil {
+		return err
+	}
+	if guest == nil || !guest.IsGuest {
+		return ErrInvalidGuestToken
+	}
+	target, err := s.repo.GetCustomer(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return ErrUserNotFound
+	}
+	if target.IsGuest {
+		return ErrGuestUpgradeSelf
+	}
+
+	merged, err := s.repo.MergeInto(ctx, guest.ID, customerID, MergeProgress)
+	if err != nil {
+		return fmt.Errorf("failed to merge guest progress: %w", err)
+	}
+	if !merged {
+		return ErrInvalidGuestToken
+	}
+	// Tài khoản khách đã bị xóa; thu hồi các access token còn hạn của nó
+	return s.tokens.LogoutAll(ctx, guest.ID)
+}
+
+// CleanupInactiveGuests xóa tài khoản khách không hoạt động lâu hơn maxIdle
+func (s *GuestService) CleanupInactiveGuests(ctx context.Context, maxIdle time.Duration) (int64, error) {
+	return s.repo.DeleteInactiveGuests(ctx, time.Now().Add(-maxIdle))
+}
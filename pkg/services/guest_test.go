@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+)
+
+func TestGuestUpgrade(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	if err := db.Where("name = ?", DefaultGuestRole).FirstOrCreate(&models.Role{Name: DefaultGuestRole}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.Customer{ID: "C_target", Name: "Target"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	issue := func(userID, email, sessionID string) (string, error) { return "access-" + sessionID, nil }
+	refreshRepo := repositories.NewRefreshTokenRepository(db)
+	tokens := NewTokenService(refreshRepo, repositories.NewUserRepository(db), issue, nil, time.Hour)
+	s := NewGuestService(repositories.NewGuestRepository(db), refreshRepo, tokens, "")
+	device := models.DeviceInfo{DeviceID: "phone-1"}
+
+	t.Run("TestRotatedTokenRejected", func(t *testing.T) {
+		guest, err := s.CreateGuest(ctx, device)
+		if err != nil {
+			t.Fatalf("CreateGuest: %v", err)
+		}
+		rotated, err := tokens.Refresh(ctx, guest.RefreshToken, device)
+		if err != nil {
+			t.Fatalf("Refresh: %v", err)
+		}
+
+		if err := s.Upgrade(ctx, "C_target", guest.RefreshToken, device.DeviceID); !errors.Is(err, ErrRefreshTokenReused) {
+			t.Fatalf("Expected ErrRefreshTokenReused for a rotated token, got %v", err)
+		}
+		// Reuse thu hồi cả family nên token mới nhất cũng không dùng được nữa
+		if err := s.Upgrade(ctx, "C_target", rotated.RefreshToken, device.DeviceID); !errors.Is(err, ErrInvalidGuestToken) {
+			t.Errorf("Expected family to be revoked, got %v", err)
+		}
+		var n int64
+		db.Model(&models.Customer{}).Where("id = ?", guest.Customer.ID).Count(&n)
+		if n != 1 {
+			t.Errorf("Expected guest not to be merged, found %d rows", n)
+		}
+	})
+
+	t.Run("TestUpgrade", func(t *testing.T) {
+		guest, err := s.CreateGuest(ctx, device)
+		if err != nil {
+			t.Fatalf("CreateGuest: %v", err)
+		}
+		if err := s.Upgrade(ctx, "C_target", guest.RefreshToken, device.DeviceID); err != nil {
+			t.Fatalf("Expected upgrade to succeed, got %v", err)
+		}
+	})
+}
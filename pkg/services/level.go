@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	repo "github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+	"gorm.io/gorm"
+)
+
+// LevelService quản lý level (A1–C2 và level tự định nghĩa) và tiến độ học theo level
+type LevelService struct {
+	levelRepo *repo.LevelRepository
+}
+
+// NewLevelService creates a new LevelService instance
+func NewLevelService(levelRepo *repo.LevelRepository) *LevelService {
+	return &LevelService{levelRepo: levelRepo}
+}
+
+// GetAllLevels lấy tất cả level theo thứ tự từ dễ tới khó
+func (s *LevelService) GetAllLevels(ctx context.Context) ([]models.Level, error) {
+	return s.levelRepo.GetAllLevels(ctx)
+}
+
+// GetLevel lấy level theo ID
+func (s *LevelService) GetLevel(ctx context.Context, id string) (*models.Level, error) {
+	if id == "" {
+		return nil, ErrLevelIDRequired
+	}
+	level, err := s.levelRepo.GetLevel(ctx, id)
+	return level, mapLevelNotFound(err)
+}
+
+// CreateLevel tạo level tự định nghĩa; mã level được viết hoa
+func (s *LevelService) CreateLevel(ctx context.Context, req models.CreateLevelRequest) (*models.Level, error) {
+	level := &models.Level{
+		Code:        normalizeLevelCode(req.Code),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Rank:        req.Rank,
+	}
+	if err := validateLevel(level); err != nil {
+		return nil, err
+	}
+	if err := s.ensureCodeAvailable(ctx, level.Code, ""); err != nil {
+		return nil, err
+	}
+	id, err := utils.GenerateUniqueID("level")
+	if err != nil {
+		return nil, err
+	}
+	level.ID = id
+	if err := s.levelRepo.CreateLevel(ctx, level); err != nil {
+		return nil, err
+	}
+	return level, nil
+}
+
+// UpdateLevel cập nhật các trường khác nil; level CEFR không đổi được mã
+func (s *LevelService) UpdateLevel(ctx context.Context, id string, req models.UpdateLevelRequest) (*models.Level, error) {
+	level, err := s.GetLevel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if req.Code != nil {
+		code := normalizeLevelCode(*req.Code)
+		if code != level.Code {
+			if level.IsCEFR() {
+				return nil, ErrLevelBuiltIn
+			}
+			level.Code = code
+			fields["code"] = code
+		}
+	}
+	if req.Name != nil {
+		level.Name = strings.TrimSpace(*req.Name)
+		fields["name"] = level.Name
+	}
+	if req.Description != nil {
+		level.Description = *req.Description
+		fields["description"] = level.Description
+	}
+	if req.Rank != nil {
+		level.Rank = *req.Rank
+		fields["rank"] = level.Rank
+	}
+	if len(fields) == 0 {
+		return level, nil
+	}
+	if err := validateLevel(level); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["code"]; ok {
+		if err := s.ensureCodeAvailable(ctx, level.Code, id); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.levelRepo.UpdateLevelFields(ctx, id, fields); err != nil {
+		return nil, mapLevelNotFound(err)
+	}
+	return s.GetLevel(ctx, id)
+}
+
+// DeleteLevel xoá level tự định nghĩa; topic và dialog đang dùng level này được bỏ gán
+func (s *LevelService) DeleteLevel(ctx context.Context, id string) error {
+	level, err := s.GetLevel(ctx, id)
+	if err != nil {
+		return err
+	}
+	if level.IsCEFR() {
+		return ErrLevelBuiltIn
+	}
+	return mapLevelNotFound(s.levelRepo.DeleteLevel(ctx, id))
+}
+
+// GetLevelProgress trả về tiến độ học của customer theo từng level
+func (s *LevelService) GetLevelProgress(ctx context.Context, customerID string) ([]models.LevelProgress, error) {
+	progress, err := s.levelRepo.GetLevelProgress(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range progress {
+		if p := &progress[i]; p.TotalDialogs > 0 {
+			p.CompletionPercent = math.Round(float64(p.CompletedDialogs)*10000/float64(p.TotalDialogs)) / 100
+		}
+	}
+	return progress, nil
+}
+
+func (s *LevelService) ensureCodeAvailable(ctx context.Context, code, excludeID string) error {
+	exists, err := s.levelRepo.LevelCodeExists(ctx, code, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrLevelExists
+	}
+	return nil
+}
+
+func normalizeLevelCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validateLevel(level *models.Level) error {
+	if level.Code == "" {
+		return ErrLevelCodeRequired
+	}
+	if level.Name == "" {
+		return ErrLevelNameRequired
+	}
+	return nil
+}
+
+// ensureLevel kiểm tra level gán cho topic/dialog có tồn tại; id rỗng là không gán level
+func ensureLevel(ctx context.Context, exists func(context.Context, string) (bool, error), id string) error {
+	if id == "" {
+		return nil
+	}
+	ok, err := exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLevelNotFound
+	}
+	return nil
+}
+
+// levelRef chuyển level_id từ request sang giá trị lưu vào cột; chuỗi rỗng thành NULL
+func levelRef(id string) *string {
+	if id == "" {
+		return nil
+	}
+	return &id
+}
+
+func mapLevelNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrLevelNotFound
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+)
+
+func TestLevelService(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	s := NewLevelService(repositories.NewLevelRepository(db))
+	if err := db.Create(&models.Level{ID: "L_a1", Code: "A1", Name: "Beginner", Rank: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("TestCreate", func(t *testing.T) {
+		level, err := s.CreateLevel(ctx, models.CreateLevelRequest{Code: " kids ", Name: " Kids ", Rank: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if level.Code != "KIDS" || level.Name != "Kids" || level.ID == "" {
+			t.Errorf("Expected normalized level with ID, got %+v", level)
+		}
+		if _, err := s.CreateLevel(ctx, models.CreateLevelRequest{Code: "Kids", Name: "Again"}); !errors.Is(err, ErrLevelExists) {
+			t.Errorf("Expected ErrLevelExists, got %v", err)
+		}
+		if _, err := s.CreateLevel(ctx, models.CreateLevelRequest{Code: " ", Name: "No code"}); !errors.Is(err, ErrLevelCodeRequired) {
+			t.Errorf("Expected ErrLevelCodeRequired, got %v", err)
+		}
+		if _, err := s.CreateLevel(ctx, models.CreateLevelRequest{Code: "X"}); !errors.Is(err, ErrLevelNameRequired) {
+			t.Errorf("Expected ErrLevelNameRequired, got %v", err)
+		}
+	})
+
+	t.Run("TestUpdate", func(t *testing.T) {
+		level, err := s.CreateLevel(ctx, models.CreateLevelRequest{Code: "BIZ", Name: "Business"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		code, rank := "biz-en", 7
+		updated, err := s.UpdateLevel(ctx, level.ID, models.UpdateLevelRequest{Code: &code, Rank: &rank})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Code != "BIZ-EN" || updated.Rank != 7 {
+			t.Errorf("Expected code BIZ-EN and rank 7, got %+v", updated)
+		}
+		taken := "kids"
+		if _, err := s.UpdateLevel(ctx, level.ID, models.UpdateLevelRequest{Code: &taken}); !errors.Is(err, ErrLevelExists) {
+			t.Errorf("Expected ErrLevelExists, got %v", err)
+		}
+		cefr := "A0"
+		if _, err := s.UpdateLevel(ctx, "L_a1", models.UpdateLevelRequest{Code: &cefr}); !errors.Is(err, ErrLevelBuiltIn) {
+			t.Errorf("Expected ErrLevelBuiltIn, got %v", err)
+		}
+		if _, err := s.UpdateLevel(ctx, "L_missing", models.UpdateLevelRequest{Rank: &rank}); !errors.Is(err, ErrLevelNotFound) {
+			t.Errorf("Expected ErrLevelNotFound, got %v", err)
+		}
+	})
+
+	t.Run("TestDelete", func(t *testing.T) {
+		level, err := s.CreateLevel(ctx, models.CreateLevelRequest{Code: "TMP", Name: "Temp"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.DeleteLevel(ctx, level.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.GetLevel(ctx, level.ID); !errors.Is(err, ErrLevelNotFound) {
+			t.Errorf("Expected ErrLevelNotFound after delete, got %v", err)
+		}
+		if err := s.DeleteLevel(ctx, "L_a1"); !errors.Is(err, ErrLevelBuiltIn) {
+			t.Errorf("Expected ErrLevelBuiltIn, got %v", err)
+		}
+	})
+
+	t.Run("TestProgressPercent", func(t *testing.T) {
+		levelID := "L_a1"
+		author := models.Employee{ID: "E_level", Name: "Author", Email: "level@example.com"}
+		topic := models.Topic{ID: "T_level", Title: "Topic", Visibility: models.TopicVisibilityPublic, LevelID: &levelID}
+		if err := db.Create(&author).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Create(&topic).Error; err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range []string{"D_l1", "D_l2", "D_l3"} {
+			if err := db.Create(&models.Dialog{ID: id, TopicID: topic.ID, Title: id, AuthorID: author.ID}).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := db.Create(&models.Customer{ID: "C_level", Name: "Learner"}).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Create(&models.DialogCompletion{CustomerID: "C_level", DialogID: "D_l1", TopicID: topic.ID,
+			ListeningCompleted: true, SpeakingCompleted: true, WritingCompleted: true}).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		progress, err := s.GetLevelProgress(ctx, "C_level")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range progress {
+			switch p.Code {
+			case "A1":
+				if p.TotalDialogs != 3 || p.CompletedDialogs != 1 || p.CompletionPercent != 33.33 {
+					t.Errorf("Expected A1 1/3 = 33.33%%, got %+v", p)
+				}
+			default:
+				if p.TotalDialogs != 0 || p.CompletionPercent != 0 {
+					t.Errorf("Expected empty level %s to have 0%%, got %+v", p.Code, p)
+				}
+			}
+		}
+	})
+}
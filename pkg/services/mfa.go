@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+)
+
+// MFAConfig cấu hình xác thực hai lớp bằng TOTP
+type MFAConfig struct {
+	Issuer            string // tên hiển thị trong ứng dụng authenticator
+	EncryptionKey     string // khóa mã hóa secret TOTP trong DB, bắt buộc
+	Skew              int    // số bước 30 giây chấp nhận lệch đồng hồ, mặc định 1
+	RecoveryCodeCount int    // mặc định 10
+
+	MaxFailedAttempts int           // số lần nhập sai liên tiếp trước khi khóa xác minh, mặc định 5
+	LockoutDuration   time.Duration // thời gian khóa, mặc định 15 phút
+}
+
+// MFAEnrollment là thông tin trả về khi bắt đầu đăng ký TOTP
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MFAStatus cho biết trạng thái 2FA của user
+type MFAStatus struct {
+	Enabled           bool       `json:"enabled"`
+	ConfirmedAt       *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"`
+}
+
+// MFAService quản lý đăng ký TOTP, recovery code và step-up lên session đã xác thực 2FA
+type MFAService struct {
+	repo     *repositories.MFARepository
+	issueMFA AccessTokenIssuer
+	aead     cipher.AEAD
+	cfg      MFAConfig
+}
+
+// NewMFAService creates a new MFAService instance.
+// issueMFA ký access token có claim mfa (thường là middleware.GenerateMFASessionToken đã bind sẵn config).
+func NewMFAService(repo *repositories.MFARepository, issueMFA AccessTokenIssuer, cfg MFAConfig) (*MFAService, error) {
+	if cfg.EncryptionKey == "" {
+		return nil, fmt.Errorf("MFA encryption key is required")
+	}
+	if cfg.Issuer == "" {
+		cfg.Issuer = "dd_goshare"
+	}
+	if cfg.Skew <= 0 {
+		cfg.Skew = 1
+	}
+	if cfg.RecoveryCodeCount <= 0 {
+		cfg.RecoveryCodeCount = 10
+	}
+	if cfg.MaxFailedAttempts <= 0 {
+		cfg.MaxFailedAttempts = 5
+	}
+	if cfg.LockoutDuration <= 0 {
+		cfg.LockoutDuration = 15 * time.Minute
+	}
+	key := sha256.Sum256([]byte(cfg.EncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAService{repo: repo, issueMFA: issueMFA, aead: aead, cfg: cfg}, nil
+}
+
+// Status trả về trạng thái 2FA của user
+func (s *MFAService) Status(ctx context.Context, userID string) (*MFAStatus, error) {
+	factor, err := s.repo.GetFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil || factor.ConfirmedAt == nil {
+		return &MFAStatus{}, nil
+	}
+	left, err := s.repo.CountUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAStatus{Enabled: true, ConfirmedAt: factor.ConfirmedAt, RecoveryCodesLeft: left}, nil
+}
+
+// BeginEnrollment tạo secret mới (chưa kích hoạt) và otpauth URI để quét QR.
+// Gọi lại khi chưa xác nhận sẽ thay secret cũ.
+func (s *MFAService) BeginEnrollment(ctx context.Context, userID, account string) (*MFAEnrollment, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+	id, err := utils.GenerateUniqueID("mfa")
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.repo.SavePendingFactor(ctx, &models.MFAFactor{
+		ID:              id,
+		UserID:          userID,
+		Type:            models.MFATypeTOTP,
+		SecretEncrypted: encrypted,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, ErrMFAAlreadyEnrolled
+	}
+	if account == "" {
+		account = userID
+	}
+	return &MFAEnrollment{Secret: secret, URI: utils.TOTPURI(s.cfg.Issuer, account, secret)}, nil
+}
+
+// ConfirmEnrollment kích hoạt 2FA bằng mã TOTP đầu tiên và trả về recovery code (chỉ hiển thị một lần)
+func (s *MFAService) ConfirmEnrollment(ctx context.Context, userID, code string) ([]string, error) {
+	if strings.TrimSpace(code) == "" {
+		return nil, ErrMFACodeRequired
+	}
+	factor, err := s.repo.GetFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if factor.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnrolled
+	}
+	now := time.Now()
+	if factor.LockedUntil != nil && now.Before(*factor.LockedUntil) {
+		return nil, ErrMFALocked
+	}
+	ok, step, err := s.validateTOTP(factor, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, s.recordFailure(ctx, factor, now)
+	}
+	codes, records, err := s.newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ConfirmFactor(ctx, factor.ID, step, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// StepUp xác minh mã TOTP hoặc recovery code và phát access token có claim mfa cho session hiện tại
+func (s *MFAService) StepUp(ctx context.Context, userID, email, sessionID, code string) (string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return "", err
+	}
+	token, err := s.issueMFA(userID, email, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to issue access token: %w", err)
+	}
+	return token, nil
+}
+
+// Verify kiểm tra mã TOTP (mỗi mã chỉ dùng được một lần) hoặc recovery code.
+// Nhập sai MaxFailedAttempts lần liên tiếp sẽ khóa xác minh trong LockoutDuration.
+func (s *MFAService) Verify(ctx context.Context, userID, code string) error {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ErrMFACodeRequired
+	}
+	factor, err := s.repo.GetFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if factor == nil || factor.ConfirmedAt == nil {
+		return ErrMFANotEnrolled
+	}
+	now := time.Now()
+	if factor.LockedUntil != nil && now.Before(*factor.LockedUntil) {
+		return ErrMFALocked
+	}
+
+	ok, err := s.useCode(ctx, factor, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return s.recordFailure(ctx, factor, now)
+	}
+	return s.repo.ResetFailedAttempts(ctx, factor.ID)
+}
+
+// useCode dùng mã TOTP hoặc recovery code; trả về false nếu mã sai hoặc đã dùng
+func (s *MFAService) useCode(ctx context.Context, factor *models.MFAFactor, code string) (bool, error) {
+	if len(code) == utils.TOTPDigits {
+		ok, step, err := s.validateTOTP(factor, code)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return s.repo.UseStep(ctx, factor.ID, step)
+		}
+	}
+	return s.repo.UseRecoveryCode(ctx, factor.UserID, hashRefreshToken(normalizeRecoveryCode(code)))
+}
+
+// recordFailure ghi nhận một lần nhập sai và trả về lỗi tương ứng
+func (s *MFAService) recordFailure(ctx context.Context, factor *models.MFAFactor, now time.Time) error {
+	if err := s.repo.RecordFailedAttempt(ctx, factor.ID, s.cfg.MaxFailedAttempts, now.Add(s.cfg.LockoutDuration)); err != nil {
+		return err
+	}
+	return ErrInvalidMFACode
+}
+
+// Disable tắt 2FA sau khi xác minh mã hiện tại
+func (s *MFAService) Disable(ctx context.Context, userID, code string) error {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+	return s.repo.DeleteFactor(ctx, userID)
+}
+
+// RegenerateRecoveryCodes thay toàn bộ recovery code sau khi xác minh mã hiện tại
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	codes, records, err := s.newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *MFAService) validateTOTP(factor *models.MFAFactor, code string) (bool, int64, error) {
+	secret, err := s.decrypt(factor.SecretEncrypted)
+	if err != nil {
+		return false, 0, err
+	}
+	return utils.ValidateTOTP(secret, code, time.Now(), s.cfg.Skew)
+}
+
+// newRecoveryCodes tạo recovery code dạng xxxxx-xxxxx; chỉ hash được lưu
+func (s *MFAService) newRecoveryCodes(userID string) ([]string, []models.MFARecoveryCode, error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	codes := make([]string, 0, s.cfg.RecoveryCodeCount)
+	records := make([]models.MFARecoveryCode, 0, s.cfg.RecoveryCodeCount)
+	for i := 0; i < s.cfg.RecoveryCodeCount; i++ {
+		buf := make([]byte, 10)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := make([]byte, len(buf))
+		for j, b := range buf {
+			raw[j] = alphabet[int(b)%len(alphabet)]
+		}
+		code := string(raw[:5]) + "-" + string(raw[5:])
+		id, err := utils.GenerateUniqueID("mfa_recovery_code")
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, models.MFARecoveryCode{
+			ID:       id,
+			UserID:   userID,
+			CodeHash: hashRefreshToken(normalizeRecoveryCode(code)),
+		})
+	}
+	return codes, records, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+func (s *MFAService) encrypt(plain string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *MFAService) decrypt(encoded string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid MFA secret: %w", err)
+	}
+	size := s.aead.NonceSize()
+	if len(data) < size {
+		return "", fmt.Errorf("invalid MFA secret")
+	}
+	plain, err := s.aead.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt MFA secret: %w", err)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+)
+
+// enrollMFA đăng ký và xác nhận TOTP cho user, trả về recovery code
+func enrollMFA(t *testing.T, s *MFAService, userID string) []string {
+	t.Helper()
+	ctx := context.Background()
+	enrollment, err := s.BeginEnrollment(ctx, userID, "")
+	if err != nil {
+		t.Fatalf("BeginEnrollment: %v", err)
+	}
+	code, err := utils.TOTPCode(enrollment.Secret, utils.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := s.ConfirmEnrollment(ctx, userID, code)
+	if err != nil {
+		t.Fatalf("ConfirmEnrollment: %v", err)
+	}
+	return codes
+}
+
+func TestMFALockout(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	issue := func(userID, email, sessionID string) (string, error) { return "token", nil }
+	s, err := NewMFAService(repositories.NewMFARepository(db), issue, MFAConfig{
+		EncryptionKey:     "test-key",
+		MaxFailedAttempts: 3,
+		LockoutDuration:   time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("TestLocksAfterMaxFailures", func(t *testing.T) {
+		codes := enrollMFA(t, s, "E_lock")
+		for i := 0; i < 3; i++ {
+			if err := s.Verify(ctx, "E_lock", "wrong-code"); !errors.Is(err, ErrInvalidMFACode) {
+				t.Fatalf("Expected ErrInvalidMFACode on attempt %d, got %v", i+1, err)
+			}
+		}
+		// Mã đúng cũng bị từ chối khi đang khóa, áp dụng cho mọi đường xác minh
+		if _, err := s.StepUp(ctx, "E_lock", "", "sess", codes[0]); !errors.Is(err, ErrMFALocked) {
+			t.Errorf("Expected ErrMFALocked from StepUp, got %v", err)
+		}
+		if err := s.Disable(ctx, "E_lock", codes[0]); !errors.Is(err, ErrMFALocked) {
+			t.Errorf("Expected ErrMFALocked from Disable, got %v", err)
+		}
+		if _, err := s.RegenerateRecoveryCodes(ctx, "E_lock", codes[0]); !errors.Is(err, ErrMFALocked) {
+			t.Errorf("Expected ErrMFALocked from RegenerateRecoveryCodes, got %v", err)
+		}
+
+		// Hết thời gian khóa thì mã đúng được chấp nhận và bộ đếm được reset
+		if err := db.Model(&models.MFAFactor{}).Where("user_id = ?", "E_lock").
+			Update("locked_until", time.Now().Add(-time.Second)).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Verify(ctx, "E_lock", codes[0]); err != nil {
+			t.Fatalf("Expected verify after lockout expiry to succeed, got %v", err)
+		}
+		var factor models.MFAFactor
+		if err := db.Where("user_id = ?", "E_lock").First(&factor).Error; err != nil {
+			t.Fatal(err)
+		}
+		if factor.FailedAttempts != 0 || factor.LockedUntil != nil {
+			t.Errorf("Expected lockout state reset, got attempts=%d locked_until=%v", factor.FailedAttempts, factor.LockedUntil)
+		}
+	})
+
+	t.Run("TestSuccessResetsCounter", func(t *testing.T) {
+		codes := enrollMFA(t, s, "E_reset")
+		for _, code := range []string{"wrong-1", "wrong-2", codes[0], "wrong-3", "wrong-4"} {
+			err := s.Verify(ctx, "E_reset", code)
+			if errors.Is(err, ErrMFALocked) {
+				t.Fatalf("Expected no lockout after a successful verification, got %v", err)
+			}
+		}
+		if err := s.Verify(ctx, "E_reset", codes[1]); err != nil {
+			t.Errorf("Expected recovery code to be accepted, got %v", err)
+		}
+	})
+}
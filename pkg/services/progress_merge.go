@@ -0,0 +1,141 @@
+package services
+
+import (
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+)
+
+// Quy tắc gộp tiến độ học khi nâng cấp tài khoản khách. Tiến độ chỉ tăng, không bao giờ mất:
+//   - bài đã hoàn thành ở một phía thì coi như hoàn thành
+//   - điểm lấy giá trị cao hơn
+//   - trạng thái "đang học tới đâu" (NextDialogID) lấy theo bản cập nhật sau cùng
+//   - bộ đếm tích lũy lấy giá trị lớn hơn vì cùng một tiến độ có thể đã được đếm ở cả hai phía
+
+// MergeTopicProgress gộp hai bản tiến độ của cùng một topic
+func MergeTopicProgress(a, b models.CustomerTopicProgress) models.CustomerTopicProgress {
+	newer, older := a, b
+	if b.LastUpdated > a.LastUpdated {
+		newer, older = b, a
+	}
+	merged := newer
+	merged.CompletedDialogIDs = unionStrings(newer.CompletedDialogIDs, older.CompletedDialogIDs)
+	merged.IsCompleted = a.IsCompleted || b.IsCompleted
+	if merged.NextDialogID == "" && !merged.IsCompleted {
+		merged.NextDialogID = older.NextDialogID
+	}
+	return merged
+}
+
+// MergeDialogCompletion gộp hai bản hoàn thành bài tập của cùng một dialog
+func MergeDialogCompletion(a, b models.DialogCompletion) models.DialogCompletion {
+	merged := a
+	if merged.TopicID == "" {
+		merged.TopicID = b.TopicID
+	}
+	merged.ListeningCompleted = a.ListeningCompleted || b.ListeningCompleted
+	merged.SpeakingCompleted = a.SpeakingCompleted || b.SpeakingCompleted
+	merged.WritingCompleted = a.WritingCompleted || b.WritingCompleted
+	merged.ScoreSpeaking = max(a.ScoreSpeaking, b.ScoreSpeaking)
+	merged.ScoreWriting = max(a.ScoreWriting, b.ScoreWriting)
+	return merged
+}
+
+// MergeCustomerAchievement gộp trạng thái của cùng một thành tựu
+func MergeCustomerAchievement(a, b models.CustomerAchievement) models.CustomerAchievement {
+	merged := a
+	merged.Unlocked = a.Unlocked || b.Unlocked
+	merged.Claimed = a.Claimed || b.Claimed
+	return merged
+}
+
+// MergeCustomerStatistics gộp thống kê; nil nghĩa là phía đó chưa có thống kê
+func MergeCustomerStatistics(a, b *models.CustomerStatistics) *models.CustomerStatistics {
+	if a == nil && b == nil {
+		return nil
+	}
+	if a == nil {
+		copied := *b
+		return &copied
+	}
+	merged := *a
+	if b != nil {
+		merged.TotalDialogsCompleted = max(a.TotalDialogsCompleted, b.TotalDialogsCompleted)
+		merged.TotalExercisesCompleted = max(a.TotalExercisesCompleted, b.TotalExercisesCompleted)
+		merged.Streak = max(a.Streak, b.Streak)
+		merged.Score = max(a.Score, b.Score)
+	}
+	return &merged
+}
+
+// MergeProgress gộp toàn bộ tiến độ theo các quy tắc trên; kết quả mang CustomerID của target
+func MergeProgress(source, target *repositories.ProgressSnapshot) *repositories.ProgressSnapshot {
+	result := &repositories.ProgressSnapshot{}
+
+	topics := make(map[string]int)
+	for _, p := range target.TopicProgress {
+		topics[p.TopicID] = len(result.TopicProgress)
+		result.TopicProgress = append(result.TopicProgress, p)
+	}
+	for _, p := range source.TopicProgress {
+		if i, ok := topics[p.TopicID]; ok {
+			result.TopicProgress[i] = MergeTopicProgress(result.TopicProgress[i], p)
+			continue
+		}
+		topics[p.TopicID] = len(result.TopicProgress)
+		result.TopicProgress = append(result.TopicProgress, p)
+	}
+
+	dialogs := make(map[string]int)
+	for _, d := range target.DialogCompletions {
+		dialogs[d.DialogID] = len(result.DialogCompletions)
+		result.DialogCompletions = append(result.DialogCompletions, d)
+	}
+	for _, d := range source.DialogCompletions {
+		if i, ok := dialogs[d.DialogID]; ok {
+			result.DialogCompletions[i] = MergeDialogCompletion(result.DialogCompletions[i], d)
+			continue
+		}
+		dialogs[d.DialogID] = len(result.DialogCompletions)
+		result.DialogCompletions = append(result.DialogCompletions, d)
+	}
+
+	achievements := make(map[string]int)
+	for _, a := range target.Achievements {
+		achievements[a.AchievementID] = len(result.Achievements)
+		result.Achievements = append(result.Achievements, a)
+	}
+	for _, a := range source.Achievements {
+		if i, ok := achievements[a.AchievementID]; ok {
+			result.Achievements[i] = MergeCustomerAchievement(result.Achievements[i], a)
+			continue
+		}
+		achievements[a.AchievementID] = len(result.Achievements)
+		result.Achievements = append(result.Achievements, a)
+	}
+
+	result.Statistics = MergeCustomerStatistics(target.Statistics, source.Statistics)
+	if result.Statistics != nil {
+		completed := 0
+		for _, d := range result.DialogCompletions {
+			if d.ListeningCompleted && d.SpeakingCompleted && d.WritingCompleted {
+				completed++
+			}
+		}
+		result.Statistics.TotalDialogsCompleted = max(result.Statistics.TotalDialogsCompleted, completed)
+	}
+	return result
+}
+
+func unionStrings(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	out := make([]string, 0, len(a)+len(b))
+	for _, list := range [][]string{a, b} {
+		for _, v := range list {
+			if !seen[v] {
+				seen[v] = true
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
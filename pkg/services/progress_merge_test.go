@@ -0,0 +1,157 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+)
+
+func TestMergeTopicProgress(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b models.CustomerTopicProgress
+		want models.CustomerTopicProgress
+	}{
+		{
+			name: "newer next dialog wins and completed ids are unioned",
+			a:    models.CustomerTopicProgress{TopicID: "T1", CompletedDialogIDs: []string{"D1"}, NextDialogID: "D2", LastUpdated: 1},
+			b:    models.CustomerTopicProgress{TopicID: "T1", CompletedDialogIDs: []string{"D1", "D3"}, NextDialogID: "D4", LastUpdated: 2},
+			want: models.CustomerTopicProgress{TopicID: "T1", CompletedDialogIDs: []string{"D1", "D3"}, NextDialogID: "D4", LastUpdated: 2},
+		},
+		{
+			name: "completed on one side stays completed",
+			a:    models.CustomerTopicProgress{TopicID: "T1", CompletedDialogIDs: []string{"D1"}, IsCompleted: true, LastUpdated: 1},
+			b:    models.CustomerTopicProgress{TopicID: "T1", CompletedDialogIDs: []string{"D2"}, NextDialogID: "D3", LastUpdated: 2},
+			want: models.CustomerTopicProgress{TopicID: "T1", CompletedDialogIDs: []string{"D2", "D1"}, IsCompleted: true, NextDialogID: "D3", LastUpdated: 2},
+		},
+		{
+			name: "empty next dialog falls back to older",
+			a:    models.CustomerTopicProgress{TopicID: "T1", CompletedDialogIDs: []string{}, NextDialogID: "D2", LastUpdated: 1},
+			b:    models.CustomerTopicProgress{TopicID: "T1", CompletedDialogIDs: []string{}, LastUpdated: 2},
+			want: models.CustomerTopicProgress{TopicID: "T1", CompletedDialogIDs: []string{}, NextDialogID: "D2", LastUpdated: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeTopicProgress(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeTopicProgress() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeDialogCompletion(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b models.DialogCompletion
+		want models.DialogCompletion
+	}{
+		{
+			name: "skills are ORed and scores take max",
+			a:    models.DialogCompletion{DialogID: "D1", TopicID: "T1", ListeningCompleted: true, ScoreSpeaking: 80, ScoreWriting: 10},
+			b:    models.DialogCompletion{DialogID: "D1", TopicID: "T1", SpeakingCompleted: true, WritingCompleted: true, ScoreSpeaking: 60, ScoreWriting: 90},
+			want: models.DialogCompletion{DialogID: "D1", TopicID: "T1", ListeningCompleted: true, SpeakingCompleted: true, WritingCompleted: true, ScoreSpeaking: 80, ScoreWriting: 90},
+		},
+		{
+			name: "missing topic id is filled from the other side",
+			a:    models.DialogCompletion{DialogID: "D1"},
+			b:    models.DialogCompletion{DialogID: "D1", TopicID: "T1"},
+			want: models.DialogCompletion{DialogID: "D1", TopicID: "T1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeDialogCompletion(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeDialogCompletion() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeCustomerAchievement(t *testing.T) {
+	got := MergeCustomerAchievement(
+		models.CustomerAchievement{AchievementID: "A1", Unlocked: true},
+		models.CustomerAchievement{AchievementID: "A1", Claimed: true},
+	)
+	if !got.Unlocked || !got.Claimed {
+		t.Errorf("Expected unlocked and claimed, got %+v", got)
+	}
+}
+
+func TestMergeCustomerStatistics(t *testing.T) {
+	stats := &models.CustomerStatistics{ID: "S1", TotalDialogsCompleted: 3, Streak: 5, Score: 100}
+	other := &models.CustomerStatistics{ID: "S2", TotalDialogsCompleted: 7, TotalExercisesCompleted: 2, Streak: 1, Score: 40}
+
+	tests := []struct {
+		name string
+		a, b *models.CustomerStatistics
+		want *models.CustomerStatistics
+	}{
+		{name: "both nil", want: nil},
+		{name: "only b", b: other, want: other},
+		{name: "only a", a: stats, want: stats},
+		{
+			name: "counters take max and keep a's identity",
+			a:    stats,
+			b:    other,
+			want: &models.CustomerStatistics{ID: "S1", TotalDialogsCompleted: 7, TotalExercisesCompleted: 2, Streak: 5, Score: 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeCustomerStatistics(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeCustomerStatistics() = %+v, want %+v", got, tt.want)
+			}
+			if got != nil && (got == tt.a || got == tt.b) {
+				t.Error("Expected a copy, not one of the inputs")
+			}
+		})
+	}
+}
+
+func TestMergeProgress(t *testing.T) {
+	guest := &repositories.ProgressSnapshot{
+		TopicProgress: []models.CustomerTopicProgress{
+			{TopicID: "T1", CompletedDialogIDs: []string{"D2"}, LastUpdated: 5},
+			{TopicID: "T2", CompletedDialogIDs: []string{"D9"}, LastUpdated: 1},
+		},
+		DialogCompletions: []models.DialogCompletion{
+			{DialogID: "D1", ListeningCompleted: true, SpeakingCompleted: true, WritingCompleted: true},
+			{DialogID: "D2", SpeakingCompleted: true},
+		},
+		Achievements: []models.CustomerAchievement{{AchievementID: "A1", Claimed: true}},
+		Statistics:   &models.CustomerStatistics{ID: "guest-stats", TotalDialogsCompleted: 0, Score: 10},
+	}
+	target := &repositories.ProgressSnapshot{
+		TopicProgress:     []models.CustomerTopicProgress{{TopicID: "T1", CompletedDialogIDs: []string{"D1"}, LastUpdated: 3}},
+		DialogCompletions: []models.DialogCompletion{{DialogID: "D2", ListeningCompleted: true, WritingCompleted: true}},
+		Achievements:      []models.CustomerAchievement{{AchievementID: "A1", Unlocked: true}},
+	}
+
+	got := MergeProgress(guest, target)
+
+	if len(got.TopicProgress) != 2 {
+		t.Fatalf("Expected 2 topic progress rows, got %d", len(got.TopicProgress))
+	}
+	if ids := got.TopicProgress[0].CompletedDialogIDs; !reflect.DeepEqual(ids, []string{"D2", "D1"}) {
+		t.Errorf("Expected T1 completed ids [D2 D1], got %v", ids)
+	}
+	if len(got.DialogCompletions) != 2 {
+		t.Fatalf("Expected 2 dialog completions, got %d", len(got.DialogCompletions))
+	}
+	for _, d := range got.DialogCompletions {
+		if !d.ListeningCompleted || !d.SpeakingCompleted || !d.WritingCompleted {
+			t.Errorf("Expected %s fully completed after merge, got %+v", d.DialogID, d)
+		}
+	}
+	if len(got.Achievements) != 1 || !got.Achievements[0].Unlocked || !got.Achievements[0].Claimed {
+		t.Errorf("Expected merged achievement unlocked and claimed, got %+v", got.Achievements)
+	}
+	// Thống kê lấy từ guest khi target chưa có, và tính lại số dialog đã hoàn thành
+	if got.Statistics == nil || got.Statistics.TotalDialogsCompleted != 2 || got.Statistics.Score != 10 {
+		t.Errorf("Expected statistics with 2 completed dialogs and score 10, got %+v", got.Statistics)
+	}
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+)
+
+// DefaultCustomerRole là role gán cho customer mới nếu không cấu hình khác
+const DefaultCustomerRole = "customer"
+
+// lastUsedInterval giới hạn tần suất ghi last_used_at/last_login cho mỗi liên kết
+const lastUsedInterval = 5 * time.Minute
+
+// Mặc định của cache liên kết đã provisioning, đổi bằng WithCache
+const (
+	DefaultProvisioningCacheTTL  = 10 * time.Minute
+	DefaultProvisioningCacheSize = 10000
+)
+
+var nonNameChars = regexp.MustCompile(`[^\p{L}\p{N}._ -]+`)
+
+// CustomerProvisioningService tạo customer ở lần đăng nhập Firebase đầu tiên
+// và liên kết provider mới (Google, Apple...) vào customer có cùng email đã xác minh.
+// Thỏa mãn middleware.Provisioner:
+//
+//	auth := middleware.NewAuthenticator(middleware.NewFirebaseVerifier()).
+//		WithProvisioner(services.NewCustomerProvisioningService(repo, services.DefaultCustomerRole))
+type CustomerProvisioningService struct {
+	repo        *repositories.AuthProviderRepository
+	defaultRole string
+	cacheTTL    time.Duration
+	cacheSize   int
+
+	mu    sync.RWMutex
+	known map[string]*provisionedLink // provider:uid -> liên kết đã provisioning
+}
+
+type provisionedLink struct {
+	customerID string
+	linkID     string
+	touchedAt  time.Time // lần ghi last_used_at gần nhất
+	expiresAt  time.Time // sau thời điểm này phải đọc lại từ DB
+}
+
+// NewCustomerProvisioningService creates a new CustomerProvisioningService instance
+func NewCustomerProvisioningService(repo *repositories.AuthProviderRepository, defaultRole string) *CustomerProvisioningService {
+	return &CustomerProvisioningService{
+		repo:        repo,
+		defaultRole: defaultRole,
+		cacheTTL:    DefaultProvisioningCacheTTL,
+		cacheSize:   DefaultProvisioningCacheSize,
+		known:       make(map[string]*provisionedLink),
+	}
+}
+
+// WithCache đổi thời gian sống và số liên kết tối đa giữ trong cache; giá trị <= 0 giữ mặc định
+func (s *CustomerProvisioningService) WithCache(ttl time.Duration, size int) *CustomerProvisioningService {
+	if ttl > 0 {
+		s.cacheTTL = ttl
+	}
+	if size > 0 {
+		s.cacheSize = size
+	}
+	return s
+}
+
+// Forget xóa liên kết đã cache của (provider, uid), gọi khi liên kết bị gỡ hoặc chuyển sang customer khác
+func (s *CustomerProvisioningService) Forget(provider, uid string) {
+	s.mu.Lock()
+	delete(s.known, provider+":"+uid)
+	s.mu.Unlock()
+}
+
+// ForgetCustomer xóa mọi liên kết đã cache của customer (ví dụ khi customer bị xóa hoặc đổi số điện thoại)
+func (s *CustomerProvisioningService) ForgetCustomer(customerID string) {
+	s.mu.Lock()
+	for key, link := range s.known {
+		if link.customerID == customerID {
+			delete(s.known, key)
+		}
+	}
+	s.mu.Unlock()
+}
+
+// Provision đảm bảo principal Firebase có customer tương ứng và đổi principal.ID thành customer ID.
+// Principal từ nguồn khác (app JWT, API key) đã là tài khoản nội bộ nên được bỏ qua.
+func (s *CustomerProvisioningService) Provision(ctx context.Context, principal *pmodel.Principal) error {
+	if principal.TokenType != pmodel.TokenTypeFirebase {
+		return nil
+	}
+	uid := principal.ProviderUserID
+	if uid == "" {
+		uid = principal.ID
+	}
+	cacheKey := principal.Provider + ":" + uid
+
+	known, ok := s.cached(cacheKey, time.Now())
+	if !ok {
+		var err error
+		if known, err = s.resolve(ctx, principal, uid); err != nil {
+			return err
+		}
+		s.remember(cacheKey, known, time.Now())
+	}
+
+	s.touch(ctx, known)
+	principal.ID = known.customerID
+	return nil
+}
+
+// cached trả về liên kết còn hạn trong cache
+func (s *CustomerProvisioningService) cached(key string, now time.Time) (*provisionedLink, bool) {
+	s.mu.RLock()
+	link, ok := s.known[key]
+	s.mu.RUnlock()
+	if !ok || !now.Before(link.expiresAt) {
+		return nil, false
+	}
+	return link, true
+}
+
+// remember lưu liên kết vào cache; khi đầy thì bỏ các mục hết hạn, nếu vẫn đầy thì bỏ mục sắp hết hạn nhất
+func (s *CustomerProvisioningService) remember(key string, link *provisionedLink, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	link.expiresAt = now.Add(s.cacheTTL)
+	if _, ok := s.known[key]; !ok && len(s.known) >= s.cacheSize {
+		for k, l := range s.known {
+			if !now.Before(l.expiresAt) {
+				delete(s.known, k)
+			}
+		}
+		for len(s.known) >= s.cacheSize {
+			oldest := ""
+			for k, l := range s.known {
+				if oldest == "" || l.expiresAt.Before(s.known[oldest].expiresAt) {
+					oldest = k
+				}
+			}
+			delete(s.known, oldest)
+		}
+	}
+	s.known[key] = link
+}
+
+// resolve tìm hoặc tạo customer cho principal, theo thứ tự:
+// liên kết (provider, uid) đã có -> customer có ID là Firebase UID -> customer có cùng email đã xác minh -> tạo mới
+func (s *CustomerProvisioningService) resolve(ctx context.Context, principal *pmodel.Principal, uid string) (*provisionedLink, error) {
+	link, err := s.repo.GetByProviderUserID(ctx, principal.Provider, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up auth provider: %w", err)
+	}
+	if link != nil {
+		return &provisionedLink{customerID: link.CustomerID, linkID: link.ID}, nil
+	}
+
+	customer, err := s.findExisting(ctx, principal, uid)
+	if err != nil {
+		return nil, err
+	}
+	if customer != nil {
+		newLink, err := s.newLink(principal, uid, customer.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.Link(ctx, newLink); err != nil {
+			return nil, fmt.Errorf("failed to link auth provider: %w", err)
+		}
+		// Link bỏ qua khi request khác vừa tạo cùng liên kết nên đọc lại ID thật
+		if link, err = s.repo.GetByProviderUserID(ctx, principal.Provider, uid); err != nil || link == nil {
+			return nil, fmt.Errorf("failed to load auth provider link: %v", err)
+		}
+		return &provisionedLink{customerID: link.CustomerID, linkID: link.ID}, nil
+	}
+
+	created, err := s.create(ctx, principal, uid)
+	if err != nil {
+		// Hai request đăng nhập đầu tiên chạy song song: request kia đã tạo xong
+		if link, lookupErr := s.repo.GetByProviderUserID(ctx, principal.Provider, uid); lookupErr == nil && link != nil {
+			return &provisionedLink{customerID: link.CustomerID, linkID: link.ID}, nil
+		}
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *CustomerProvisioningService) findExisting(ctx context.Context, principal *pmodel.Principal, uid string) (*models.Customer, error) {
+	// Customer tạo trước khi có bảng liên kết dùng Firebase UID làm ID
+	customer, err := s.repo.GetCustomerByID(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up customer: %w", err)
+	}
+	if customer != nil || principal.Email == "" {
+		return customer, nil
+	}
+
+	customer, err = s.repo.GetCustomerByEmail(ctx, principal.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up customer: %w", err)
+	}
+	if customer != nil && !principal.EmailVerified {
+		// Không tự liên kết theo email chưa xác minh, tránh chiếm tài khoản người khác
+		return nil, pmodel.ErrAccountLinkConflict
+	}
+	return customer, nil
+}
+
+func (s *CustomerProvisioningService) create(ctx context.Context, principal *pmodel.Principal, uid string) (*provisionedLink, error) {
+	name, err := s.uniqueName(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	customer := &models.Customer{
+		ID:        uid,
+		Name:      name,
+		AvatarURL: principal.Picture,
+	}
+	// Chỉ lưu email đã xác minh: findExisting liên kết theo email nên email chưa xác minh
+	// cho phép người khác đăng ký trước email của nạn nhân rồi chiếm lần đăng nhập sau của họ
+	if principal.Email != "" && principal.EmailVerified {
+		email := strings.ToLower(principal.Email)
+		customer.Email = &email
+	}
+	if principal.Phone != "" {
+		phone := principal.Phone
+		customer.PhoneNumber = &phone
+	}
+	now := time.Now()
+	customer.LastLogin = &now
+
+	link, err := s.newLink(principal, uid, uid)
+	if err != nil {
+		return nil, err
+	}
+	link.LastUsedAt = &now
+
+	if err := s.repo.CreateCustomer(ctx, customer, link, s.defaultRole); err != nil {
+		return nil, fmt.Errorf("failed to create customer: %w", err)
+	}
+	return &provisionedLink{customerID: customer.ID, linkID: link.ID, touchedAt: now}, nil
+}
+
+func (s *CustomerProvisioningService) newLink(principal *pmodel.Principal, uid, customerID string) (*models.AuthProvider, error) {
+	id, err := utils.GenerateUniqueID("auth_provider")
+	if err != nil {
+		return nil, err
+	}
+	providerUserID := uid
+	return &models.AuthProvider{
+		ID:             id,
+		CustomerID:     customerID,
+		Provider:       principal.Provider,
+		ProviderUserID: &providerUserID,
+		Email:          principal.Email,
+	}, nil
+}
+
+// uniqueName chọn tên hiển thị chưa dùng từ display name hoặc phần trước @ của email
+func (s *CustomerProvisioningService) uniqueName(ctx context.Context, principal *pmodel.Principal) (string, error) {
+	base := strings.TrimSpace(nonNameChars.ReplaceAllString(principal.Name, ""))
+	if base == "" && principal.Email != "" {
+		base = strings.SplitN(principal.Email, "@", 2)[0]
+	}
+	if base == "" {
+		base = "user"
+	}
+	if runes := []rune(base); len(runes) > 40 {
+		base = string(runes[:40])
+	}
+
+	name := base
+	for i := 0; i < 5; i++ {
+		exists, err := s.repo.CustomerNameExists(ctx, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to check customer name: %w", err)
+		}
+		if !exists {
+			return name, nil
+		}
+		suffix, err := utils.GenerateUniqueID("u")
+		if err != nil {
+			return "", err
+		}
+		name = base + "-" + strings.ToLower(suffix[1:7])
+	}
+	return "", fmt.Errorf("could not find a free customer name for %q", base)
+}
+
+// touch ghi last_used_at/last_login tối đa mỗi lastUsedInterval; lỗi chỉ ảnh hưởng thống kê nên bỏ qua
+func (s *CustomerProvisioningService) touch(ctx context.Context, link *provisionedLink) {
+	now := time.Now()
+	s.mu.Lock()
+	if now.Sub(link.touchedAt) < lastUsedInterval {
+		s.mu.Unlock()
+		return
+	}
+	link.touchedAt = now
+	s.mu.Unlock()
+	_ = s.repo.TouchLastUsed(ctx, link.linkID, link.customerID)
+}
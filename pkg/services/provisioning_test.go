@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+)
+
+func TestProvisioningCache(t *testing.T) {
+	now := time.Now()
+
+	t.Run("TestDefaults", func(t *testing.T) {
+		s := NewCustomerProvisioningService(nil, DefaultCustomerRole).WithCache(0, -1)
+		if s.cacheTTL != DefaultProvisioningCacheTTL || s.cacheSize != DefaultProvisioningCacheSize {
+			t.Errorf("Expected default cache settings, got ttl=%v size=%d", s.cacheTTL, s.cacheSize)
+		}
+	})
+
+	t.Run("TestExpiresAfterTTL", func(t *testing.T) {
+		s := NewCustomerProvisioningService(nil, DefaultCustomerRole).WithCache(time.Minute, 10)
+		s.remember("google.com:u1", &provisionedLink{customerID: "C1", linkID: "L1"}, now)
+
+		if link, ok := s.cached("google.com:u1", now.Add(30*time.Second)); !ok || link.customerID != "C1" {
+			t.Errorf("Expected cached link for C1, got %v %v", link, ok)
+		}
+		if _, ok := s.cached("google.com:u1", now.Add(time.Minute)); ok {
+			t.Errorf("Expected link to expire after TTL")
+		}
+	})
+
+	t.Run("TestBounded", func(t *testing.T) {
+		s := NewCustomerProvisioningService(nil, DefaultCustomerRole).WithCache(time.Minute, 3)
+		for i := 0; i < 3; i++ {
+			s.remember(fmt.Sprintf("google.com:u%d", i), &provisionedLink{customerID: fmt.Sprintf("C%d", i)}, now.Add(time.Duration(i)*time.Second))
+		}
+		s.remember("google.com:u3", &provisionedLink{customerID: "C3"}, now.Add(3*time.Second))
+
+		if len(s.known) != 3 {
+			t.Fatalf("Expected 3 cached links, got %d", len(s.known))
+		}
+		if _, ok := s.known["google.com:u0"]; ok {
+			t.Errorf("Expected oldest link to be evicted")
+		}
+		if _, ok := s.known["google.com:u3"]; !ok {
+			t.Errorf("Expected newest link to be cached")
+		}
+	})
+
+	t.Run("TestEvictsExpiredFirst", func(t *testing.T) {
+		s := NewCustomerProvisioningService(nil, DefaultCustomerRole).WithCache(time.Minute, 3)
+		s.remember("google.com:u0", &provisionedLink{customerID: "C0"}, now)
+		s.remember("google.com:u1", &provisionedLink{customerID: "C1"}, now)
+		s.remember("google.com:u2", &provisionedLink{customerID: "C2"}, now.Add(90*time.Second))
+		s.remember("google.com:u3", &provisionedLink{customerID: "C3"}, now.Add(2*time.Minute))
+
+		if len(s.known) != 2 {
+			t.Errorf("Expected expired links to be dropped, got %d cached", len(s.known))
+		}
+		if _, ok := s.known["google.com:u2"]; !ok {
+			t.Errorf("Expected unexpired link to be kept")
+		}
+	})
+
+	t.Run("TestReplaceDoesNotEvict", func(t *testing.T) {
+		s := NewCustomerProvisioningService(nil, DefaultCustomerRole).WithCache(time.Minute, 2)
+		s.remember("google.com:u0", &provisionedLink{customerID: "C0"}, now)
+		s.remember("google.com:u1", &provisionedLink{customerID: "C1"}, now)
+		s.remember("google.com:u1", &provisionedLink{customerID: "C1b"}, now.Add(time.Second))
+
+		if len(s.known) != 2 || s.known["google.com:u1"].customerID != "C1b" {
+			t.Errorf("Expected in-place replacement, got %d cached", len(s.known))
+		}
+	})
+
+	t.Run("TestForget", func(t *testing.T) {
+		s := NewCustomerProvisioningService(nil, DefaultCustomerRole)
+		s.remember("google.com:u1", &provisionedLink{customerID: "C1"}, now)
+		s.remember("apple.com:u1", &provisionedLink{customerID: "C1"}, now)
+		s.remember("google.com:u2", &provisionedLink{customerID: "C2"}, now)
+
+		s.Forget("google.com", "u2")
+		if _, ok := s.cached("google.com:u2", now); ok {
+			t.Errorf("Expected forgotten link to be removed")
+		}
+
+		s.ForgetCustomer("C1")
+		if len(s.known) != 0 {
+			t.Errorf("Expected all links of C1 to be removed, got %d cached", len(s.known))
+		}
+	})
+}
+
+func TestProvisionUnverifiedEmail(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	s := NewCustomerProvisioningService(repositories.NewAuthProviderRepository(db), DefaultCustomerRole)
+	firebase := func(provider, uid string, verified bool) *pmodel.Principal {
+		return &pmodel.Principal{
+			ID:             uid,
+			ProviderUserID: uid,
+			Provider:       provider,
+			TokenType:      pmodel.TokenTypeFirebase,
+			Email:          "victim@example.com",
+			EmailVerified:  verified,
+		}
+	}
+
+	attacker := firebase("password", "uid-attacker", false)
+	if err := s.Provision(ctx, attacker); err != nil {
+		t.Fatalf("Provision unverified: %v", err)
+	}
+	var created models.Customer
+	if err := db.Where("id = ?", attacker.ID).First(&created).Error; err != nil {
+		t.Fatal(err)
+	}
+	if created.Email != nil {
+		t.Errorf("Expected unverified email not to be stored, got %q", *created.Email)
+	}
+
+	victim := firebase("google.com", "uid-victim", true)
+	if err := s.Provision(ctx, victim); err != nil {
+		t.Fatalf("Provision verified: %v", err)
+	}
+	if victim.ID == attacker.ID {
+		t.Errorf("Expected verified sign-in not to be linked into the unverified customer")
+	}
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+)
+
+// SMSProvider là tên provider trong bảng auth_providers cho số điện thoại đã xác thực
+const SMSProvider = "sms"
+
+// SMSSender gửi tin nhắn SMS (Twilio, eSMS, SpeedSMS...)
+type SMSSender interface {
+	Send(ctx context.Context, phoneNumber, message string) error
+}
+
+// SentSMS là tin nhắn LoggingSMSSender đã "gửi"
+type SentSMS struct {
+	PhoneNumber string
+	Message     string
+}
+
+// LoggingSMSSender chỉ ghi log và giữ tin nhắn trong bộ nhớ, dùng cho dev/test
+type LoggingSMSSender struct {
+	mu       sync.Mutex
+	messages []SentSMS
+}
+
+// NewLoggingSMSSender tạo sender giả
+func NewLoggingSMSSender() *LoggingSMSSender {
+	return &LoggingSMSSender{}
+}
+
+func (s *LoggingSMSSender) Send(_ context.Context, phoneNumber, message string) error {
+	s.mu.Lock()
+	s.messages = append(s.messages, SentSMS{PhoneNumber: phoneNumber, Message: message})
+	s.mu.Unlock()
+	log.Printf("sms: to %s: %s", phoneNumber, message)
+	return nil
+}
+
+// Messages trả về các tin nhắn đã gửi
+func (s *LoggingSMSSender) Messages() []SentSMS {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SentSMS(nil), s.messages...)
+}
+
+// SMSVerificationConfig cấu hình mã OTP
+type SMSVerificationConfig struct {
+	CodeLength         int           // số chữ số, mặc định 6
+	CodeTTL            time.Duration // mặc định 5 phút
+	MaxAttempts        int           // số lần nhập sai tối đa cho một mã, mặc định 5
+	ResendCooldown     time.Duration // khoảng chờ giữa hai lần gửi, mặc định 60 giây
+	MaxPerHour         int           // số mã tối đa gửi tới một số trong một giờ, mặc định 5
+	DefaultCountryCode string        // dùng khi số bắt đầu bằng 0, mặc định "+84"
+	HashKey            []byte        // khóa HMAC cho mã OTP, bắt buộc và tối thiểu MinSMSHashKeyLength byte
+	MessageTemplate    string        // fmt template với %s là mã, %d là số phút hiệu lực
+}
+
+// SMSCodeInfo là thông tin trả về sau khi gửi mã
+type SMSCodeInfo struct {
+	PhoneNumber string    `json:"phone_number"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	ResendAt    time.Time `json:"resend_at"`
+}
+
+// SMSVerificationService gửi và xác thực mã OTP qua SMS
+type SMSVerificationService struct {
+	repo   *repositories.SMSVerificationRepository
+	sender SMSSender
+	cfg    SMSVerificationConfig
+}
+
+// MinSMSHashKeyLength là độ dài tối thiểu (byte) của HashKey, bằng kích thước khối ra của SHA-256
+const MinSMSHashKeyLength = 32
+
+// NewSMSVerificationService creates a new SMSVerificationService instance.
+// HashKey rỗng hoặc quá ngắn bị từ chối: mã OTP chỉ có 10^CodeLength khả năng nên hash với khóa yếu dò ngược được.
+func NewSMSVerificationService(repo *repositories.SMSVerificationRepository, sender SMSSender, cfg SMSVerificationConfig) (*SMSVerificationService, error) {
+	if len(cfg.HashKey) < MinSMSHashKeyLength {
+		return nil, fmt.Errorf("SMS verification hash key must be at least %d bytes", MinSMSHashKeyLength)
+	}
+	if cfg.CodeLength <= 0 {
+		cfg.CodeLength = 6
+	}
+	if cfg.CodeTTL <= 0 {
+		cfg.CodeTTL = 5 * time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.ResendCooldown <= 0 {
+		cfg.ResendCooldown = time.Minute
+	}
+	if cfg.MaxPerHour <= 0 {
+		cfg.MaxPerHour = 5
+	}
+	if cfg.DefaultCountryCode == "" {
+		cfg.DefaultCountryCode = "+84"
+	}
+	if cfg.MessageTemplate == "" {
+		cfg.MessageTemplate = "Ma xac thuc cua ban la %s. Ma co hieu luc trong %d phut."
+	}
+	return &SMSVerificationService{repo: repo, sender: sender, cfg: cfg}, nil
+}
+
+// SendCode tạo mã mới cho customer và gửi SMS
+func (s *SMSVerificationService) SendCode(ctx context.Context, customerID, phoneNumber string) (*SMSCodeInfo, error) {
+	phone, err := NormalizePhoneNumber(phoneNumber, s.cfg.DefaultCountryCode)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	latest, err := s.repo.GetLatest(ctx, "", phone)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && now.Before(latest.CreatedAt.Add(s.cfg.ResendCooldown)) {
+		return nil, ErrSMSResendCooldown
+	}
+	sent, err := s.repo.CountSince(ctx, phone, now.Add(-time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	if sent >= int64(s.cfg.MaxPerHour) {
+		return nil, ErrSMSTooManyRequests
+	}
+
+	code, err := s.generateCode()
+	if err != nil {
+		return nil, err
+	}
+	id, err := utils.GenerateUniqueID("sms")
+	if err != nil {
+		return nil, err
+	}
+	verification := &models.SMSVerification{
+		ID:          id,
+		CustomerID:  customerID,
+		PhoneNumber: phone,
+		CodeHash:    s.hashCode(id, code),
+		ExpiresAt:   now.Add(s.cfg.CodeTTL),
+	}
+	if err := s.repo.Create(ctx, verification); err != nil {
+		return nil, fmt.Errorf("failed to store sms verification: %w", err)
+	}
+
+	message := fmt.Sprintf(s.cfg.MessageTemplate, code, int(s.cfg.CodeTTL.Minutes()))
+	if err := s.sender.Send(ctx, phone, message); err != nil {
+		return nil, fmt.Errorf("failed to send sms: %w", err)
+	}
+
+	return &SMSCodeInfo{
+		PhoneNumber: phone,
+		ExpiresAt:   verification.ExpiresAt,
+		ResendAt:    now.Add(s.cfg.ResendCooldown),
+	}, nil
+}
+
+// VerifyCode kiểm tra mã gần nhất đã gửi cho customer. Thành công thì gán Customer.PhoneNumber
+// và tạo liên kết AuthProvider "sms".
+func (s *SMSVerificationService) VerifyCode(ctx context.Context, customerID, phoneNumber, code string) error {
+	phone, err := NormalizePhoneNumber(phoneNumber, s.cfg.DefaultCountryCode)
+	if err != nil {
+		return err
+	}
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ErrSMSCodeRequired
+	}
+
+	verification, err := s.repo.GetLatest(ctx, customerID, phone)
+	if err != nil {
+		return err
+	}
+	if verification == nil || verification.Verified {
+		return ErrSMSCodeInvalid
+	}
+	if time.Now().After(verification.ExpiresAt) {
+		return ErrSMSCodeExpired
+	}
+	if verification.Attempts >= s.cfg.MaxAttempts {
+		return ErrSMSTooManyAttempts
+	}
+
+	if !hmac.Equal([]byte(verification.CodeHash), []byte(s.hashCode(verification.ID, code))) {
+		allowed, err := s.repo.IncrementAttempts(ctx, verification.ID, s.cfg.MaxAttempts)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return ErrSMSTooManyAttempts
+		}
+		return ErrSMSCodeInvalid
+	}
+
+	linkID, err := utils.GenerateUniqueID("auth_provider")
+	if err != nil {
+		return err
+	}
+	link := &models.AuthProvider{
+		ID:             linkID,
+		CustomerID:     customerID,
+		Provider:       SMSProvider,
+		ProviderUserID: &phone,
+	}
+	completed, err := s.repo.CompleteVerification(ctx, verification, link)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPhoneNumberInUse) {
+			return ErrPhoneNumberInUse
+		}
+		return fmt.Errorf("failed to complete sms verification: %w", err)
+	}
+	if !completed {
+		return ErrSMSCodeInvalid
+	}
+	return nil
+}
+
+func (s *SMSVerificationService) generateCode() (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(s.cfg.CodeLength)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate sms code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", s.cfg.CodeLength, n), nil
+}
+
+// hashCode dùng HMAC với ID bản ghi để cùng một mã không cho cùng hash;
+// mã chỉ có 10^6 khả năng nên cần HashKey bí mật để không dò ngược được từ database
+func (s *SMSVerificationService) hashCode(id, code string) string {
+	mac := hmac.New(sha256.New, s.cfg.HashKey)
+	mac.Write([]byte(id + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NormalizePhoneNumber chuẩn hóa số điện thoại về dạng E.164 (+84901234567)
+func NormalizePhoneNumber(phoneNumber, defaultCountryCode string) (string, error) {
+	phone := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(strings.TrimSpace(phoneNumber))
+	if phone == "" {
+		return "", ErrPhoneNumberRequired
+	}
+	switch {
+	case strings.HasPrefix(phone, "00"):
+		phone = "+" + phone[2:]
+	case strings.HasPrefix(phone, "0"):
+		phone = defaultCountryCode + phone[1:]
+	case !strings.HasPrefix(phone, "+"):
+		phone = "+" + phone
+	}
+	digits := phone[1:]
+	if len(digits) < 8 || len(digits) > 15 {
+		return "", ErrInvalidPhoneNumber
+	}
+	for _, ch := range digits {
+		if ch < '0' || ch > '9' {
+			return "", ErrInvalidPhoneNumber
+		}
+	}
+	return phone, nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+)
+
+var testSMSHashKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestNewSMSVerificationService(t *testing.T) {
+	cases := []struct {
+		name    string
+		key     []byte
+		wantErr bool
+	}{
+		{"Empty", nil, true},
+		{"TooShort", []byte("short-key"), true},
+		{"MinLength", testSMSHashKey, false},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			_, err := NewSMSVerificationService(nil, nil, SMSVerificationConfig{HashKey: tc.key})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error=%v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+		err   error
+	}{
+		{"0901234567", "+84901234567", nil},
+		{"090 123-4567", "+84901234567", nil},
+		{"(090).123.4567", "+84901234567", nil},
+		{"+84901234567", "+84901234567", nil},
+		{"0084901234567", "+84901234567", nil},
+		{"84901234567", "+84901234567", nil},
+		{"  ", "", ErrPhoneNumberRequired},
+		{"0123", "", ErrInvalidPhoneNumber},
+		{"+8490123456789012", "", ErrInvalidPhoneNumber},
+		{"09012x4567", "", ErrInvalidPhoneNumber},
+	}
+	for _, tc := range cases {
+		t.Run("TestNormalize_"+tc.input, func(t *testing.T) {
+			got, err := NormalizePhoneNumber(tc.input, "+84")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Expected error %v, got %v", tc.err, err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSMSSendAndVerify(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	if err := db.Create(&models.Customer{ID: "C_sms", Name: "SMS"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	sender := NewLoggingSMSSender()
+	s, err := NewSMSVerificationService(repositories.NewSMSVerificationRepository(db), sender, SMSVerificationConfig{
+		HashKey:         testSMSHashKey,
+		MaxAttempts:     2,
+		ResendCooldown:  time.Hour,
+		MessageTemplate: "%s|%d",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastCode := func() string {
+		messages := sender.Messages()
+		return strings.SplitN(messages[len(messages)-1].Message, "|", 2)[0]
+	}
+
+	info, err := s.SendCode(ctx, "C_sms", "0901234567")
+	if err != nil {
+		t.Fatalf("SendCode: %v", err)
+	}
+	if info.PhoneNumber != "+84901234567" {
+		t.Errorf("Expected normalized phone, got %q", info.PhoneNumber)
+	}
+	if _, err := s.SendCode(ctx, "C_sms", "0901234567"); !errors.Is(err, ErrSMSResendCooldown) {
+		t.Errorf("Expected ErrSMSResendCooldown, got %v", err)
+	}
+
+	code := lastCode()
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	if err := s.VerifyCode(ctx, "C_sms", "0901234567", wrong); !errors.Is(err, ErrSMSCodeInvalid) {
+		t.Errorf("Expected ErrSMSCodeInvalid, got %v", err)
+	}
+	if err := s.VerifyCode(ctx, "C_sms", "0901234567", code); err != nil {
+		t.Fatalf("Expected code to verify, got %v", err)
+	}
+	var customer models.Customer
+	if err := db.First(&customer, "id = ?", "C_sms").Error; err != nil {
+		t.Fatal(err)
+	}
+	if customer.PhoneNumber == nil || *customer.PhoneNumber != "+84901234567" {
+		t.Errorf("Expected phone number saved on customer, got %v", customer.PhoneNumber)
+	}
+	if err := s.VerifyCode(ctx, "C_sms", "0901234567", code); !errors.Is(err, ErrSMSCodeInvalid) {
+		t.Errorf("Expected used code to be rejected, got %v", err)
+	}
+}
@@ -1,26 +1,78 @@
 package services
 
 import (
-	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"context"
+	"errors"
+	"time"
+
 	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+	"gorm.io/gorm"
 )
 
 type SubscriptionService struct {
+	unitOfWork
 	subscriptionRepo *repositories.SubscriptionRepository
 }
 
-func NewSubscriptionService(subscriptionRepo *repositories.SubscriptionRepository) *SubscriptionService {
+// NewSubscriptionService creates a new SubscriptionService instance.
+// tx (thường là repositories.NewUnitOfWork(db)) bắt buộc cho Activate.
+func NewSubscriptionService(subscriptionRepo *repositories.SubscriptionRepository, tx Transactor) *SubscriptionService {
 	return &SubscriptionService{
+		unitOfWork:       unitOfWork{tx: tx},
 		subscriptionRepo: subscriptionRepo,
 	}
 }
 
 // GetAllSubscriptions lấy tất cả subscriptions
-func (s *SubscriptionService) GetAllSubscriptions() ([]models.Subscription, error) {
-	return s.subscriptionRepo.GetAllSubscriptions()
+func (s *SubscriptionService) GetAllSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+	return s.subscriptionRepo.GetAllSubscriptions(ctx)
 }
 
 // GetSubscriptionByID lấy subscription theo ID
-func (s *SubscriptionService) GetSubscriptionByID(id string) (*models.Subscription, error) {
-	return s.subscriptionRepo.GetSubscriptionByID(id)
+func (s *SubscriptionService) GetSubscriptionByID(ctx context.Context, id string) (*models.Subscription, error) {
+	return s.subscriptionRepo.GetSubscriptionByID(ctx, id)
+}
+
+// Activate lưu thanh toán và gói đăng ký của customer trong cùng transaction; lỗi ở bước nào cũng rollback cả hai
+func (s *SubscriptionService) Activate(ctx context.Context, payment *models.Payment, subscription *models.CustomerSubscription) error {
+	return s.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.subscriptionRepo.GetSubscriptionByID(ctx, subscription.SubscriptionID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSubscriptionNotFound
+			}
+			return err
+		}
+
+		if payment.ID == "" {
+			id, err := utils.GenerateUniqueID("payment")
+			if err != nil {
+				return err
+			}
+			payment.ID = id
+		}
+		if payment.CustomerID == "" {
+			payment.CustomerID = subscription.CustomerID
+		}
+		if payment.SubscriptionID == nil {
+			payment.SubscriptionID = &subscription.SubscriptionID
+		}
+		if err := s.subscriptionRepo.CreatePayment(ctx, payment); err != nil {
+			return err
+		}
+
+		if subscription.ID == "" {
+			id, err := utils.GenerateUniqueID("user_subscription")
+			if err != nil {
+				return err
+			}
+			subscription.ID = id
+		}
+		if subscription.StartDate.IsZero() {
+			subscription.StartDate = time.Now()
+		}
+		subscription.PaymentID = &payment.ID
+		return s.subscriptionRepo.CreateCustomerSubscription(ctx, subscription)
+	})
 }
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
+	"gorm.io/gorm"
+)
+
+// AccessTokenIssuer ký access token cho user trong một session.
+// Thường là middleware.GenerateSessionToken đã bind sẵn config.
+type AccessTokenIssuer func(userID, email, sessionID string) (string, error)
+
+// SessionRevoker thu hồi access token đã phát hành (thường là *middleware.TokenRevocationStore)
+type SessionRevoker interface {
+	RevokeSession(ctx context.Context, sessionID, userID, reason string) error
+	RevokeUser(ctx context.Context, userID, reason string) error
+}
+
+// TokenPair là cặp access token và refresh token trả về cho client
+type TokenPair struct {
+	AccessToken           string    `json:"access_token"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// TokenService quản lý vòng đời refresh token: cấp, xoay vòng và thu hồi
+type TokenService struct {
+	unitOfWork
+	refreshRepo   *repositories.RefreshTokenRepository
+	userRepo      *repositories.UserRepository
+	issueAccess   AccessTokenIssuer
+	revoker       SessionRevoker
+	sessions      *repositories.SessionRepository
+	refreshExpiry time.Duration
+}
+
+// SessionView là session kèm cờ đánh dấu session của request hiện tại
+type SessionView struct {
+	models.Session
+	Current bool `json:"current"`
+}
+
+// NewTokenService creates a new TokenService instance.
+// revoker có thể nil nếu không dùng denylist access token.
+func NewTokenService(refreshRepo *repositories.RefreshTokenRepository, userRepo *repositories.UserRepository,
+	issueAccess AccessTokenIssuer, revoker SessionRevoker, refreshExpiry time.Duration) *TokenService {
+	return &TokenService{
+		refreshRepo:   refreshRepo,
+		userRepo:      userRepo,
+		issueAccess:   issueAccess,
+		revoker:       revoker,
+		refreshExpiry: refreshExpiry,
+	}
+}
+
+// WithSessions bật lưu thông tin thiết bị/phiên đăng nhập vào bảng sessions.
+// tx (thường là repositories.NewUnitOfWork(db)) ghi refresh token và session trong cùng transaction.
+func (s *TokenService) WithSessions(sessions *repositories.SessionRepository, tx Transactor) *TokenService {
+	s.sessions = sessions
+	s.unitOfWork = unitOfWork{tx: tx}
+	return s
+}
+
+// IssueTokens cấp cặp token mới khi customer đăng nhập, bắt đầu một token family mới.
+// FamilyID cũng là session ID (claim sid) của các access token trong family.
+func (s *TokenService) IssueTokens(ctx context.Context, customerID, email string, device models.DeviceInfo) (*TokenPair, error) {
+	familyID, err := utils.GenerateUniqueID("refresh_token")
+	if err != nil {
+		return nil, err
+	}
+
+	rawToken, record, err := s.newRefreshToken(customerID, familyID, device.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	// Token đầu tiên của family dùng luôn FamilyID làm ID
+	record.ID = familyID
+
+	if s.sessions == nil {
+		if err := s.refreshRepo.Create(ctx, record); err != nil {
+			return nil, fmt.Errorf("failed to store refresh token: %w", err)
+		}
+	} else {
+		// Refresh token và session ghi cùng transaction để không còn token hợp lệ mà không có session để thu hồi
+		now := time.Now()
+		err := s.WithinTx(ctx, func(ctx context.Context) error {
+			if err := s.refreshRepo.Create(ctx, record); err != nil {
+				return fmt.Errorf("failed to store refresh token: %w", err)
+			}
+			if err := s.sessions.Create(ctx, &models.Session{
+				ID:         familyID,
+				CustomerID: customerID,
+				DeviceID:   device.DeviceID,
+				DeviceName: device.DeviceName,
+				Platform:   device.Platform,
+				AppVersion: device.AppVersion,
+				IPAddress:  device.IPAddress,
+				UserAgent:  device.UserAgent,
+				LastSeenAt: now,
+				CreatedAt:  now,
+			}); err != nil {
+				return fmt.Errorf("failed to store session: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	accessToken, err := s.issueAccess(customerID, email, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue access token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:           accessToken,
+		RefreshToken:          rawToken,
+		RefreshTokenExpiresAt: record.ExpiresAt,
+	}, nil
+}
+
+// Refresh đổi refresh token lấy cặp token mới.
+// Nếu refresh token đã được dùng trước đó, toàn bộ family bị thu hồi vì token có thể đã bị lộ.
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string, device models.DeviceInfo) (*TokenPair, error) {
+	current, err := s.lookup(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if current.UsedAt != nil {
+		if err := s.revokeFamily(ctx, current.FamilyID, current.CustomerID, "refresh token reuse"); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return nil, ErrRefreshTokenExpired
+	}
+	// Token gắn với thiết bị thì request refresh phải mang đúng device_id đó
+	if current.DeviceID != "" && current.DeviceID != device.DeviceID {
+		return nil, ErrRefreshTokenDeviceMismatch
+	}
+
+	user, err := s.userRepo.GetByUserID(ctx, "customers", current.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	rawToken, next, err := s.newRefreshToken(current.CustomerID, current.FamilyID, current.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.refreshRepo.Rotate(ctx, current.ID, next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// Một request khác đã dùng token này trước: coi như reuse
+		if err := s.revokeFamily(ctx, current.FamilyID, current.CustomerID, "refresh token reuse"); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if s.sessions != nil {
+		// Lỗi cập nhật last-seen không làm hỏng lần refresh vì token đã được xoay vòng
+		_ = s.sessions.Touch(ctx, current.FamilyID, current.CustomerID, device, time.Now())
+	}
+
+	accessToken, err := s.issueAccess(user.ID, user.Email, current.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue access token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:           accessToken,
+		RefreshToken:          rawToken,
+		RefreshTokenExpiresAt: next.ExpiresAt,
+	}, nil
+}
+
+// Logout thu hồi toàn bộ family của refresh token cùng các access token của session đó
+func (s *TokenService) Logout(ctx context.Context, refreshToken string) error {
+	current, err := s.lookup(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	return s.revokeFamily(ctx, current.FamilyID, current.CustomerID, "logout")
+}
+
+// LogoutAll thu hồi mọi refresh token và access token của customer trên tất cả thiết bị
+func (s *TokenService) LogoutAll(ctx context.Context, customerID string) error {
+	if err := s.refreshRepo.RevokeAllForCustomer(ctx, customerID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	if s.sessions != nil {
+		if err := s.sessions.MarkAllRevoked(ctx, customerID); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+	if s.revoker != nil {
+		if err := s.revoker.RevokeUser(ctx, customerID, "logout all"); err != nil {
+			return fmt.Errorf("failed to revoke access tokens: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListSessions trả về các session của customer; currentSessionID được đánh dấu Current
+func (s *TokenService) ListSessions(ctx context.Context, customerID, currentSessionID string, includeRevoked bool) ([]SessionView, error) {
+	if s.sessions == nil {
+		return nil, ErrSessionTrackingDisabled
+	}
+	sessions, err := s.sessions.ListByCustomer(ctx, customerID, includeRevoked)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]SessionView, 0, len(sessions))
+	for _, session := range sessions {
+		views = append(views, SessionView{Session: session, Current: session.ID == currentSessionID})
+	}
+	return views, nil
+}
+
+// RevokeSession thu hồi một session của customer (refresh token family và access token của session đó)
+func (s *TokenService) RevokeSession(ctx context.Context, customerID, sessionID string) error {
+	if s.sessions == nil {
+		return ErrSessionTrackingDisabled
+	}
+	session, err := s.sessions.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.CustomerID != customerID {
+		return ErrSessionNotFound
+	}
+	return s.revokeFamily(ctx, session.ID, session.CustomerID, "session revoked")
+}
+
+// RevokeOtherSessions thu hồi mọi session đang hoạt động của customer trừ keepSessionID
+func (s *TokenService) RevokeOtherSessions(ctx context.Context, customerID, keepSessionID string) error {
+	if s.sessions == nil {
+		return ErrSessionTrackingDisabled
+	}
+	sessions, err := s.sessions.ListByCustomer(ctx, customerID, false)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID == keepSessionID {
+			continue
+		}
+		if err := s.revokeFamily(ctx, session.ID, customerID, "session revoked"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TokenService) revokeFamily(ctx context.Context, familyID, customerID, reason string) error {
+	if err := s.refreshRepo.RevokeFamily(ctx, familyID); err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+	if s.sessions != nil {
+		if err := s.sessions.MarkRevoked(ctx, familyID); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+	if s.revoker != nil {
+		if err := s.revoker.RevokeSession(ctx, familyID, customerID, reason); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *TokenService) lookup(ctx context.Context, refreshToken string) (*models.RefreshToken, error) {
+	if refreshToken == "" {
+		return nil, ErrRefreshTokenRequired
+	}
+	token, err := s.refreshRepo.GetByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *TokenService) newRefreshToken(customerID, familyID, deviceID string) (string, *models.RefreshToken, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	rawToken := base64.RawURLEncoding.EncodeToString(buf)
+
+	id, err := utils.GenerateUniqueID("refresh_token")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return rawToken, &models.RefreshToken{
+		ID:         id,
+		CustomerID: customerID,
+		FamilyID:   familyID,
+		DeviceID:   deviceID,
+		TokenHash:  hashRefreshToken(rawToken),
+		ExpiresAt:  time.Now().Add(s.refreshExpiry),
+	}, nil
+}
+
+// hashRefreshToken chỉ lưu SHA-256 của token; token có 256 bit ngẫu nhiên nên không cần salt
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
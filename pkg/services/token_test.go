@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/database/testdb"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+)
+
+func TestRefreshDeviceBinding(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	email := "device@example.com"
+	if err := db.Create(&models.Customer{ID: "C_device", Name: "Device", Email: &email}).Error; err != nil {
+		t.Fatal(err)
+	}
+	issue := func(userID, email, sessionID string) (string, error) { return "access-" + sessionID, nil }
+	s := NewTokenService(repositories.NewRefreshTokenRepository(db), repositories.NewUserRepository(db), issue, nil, time.Hour)
+
+	t.Run("TestBoundToDevice", func(t *testing.T) {
+		pair, err := s.IssueTokens(ctx, "C_device", "device@example.com", models.DeviceInfo{DeviceID: "phone-1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, deviceID := range []string{"", "phone-2"} {
+			if _, err := s.Refresh(ctx, pair.RefreshToken, models.DeviceInfo{DeviceID: deviceID}); !errors.Is(err, ErrRefreshTokenDeviceMismatch) {
+				t.Errorf("Expected ErrRefreshTokenDeviceMismatch for device %q, got %v", deviceID, err)
+			}
+		}
+		// Request bị từ chối không tiêu token nên thiết bị đúng vẫn refresh được
+		if _, err := s.Refresh(ctx, pair.RefreshToken, models.DeviceInfo{DeviceID: "phone-1"}); err != nil {
+			t.Errorf("Expected refresh from the bound device to succeed, got %v", err)
+		}
+	})
+
+	t.Run("TestUnboundToken", func(t *testing.T) {
+		pair, err := s.IssueTokens(ctx, "C_device", "device@example.com", models.DeviceInfo{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Refresh(ctx, pair.RefreshToken, models.DeviceInfo{DeviceID: "phone-3"}); err != nil {
+			t.Errorf("Expected token without device to refresh, got %v", err)
+		}
+	})
+}
+
+func TestSessions(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	for _, id := range []string{"C_owner", "C_other"} {
+		if err := db.Create(&models.Customer{ID: id, Name: id}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	issue := func(userID, email, sessionID string) (string, error) { return "access-" + sessionID, nil }
+	s := NewTokenService(repositories.NewRefreshTokenRepository(db), repositories.NewUserRepository(db), issue, nil, time.Hour).
+		WithSessions(repositories.NewSessionRepository(db), repositories.NewUnitOfWork(db))
+
+	login := func(customerID, deviceID string) string {
+		t.Helper()
+		pair, err := s.IssueTokens(ctx, customerID, "", models.DeviceInfo{DeviceID: deviceID})
+		if err != nil {
+			t.Fatalf("IssueTokens: %v", err)
+		}
+		return strings.TrimPrefix(pair.AccessToken, "access-")
+	}
+	phone := login("C_owner", "phone")
+	tablet := login("C_owner", "tablet")
+	login("C_owner", "laptop")
+	foreign := login("C_other", "phone")
+
+	t.Run("TestUpdatesLastLogin", func(t *testing.T) {
+		var customer models.Customer
+		if err := db.Where("id = ?", "C_owner").First(&customer).Error; err != nil {
+			t.Fatal(err)
+		}
+		if customer.LastLogin == nil || time.Since(*customer.LastLogin) > time.Minute {
+			t.Errorf("Expected last_login to be set on login, got %v", customer.LastLogin)
+		}
+	})
+
+	t.Run("TestList", func(t *testing.T) {
+		views, err := s.ListSessions(ctx, "C_owner", phone, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(views) != 3 {
+			t.Fatalf("Expected 3 sessions of C_owner, got %d", len(views))
+		}
+		for _, view := range views {
+			if view.CustomerID != "C_owner" {
+				t.Errorf("Expected only C_owner sessions, got %s", view.CustomerID)
+			}
+			if view.Current != (view.ID == phone) {
+				t.Errorf("Expected Current only for %s, got %s=%v", phone, view.ID, view.Current)
+			}
+		}
+	})
+
+	t.Run("TestRevokeOneChecksOwner", func(t *testing.T) {
+		if err := s.RevokeSession(ctx, "C_owner", foreign); !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("Expected ErrSessionNotFound for another customer's session, got %v", err)
+		}
+		if err := s.RevokeSession(ctx, "C_owner", tablet); err != nil {
+			t.Fatalf("RevokeSession: %v", err)
+		}
+		views, err := s.ListSessions(ctx, "C_owner", phone, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(views) != 2 {
+			t.Errorf("Expected 2 active sessions after revoking one, got %d", len(views))
+		}
+	})
+
+	t.Run("TestRevokeOthers", func(t *testing.T) {
+		if err := s.RevokeOtherSessions(ctx, "C_owner", phone); err != nil {
+			t.Fatalf("RevokeOtherSessions: %v", err)
+		}
+		views, err := s.ListSessions(ctx, "C_owner", phone, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(views) != 1 || views[0].ID != phone {
+			t.Errorf("Expected only the current session to remain, got %+v", views)
+		}
+		// Session của customer khác không bị ảnh hưởng
+		others, err := s.ListSessions(ctx, "C_other", "", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(others) != 1 || others[0].ID != foreign {
+			t.Errorf("Expected C_other session to stay active, got %+v", others)
+		}
+	})
+
+	t.Run("TestRollbackWhenSessionFails", func(t *testing.T) {
+		// device_name quá dài làm insert session lỗi sau khi refresh token đã được ghi
+		device := models.DeviceInfo{DeviceID: "broken", DeviceName: strings.Repeat("x", 200)}
+		if _, err := s.IssueTokens(ctx, "C_other", "", device); err == nil {
+			t.Fatal("Expected IssueTokens to fail")
+		}
+		var n int64
+		db.Model(&models.RefreshToken{}).Where("customer_id = ? AND device_id = ?", "C_other", "broken").Count(&n)
+		if n != 0 {
+			t.Errorf("Expected refresh token to be rolled back, found %d", n)
+		}
+	})
+}
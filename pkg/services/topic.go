@@ -1,42 +1,309 @@
 package services
 
 import (
+	"context"
+	"sort"
+	"strings"
+
 	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
 	repo "github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"github.com/techmaster-vietnam/dd_goshare/utils"
 )
 
 type TopicService struct {
+	unitOfWork
 	topicRepo  *repo.TopicRepository
 	dialogRepo *repo.DialogRepository
 }
 
-// NewTopicService creates a new TopicService instance
-func NewTopicService(topicRepo *repo.TopicRepository, dialogRepo *repo.DialogRepository) *TopicService {
+// NewTopicService creates a new TopicService instance.
+// tx (thường là repositories.NewUnitOfWork(db)) bắt buộc cho ReorderTopics.
+func NewTopicService(topicRepo *repo.TopicRepository, dialogRepo *repo.DialogRepository, tx Transactor) *TopicService {
 	return &TopicService{
+		unitOfWork: unitOfWork{tx: tx},
 		topicRepo:  topicRepo,
 		dialogRepo: dialogRepo,
 	}
 }
 
 // GetTopic retrieves a topic by ID
-func (s *TopicService) GetTopic(id string) (*models.Topic, error) {
+func (s *TopicService) GetTopic(ctx context.Context, id string) (*models.Topic, error) {
 	if id == "" {
 		return nil, ErrTopicIDRequired
 	}
 
-	return s.topicRepo.GetTopic(id)
+	return s.topicRepo.GetTopic(ctx, id)
 }
 
 // GetAllTopics retrieves all topics
-func (s *TopicService) GetAllTopics() ([]models.Topic, error) {
-	return s.topicRepo.GetAllTopics()
+func (s *TopicService) GetAllTopics(ctx context.Context) ([]models.Topic, error) {
+	return s.topicRepo.GetAllTopics(ctx)
+}
+
+// Số kết quả mặc định và tối đa của mỗi nhóm trong SearchKeyword
+const (
+	DefaultSearchLimit = 10
+	MaxSearchLimit     = 50
+)
+
+// SearchKeyword tìm full-text theo tiêu đề topic, tiêu đề dialog và nội dung dialog (chỉ nội dung public).
+// Từ khoá khớp theo tiền tố và không phân biệt dấu nên dùng được cho gợi ý khi gõ;
+// mỗi nhóm sắp theo độ liên quan và có snippet tô sáng từ khớp.
+func (s *TopicService) SearchKeyword(ctx context.Context, keyword string, limit int) (*models.SearchKeyword, error) {
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	limit = min(limit, MaxSearchLimit)
+
+	var result models.SearchKeyword
+	var err error
+	if result.TopicTitle, err = s.topicRepo.SearchTopics(ctx, keyword, limit); err != nil {
+		return nil, err
+	}
+	if result.DialogTitle, err = s.dialogRepo.SearchDialogTitles(ctx, keyword, limit); err != nil {
+		return nil, err
+	}
+	if result.DialogRawText, err = s.dialogRepo.SearchDialogRawText(ctx, keyword, limit); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SearchTopicList returns dialogs grouped by topic in TitleDialogResponse format (chỉ topic public)
+func (s *TopicService) SearchTopicList(ctx context.Context, title string, tags, levels []string, page, limit int, sort string, asc bool) ([]models.TitleDialogResponse, int64, error) {
+	return s.topicRepo.SearchTopicList(ctx, title, tags, levels, page, limit, sort, asc)
+}
+
+// ListTopics liệt kê topic theo sort_order kèm số dialog, dialog đầu tiên và ảnh bìa;
+// includeHidden là false thì chỉ trả về topic public
+func (s *TopicService) ListTopics(ctx context.Context, includeHidden bool) ([]models.TopicListItem, error) {
+	if includeHidden {
+		return s.topicRepo.ListTopicItems(ctx, nil)
+	}
+	return s.topicRepo.ListTopicItems(ctx, []string{models.TopicVisibilityPublic})
+}
+
+// GetTopicItem trả về topic kèm số dialog, dialog đầu tiên và ảnh bìa;
+// includeHidden là false thì topic private/draft được coi như không tồn tại
+func (s *TopicService) GetTopicItem(ctx context.Context, id string, includeHidden bool) (*models.TopicListItem, error) {
+	if id == "" {
+		return nil, ErrTopicIDRequired
+	}
+	var visibilities []string
+	if !includeHidden {
+		visibilities = []string{models.TopicVisibilityPublic}
+	}
+	item, err := s.topicRepo.GetTopicItem(ctx, id, visibilities)
+	return item, mapTopicNotFound(err)
+}
+
+// CreateTopic tạo topic; mặc định public và xếp cuối danh sách
+func (s *TopicService) CreateTopic(ctx context.Context, req models.CreateTopicRequest) (*models.Topic, error) {
+	topic := &models.Topic{
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+		Difficulty:  req.Difficulty,
+		Visibility:  req.Visibility,
+		LevelID:     levelRef(req.LevelID),
+	}
+	if topic.Visibility == "" {
+		topic.Visibility = models.TopicVisibilityPublic
+	}
+	if err := validateTopic(topic); err != nil {
+		return nil, err
+	}
+	if err := s.ensureTitleAvailable(ctx, topic.Title, ""); err != nil {
+		return nil, err
+	}
+	if err := ensureLevel(ctx, s.topicRepo.LevelExists, req.LevelID); err != nil {
+		return nil, err
+	}
+
+	if req.SortOrder != nil {
+		topic.SortOrder = *req.SortOrder
+	} else {
+		next, err := s.topicRepo.NextSortOrder(ctx)
+		if err != nil {
+			return nil, err
+		}
+		topic.SortOrder = next
+	}
+	id, err := utils.GenerateUniqueID("topic")
+	if err != nil {
+		return nil, err
+	}
+	topic.ID = id
+	if err := s.topicRepo.CreateTopic(ctx, topic); err != nil {
+		return nil, err
+	}
+	return topic, nil
+}
+
+// UpdateTopic cập nhật các trường khác nil của topic
+func (s *TopicService) UpdateTopic(ctx context.Context, id string, req models.UpdateTopicRequest) (*models.Topic, error) {
+	topic, err := s.GetTopic(ctx, id)
+	if err != nil {
+		return nil, mapTopicNotFound(err)
+	}
+
+	fields := map[string]interface{}{}
+	if req.Title != nil {
+		topic.Title = strings.TrimSpace(*req.Title)
+		fields["title"] = topic.Title
+	}
+	if req.Description != nil {
+		topic.Description = *req.Description
+		fields["description"] = topic.Description
+	}
+	if req.Difficulty != nil {
+		topic.Difficulty = *req.Difficulty
+		fields["difficulty"] = topic.Difficulty
+	}
+	if req.Visibility != nil {
+		topic.Visibility = *req.Visibility
+		fields["visibility"] = topic.Visibility
+	}
+	if req.SortOrder != nil {
+		topic.SortOrder = *req.SortOrder
+		fields["sort_order"] = topic.SortOrder
+	}
+	if req.LevelID != nil {
+		if err := ensureLevel(ctx, s.topicRepo.LevelExists, *req.LevelID); err != nil {
+			return nil, err
+		}
+		topic.LevelID = levelRef(*req.LevelID)
+		fields["level_id"] = topic.LevelID
+	}
+	if len(fields) == 0 {
+		return topic, nil
+	}
+	if err := validateTopic(topic); err != nil {
+		return nil, err
+	}
+	if req.Title != nil {
+		if err := s.ensureTitleAvailable(ctx, topic.Title, id); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.topicRepo.UpdateTopicFields(ctx, id, fields); err != nil {
+		return nil, mapTopicNotFound(err)
+	}
+	return s.GetTopic(ctx, id)
+}
+
+// DeleteTopic chuyển topic cùng dialog, ảnh và comment của nó vào thùng rác
+func (s *TopicService) DeleteTopic(ctx context.Context, id string) error {
+	if id == "" {
+		return ErrTopicIDRequired
+	}
+	return mapTopicNotFound(s.topicRepo.SoftDeleteTopic(ctx, id))
+}
+
+// ReorderTopics sắp xếp lại các topic theo thứ tự ids. Các topic được đổi chỗ trong chính các vị trí
+// (sort_order) chúng đang chiếm nên có thể sắp lại một phần danh sách mà không ảnh hưởng topic khác.
+func (s *TopicService) ReorderTopics(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return ErrTopicReorderInvalid
+	}
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			return ErrTopicReorderInvalid
+		}
+		seen[id] = true
+	}
+
+	return s.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.topicRepo.GetSortOrders(ctx, ids)
+		if err != nil {
+			return err
+		}
+		if len(current) != len(ids) {
+			return ErrTopicNotFound
+		}
+		slots := make([]int, 0, len(ids))
+		for _, order := range current {
+			slots = append(slots, order)
+		}
+		sort.Ints(slots)
+		// Vị trí trùng nhau (ví dụ cùng giá trị mặc định 0) được giãn ra để thứ tự mới có hiệu lực
+		for i := 1; i < len(slots); i++ {
+			if slots[i] <= slots[i-1] {
+				slots[i] = slots[i-1] + 1
+			}
+		}
+		orders := make(map[string]int, len(ids))
+		for i, id := range ids {
+			if current[id] != slots[i] {
+				orders[id] = slots[i]
+			}
+		}
+		return s.topicRepo.UpdateSortOrders(ctx, orders)
+	})
+}
+
+// SetCoverImage đặt ảnh bìa của topic (image có topic_id, không thuộc dialog); ảnh bìa cũ được thay URL
+func (s *TopicService) SetCoverImage(ctx context.Context, topicID, fileURL, authorID string) (*models.Image, error) {
+	if fileURL == "" {
+		return nil, ErrTopicCoverURLRequired
+	}
+	if _, err := s.GetTopic(ctx, topicID); err != nil {
+		return nil, mapTopicNotFound(err)
+	}
+	cover, err := s.topicRepo.GetCoverImage(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	if cover == nil {
+		id, err := utils.GenerateUniqueID("image")
+		if err != nil {
+			return nil, err
+		}
+		cover = &models.Image{ID: id, TopicID: &topicID, AuthorID: authorID}
+	}
+	cover.FileURL = fileURL
+	if err := s.topicRepo.SaveImage(ctx, cover); err != nil {
+		return nil, err
+	}
+	return cover, nil
+}
+
+// RemoveCoverImage chuyển ảnh bìa của topic vào thùng rác
+func (s *TopicService) RemoveCoverImage(ctx context.Context, topicID string) error {
+	if topicID == "" {
+		return ErrTopicIDRequired
+	}
+	cover, err := s.topicRepo.GetCoverImage(ctx, topicID)
+	if err != nil {
+		return err
+	}
+	if cover == nil {
+		return ErrTopicCoverNotFound
+	}
+	return s.topicRepo.SoftDeleteImage(ctx, cover.ID)
 }
 
-func (s *TopicService) SearchKeyword(keyword string) ([]models.Topic, error) {
-	return s.topicRepo.GetTopicByKeyword(keyword)
+func (s *TopicService) ensureTitleAvailable(ctx context.Context, title, excludeID string) error {
+	exists, err := s.topicRepo.TopicTitleExists(ctx, title, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrTopicExists
+	}
+	return nil
 }
 
-// SearchTopicList returns dialogs grouped by topic in TitleDialogResponse format
-func (s *TopicService) SearchTopicList(title string, tags []string, page, limit int, sort string, asc bool) ([]models.TitleDialogResponse, int64, error) {
-	return s.topicRepo.SearchTopicList(title, tags, page, limit, sort, asc)
+func validateTopic(topic *models.Topic) error {
+	if topic.Title == "" {
+		return ErrTopicNameRequired
+	}
+	if topic.Difficulty < 0 || topic.Difficulty > models.MaxTopicDifficulty {
+		return ErrTopicDifficultyInvalid
+	}
+	switch topic.Visibility {
+	case models.TopicVisibilityPublic, models.TopicVisibilityPrivate, models.TopicVisibilityDraft:
+		return nil
+	}
+	return ErrTopicVisibilityInvalid
 }
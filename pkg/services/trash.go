@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/repositories"
+	"gorm.io/gorm"
+)
+
+// DefaultTrashRetention là thời gian giữ bản ghi trong thùng rác trước khi purge
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashService xoá mềm, khôi phục và purge topic, dialog, comment, tag, image
+type TrashService struct {
+	trashRepo *repositories.TrashRepository
+	retention time.Duration
+}
+
+// NewTrashService creates a new TrashService; retention <= 0 dùng DefaultTrashRetention
+func NewTrashService(trashRepo *repositories.TrashRepository, retention time.Duration) *TrashService {
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+	return &TrashService{
+		trashRepo: trashRepo,
+		retention: retention,
+	}
+}
+
+// SoftDelete chuyển bản ghi và cây con của nó vào thùng rác
+func (s *TrashService) SoftDelete(ctx context.Context, kind models.TrashKind, id string) (models.TrashChanges, error) {
+	if err := validateTrashTarget(kind, id); err != nil {
+		return nil, err
+	}
+	changes, err := s.trashRepo.SoftDelete(ctx, kind, id)
+	return changes, mapTrashError(err)
+}
+
+// Restore đưa bản ghi và các bản ghi con bị xoá cùng nó ra khỏi thùng rác
+func (s *TrashService) Restore(ctx context.Context, kind models.TrashKind, id string) (models.TrashChanges, error) {
+	if err := validateTrashTarget(kind, id); err != nil {
+		return nil, err
+	}
+	changes, err := s.trashRepo.Restore(ctx, kind, id)
+	return changes, mapTrashError(err)
+}
+
+// ListTrash liệt kê thùng rác; kind rỗng là mọi loại
+func (s *TrashService) ListTrash(ctx context.Context, kind models.TrashKind, page, limit int) ([]models.TrashItem, int64, error) {
+	if kind != "" && !kind.Valid() {
+		return nil, 0, ErrTrashKindInvalid
+	}
+	return s.trashRepo.ListTrash(ctx, kind, page, limit)
+}
+
+// Purge xoá hẳn bản ghi nằm trong thùng rác lâu hơn thời gian giữ
+func (s *TrashService) Purge(ctx context.Context) (models.TrashChanges, error) {
+	return s.trashRepo.Purge(ctx, time.Now().Add(-s.retention))
+}
+
+// StartPurgeJob chạy Purge định kỳ cho tới khi ctx bị hủy
+func (s *TrashService) StartPurgeJob(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				changes, err := s.Purge(ctx)
+				if err != nil {
+					log.Printf("trash: purge failed: %v", err)
+					continue
+				}
+				for kind, n := range changes {
+					if n > 0 {
+						log.Printf("trash: purged %d %s(s)", n, kind)
+					}
+				}
+			}
+		}
+	}()
+}
+
+func validateTrashTarget(kind models.TrashKind, id string) error {
+	if !kind.Valid() {
+		return ErrTrashKindInvalid
+	}
+	if id == "" {
+		return ErrTrashIDRequired
+	}
+	return nil
+}
+
+func mapTrashError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrTrashItemNotFound
+	case errors.Is(err, repositories.ErrTrashParentDeleted):
+		return ErrTrashParentDeleted
+	case errors.Is(err, repositories.ErrTrashNameConflict):
+		return ErrTrashNameConflict
+	case errors.Is(err, repositories.ErrTrashKindInvalid):
+		return ErrTrashKindInvalid
+	}
+	return err
+}
@@ -0,0 +1,21 @@
+package services
+
+import "context"
+
+// Transactor chạy fn trong một transaction mang theo ctx; *repositories.UnitOfWork thỏa mãn interface này
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// unitOfWork được nhúng vào service để cung cấp WithinTx
+type unitOfWork struct {
+	tx Transactor
+}
+
+// WithinTx chạy fn trong transaction; các repository gọi với ctx của fn dùng chung transaction đó
+func (u unitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if u.tx == nil {
+		return ErrUnitOfWorkNotConfigured
+	}
+	return u.tx.WithinTx(ctx, fn)
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type ctxKey struct{}
+
+// fakeTransactor ghi nhận số lần WithinTx được gọi và gắn một giá trị vào ctx như transaction thật
+type fakeTransactor struct {
+	calls int
+}
+
+func (f *fakeTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	return fn(context.WithValue(ctx, ctxKey{}, "tx"))
+}
+
+func TestUnitOfWork(t *testing.T) {
+	t.Run("TestNotConfigured", func(t *testing.T) {
+		called := false
+		err := unitOfWork{}.WithinTx(context.Background(), func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+		if !errors.Is(err, ErrUnitOfWorkNotConfigured) || called {
+			t.Errorf("Expected ErrUnitOfWorkNotConfigured without calling fn, got %v (called=%v)", err, called)
+		}
+	})
+
+	t.Run("TestConstructorsInjectTransactor", func(t *testing.T) {
+		tx := &fakeTransactor{}
+		services := map[string]interface {
+			WithinTx(context.Context, func(context.Context) error) error
+		}{
+			"dialog":       NewDialogService(nil, tx),
+			"topic":        NewTopicService(nil, nil, tx),
+			"subscription": NewSubscriptionService(nil, tx),
+		}
+		for name, s := range services {
+			var got interface{}
+			err := s.WithinTx(context.Background(), func(ctx context.Context) error {
+				got = ctx.Value(ctxKey{})
+				return nil
+			})
+			if err != nil || got != "tx" {
+				t.Errorf("Expected %s service to run fn inside the injected transactor, got %v, %v", name, got, err)
+			}
+		}
+		if tx.calls != len(services) {
+			t.Errorf("Expected %d transactor calls, got %d", len(services), tx.calls)
+		}
+	})
+
+	t.Run("TestPropagatesError", func(t *testing.T) {
+		want := errors.New("boom")
+		err := NewTopicService(nil, nil, &fakeTransactor{}).WithinTx(context.Background(), func(ctx context.Context) error {
+			return want
+		})
+		if !errors.Is(err, want) {
+			t.Errorf("Expected fn error, got %v", err)
+		}
+	})
+}
@@ -39,6 +39,7 @@ When a route path changes in code (e.g., /api/user -> /api/users):
 */
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -91,6 +92,7 @@ func RegisterRulesToDB() error {
 			IsPrivate:  route.IsPrivate,
 			Service:    config.Service,
 			AccessType: route.AccessType, // ✅ Thêm access_type từ code
+			RequireMFA: route.RequireMFA,
 		}
 		rules = append(rules, rule)
 	}
@@ -150,6 +152,10 @@ func RegisterRulesToDB() error {
 				"is_private": rule.IsPrivate,
 				// NOTE: Do NOT update access_type - preserve user customizations
 			}
+			// Code chỉ được bật require_mfa, không tắt cấu hình admin đã bật trong DB
+			if rule.RequireMFA {
+				updates["require_mfa"] = true
+			}
 			if err := db.Model(&existingRule).Updates(updates).Error; err != nil {
 				return fmt.Errorf("failed to update rule %s %s: %w", rule.Method, rule.Path, err)
 			}
@@ -824,6 +830,25 @@ func ReloadRoles() error {
 	return nil
 }
 
+// ErrRuleNotFound trả về khi rule ID không tồn tại
+var ErrRuleNotFound = errors.New("rule not found")
+
+// SetRuleRequireMFA bật/tắt yêu cầu 2FA cho rule và reload rules vào bộ nhớ
+func SetRuleRequireMFA(ruleID int, require bool) error {
+	db := GetDB()
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	result := db.Model(&models.Rule{}).Where("id = ?", ruleID).Update("require_mfa", require)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update rule %d: %w", ruleID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("rule %d: %w", ruleID, ErrRuleNotFound)
+	}
+	return ReloadRules()
+}
+
 // GetRouteInfo returns route information for debugging
 func GetRouteInfo(path, method string) (Route, bool) {
 	routeKey := method + " " + path
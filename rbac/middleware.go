@@ -39,6 +39,10 @@ func CheckPermissionMiddleware() fiber.Handler {
 						"error":   "Bạn chưa đăng nhập",
 					})
 				}
+				// Đã đăng nhập, route nhạy cảm yêu cầu session đã xác thực 2FA
+				if registeredRoute.RequireMFA && !hasMFA(c) {
+					return mfaRequiredJSON(c)
+				}
 				// Đã đăng nhập, tiếp tục kiểm tra access_type
 			} else {
 				// Public: ai cũng truy cập, không cần đăng nhập
@@ -128,6 +132,10 @@ func checkDatabaseBasedAccess(c *fiber.Ctx, route string, method string) error {
 
 	// Check admin privilege first (admin bypasses all checks)
 	if checkAdmin(userRoles) {
+		// Admin bỏ qua phân quyền nhưng không bỏ qua yêu cầu 2FA
+		if !hasMFA(c) && dbRuleRequiresMFA(c, route, method) {
+			return mfaRequiredJSON(c)
+		}
 		log.Printf("DEBUG RBAC: Admin user, allowing access")
 		return c.Next()
 	}
@@ -145,9 +153,10 @@ func checkDatabaseBasedAccess(c *fiber.Ctx, route string, method string) error {
 		ID         int
 		IsPrivate  bool
 		AccessType int
+		RequireMFA bool
 	}
 	log.Printf("DEBUG RBAC: Querying DB for rule with path='%s', method='%s'", route, method)
-	err := db.Table("rules").Select("id, is_private, access_type").Where("path = ? AND method = ?", route, method).First(&rule).Error
+	err := db.Table("rules").Select("id, is_private, access_type, require_mfa").Where("path = ? AND method = ?", route, method).First(&rule).Error
 	if err != nil {
 		log.Printf("DEBUG RBAC: Rule not found for %s %s (path='%s', method='%s')", method, route, route, method)
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -160,6 +169,9 @@ func checkDatabaseBasedAccess(c *fiber.Ctx, route string, method string) error {
 		log.Printf("DEBUG RBAC: Route is public in DB, allow access")
 		return c.Next()
 	}
+	if rule.RequireMFA && !hasMFA(c) {
+		return mfaRequiredJSON(c)
+	}
 
 	// Không còn allow_all/forbid_all trong v2.0
 
@@ -172,10 +184,100 @@ func checkDatabaseBasedAccess(c *fiber.Ctx, route string, method string) error {
 	})
 }
 
+// hasMFA kiểm tra token của request đã qua xác thực 2FA (claim mfa)
+func hasMFA(c *fiber.Ctx) bool {
+	principal, ok := c.Locals(pmodel.PrincipalLocalsKey).(*pmodel.Principal)
+	return ok && principal != nil && principal.MFA
+}
+
+// dbRuleRequiresMFA đọc cờ require_mfa của rule trong DB cho route chưa nạp vào bộ nhớ
+func dbRuleRequiresMFA(c *fiber.Ctx, route, method string) bool {
+	db, ok := c.Locals("db").(*gorm.DB)
+	if !ok || db == nil {
+		return false
+	}
+	var requireMFA bool
+	db.Table("rules").Select("require_mfa").Where("path = ? AND method = ?", route, method).Scan(&requireMFA)
+	return requireMFA
+}
+
+func mfaRequiredJSON(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success":      false,
+		"error":        "Tác vụ này yêu cầu xác thực hai lớp (2FA)",
+		"mfa_required": true,
+	})
+}
+
+// RequireRoles chỉ cho qua request mà principal đã xác thực có một trong các role (theo tên).
+// Dùng cho route không đăng ký qua helper của rbac (ví dụ /metrics); không đọc header X-Roles.
+func RequireRoles(names ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userRoles := lookupUserRoles(c)
+		if len(userRoles) == 0 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Bạn chưa đăng nhập",
+			})
+		}
+		for _, name := range names {
+			if id, ok := Roles[strings.ToLower(name)]; ok && userRoles[id] {
+				return c.Next()
+			}
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Bạn không có quyền truy cập route này",
+		})
+	}
+}
+
 // // Dummy các hàm dưới đây, bạn cần triển khai thực tế
 func getUserRolesFromContext(c *fiber.Ctx) map[int]bool {
+	userRoles := lookupUserRoles(c)
+	// Nếu chưa có thì fallback sang header (cho test hoặc trường hợp đặc biệt).
+	// Request đã xác thực không bao giờ dùng header: principal không có role thì coi như không có quyền.
+	if len(userRoles) == 0 && !authenticated(c) {
+		if hdr := c.Get("X-Roles"); hdr != "" {
+			for _, r := range strings.Split(hdr, ",") {
+				if t := strings.TrimSpace(r); t != "" {
+					if id, err := strconv.Atoi(t); err == nil {
+						userRoles[id] = true
+					}
+				}
+			}
+		}
+	}
+	return userRoles
+}
+
+// authenticated cho biết request đã có principal hoặc user_id do middleware xác thực đặt
+func authenticated(c *fiber.Ctx) bool {
+	if principal, ok := c.Locals(pmodel.PrincipalLocalsKey).(*pmodel.Principal); ok && principal != nil {
+		return true
+	}
+	userID, _ := c.Locals("user_id").(string)
+	return userID != ""
+}
+
+// lookupUserRoles trả về role ID của principal: role gắn với API key, hoặc tra bảng user_roles
+func lookupUserRoles(c *fiber.Ctx) map[int]bool {
 	userRoles := make(map[int]bool)
-	userId, _ := c.Locals("user_id").(string)
+	var userId string
+	if principal, ok := c.Locals(pmodel.PrincipalLocalsKey).(*pmodel.Principal); ok && principal != nil {
+		// API key mang sẵn các role được gắn khi tạo key, không tra user_roles
+		if principal.TokenType == pmodel.TokenTypeAPIKey {
+			for _, name := range principal.Roles {
+				if id, exists := Roles[strings.ToLower(name)]; exists {
+					userRoles[id] = true
+				}
+			}
+			return userRoles
+		}
+		userId = principal.ID
+	} else {
+		userId, _ = c.Locals("user_id").(string)
+	}
 	if userId != "" {
 		db, ok := c.Locals("db").(*gorm.DB)
 		if ok && db != nil {
@@ -188,18 +290,6 @@ func getUserRolesFromContext(c *fiber.Ctx) map[int]bool {
 			}
 		}
 	}
-	// Nếu chưa có thì fallback sang header (cho test hoặc trường hợp đặc biệt)
-	if len(userRoles) == 0 {
-		if hdr := c.Get("X-Roles"); hdr != "" {
-			for _, r := range strings.Split(hdr, ",") {
-				if t := strings.TrimSpace(r); t != "" {
-					if id, err := strconv.Atoi(t); err == nil {
-						userRoles[id] = true
-					}
-				}
-			}
-		}
-	}
 	return userRoles
 }
 
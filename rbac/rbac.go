@@ -60,6 +60,7 @@ type Route struct {
 	IsPrivate  bool
 	Roles      pmodel.Roles
 	AccessType int
+	RequireMFA bool // bắt buộc token có claim mfa
 }
 
 // Cấu trúc dùng để lưu thông tin của một rule
@@ -70,6 +71,7 @@ type Rule struct {
 	Name       string
 	AccessType int
 	Service    string
+	RequireMFA bool
 }
 
 // InitRBAC khởi tạo hệ thống RBAC với cấu hình
@@ -140,12 +142,13 @@ func LoadRulesFromDB() error {
 		IsPrivate  bool   `json:"is_private"`
 		Service    string `json:"service"`
 		AccessType int    `json:"access_type"`
+		RequireMFA bool   `json:"require_mfa"`
 		RoleIDs    string `json:"role_ids"`
 	}
 
 	var rules []RuleWithRoles
 	query := `
-	SELECT r.id, r.path, r.method, r.is_private, r.service, r.access_type,
+	SELECT r.id, r.path, r.method, r.is_private, r.service, r.access_type, r.require_mfa,
        STRING_AGG(rr.role_id::text, ',') as role_ids
 	FROM rules r
 	LEFT JOIN rule_roles rr ON r.id = rr.rule_id
@@ -167,6 +170,7 @@ func LoadRulesFromDB() error {
 			Method:     strings.ToUpper(rule.Method),
 			IsPrivate:  rule.IsPrivate,
 			AccessType: rule.AccessType,
+			RequireMFA: rule.RequireMFA,
 			Roles:      make(pmodel.Roles),
 		}
 
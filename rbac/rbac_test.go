@@ -1,9 +1,13 @@
 package rbac
 
 import (
+	"io"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
 )
 
 func TestRBACFunctions(t *testing.T) {
@@ -106,3 +110,96 @@ func TestRoleExpCombination(t *testing.T) {
 	})
 
 }
+
+func TestRequireRoles(t *testing.T) {
+	Roles = map[string]int{"admin": 1, "user": 3}
+
+	newApp := func(principal *pmodel.Principal) *fiber.App {
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			if principal != nil {
+				c.Locals(pmodel.PrincipalLocalsKey, principal)
+			}
+			return c.Next()
+		})
+		app.Get("/metrics", RequireRoles("Admin"), func(c *fiber.Ctx) error {
+			return c.SendString("ok")
+		})
+		return app
+	}
+	apiKey := func(roles ...string) *pmodel.Principal {
+		return &pmodel.Principal{ID: "key", TokenType: pmodel.TokenTypeAPIKey, Roles: roles}
+	}
+
+	cases := []struct {
+		name      string
+		principal *pmodel.Principal
+		header    string
+		want      int
+	}{
+		{"NoPrincipal", nil, "", fiber.StatusUnauthorized},
+		{"HeaderIgnored", nil, "1", fiber.StatusUnauthorized},
+		{"WrongRole", apiKey("user"), "1", fiber.StatusForbidden},
+		{"MatchingRole", apiKey("admin"), "", fiber.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/metrics", nil)
+			if tc.header != "" {
+				req.Header.Set("X-Roles", tc.header)
+			}
+			resp, err := newApp(tc.principal).Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tc.want {
+				t.Errorf("Expected status %d, got %d", tc.want, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRolesHeaderFallback(t *testing.T) {
+	Roles = map[string]int{"admin": 1, "guest": 4}
+
+	newApp := func(principal *pmodel.Principal) *fiber.App {
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			if principal != nil {
+				c.Locals(pmodel.PrincipalLocalsKey, principal)
+			}
+			return c.Next()
+		})
+		app.Get("/roles", func(c *fiber.Ctx) error {
+			if getUserRolesFromContext(c)[1] {
+				return c.SendString("admin")
+			}
+			return c.SendString("none")
+		})
+		return app
+	}
+
+	cases := []struct {
+		name      string
+		principal *pmodel.Principal
+		want      string
+	}{
+		{"Anonymous", nil, "admin"},
+		{"PrincipalWithoutRoles", &pmodel.Principal{ID: "key", TokenType: pmodel.TokenTypeAPIKey}, "none"},
+		{"PrincipalWithRoles", &pmodel.Principal{ID: "key", TokenType: pmodel.TokenTypeAPIKey, Roles: []string{"guest"}}, "none"},
+	}
+	for _, tc := range cases {
+		t.Run("Test"+tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/roles", nil)
+			req.Header.Set("X-Roles", "1")
+			resp, err := newApp(tc.principal).Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, body)
+			}
+		})
+	}
+}
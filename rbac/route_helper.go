@@ -32,6 +32,27 @@ func assignRoles(route Route) {
 	}
 }
 
+// RequireMFA đánh dấu route đã đăng ký chỉ cho phép token đã xác thực 2FA (claim mfa).
+// Gọi sau rbac.Get/Post/...: rbac.RequireMFA(api, "DELETE", "/roles/:id")
+func RequireMFA(group fiber.Router, method, path string) {
+	re, _ := regexp.Compile("/+")
+	fullPath := re.ReplaceAllLiteralString(getFullPath(group, path), "/")
+	routeKey := strings.ToUpper(method) + " " + fullPath
+
+	if route, ok := routesRoles[routeKey]; ok {
+		route.RequireMFA = true
+		routesRoles[routeKey] = route
+	}
+	if route, ok := freshRoutes[routeKey]; ok {
+		route.RequireMFA = true
+		freshRoutes[routeKey] = route
+	}
+	if route, ok := pathsRoles[fullPath]; ok && route.Method == strings.ToUpper(method) {
+		route.RequireMFA = true
+		pathsRoles[fullPath] = route
+	}
+}
+
 func getFullPath(_ fiber.Router, path string) string {
 	if !strings.HasPrefix(path, "/api") && strings.HasPrefix(path, "/") {
 		return "/api" + path
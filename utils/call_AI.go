@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/goccy/go-json"
+	"github.com/techmaster-vietnam/dd_goshare/config"
 	"github.com/techmaster-vietnam/dd_goshare/pkg/models"
 )
 
@@ -18,7 +19,7 @@ import (
 // userMessage: Tin nhắn người dùng
 
 func CallAI(systemMessage, userMessage string) (string, error) {
-	apiKey := os.Getenv("API_KEY")
+	apiKey := config.GetEnv("API_KEY", "")
 	apiURL := os.Getenv("API_URL")
 
 	// Tạo request body
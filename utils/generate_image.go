@@ -10,6 +10,7 @@ import (
 	"os"
 
 	"github.com/goccy/go-json"
+	"github.com/techmaster-vietnam/dd_goshare/config"
 )
 
 // ImageRequest cấu trúc request để tạo ảnh
@@ -38,7 +39,7 @@ type ImageResponse struct {
 // size: Kích thước ảnh (mặc định là "640x360")
 // responseFormat: Định dạng response ("url" hoặc "b64_json")
 func GenerateImage(prompt string, n int, size string, responseFormat string) ([]string, error) {
-	apiKey := os.Getenv("API_KEY")
+	apiKey := config.GetEnv("API_KEY", "")
 	apiURL := os.Getenv("GEN_IMAGE_URL")
 
 	if n <= 0 {
@@ -101,7 +102,7 @@ func GenerateImage(prompt string, n int, size string, responseFormat string) ([]
 	return base64Images, nil
 }
 func EditImage(imagePath string, prompt string) ([]string, error) {
-	apiKey := os.Getenv("API_KEY")
+	apiKey := config.GetEnv("API_KEY", "")
 	apiURL := os.Getenv("GEN_IMAGE_URL")
 
 	// Tạo form-data
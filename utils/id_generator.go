@@ -85,6 +85,22 @@ func normalizePrefix(contentType string) string {
 		return "U"
 	case "auth", "auth_provider", "auth_providers":
 		return "P"
+	case "r", "refresh_token", "refresh_tokens":
+		return "R"
+	case "v", "sms", "sms_verification", "sms_verifications":
+		return "V"
+	case "x", "password_reset", "password_reset_token", "password_reset_tokens":
+		return "X"
+	case "m", "mfa", "mfa_factor", "mfa_factors", "mfa_recovery_code", "mfa_recovery_codes":
+		return "M"
+	case "k", "api_key", "api_keys":
+		return "K"
+	case "g", "tag", "tags":
+		return "G"
+	case "h", "achievement", "achievements":
+		return "H"
+	case "y", "payment", "payments":
+		return "Y"
 	default:
 		// Nếu người dùng truyền prefix 1 ký tự chữ cái, tôn trọng nó
 		if len(ct) == 1 {
@@ -1,53 +1,21 @@
 package utils
 
 import (
-	"log"
-	"strings"
-
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/techmaster-vietnam/dd_goshare/config"
+	"github.com/techmaster-vietnam/dd_goshare/pkg/pmodel"
 )
 
-// extractUserIDFromToken extracts user_id from JWT token
+// ExtractUserIDFromToken trả về user ID của request đã được xác thực, "" nếu là khách.
+//
+// Deprecated: dùng middleware.GetPrincipal. Hàm này không còn tự parse JWT mà đọc
+// principal do middleware.Authenticator lưu vào context, nên route công khai cần
+// gắn Authenticator.Optional() để nhận diện user đã đăng nhập.
 func ExtractUserIDFromToken(c *fiber.Ctx) string {
-	authHeader := c.Get("Authorization")
-	if authHeader == "" {
-		return ""
+	if principal, ok := c.Locals(pmodel.PrincipalLocalsKey).(*pmodel.Principal); ok && principal != nil {
+		return principal.ID
 	}
-
-	// Remove "Bearer " prefix
-	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-	if tokenString == authHeader {
-		return "" // No Bearer prefix found
+	if userID, ok := c.Locals("user_id").(string); ok {
+		return userID
 	}
-
-	// Load config to get JWT secret
-	cfg := config.LoadAllConfigs()
-
-	// Parse JWT token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		// Return secret key from config
-		return []byte(cfg.JWT.Secret), nil
-	})
-
-	if err != nil || !token.Valid {
-		log.Printf("DEBUG: JWT parse error: %v", err)
-		return ""
-	}
-
-	// Extract user_id from claims
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		if userID, exists := claims["user_id"]; exists {
-			if userIDStr, ok := userID.(string); ok {
-				return userIDStr
-			}
-		}
-	}
-
 	return ""
-}
\ No newline at end of file
+}
@@ -8,13 +8,24 @@ import (
 	"path/filepath"
 )
 
+// MFADictionaryFile là file từ điển MFA cần có trong DataDir
+const MFADictionaryFile = "english_us_mfa.dict"
+
+// MFAAcousticModelFile là acoustic model MFA cần có trong DataDir
+const MFAAcousticModelFile = "english_mfa.zip"
+
+// DataDir trả về thư mục data của pipeline media (DATA_DIR, mặc định ../data)
+func DataDir() string {
+	if dataDir := os.Getenv("DATA_DIR"); dataDir != "" {
+		return dataDir
+	}
+	return "../data"
+}
+
 // RunMFAAlignment chạy lệnh MFA align từ thư mục data
 func RunMFAAlignment() error {
-	dataDir := os.Getenv("DATA_DIR")
-	if dataDir == "" {
-		dataDir = "../data"
-	}
-	dictPath := filepath.Join(dataDir, "english_us_mfa.dict")
+	dataDir := DataDir()
+	dictPath := filepath.Join(dataDir, MFADictionaryFile)
 
 	// Log thư mục làm việc thực tế
 	wd, _ := os.Getwd()
@@ -28,8 +39,8 @@ func RunMFAAlignment() error {
 
 	cmd := exec.Command("mfa", "align", "--clean", "--use_mp", "--output_format", "json",
 		"corpus",
-		"english_us_mfa.dict",
-		"english_mfa.zip",
+		MFADictionaryFile,
+		MFAAcousticModelFile,
 		"output")
 	cmd.Dir = dataDir
 
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Tham số nhỏ để test chạy nhanh
+var testArgon2Params = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestPasswordHasherArgon2(t *testing.T) {
+	hasher := NewPasswordHasher(testArgon2Params)
+	hash, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+
+	if ok, rehash, err := hasher.Verify("correct horse", hash); err != nil || !ok || rehash {
+		t.Errorf("expected valid password without rehash, got ok=%v rehash=%v err=%v", ok, rehash, err)
+	}
+	if ok, _, _ := hasher.Verify("wrong", hash); ok {
+		t.Error("expected wrong password to fail")
+	}
+
+	// Đổi tham số thì hash cũ cần rehash
+	stronger := NewPasswordHasher(Argon2Params{Memory: 2048, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	if ok, rehash, _ := stronger.Verify("correct horse", hash); !ok || !rehash {
+		t.Errorf("expected rehash after parameter change, got ok=%v rehash=%v", ok, rehash)
+	}
+}
+
+func TestPasswordHasherBcrypt(t *testing.T) {
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	hasher := NewPasswordHasher(testArgon2Params)
+
+	if ok, rehash, err := hasher.Verify("secret123", string(legacy)); err != nil || !ok || !rehash {
+		t.Errorf("expected bcrypt hash to verify and need rehash, got ok=%v rehash=%v err=%v", ok, rehash, err)
+	}
+	if ok, _, _ := hasher.Verify("nope", string(legacy)); ok {
+		t.Error("expected wrong password to fail")
+	}
+	if _, _, err := hasher.Verify("x", "plaintext"); err != ErrUnsupportedPasswordHash {
+		t.Errorf("expected ErrUnsupportedPasswordHash, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// Vector SHA-1 trong RFC 6238, lấy 6 chữ số cuối
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		got, err := TOTPCode(secret, TOTPStep(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Errorf("at %d: expected %s, got %s", unix, want, got)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	previous, _ := TOTPCode(secret, TOTPStep(now)-1)
+
+	if ok, step, _ := ValidateTOTP(secret, previous, now, 1); !ok || step != TOTPStep(now)-1 {
+		t.Errorf("expected previous step code to be accepted with skew 1, got ok=%v step=%d", ok, step)
+	}
+	if ok, _, _ := ValidateTOTP(secret, previous, now, 0); ok {
+		t.Error("expected previous step code to be rejected without skew")
+	}
+}